@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/jagadeesh/grainlify/backend/internal/api"
+	"github.com/jagadeesh/grainlify/backend/internal/broadcast"
 	"github.com/jagadeesh/grainlify/backend/internal/bus"
 	"github.com/jagadeesh/grainlify/backend/internal/bus/natsbus"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
@@ -74,6 +75,7 @@ func main() {
 		slog.Info("db connection successful", "step", "4.3", "action", "db_connection_successful",
 			"max_conns", 10,
 		)
+		d.SetSlowQueryThreshold(cfg.DBSlowQueryThreshold)
 		database = d
 		defer func() {
 			slog.Info("closing database connection")
@@ -135,7 +137,11 @@ func main() {
 	}
 
 	slog.Info("initializing api", "step", "7", "action", "initializing_api")
-	app := api.New(cfg, api.Deps{DB: database, Bus: eventBus})
+	// Sync completions refresh the leaderboard; appCtx ends open streams before shutdown.
+	syncCompleted := broadcast.NewSignal()
+	appCtx, stopApp := context.WithCancel(context.Background())
+	defer stopApp()
+	app := api.New(cfg, api.Deps{DB: database, Bus: eventBus, SyncCompleted: syncCompleted, Ctx: appCtx})
 	slog.Info("api initialized", "step", "7", "action", "api_initialized")
 
 	// Background workers (dev convenience). In production we run `cmd/worker` instead.
@@ -143,6 +149,7 @@ func main() {
 	if cfg.NATSURL == "" && database != nil && database.Pool != nil {
 		slog.Info("starting background worker", "step", "8", "action", "starting_background_worker")
 		worker := syncjobs.New(cfg, database.Pool)
+		worker.NotifyCompletions(syncCompleted)
 		go func() {
 			slog.Info("background worker started")
 			_ = worker.Run(context.Background())
@@ -199,6 +206,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	stopApp()
 	if err := api.Shutdown(ctx, app); err != nil {
 		slog.Error("graceful shutdown failed",
 			"error", err,
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"strings"
 	"time"
@@ -12,15 +14,23 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 
 	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/broadcast"
 	"github.com/jagadeesh/grainlify/backend/internal/bus"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 	"github.com/jagadeesh/grainlify/backend/internal/handlers"
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
 )
 
 type Deps struct {
 	DB  *db.DB
 	Bus bus.Bus
+
+	// SyncCompleted is notified by the sync worker after each completed job (optional).
+	SyncCompleted *broadcast.Signal
+	// Ctx ends long-lived responses such as leaderboard streams; cancel it before
+	// Shutdown. Defaults to context.Background().
+	Ctx context.Context
 }
 
 func New(cfg config.Config, deps Deps) *fiber.App {
@@ -138,6 +148,46 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 	app.Get("/health", handlers.Health())
 	app.Get("/ready", handlers.Ready(deps.DB))
 
+	// Load balancer probes: /healthz is liveness, /readyz also checks DB and Soroban RPC.
+	var sorobanClient *soroban.Client
+	rpcNetworkMismatch := false
+	if cfg.SorobanRPCURL != "" {
+		client, err := soroban.NewClient(soroban.Config{
+			RPCURL:            cfg.SorobanRPCURL,
+			NetworkPassphrase: cfg.SorobanNetworkPassphrase,
+			Network:           soroban.Network(cfg.SorobanNetwork),
+			CircuitBreaker: soroban.CircuitBreakerConfig{
+				FailureThreshold: cfg.SorobanBreakerThreshold,
+				Window:           cfg.SorobanBreakerWindow,
+				Cooldown:         cfg.SorobanBreakerCooldown,
+			},
+		})
+		if err != nil {
+			slog.Warn("soroban client unavailable for readiness checks", "error", err)
+		} else {
+			verifyCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err = client.Verify(verifyCtx)
+			cancel()
+			switch {
+			case errors.Is(err, soroban.ErrNetworkMismatch):
+				// Pointing at the wrong network is a misconfiguration, not an outage; refuse to use it.
+				slog.Error("soroban RPC serves a different network than configured", "error", err)
+				rpcNetworkMismatch = true
+			case err != nil:
+				slog.Warn("could not verify soroban RPC network", "error", err)
+				sorobanClient = client
+			default:
+				sorobanClient = client
+			}
+		}
+	}
+	readiness := handlers.NewReadinessHandler(deps.DB, sorobanClient)
+	if rpcNetworkMismatch {
+		readiness.SetRPCNetworkMismatch()
+	}
+	app.Get("/healthz", readiness.Healthz())
+	app.Get("/readyz", readiness.Readyz())
+
 	authHandler := handlers.NewAuthHandler(cfg, deps.DB)
 	authGroup := app.Group("/auth")
 	app.Get("/me", auth.RequireAuth(cfg.JWTSecret), authHandler.Me())
@@ -174,9 +224,12 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 	authGroup.Post("/kyc/start", auth.RequireAuth(cfg.JWTSecret), kyc.Start())
 	authGroup.Get("/kyc/status", auth.RequireAuth(cfg.JWTSecret), kyc.Status())
 
-	// Public ecosystems list (includes computed project_count and user_count).
+	// Public ecosystems list (includes cached project_count and user_count).
 	ecosystems := handlers.NewEcosystemsPublicHandler(deps.DB)
 	app.Get("/ecosystems", ecosystems.ListActive())
+	app.Get("/ecosystems/:slug", ecosystems.GetBySlug())
+	app.Get("/ecosystems/:slug/projects", auth.OptionalAuth(cfg.JWTSecret), ecosystems.Projects())
+	app.Get("/ecosystems/:slug/stats", ecosystems.Stats())
 
 	// Open Source Week (public)
 	osw := handlers.NewOpenSourceWeekHandler(deps.DB)
@@ -184,9 +237,39 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 	app.Get("/open-source-week/events/:id", osw.GetPublic())
 
 	// Public leaderboard
-	leaderboard := handlers.NewLeaderboardHandler(deps.DB)
-	app.Get("/leaderboard", leaderboard.Leaderboard())
-	app.Get("/leaderboard/projects", leaderboard.ProjectsLeaderboard())
+	leaderboard := handlers.NewLeaderboardHandler(deps.DB, cfg.LeaderboardCacheTTL)
+	if scoring, err := handlers.ParseScoreConfig(cfg.LeaderboardScoreWeights); err != nil {
+		slog.Warn("invalid LEADERBOARD_SCORE_WEIGHTS, using default score weights", "error", err)
+	} else {
+		leaderboard.SetScoreConfig(scoring)
+	}
+	if tieBreak, err := handlers.ParseLeaderboardTieBreak(cfg.LeaderboardTieBreak); err != nil {
+		slog.Warn("invalid LEADERBOARD_TIE_BREAK, ordering ties alphabetically", "error", err)
+	} else {
+		leaderboard.SetTieBreak(tieBreak)
+	}
+	leaderboard.SetAvatarProxy(cfg.LeaderboardAvatarProxyURL)
+	leaderboard.SetCompressionThreshold(cfg.LeaderboardCompressMinBytes)
+	leaderboard.SetWarmConcurrency(cfg.LeaderboardWarmConcurrency)
+	app.Get("/leaderboard", leaderboard.Compress(), leaderboard.Leaderboard())
+	app.Get("/leaderboard/projects", leaderboard.Compress(), auth.OptionalAuth(cfg.JWTSecret), leaderboard.ProjectsLeaderboard())
+	app.Get("/leaderboard/export", leaderboard.Export())
+	app.Get("/leaderboard/tiers", leaderboard.TierDistribution())
+	app.Get("/leaderboard/tier-definitions", leaderboard.TierDefinitions())
+	app.Get("/leaderboard/scoring", leaderboard.Scoring())
+	app.Get("/leaderboard/stream", leaderboard.Stream())
+	app.Post("/leaderboard/ranks", leaderboard.Ranks())
+	app.Post("/leaderboard/snapshots", auth.RequireAuth(cfg.JWTSecret), auth.RequireRole("admin"), leaderboard.CreateSnapshot())
+	streamsCtx := deps.Ctx
+	if streamsCtx == nil {
+		streamsCtx = context.Background()
+	}
+	leaderboard.WatchSyncs(streamsCtx, deps.SyncCompleted)
+	app.Get("/contributors/:username/breakdown", leaderboard.ContributorBreakdown())
+	app.Get("/contributors/:username/stats", leaderboard.ContributorStats())
+	app.Get("/contributors/:username/projects", leaderboard.ContributorProjects())
+	app.Get("/me/ecosystems", auth.RequireAuth(cfg.JWTSecret), leaderboard.MyEcosystems())
+	app.Get("/wallets/:address/profile", leaderboard.WalletProfile())
 
 	// Public landing stats
 	landingStats := handlers.NewLandingStatsHandler(deps.DB)
@@ -207,6 +290,7 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 	app.Get("/projects/:id", projectsPublic.Get())
 	app.Get("/projects/:id/issues/public", projectsPublic.IssuesPublic())
 	app.Get("/projects/:id/prs/public", projectsPublic.PRsPublic())
+	app.Get("/projects/:id/contributors", projectsPublic.Contributors())
 	app.Post("/projects/:id/verify", auth.RequireAuth(cfg.JWTSecret), projects.Verify())
 
 	sync := handlers.NewSyncHandler(deps.DB)
@@ -229,9 +313,20 @@ func New(cfg config.Config, deps Deps) *fiber.App {
 
 	ecosystemsAdmin := handlers.NewEcosystemsAdminHandler(deps.DB)
 	adminGroup.Get("/ecosystems", auth.RequireRole("admin"), ecosystemsAdmin.List())
+	adminGroup.Get("/ecosystems/slug-preview", auth.RequireRole("admin"), ecosystemsAdmin.SlugPreview())
+	adminGroup.Get("/ecosystems/:id", auth.RequireRole("admin"), ecosystemsAdmin.Get())
+	adminGroup.Get("/ecosystems/:id/history", auth.RequireRole("admin"), ecosystemsAdmin.History())
 	adminGroup.Post("/ecosystems", auth.RequireRole("admin"), ecosystemsAdmin.Create())
+	adminGroup.Post("/ecosystems/bulk", auth.RequireRole("admin"), ecosystemsAdmin.BulkCreate())
+	adminGroup.Put("/ecosystems/by-slug/:slug", auth.RequireRole("admin"), ecosystemsAdmin.Upsert())
 	adminGroup.Put("/ecosystems/:id", auth.RequireRole("admin"), ecosystemsAdmin.Update())
+	adminGroup.Patch("/ecosystems/:id", auth.RequireRole("admin"), ecosystemsAdmin.Patch())
 	adminGroup.Delete("/ecosystems/:id", auth.RequireRole("admin"), ecosystemsAdmin.Delete())
+	adminGroup.Post("/ecosystems/:id/restore", auth.RequireRole("admin"), ecosystemsAdmin.Restore())
+	adminGroup.Post("/ecosystems/:id/recount", auth.RequireRole("admin"), ecosystemsAdmin.Recount())
+	adminGroup.Post("/ecosystems/:id/projects/move", auth.RequireRole("admin"), ecosystemsAdmin.MoveProjects())
+
+	adminGroup.Post("/leaderboard/cache/invalidate", auth.RequireRole("admin"), leaderboard.InvalidateCacheHandler())
 
 	projectsAdmin := handlers.NewProjectsAdminHandler(deps.DB)
 	adminGroup.Delete("/projects/:id", auth.RequireRole("admin"), projectsAdmin.Delete())
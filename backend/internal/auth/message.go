@@ -1,6 +1,11 @@
 package auth
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
 
 func LoginMessage(nonce string) string {
 	// Keep this stable; clients must sign this exact string.
@@ -12,5 +17,34 @@ func LegacyLoginMessage(nonce string) string {
 	return fmt.Sprintf("Patchwork login\nNonce: %s", nonce)
 }
 
+// EVMLoginMessage is LoginMessage bound to an EVM chain, so a signature made for one
+// chain can't be replayed against a deployment expecting another (see CheckChainID).
+func EVMLoginMessage(nonce string, chainID int64) string {
+	return fmt.Sprintf("%s\nChain ID: %d", LoginMessage(nonce), chainID)
+}
+
+var (
+	ErrChainIDMissing  = errors.New("chain_id_missing")
+	ErrChainIDMismatch = errors.New("chain_id_mismatch")
+)
 
+// chainIDLine matches a chain ID field on a line of its own: "Chain ID: 1" as in
+// EIP-4361 (SIWE) messages, or chain_id / chainId with ":" or "=" in plain ones.
+var chainIDLine = regexp.MustCompile(`(?im)^[ \t]*chain[ _]?id[ \t]*[:=][ \t]*([0-9]+)[ \t]*$`)
 
+// CheckChainID checks that a signed message declares the expected chain ID. It returns
+// ErrChainIDMissing when the message declares none, and ErrChainIDMismatch when any
+// chain ID it declares is another one.
+func CheckChainID(message string, expected int64) error {
+	matches := chainIDLine.FindAllStringSubmatch(message, -1)
+	if len(matches) == 0 {
+		return ErrChainIDMissing
+	}
+	for _, m := range matches {
+		id, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || id != expected {
+			return ErrChainIDMismatch
+		}
+	}
+	return nil
+}
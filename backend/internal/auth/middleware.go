@@ -79,11 +79,21 @@ func RequireRole(roles ...string) fiber.Handler {
 	}
 }
 
-
-
-
-
-
-
-
-
+// OptionalAuth sets the user ID and role locals when the request carries a valid
+// bearer token, and otherwise lets it through anonymously. It's for public routes
+// whose response depends on the caller, e.g. showing admins more.
+func OptionalAuth(jwtSecret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		h := strings.TrimSpace(c.Get("Authorization"))
+		if !strings.HasPrefix(strings.ToLower(h), "bearer ") {
+			return c.Next()
+		}
+		claims, err := ParseJWT(jwtSecret, strings.TrimSpace(h[len("bearer "):]))
+		if err != nil {
+			return c.Next()
+		}
+		c.Locals(LocalUserID, claims.Subject)
+		c.Locals(LocalRole, claims.Role)
+		return c.Next()
+	}
+}
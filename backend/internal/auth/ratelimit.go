@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// attemptLimiterIdleTTL is how long an unused key keeps its bucket before it is dropped.
+const attemptLimiterIdleTTL = 10 * time.Minute
+
+// AttemptLimiter is a keyed token bucket (e.g. per IP or per wallet address) used to
+// throttle login attempts before the expensive signature verification runs.
+type AttemptLimiter struct {
+	limit rate.Limit
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*attemptBucket
+	lastSweep time.Time
+}
+
+type attemptBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewAttemptLimiter allows up to perMinute attempts per key per minute, refilled
+// continuously. perMinute <= 0 disables limiting.
+func NewAttemptLimiter(perMinute int) *AttemptLimiter {
+	l := &AttemptLimiter{
+		limit:   rate.Inf,
+		now:     time.Now,
+		buckets: map[string]*attemptBucket{},
+	}
+	if perMinute > 0 {
+		l.limit = rate.Every(time.Minute / time.Duration(perMinute))
+		l.burst = perMinute
+	}
+	return l
+}
+
+// Allow records an attempt for key and reports whether it is within the limit.
+func (l *AttemptLimiter) Allow(key string) bool {
+	if l == nil || l.limit == rate.Inf {
+		return true
+	}
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= attemptLimiterIdleTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) >= attemptLimiterIdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &attemptBucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b.limiter.AllowN(now, 1)
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttemptLimiter_TripsAfterLimitAndResets(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := NewAttemptLimiter(5)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		if !l.Allow("1.2.3.4") {
+			t.Fatalf("attempt %d should be allowed", i+1)
+		}
+	}
+	if l.Allow("1.2.3.4") {
+		t.Fatal("6th attempt within the window should be rejected")
+	}
+	if !l.Allow("5.6.7.8") {
+		t.Fatal("other keys have their own bucket")
+	}
+
+	now = now.Add(time.Minute)
+	for i := 0; i < 5; i++ {
+		if !l.Allow("1.2.3.4") {
+			t.Fatalf("attempt %d after the window should be allowed", i+1)
+		}
+	}
+	if l.Allow("1.2.3.4") {
+		t.Fatal("limit should apply again after reset")
+	}
+}
+
+func TestAttemptLimiter_Disabled(t *testing.T) {
+	l := NewAttemptLimiter(0)
+	for i := 0; i < 100; i++ {
+		if !l.Allow("k") {
+			t.Fatalf("disabled limiter rejected attempt %d", i+1)
+		}
+	}
+}
+
+func TestAttemptLimiter_DropsIdleKeys(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := NewAttemptLimiter(1)
+	l.now = func() time.Time { return now }
+
+	l.Allow("a")
+	now = now.Add(attemptLimiterIdleTTL)
+	l.Allow("b")
+	if _, ok := l.buckets["a"]; ok {
+		t.Fatal("idle bucket should have been swept")
+	}
+}
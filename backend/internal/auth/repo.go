@@ -21,7 +21,9 @@ type User struct {
 type Wallet struct {
 	WalletType WalletType `json:"wallet_type"`
 	Address    string     `json:"address"`
-	PublicKey  string     `json:"public_key,omitempty"`
+	// ChecksumAddress is the EIP-55 form of an EVM address (see ChecksumAddress).
+	ChecksumAddress string `json:"checksum_address,omitempty"`
+	PublicKey       string `json:"public_key,omitempty"`
 }
 
 type Nonce struct {
@@ -131,9 +133,10 @@ WHERE wallet_type = $1 AND address = $2
 	return VerifyResult{
 		User: User{ID: userID, Role: role},
 		Wallet: Wallet{
-			WalletType: walletType,
-			Address:    address,
-			PublicKey:  publicKey,
+			WalletType:      walletType,
+			Address:         address,
+			ChecksumAddress: ChecksumAddress(walletType, address),
+			PublicKey:       publicKey,
 		},
 	}, nil
 }
@@ -3,14 +3,16 @@ package auth
 import (
 	"crypto/ed25519"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 	"github.com/ethereum/go-ethereum/accounts"
-	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -31,6 +33,13 @@ func NormalizeWalletType(v string) (WalletType, error) {
 	}
 }
 
+// ErrInvalidEVMChecksum is returned by NormalizeAddress for a mixed-case EVM address
+// whose capitalization doesn't match its EIP-55 checksum.
+var ErrInvalidEVMChecksum = errors.New("invalid_evm_checksum")
+
+// NormalizeAddress returns the canonical form of addr used for storage and comparison.
+// EVM addresses are 0x-prefixed lowercase; mixed-case input must carry a valid EIP-55
+// checksum, while all-lowercase (or all-uppercase) input has none to check.
 func NormalizeAddress(t WalletType, addr string) (string, error) {
 	a := strings.TrimSpace(addr)
 	if a == "" {
@@ -38,15 +47,20 @@ func NormalizeAddress(t WalletType, addr string) (string, error) {
 	}
 	switch t {
 	case WalletTypeEVM:
-		// Normalize to 0x-prefixed lowercase.
-		a = strings.ToLower(a)
-		if !strings.HasPrefix(a, "0x") {
+		if strings.HasPrefix(a, "0X") {
+			a = "0x" + a[2:]
+		} else if !strings.HasPrefix(a, "0x") {
 			a = "0x" + a
 		}
-		if len(a) != 42 {
+		if len(a) != 42 || !common.IsHexAddress(a) {
 			return "", fmt.Errorf("invalid evm address")
 		}
-		return a, nil
+		body := a[2:]
+		if body != strings.ToLower(body) && body != strings.ToUpper(body) && a != ChecksumAddress(t, a) {
+			return "", ErrInvalidEVMChecksum
+		}
+		// Normalize to 0x-prefixed lowercase.
+		return strings.ToLower(a), nil
 	case WalletTypeStellarEd25519, WalletTypeStellarSecp256k1:
 		// For now we treat `address` as an opaque identifier (often public key hex or account-hash).
 		return strings.ToLower(a), nil
@@ -55,88 +69,212 @@ func NormalizeAddress(t WalletType, addr string) (string, error) {
 	}
 }
 
+// ChecksumAddress returns the display form of a normalized address: the EIP-55
+// checksummed form for EVM, and the address unchanged for other wallet types.
+func ChecksumAddress(t WalletType, addr string) string {
+	if t != WalletTypeEVM {
+		return addr
+	}
+	return common.HexToAddress(addr).Hex()
+}
+
+// MessageHashing is what a Stellar signature covers: the message bytes themselves or
+// their SHA-256 digest. The zero value uses the wallet type's default (see
+// VerifySignatureBytes); EVM signatures always cover the personal_sign hash.
+type MessageHashing string
+
+const (
+	RawMessage MessageHashing = "raw"
+	SHA256     MessageHashing = "sha256"
+)
+
+// ParseMessageHashing parses a message_hashing value; empty means the default.
+func ParseMessageHashing(v string) (MessageHashing, error) {
+	switch h := MessageHashing(strings.ToLower(strings.TrimSpace(v))); h {
+	case "", RawMessage, SHA256:
+		return h, nil
+	default:
+		return "", fmt.Errorf("invalid message_hashing")
+	}
+}
+
 // VerifySignature verifies a wallet signature against our canonical login message.
+// It decodes the hex inputs and delegates to VerifySignatureBytes.
 //
 // Inputs:
 // - signatureHex: hex string (0x prefix optional)
 // - publicKeyHex: required for Stellar; ignored for EVM
 func VerifySignature(t WalletType, address string, message string, signatureHex string, publicKeyHex string) error {
+	return VerifySignatureWithHashing(t, address, message, signatureHex, publicKeyHex, "")
+}
+
+// VerifySignatureWithHashing is VerifySignature for a signature made with the given
+// hashing strategy, e.g. SHA256 for an ed25519 wallet that signs the message digest.
+func VerifySignatureWithHashing(t WalletType, address string, message string, signatureHex string, publicKeyHex string, hashing MessageHashing) error {
+	sig, err := decodeHex(signatureHex)
+	if err != nil {
+		return fmt.Errorf("invalid signature hex")
+	}
+	var pub []byte
+	if t == WalletTypeStellarEd25519 || t == WalletTypeStellarSecp256k1 {
+		if pub, err = decodeHex(publicKeyHex); err != nil {
+			return fmt.Errorf("invalid public_key")
+		}
+	}
+	return VerifySignatureBytesWithHashing(t, address, []byte(message), sig, pub, hashing)
+}
+
+// VerifyEVMLogin verifies an EVM personal_sign signature over message like
+// VerifySignature, after checking that message is bound to expectedChainID (see
+// CheckChainID). A message for another chain fails with ErrChainIDMismatch before its
+// signature is looked at.
+func VerifyEVMLogin(address, message, signatureHex string, expectedChainID int64) error {
+	if err := CheckChainID(message, expectedChainID); err != nil {
+		return err
+	}
+	return VerifySignature(WalletTypeEVM, address, message, signatureHex, "")
+}
+
+// ErrNoWalletTypeVerified is returned (joined with each candidate's error) by VerifyAny
+// when no candidate wallet type verifies the signature.
+var ErrNoWalletTypeVerified = errors.New("no_wallet_type_verified")
+
+// VerifyAny verifies a signature whose wallet type isn't known, e.g. a Stellar key that
+// may be ed25519 or secp256k1. It tries VerifySignature with each candidate in order and
+// returns the first that verifies. Otherwise the error wraps ErrNoWalletTypeVerified
+// and every candidate's error, prefixed with its wallet type.
+//
+// Inputs are validated per type before use, so a signature or key of the wrong shape
+// for a candidate only fails that candidate.
+func VerifyAny(address, message, sig, pub string, candidates []WalletType) (WalletType, error) {
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("%w: no candidates", ErrNoWalletTypeVerified)
+	}
+	errs := []error{ErrNoWalletTypeVerified}
+	for _, t := range candidates {
+		err := VerifySignature(t, address, message, sig, pub)
+		if err == nil {
+			return t, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", t, err))
+	}
+	return "", errors.Join(errs...)
+}
+
+// VerifySignatureBytes verifies a wallet signature over an arbitrary (possibly binary)
+// message, exactly as signed, with each wallet type's default hashing:
+// - EVM: personal_sign over message (accounts.TextHash); pub is ignored
+// - Stellar ed25519: over message itself (RawMessage)
+// - Stellar secp256k1: over SHA-256(message) (SHA256), DER or compact 64-byte signature
+func VerifySignatureBytes(t WalletType, address string, message []byte, sig, pub []byte) error {
+	return VerifySignatureBytesWithHashing(t, address, message, sig, pub, "")
+}
+
+// VerifySignatureBytesWithHashing is VerifySignatureBytes with an explicit hashing
+// strategy for Stellar signatures. ed25519 accepts both; RawMessage with secp256k1
+// means message already is the 32-byte digest that was signed. EVM only accepts the
+// default.
+func VerifySignatureBytesWithHashing(t WalletType, address string, message []byte, sig, pub []byte, hashing MessageHashing) error {
 	switch t {
 	case WalletTypeEVM:
-		return verifyEVM(address, message, signatureHex)
+		if hashing != "" {
+			return fmt.Errorf("unsupported message_hashing for evm")
+		}
+		return verifyEVM(address, message, sig)
 	case WalletTypeStellarEd25519:
-		return verifyStellarEd25519(message, signatureHex, publicKeyHex)
+		if hashing == SHA256 {
+			h := sha256.Sum256(message)
+			message = h[:]
+		}
+		return verifyStellarEd25519(message, sig, pub)
 	case WalletTypeStellarSecp256k1:
-		return verifyStellarSecp256k1(message, signatureHex, publicKeyHex)
+		if hashing != RawMessage {
+			h := sha256.Sum256(message)
+			message = h[:]
+		}
+		return verifyStellarSecp256k1(message, sig, pub)
 	default:
 		return fmt.Errorf("unsupported wallet_type")
 	}
 }
 
-func verifyEVM(expectedAddr string, message string, signatureHex string) error {
-	sig, err := hexutil.Decode(signatureHex)
+// RecoverEVMSigner returns the address that produced an EVM personal_sign signature
+// over message. It does not check the signer against anything; use it to compare with
+// several candidate addresses or to log who signed.
+//
+// signatureHex is the 65-byte R||S||V signature in hex (0x prefix optional), with V as
+// 0/1 or 27/28.
+func RecoverEVMSigner(message, signatureHex string) (common.Address, error) {
+	sig, err := decodeHex(signatureHex)
 	if err != nil {
-		return fmt.Errorf("invalid signature hex")
+		return common.Address{}, fmt.Errorf("invalid signature hex")
 	}
-	if len(sig) != 65 {
-		return fmt.Errorf("invalid signature length")
+	return recoverEVMSigner([]byte(message), sig)
+}
+
+func recoverEVMSigner(message []byte, signature []byte) (common.Address, error) {
+	if len(signature) != 65 {
+		return common.Address{}, fmt.Errorf("invalid signature length")
 	}
-	// Transform V from {27,28} to {0,1} if necessary.
+	// Transform V from {27,28} to {0,1} if necessary, without touching the caller's slice.
+	sig := append([]byte(nil), signature...)
 	if sig[64] >= 27 {
 		sig[64] -= 27
 	}
 
-	hash := accounts.TextHash([]byte(message))
+	hash := accounts.TextHash(message)
 	pub, err := crypto.SigToPub(hash, sig)
 	if err != nil {
-		return fmt.Errorf("signature recovery failed")
+		return common.Address{}, fmt.Errorf("signature recovery failed")
 	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
 
-	recovered := strings.ToLower(crypto.PubkeyToAddress(*pub).Hex())
-	if strings.ToLower(expectedAddr) != recovered {
+func verifyEVM(expectedAddr string, message []byte, signature []byte) error {
+	signer, err := recoverEVMSigner(message, signature)
+	if err != nil {
+		return err
+	}
+	if !equalFoldConstantTime(expectedAddr, signer.Hex()) {
 		return fmt.Errorf("signature does not match address")
 	}
 	return nil
 }
 
-func verifyStellarEd25519(message string, signatureHex string, publicKeyHex string) error {
-	pubKeyBytes, err := decodeHex(publicKeyHex)
-	if err != nil || len(pubKeyBytes) != ed25519.PublicKeySize {
+// equalFoldConstantTime compares a and b case-insensitively, in time that depends only
+// on their lengths, so a mismatch doesn't reveal how much of an address matched.
+func equalFoldConstantTime(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(strings.ToLower(a)), []byte(strings.ToLower(b))) == 1
+}
+
+func verifyStellarEd25519(message []byte, sigBytes []byte, pubKeyBytes []byte) error {
+	if len(pubKeyBytes) != ed25519.PublicKeySize {
 		return fmt.Errorf("invalid public_key")
 	}
-	sigBytes, err := decodeHex(signatureHex)
-	if err != nil || len(sigBytes) != ed25519.SignatureSize {
+	if len(sigBytes) != ed25519.SignatureSize {
 		return fmt.Errorf("invalid signature")
 	}
-	if !ed25519.Verify(ed25519.PublicKey(pubKeyBytes), []byte(message), sigBytes) {
+	if !ed25519.Verify(ed25519.PublicKey(pubKeyBytes), message, sigBytes) {
 		return fmt.Errorf("invalid signature")
 	}
 	return nil
 }
 
-func verifyStellarSecp256k1(message string, signatureHex string, publicKeyHex string) error {
-	pubKeyBytes, err := decodeHex(publicKeyHex)
-	if err != nil {
-		return fmt.Errorf("invalid public_key")
-	}
+// verifyStellarSecp256k1 verifies sigBytes over digest, the 32-byte hash that was signed.
+func verifyStellarSecp256k1(digest []byte, sigBytes []byte, pubKeyBytes []byte) error {
 	pubKey, err := secp256k1ParsePubKey(pubKeyBytes)
 	if err != nil {
 		return fmt.Errorf("invalid public_key")
 	}
-
-	sigBytes, err := decodeHex(signatureHex)
-	if err != nil {
-		return fmt.Errorf("invalid signature")
+	if len(digest) != sha256.Size {
+		return fmt.Errorf("secp256k1 signatures must cover a 32-byte digest")
 	}
 
-	// Many systems verify secp256k1 signatures over a hash; we standardize on SHA-256(message).
-	h := sha256.Sum256([]byte(message))
-
 	sig, err := parseSecp256k1Signature(sigBytes)
 	if err != nil {
 		return fmt.Errorf("invalid signature")
 	}
-	if !sig.Verify(h[:], pubKey) {
+	if !sig.Verify(digest, pubKey) {
 		return fmt.Errorf("invalid signature")
 	}
 	return nil
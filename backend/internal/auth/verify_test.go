@@ -0,0 +1,439 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Test vectors from EIP-55.
+var eip55Addresses = []string{
+	"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+	"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+	"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+}
+
+func TestNormalizeAddress_EVMValidChecksum(t *testing.T) {
+	for _, addr := range eip55Addresses {
+		got, err := NormalizeAddress(WalletTypeEVM, addr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", addr, err)
+			continue
+		}
+		if got != strings.ToLower(addr) {
+			t.Errorf("%s: expected lowercase canonical form, got %s", addr, got)
+		}
+		if cs := ChecksumAddress(WalletTypeEVM, got); cs != addr {
+			t.Errorf("%s: checksum form = %s", addr, cs)
+		}
+	}
+}
+
+func TestNormalizeAddress_EVMInvalidChecksum(t *testing.T) {
+	// Flip the case of one letter in an otherwise valid checksummed address.
+	bad := "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"
+	if _, err := NormalizeAddress(WalletTypeEVM, bad); !errors.Is(err, ErrInvalidEVMChecksum) {
+		t.Errorf("expected ErrInvalidEVMChecksum, got %v", err)
+	}
+}
+
+func TestNormalizeAddress_EVMSingleCase(t *testing.T) {
+	for _, in := range []string{
+		"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED",
+	} {
+		got, err := NormalizeAddress(WalletTypeEVM, in)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", in, err)
+			continue
+		}
+		if got != "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed" {
+			t.Errorf("%s: got %s", in, got)
+		}
+	}
+	if cs := ChecksumAddress(WalletTypeEVM, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"); cs != eip55Addresses[0] {
+		t.Errorf("checksum form = %s", cs)
+	}
+}
+
+func TestNormalizeAddress_EVMRejectsMalformed(t *testing.T) {
+	for _, in := range []string{"", "0x1234", "0xzzaeb6053f3e94c9b9a09f33669435e7ef1beaed"} {
+		if _, err := NormalizeAddress(WalletTypeEVM, in); err == nil || errors.Is(err, ErrInvalidEVMChecksum) {
+			t.Errorf("%q: expected an invalid address error, got %v", in, err)
+		}
+	}
+}
+
+// binaryChallenge contains a NUL byte, high-bit bytes and an invalid UTF-8 sequence.
+var binaryChallenge = []byte{0x00, 'g', 'r', 'a', 'i', 'n', 0x00, 0xff, 0xfe, 0x80, 0xc3, 0x28, 0x7f}
+
+func TestVerifySignatureBytes_EVM(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	sig, err := crypto.Sign(accounts.TextHash(binaryChallenge), key)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	sig[64] += 27 // wallets report V as 27/28
+
+	if err := VerifySignatureBytes(WalletTypeEVM, addr, binaryChallenge, sig, nil); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+	if sig[64] < 27 {
+		t.Error("the caller's signature slice must not be modified")
+	}
+	// The string wrapper carries the same bytes unchanged
+	if err := VerifySignature(WalletTypeEVM, addr, string(binaryChallenge), hex.EncodeToString(sig), ""); err != nil {
+		t.Errorf("expected the string form to verify too, got %v", err)
+	}
+
+	tampered := append([]byte(nil), binaryChallenge...)
+	tampered[0] = 0x01
+	if err := VerifySignatureBytes(WalletTypeEVM, addr, tampered, sig, nil); err == nil {
+		t.Error("expected a changed message to fail verification")
+	}
+}
+
+// Hardhat's first default account, a widely published test key
+const (
+	testEVMPrivateKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	testEVMAddress    = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+)
+
+func TestRecoverEVMSigner(t *testing.T) {
+	key, err := crypto.HexToECDSA(testEVMPrivateKey)
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+	message := "Sign in to Grainlify"
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// V may come as 0/1 or 27/28, with or without the 0x prefix
+	for _, sigHex := range []string{hex.EncodeToString(sig), "0x" + hex.EncodeToString(append(sig[:64:64], sig[64]+27))} {
+		signer, err := RecoverEVMSigner(message, sigHex)
+		if err != nil {
+			t.Fatalf("RecoverEVMSigner(%s) failed: %v", sigHex, err)
+		}
+		if signer != common.HexToAddress(testEVMAddress) {
+			t.Errorf("expected signer %s, got %s", testEVMAddress, signer.Hex())
+		}
+	}
+
+	// A different message recovers some other address, not an error
+	signer, err := RecoverEVMSigner("Sign in to Grainlify!", hex.EncodeToString(sig))
+	if err != nil {
+		t.Fatalf("RecoverEVMSigner failed: %v", err)
+	}
+	if signer == common.HexToAddress(testEVMAddress) {
+		t.Error("expected a changed message to recover a different address")
+	}
+
+	for _, bad := range []string{"", "zz", hex.EncodeToString(sig[:64])} {
+		if _, err := RecoverEVMSigner(message, bad); err == nil {
+			t.Errorf("expected an error for signature %q", bad)
+		}
+	}
+}
+
+func TestCheckChainID(t *testing.T) {
+	cases := []struct {
+		name    string
+		message string
+		want    error
+	}{
+		{"login message", EVMLoginMessage("abc", 137), nil},
+		{"siwe", "example.com wants you to sign in with your Ethereum account:\n" + testEVMAddress + "\n\nURI: https://example.com\nVersion: 1\nChain ID: 137\nNonce: abc", nil},
+		{"plain field", "Sign in\nchain_id=137", nil},
+		{"camel case", "Sign in\nchainId: 137", nil},
+		{"other chain", EVMLoginMessage("abc", 1), ErrChainIDMismatch},
+		{"conflicting fields", EVMLoginMessage("abc", 137) + "\nChain ID: 1", ErrChainIDMismatch},
+		{"no chain", LoginMessage("abc"), ErrChainIDMissing},
+		{"not on its own line", "Sign in on Chain ID: 137 only", ErrChainIDMissing},
+	}
+	for _, tc := range cases {
+		if err := CheckChainID(tc.message, 137); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestVerifyEVMLogin_ChainID(t *testing.T) {
+	key, err := crypto.HexToECDSA(testEVMPrivateKey)
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+	sign := func(message string) string {
+		sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return hex.EncodeToString(sig)
+	}
+
+	mainnet := EVMLoginMessage("abc", 1)
+	if err := VerifyEVMLogin(testEVMAddress, mainnet, sign(mainnet), 1); err != nil {
+		t.Errorf("expected a signature for the expected chain to verify, got %v", err)
+	}
+
+	// A genuine signature for another chain is rejected as such, not as a bad signature
+	polygon := EVMLoginMessage("abc", 137)
+	if err := VerifyEVMLogin(testEVMAddress, polygon, sign(polygon), 1); !errors.Is(err, ErrChainIDMismatch) {
+		t.Errorf("expected ErrChainIDMismatch, got %v", err)
+	}
+
+	// Replaying that signature over the expected chain's message recovers someone else
+	if err := VerifyEVMLogin(testEVMAddress, mainnet, sign(polygon), 1); err == nil || errors.Is(err, ErrChainIDMismatch) {
+		t.Errorf("expected a signature mismatch, got %v", err)
+	}
+
+	unbound := LoginMessage("abc")
+	if err := VerifyEVMLogin(testEVMAddress, unbound, sign(unbound), 1); !errors.Is(err, ErrChainIDMissing) {
+		t.Errorf("expected ErrChainIDMissing, got %v", err)
+	}
+}
+
+func TestVerifySignatureBytes_StellarEd25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	sig := ed25519.Sign(priv, binaryChallenge)
+
+	if err := VerifySignatureBytes(WalletTypeStellarEd25519, "", binaryChallenge, sig, pub); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+	if err := VerifySignature(WalletTypeStellarEd25519, "", string(binaryChallenge), hex.EncodeToString(sig), hex.EncodeToString(pub)); err != nil {
+		t.Errorf("expected the string form to verify too, got %v", err)
+	}
+	if err := VerifySignatureBytes(WalletTypeStellarEd25519, "", binaryChallenge[1:], sig, pub); err == nil {
+		t.Error("expected a truncated message to fail verification")
+	}
+}
+
+func TestVerifySignatureBytesWithHashing_StellarEd25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	digest := sha256.Sum256(binaryChallenge)
+	rawSig := ed25519.Sign(priv, binaryChallenge)
+	hashSig := ed25519.Sign(priv, digest[:])
+
+	cases := []struct {
+		name    string
+		sig     []byte
+		hashing MessageHashing
+		ok      bool
+	}{
+		{"raw signature, default", rawSig, "", true},
+		{"raw signature, raw", rawSig, RawMessage, true},
+		{"raw signature, sha256", rawSig, SHA256, false},
+		{"hash signature, default", hashSig, "", false},
+		{"hash signature, sha256", hashSig, SHA256, true},
+	}
+	for _, tc := range cases {
+		err := VerifySignatureBytesWithHashing(WalletTypeStellarEd25519, "", binaryChallenge, tc.sig, pub, tc.hashing)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: expected ok=%t, got %v", tc.name, tc.ok, err)
+		}
+	}
+	if err := VerifySignatureWithHashing(WalletTypeStellarEd25519, "", string(binaryChallenge), hex.EncodeToString(hashSig), hex.EncodeToString(pub), SHA256); err != nil {
+		t.Errorf("expected the string form to verify a hash signature too, got %v", err)
+	}
+}
+
+func TestVerifySignatureBytesWithHashing_Secp256k1AndEVM(t *testing.T) {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	h := sha256.Sum256(binaryChallenge)
+	sig := ecdsa.Sign(priv, h[:]).Serialize()
+	pub := priv.PubKey().SerializeCompressed()
+
+	// SHA256 is the default; RawMessage takes the message to be the signed digest
+	if err := VerifySignatureBytesWithHashing(WalletTypeStellarSecp256k1, "", binaryChallenge, sig, pub, SHA256); err != nil {
+		t.Errorf("expected sha256 to verify, got %v", err)
+	}
+	if err := VerifySignatureBytesWithHashing(WalletTypeStellarSecp256k1, "", h[:], sig, pub, RawMessage); err != nil {
+		t.Errorf("expected a prehashed raw message to verify, got %v", err)
+	}
+	if err := VerifySignatureBytesWithHashing(WalletTypeStellarSecp256k1, "", binaryChallenge, sig, pub, RawMessage); err == nil {
+		t.Error("expected a raw message that isn't a digest to be rejected")
+	}
+
+	if err := VerifySignatureBytesWithHashing(WalletTypeEVM, testEVMAddress, binaryChallenge, make([]byte, 65), nil, SHA256); err == nil {
+		t.Error("expected EVM to reject an explicit hashing strategy")
+	}
+}
+
+func TestParseMessageHashing(t *testing.T) {
+	for in, want := range map[string]MessageHashing{"": "", "raw": RawMessage, " SHA256 ": SHA256} {
+		if got, err := ParseMessageHashing(in); err != nil || got != want {
+			t.Errorf("ParseMessageHashing(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseMessageHashing("keccak"); err == nil {
+		t.Error("expected an unknown strategy to be rejected")
+	}
+}
+
+func TestVerifySignatureBytes_StellarSecp256k1(t *testing.T) {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	h := sha256.Sum256(binaryChallenge)
+	sig := ecdsa.Sign(priv, h[:]).Serialize() // DER
+	pub := priv.PubKey().SerializeCompressed()
+
+	if err := VerifySignatureBytes(WalletTypeStellarSecp256k1, "", binaryChallenge, sig, pub); err != nil {
+		t.Errorf("expected a valid signature, got %v", err)
+	}
+	if err := VerifySignatureBytes(WalletTypeStellarSecp256k1, "", append(binaryChallenge, 0x00), sig, pub); err == nil {
+		t.Error("expected a message with a trailing NUL to fail verification")
+	}
+}
+
+func TestVerifyAny(t *testing.T) {
+	message := string(binaryChallenge)
+
+	// A Stellar secp256k1 signature, tried as ed25519 first
+	secpKey, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	h := sha256.Sum256(binaryChallenge)
+	secpSig := hex.EncodeToString(ecdsa.Sign(secpKey, h[:]).Serialize())
+	secpPub := hex.EncodeToString(secpKey.PubKey().SerializeCompressed())
+	stellar := []WalletType{WalletTypeStellarEd25519, WalletTypeStellarSecp256k1}
+	if got, err := VerifyAny("", message, secpSig, secpPub, stellar); err != nil || got != WalletTypeStellarSecp256k1 {
+		t.Errorf("expected stellar_secp256k1, got %q, %v", got, err)
+	}
+
+	// An ed25519 signature, tried as EVM first
+	edPub, edPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	edSig := hex.EncodeToString(ed25519.Sign(edPriv, binaryChallenge))
+	if got, err := VerifyAny("", message, edSig, hex.EncodeToString(edPub), []WalletType{WalletTypeEVM, WalletTypeStellarEd25519}); err != nil || got != WalletTypeStellarEd25519 {
+		t.Errorf("expected stellar_ed25519, got %q, %v", got, err)
+	}
+
+	// Nothing verifies: every candidate's failure is reported, none panics
+	all := []WalletType{WalletTypeEVM, WalletTypeStellarEd25519, WalletTypeStellarSecp256k1, "unknown"}
+	for _, in := range []struct{ sig, pub string }{
+		{"", ""},
+		{"zz", "zz"},
+		{"00", "00"},
+		{strings.Repeat("ff", 65), strings.Repeat("ff", 65)},
+		{edSig, secpPub},
+		{secpSig, hex.EncodeToString(edPub)},
+	} {
+		got, err := VerifyAny("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", message, in.sig, in.pub, all)
+		if !errors.Is(err, ErrNoWalletTypeVerified) || got != "" {
+			t.Errorf("sig %q pub %q: expected ErrNoWalletTypeVerified, got %q, %v", in.sig, in.pub, got, err)
+			continue
+		}
+		for _, wt := range all {
+			if !strings.Contains(err.Error(), string(wt)+": ") {
+				t.Errorf("expected the %s failure in %q", wt, err)
+			}
+		}
+	}
+
+	if _, err := VerifyAny("", message, edSig, hex.EncodeToString(edPub), nil); !errors.Is(err, ErrNoWalletTypeVerified) {
+		t.Errorf("expected ErrNoWalletTypeVerified without candidates, got %v", err)
+	}
+}
+
+func TestVerifySignatureBytes_EVMAddressCase(t *testing.T) {
+	key, err := crypto.HexToECDSA(testEVMPrivateKey)
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+	sig, err := crypto.Sign(accounts.TextHash(binaryChallenge), key)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// The expected address is matched case-insensitively, prefix included
+	for _, addr := range []string{testEVMAddress, strings.ToLower(testEVMAddress), "0x" + strings.ToUpper(testEVMAddress[2:])} {
+		if err := VerifySignatureBytes(WalletTypeEVM, addr, binaryChallenge, sig, nil); err != nil {
+			t.Errorf("%s: expected a valid signature, got %v", addr, err)
+		}
+	}
+	for _, addr := range []string{testEVMAddress[2:], testEVMAddress[:41], eip55Addresses[0], ""} {
+		if err := VerifySignatureBytes(WalletTypeEVM, addr, binaryChallenge, sig, nil); err == nil {
+			t.Errorf("%q: expected a mismatch", addr)
+		}
+	}
+}
+
+func BenchmarkVerifySignatureBytes_EVM(b *testing.B) {
+	key, err := crypto.HexToECDSA(testEVMPrivateKey)
+	if err != nil {
+		b.Fatalf("load key: %v", err)
+	}
+	sig, err := crypto.Sign(accounts.TextHash(binaryChallenge), key)
+	if err != nil {
+		b.Fatalf("sign: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := VerifySignatureBytes(WalletTypeEVM, testEVMAddress, binaryChallenge, sig, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerifySignatureBytes_StellarEd25519(b *testing.B) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		b.Fatalf("generate key: %v", err)
+	}
+	sig := ed25519.Sign(priv, binaryChallenge)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := VerifySignatureBytes(WalletTypeStellarEd25519, "", binaryChallenge, sig, pub); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerifySignatureBytes_StellarSecp256k1(b *testing.B) {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		b.Fatalf("generate key: %v", err)
+	}
+	h := sha256.Sum256(binaryChallenge)
+	sig := ecdsa.Sign(priv, h[:]).Serialize()
+	pub := priv.PubKey().SerializeCompressed()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := VerifySignatureBytes(WalletTypeStellarSecp256k1, "", binaryChallenge, sig, pub); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package broadcast
+
+import "sync"
+
+// Signal fans a payload-free "something changed" notification out to any number of
+// subscribers. Notifications coalesce: a subscriber that hasn't consumed the previous
+// one still has exactly one pending, so a slow subscriber never blocks Notify.
+//
+// A nil *Signal is valid; Notify on it is a no-op.
+type Signal struct {
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+func NewSignal() *Signal {
+	return &Signal{subs: map[chan struct{}]struct{}{}}
+}
+
+// Subscribe returns a channel that receives a value after each Notify, and a function
+// that unsubscribes it. The channel is never closed.
+func (s *Signal) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, ch)
+			s.mu.Unlock()
+		})
+	}
+}
+
+// Notify wakes every subscriber without blocking.
+func (s *Signal) Notify() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// Already pending
+		}
+	}
+}
+
+// Subscribers returns the number of current subscribers.
+func (s *Signal) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subs)
+}
@@ -0,0 +1,53 @@
+package broadcast
+
+import "testing"
+
+func TestSignal_NotifiesEverySubscriber(t *testing.T) {
+	s := NewSignal()
+	a, unsubA := s.Subscribe()
+	b, unsubB := s.Subscribe()
+	defer unsubB()
+
+	s.Notify()
+	for name, ch := range map[string]<-chan struct{}{"a": a, "b": b} {
+		select {
+		case <-ch:
+		default:
+			t.Errorf("subscriber %s was not notified", name)
+		}
+	}
+
+	unsubA()
+	unsubA() // idempotent
+	if n := s.Subscribers(); n != 1 {
+		t.Errorf("expected 1 subscriber after unsubscribe, got %d", n)
+	}
+	s.Notify()
+	select {
+	case <-a:
+		t.Error("unsubscribed channel should not be notified")
+	default:
+	}
+}
+
+func TestSignal_Coalesces(t *testing.T) {
+	s := NewSignal()
+	ch, unsub := s.Subscribe()
+	defer unsub()
+
+	// Notify never blocks on a subscriber that isn't reading.
+	for i := 0; i < 10; i++ {
+		s.Notify()
+	}
+	<-ch
+	select {
+	case <-ch:
+		t.Error("expected pending notifications to coalesce into one")
+	default:
+	}
+}
+
+func TestSignal_NilIsNoop(t *testing.T) {
+	var s *Signal
+	s.Notify()
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -14,9 +15,18 @@ type Config struct {
 
 	DBURL       string
 	AutoMigrate bool
+	// Timed queries slower than this are logged (0 uses the default, negative disables).
+	DBSlowQueryThreshold time.Duration
 
 	JWTSecret string
 
+	// Wallet login attempts allowed per minute before signature verification (0 disables).
+	AuthVerifyPerIPPerMinute      int
+	AuthVerifyPerAddressPerMinute int
+	// AuthEVMChainID binds EVM login messages to this chain ID and rejects signatures
+	// made for another chain (0 keeps the unbound message).
+	AuthEVMChainID int
+
 	NATSURL string
 
 	GitHubOAuthClientID           string
@@ -64,6 +74,27 @@ type Config struct {
 	EscrowContractID         string
 	ProgramEscrowContractID  string
 	TokenContractID          string
+	// Soroban RPC circuit breaker: consecutive failures within the window that open it
+	// (0 uses the default, negative disables it), and how long it stays open.
+	SorobanBreakerThreshold int
+	SorobanBreakerWindow    time.Duration
+	SorobanBreakerCooldown  time.Duration
+
+	// How long leaderboard pages are cached in memory (e.g. "60s"; "-1s" disables).
+	LeaderboardCacheTTL time.Duration
+	// JSON score weights overriding the defaults, e.g. {"contributor":{"merged_pull_requests":2}}.
+	LeaderboardScoreWeights string
+	// How leaderboard ties are ordered: "alphabetical" (default) or "first_contribution".
+	LeaderboardTieBreak string
+	// Image CDN base URL serving GitHub avatars by path (<base>/<login>.png?size=N), used
+	// instead of github.com for contributors without a stored avatar. Empty means GitHub.
+	LeaderboardAvatarProxyURL string
+	// Smallest /leaderboard and /leaderboard/projects response, in bytes, that is gzip or
+	// deflate compressed for clients accepting it. 0 uses the default (1024); negative disables.
+	LeaderboardCompressMinBytes int
+	// How many leaderboard pages are computed at once when warming the cache after a
+	// contribution sync. 0 uses the default (4); negative disables warming.
+	LeaderboardWarmConcurrency int
 }
 
 func Load() Config {
@@ -85,8 +116,14 @@ func Load() Config {
 		DBURL:       getEnv("DB_URL", ""),
 		AutoMigrate: getEnvBool("AUTO_MIGRATE", false),
 
+		DBSlowQueryThreshold: getEnvDuration("DB_SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+
 		JWTSecret: getEnv("JWT_SECRET", ""),
 
+		AuthVerifyPerIPPerMinute:      getEnvInt("AUTH_VERIFY_PER_IP_PER_MINUTE", 20),
+		AuthVerifyPerAddressPerMinute: getEnvInt("AUTH_VERIFY_PER_ADDRESS_PER_MINUTE", 5),
+		AuthEVMChainID:                getEnvInt("AUTH_EVM_CHAIN_ID", 0),
+
 		NATSURL: getEnv("NATS_URL", ""),
 
 		GitHubOAuthClientID:           getEnv("GITHUB_OAUTH_CLIENT_ID", ""),
@@ -123,6 +160,16 @@ func Load() Config {
 		EscrowContractID:         getEnv("ESCROW_CONTRACT_ID", ""),
 		ProgramEscrowContractID:  getEnv("PROGRAM_ESCROW_CONTRACT_ID", ""),
 		TokenContractID:          getEnv("TOKEN_CONTRACT_ID", ""),
+		SorobanBreakerThreshold:  getEnvInt("SOROBAN_BREAKER_THRESHOLD", 0),
+		SorobanBreakerWindow:     getEnvDuration("SOROBAN_BREAKER_WINDOW", 0),
+		SorobanBreakerCooldown:   getEnvDuration("SOROBAN_BREAKER_COOLDOWN", 0),
+
+		LeaderboardCacheTTL:         getEnvDuration("LEADERBOARD_CACHE_TTL", 60*time.Second),
+		LeaderboardScoreWeights:     getEnv("LEADERBOARD_SCORE_WEIGHTS", ""),
+		LeaderboardTieBreak:         getEnv("LEADERBOARD_TIE_BREAK", ""),
+		LeaderboardAvatarProxyURL:   getEnv("LEADERBOARD_AVATAR_PROXY_URL", ""),
+		LeaderboardCompressMinBytes: getEnvInt("LEADERBOARD_COMPRESS_MIN_BYTES", 0),
+		LeaderboardWarmConcurrency:  getEnvInt("LEADERBOARD_WARM_CONCURRENCY", 0),
 	}
 }
 
@@ -167,3 +214,27 @@ func getEnvBool(key string, fallback bool) bool {
 		return fallback
 	}
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+func getEnvInt(key string, fallback int) int {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
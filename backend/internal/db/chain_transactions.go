@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+)
+
+// ChainTransactionSink records submitted contract transactions in the
+// chain_transactions table, and the payouts they request in chain_transaction_payouts.
+// It satisfies soroban.ResultSink and soroban.PayoutRecordSource.
+type ChainTransactionSink struct {
+	db *DB
+}
+
+// NewChainTransactionSink returns a sink writing to d.
+func NewChainTransactionSink(d *DB) *ChainTransactionSink {
+	return &ChainTransactionSink{db: d}
+}
+
+// RecordResult upserts res by hash. Fields the result leaves unset (no ledger or
+// confirmation time yet) keep whatever an earlier report stored. The payouts decoded
+// from res's envelope are stored with it; an envelope that can't be decoded is logged
+// and only its payouts are skipped.
+func (s *ChainTransactionSink) RecordResult(ctx context.Context, function string, res *soroban.TransactionResult) error {
+	var ledger, fee *int64
+	if res.Ledger != 0 {
+		v := int64(res.Ledger)
+		ledger = &v
+	}
+	if res.FeeCharged != 0 {
+		fee = &res.FeeCharged
+	}
+	var confirmedAt any
+	if !res.Confirmed.IsZero() {
+		confirmedAt = res.Confirmed
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, `
+INSERT INTO chain_transactions (hash, function, status, ledger, fee_charged, submitted_at, confirmed_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (hash) DO UPDATE SET
+  function = CASE WHEN EXCLUDED.function <> '' THEN EXCLUDED.function ELSE chain_transactions.function END,
+  status = CASE WHEN chain_transactions.status = 'success' THEN chain_transactions.status ELSE EXCLUDED.status END,
+  ledger = COALESCE(EXCLUDED.ledger, chain_transactions.ledger),
+  fee_charged = COALESCE(EXCLUDED.fee_charged, chain_transactions.fee_charged),
+  confirmed_at = COALESCE(EXCLUDED.confirmed_at, chain_transactions.confirmed_at),
+  updated_at = now()
+`, res.Hash, function, res.Status, ledger, fee, res.Submitted, confirmedAt)
+	if err != nil {
+		return err
+	}
+
+	// The transaction row is kept even when its payouts can't be read from the envelope
+	payouts, err := soroban.RequestedPayouts(res.EnvelopeXDR)
+	if err != nil {
+		slog.Warn("failed to decode requested payouts, not recording them",
+			"error", err,
+			"tx_hash", res.Hash,
+			"function", function,
+		)
+		payouts = nil
+	}
+	for i, p := range payouts {
+		_, err = tx.Exec(ctx, `
+INSERT INTO chain_transaction_payouts (hash, position, recipient, amount)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (hash, position) DO NOTHING
+`, res.Hash, i, p.Recipient, p.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// RecordedPayouts returns the payouts of the transactions that confirmed successfully
+// at or after fromLedger, by ledger and then in transaction order.
+func (s *ChainTransactionSink) RecordedPayouts(ctx context.Context, fromLedger uint32) ([]soroban.RecordedPayout, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+SELECT p.hash, p.recipient, p.amount
+FROM chain_transaction_payouts p
+JOIN chain_transactions t ON t.hash = p.hash
+WHERE t.status = 'success' AND t.ledger >= $1
+ORDER BY t.ledger, p.hash, p.position
+`, int64(fromLedger))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payouts []soroban.RecordedPayout
+	for rows.Next() {
+		var p soroban.RecordedPayout
+		if err := rows.Scan(&p.TxHash, &p.Recipient, &p.Amount); err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, p)
+	}
+	return payouts, rows.Err()
+}
@@ -11,6 +11,10 @@ import (
 
 type DB struct {
 	Pool *pgxpool.Pool
+
+	// Timing of QueryTimed / QueryRowTimed, see timing.go
+	observer           QueryObserver
+	slowQueryThreshold time.Duration
 }
 
 func Connect(ctx context.Context, dbURL string) (*DB, error) {
@@ -0,0 +1,27 @@
+package db
+
+import "context"
+
+// RecipientAllowlist approves payout recipients listed for one program in the
+// payout_recipient_allowlist table. It satisfies soroban.RecipientAllowlist.
+type RecipientAllowlist struct {
+	db        *DB
+	programID string
+}
+
+// NewRecipientAllowlist returns the allowlist of programID.
+func NewRecipientAllowlist(d *DB, programID string) *RecipientAllowlist {
+	return &RecipientAllowlist{db: d, programID: programID}
+}
+
+// IsAllowed reports whether address is approved for the program. Addresses are
+// compared as stored, so callers pass canonical (upper-case) strkeys.
+func (a *RecipientAllowlist) IsAllowed(ctx context.Context, address string) (bool, error) {
+	var ok bool
+	err := a.db.QueryRowTimed(ctx, "recipient_allowlist", `
+SELECT EXISTS(
+  SELECT 1 FROM payout_recipient_allowlist WHERE program_id = $1 AND address = $2
+)
+`, a.programID, address).Scan(&ok)
+	return ok, err
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultSlowQueryThreshold is how long a timed query may take before it's logged as slow
+const DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+// QueryStats describes one finished timed query.
+type QueryStats struct {
+	Label    string        // stable name of the query, e.g. "leaderboard"
+	Duration time.Duration // from sending the query until its rows were read or closed
+	Rows     int64         // rows read by the caller
+	Err      error         // the query's error, if any (pgx.ErrNoRows counts as none)
+}
+
+// QueryObserver receives the stats of every timed query, e.g. to feed a latency
+// histogram. It's called synchronously, so it must be quick.
+type QueryObserver func(QueryStats)
+
+// SetQueryObserver registers fn to receive the stats of every timed query. Call it
+// before serving; nil removes the observer.
+func (d *DB) SetQueryObserver(fn QueryObserver) {
+	d.observer = fn
+}
+
+// SetSlowQueryThreshold sets how long a timed query may take before it's logged as
+// slow. Zero restores DefaultSlowQueryThreshold; a negative value disables the log.
+func (d *DB) SetSlowQueryThreshold(threshold time.Duration) {
+	d.slowQueryThreshold = threshold
+}
+
+// QueryTimed is Pool.Query with timing: when the returned rows are exhausted or closed,
+// the duration and the number of rows read are reported under label (see QueryStats).
+func (d *DB) QueryTimed(ctx context.Context, label, sql string, args ...any) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := d.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		d.record(QueryStats{Label: label, Duration: time.Since(start), Err: err})
+		return nil, err
+	}
+	return &timedRows{Rows: rows, db: d, label: label, start: start}, nil
+}
+
+// QueryRowTimed is Pool.QueryRow with timing, reported when the row is scanned.
+func (d *DB) QueryRowTimed(ctx context.Context, label, sql string, args ...any) pgx.Row {
+	// QueryRow runs the query, so the clock starts before it
+	start := time.Now()
+	row := d.Pool.QueryRow(ctx, sql, args...)
+	return &timedRow{row: row, db: d, label: label, start: start}
+}
+
+// record reports a finished query to the observer and logs it when slow.
+func (d *DB) record(stats QueryStats) {
+	if d.observer != nil {
+		d.observer(stats)
+	}
+
+	threshold := d.slowQueryThreshold
+	if threshold == 0 {
+		threshold = DefaultSlowQueryThreshold
+	}
+	if threshold > 0 && stats.Duration >= threshold {
+		slog.Warn("slow db query",
+			"label", stats.Label,
+			"duration", stats.Duration,
+			"rows", stats.Rows,
+			"error", stats.Err,
+		)
+	}
+}
+
+// timedRows counts the rows read and records the query once, when Next runs out or
+// Close is called, whichever comes first.
+type timedRows struct {
+	pgx.Rows
+	db    *DB
+	label string
+	start time.Time
+	rows  int64
+	done  bool
+}
+
+func (r *timedRows) Next() bool {
+	if r.Rows.Next() {
+		r.rows++
+		return true
+	}
+	r.finish()
+	return false
+}
+
+func (r *timedRows) Close() {
+	r.Rows.Close()
+	r.finish()
+}
+
+func (r *timedRows) finish() {
+	if r.done {
+		return
+	}
+	r.done = true
+	r.db.record(QueryStats{Label: r.label, Duration: time.Since(r.start), Rows: r.rows, Err: r.Rows.Err()})
+}
+
+// timedRow records its query when scanned.
+type timedRow struct {
+	row   pgx.Row
+	db    *DB
+	label string
+	start time.Time
+}
+
+func (r *timedRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	stats := QueryStats{Label: r.label, Duration: time.Since(r.start)}
+	switch {
+	case err == nil:
+		stats.Rows = 1
+	case !errors.Is(err, pgx.ErrNoRows):
+		stats.Err = err
+	}
+	r.db.record(stats)
+	return err
+}
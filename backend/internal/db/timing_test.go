@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeRows yields n rows and then stops with err.
+type fakeRows struct {
+	pgx.Rows
+	n      int
+	err    error
+	closed bool
+}
+
+func (r *fakeRows) Next() bool {
+	if r.closed || r.n == 0 {
+		return false
+	}
+	r.n--
+	return true
+}
+
+func (r *fakeRows) Close()     { r.closed = true }
+func (r *fakeRows) Err() error { return r.err }
+
+type fakeRow struct{ err error }
+
+func (r fakeRow) Scan(dest ...any) error { return r.err }
+
+func observed(d *DB) *[]QueryStats {
+	var got []QueryStats
+	d.SetQueryObserver(func(s QueryStats) { got = append(got, s) })
+	return &got
+}
+
+func TestTimedRows_ReportsLabelAndRowsOnce(t *testing.T) {
+	d := &DB{}
+	got := observed(d)
+
+	rows := &timedRows{Rows: &fakeRows{n: 3}, db: d, label: "leaderboard", start: time.Now()}
+	for rows.Next() {
+	}
+	rows.Close()
+
+	if len(*got) != 1 {
+		t.Fatalf("expected 1 observation, got %d", len(*got))
+	}
+	if s := (*got)[0]; s.Label != "leaderboard" || s.Rows != 3 || s.Err != nil {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func TestTimedRows_ReportsOnEarlyClose(t *testing.T) {
+	d := &DB{}
+	got := observed(d)
+
+	rowErr := errors.New("boom")
+	rows := &timedRows{Rows: &fakeRows{n: 5, err: rowErr}, db: d, label: "projects_leaderboard", start: time.Now()}
+	rows.Next()
+	rows.Close()
+	rows.Close()
+
+	if len(*got) != 1 {
+		t.Fatalf("expected 1 observation, got %d", len(*got))
+	}
+	if s := (*got)[0]; s.Label != "projects_leaderboard" || s.Rows != 1 || !errors.Is(s.Err, rowErr) {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func TestTimedRow_NoRowsIsNotAnError(t *testing.T) {
+	d := &DB{}
+	got := observed(d)
+
+	row := &timedRow{row: fakeRow{err: pgx.ErrNoRows}, db: d, label: "contributor_stats", start: time.Now()}
+	if err := row.Scan(); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows to be returned, got %v", err)
+	}
+
+	if len(*got) != 1 {
+		t.Fatalf("expected 1 observation, got %d", len(*got))
+	}
+	if s := (*got)[0]; s.Label != "contributor_stats" || s.Rows != 0 || s.Err != nil {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func TestRecord_SlowQueryThreshold(t *testing.T) {
+	d := &DB{}
+	got := observed(d)
+
+	// A disabled slow log must not stop the observer from seeing the query.
+	d.SetSlowQueryThreshold(-1)
+	d.record(QueryStats{Label: "ecosystems_list", Duration: time.Hour})
+
+	if len(*got) != 1 || (*got)[0].Label != "ecosystems_list" {
+		t.Fatalf("unexpected observations: %+v", *got)
+	}
+}
+
+func TestQueryRowTimed_IncludesQueryTime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL not set, skipping integration test")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	d, err := Connect(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	t.Cleanup(d.Close)
+	got := observed(d)
+
+	// The sleep happens while QueryRow waits for the result, before Scan
+	var one int
+	if err := d.QueryRowTimed(ctx, "sleep", `SELECT 1 FROM pg_sleep(0.2)`).Scan(&one); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if len(*got) != 1 {
+		t.Fatalf("expected 1 observation, got %d", len(*got))
+	}
+	if s := (*got)[0]; s.Label != "sleep" || s.Duration < 200*time.Millisecond {
+		t.Fatalf("expected the query time in the duration, got %+v", s)
+	}
+}
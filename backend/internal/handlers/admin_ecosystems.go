@@ -1,13 +1,22 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/text/unicode/norm"
 
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 )
@@ -20,13 +29,109 @@ func NewEcosystemsAdminHandler(d *db.DB) *EcosystemsAdminHandler {
 	return &EcosystemsAdminHandler{db: d}
 }
 
+// ecosystemListSorts maps ?sort= values of List to whitelisted ORDER BY expressions
+// and their default direction. User input never reaches the SQL directly.
+var ecosystemListSorts = map[string]struct {
+	expr         string
+	defaultOrder string
+}{
+	"created":  {"e.created_at", "desc"},
+	"name":     {"LOWER(e.name)", "asc"},
+	"projects": {"e.cached_project_count", "desc"},
+	"users":    {"e.cached_user_count", "desc"},
+}
+
+// ecosystemListOrderBy validates sort/order and returns List's ORDER BY clause (without
+// the keyword), breaking ties by name and then id. Empty values fall back to the
+// defaults, newest first.
+func ecosystemListOrderBy(sort, order string) (string, error) {
+	sort = strings.ToLower(strings.TrimSpace(sort))
+	order = strings.ToLower(strings.TrimSpace(order))
+	if sort == "" {
+		sort = "created"
+	}
+	spec, ok := ecosystemListSorts[sort]
+	if !ok {
+		return "", fmt.Errorf("invalid sort %q", sort)
+	}
+	if order == "" {
+		order = spec.defaultOrder
+	}
+	if order != "asc" && order != "desc" {
+		return "", fmt.Errorf("invalid order %q", order)
+	}
+
+	clause := spec.expr + " " + strings.ToUpper(order)
+	if sort != "name" {
+		clause += ", LOWER(e.name) ASC"
+	}
+	return clause + ", e.id ASC", nil
+}
+
+// List returns up to 200 ecosystems. ?sort=created|name|projects|users and
+// ?order=asc|desc choose the ordering; the default is newest first.
 func (h *EcosystemsAdminHandler) List() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
 			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
 		}
 
-		rows, err := h.db.Pool.Query(c.Context(), `
+		status := strings.TrimSpace(c.Query("status"))
+		q := strings.TrimSpace(c.Query("q"))
+		orderBy, err := ecosystemListOrderBy(c.Query("sort"), c.Query("order"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_sort"})
+		}
+
+		// Build WHERE clause and args
+		var conditions []string
+		var args []any
+		argPos := 1
+
+		// Soft-deleted ecosystems are hidden unless explicitly requested (e.g. to restore one).
+		if !c.QueryBool("include_deleted", false) {
+			conditions = append(conditions, "e.deleted_at IS NULL")
+		}
+
+		if status != "" {
+			if status != "active" && status != "inactive" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+			}
+			conditions = append(conditions, fmt.Sprintf("e.status = $%d", argPos))
+			args = append(args, status)
+			argPos++
+		}
+
+		// Case-insensitive substring search over name, slug and description
+		if q != "" {
+			conditions = append(conditions, fmt.Sprintf("(e.name ILIKE $%d OR e.slug ILIKE $%d OR e.description ILIKE $%d)", argPos, argPos, argPos))
+			args = append(args, "%"+escapeLike(q)+"%")
+			argPos++
+		}
+
+		// Only ecosystems with a live project owned by this user. project_count and
+		// user_count stay ecosystem-wide; owner_project_count is the user's share.
+		ownerProjectCount := "NULL::bigint"
+		if owner := strings.TrimSpace(c.Query("owner_user_id")); owner != "" {
+			ownerID, err := uuid.Parse(owner)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_owner_user_id"})
+			}
+			ownerProjectCount = fmt.Sprintf(`(
+  SELECT COUNT(*) FROM projects p_o
+  WHERE p_o.ecosystem_id = e.id AND p_o.owner_user_id = $%d AND p_o.deleted_at IS NULL
+)`, argPos)
+			conditions = append(conditions, ownerProjectCount+" > 0")
+			args = append(args, ownerID)
+			argPos++
+		}
+
+		whereClause := "TRUE"
+		if len(conditions) > 0 {
+			whereClause = strings.Join(conditions, " AND ")
+		}
+
+		rows, err := h.db.QueryTimed(c.Context(), "admin_ecosystems_list", fmt.Sprintf(`
 SELECT
   e.id,
   e.slug,
@@ -36,14 +141,18 @@ SELECT
   e.status,
   e.created_at,
   e.updated_at,
-  COUNT(p.id) AS project_count,
-  COUNT(DISTINCT p.owner_user_id) AS user_count
+  e.languages,
+  e.deleted_at,
+  e.cached_project_count,
+  e.cached_user_count,
+  cc.contributor_count,
+  %s AS owner_project_count
 FROM ecosystems e
-LEFT JOIN projects p ON p.ecosystem_id = e.id
-GROUP BY e.id
-ORDER BY e.created_at DESC
+%s
+WHERE %s
+ORDER BY %s
 LIMIT 200
-`)
+`, ownerProjectCount, ecosystemContributorCountLateralSQL, whereClause, orderBy), args...)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystems_list_failed"})
 		}
@@ -55,35 +164,115 @@ LIMIT 200
 			var slug, name, status string
 			var desc, website *string
 			var createdAt, updatedAt time.Time
+			var languagesJSON []byte
+			var deletedAt *time.Time
 			var projectCnt int64
 			var userCnt int64
-			if err := rows.Scan(&id, &slug, &name, &desc, &website, &status, &createdAt, &updatedAt, &projectCnt, &userCnt); err != nil {
+			var contributorCnt int64
+			var ownerProjectCnt *int64
+			if err := rows.Scan(&id, &slug, &name, &desc, &website, &status, &createdAt, &updatedAt, &languagesJSON, &deletedAt, &projectCnt, &userCnt, &contributorCnt, &ownerProjectCnt); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystems_list_failed"})
 			}
-			out = append(out, fiber.Map{
-				"id":          id.String(),
-				"slug":        slug,
-				"name":        name,
-				"description": desc,
-				"website_url": website,
-				"status":      status,
-				"created_at":  createdAt,
-				"updated_at":  updatedAt,
-				"project_count": projectCnt,
-				"user_count": userCnt,
-			})
+			row := fiber.Map{
+				"id":                id.String(),
+				"slug":              slug,
+				"name":              name,
+				"description":       desc,
+				"website_url":       website,
+				"status":            status,
+				"created_at":        createdAt,
+				"updated_at":        updatedAt,
+				"deleted_at":        deletedAt,
+				"project_count":     projectCnt,
+				"user_count":        userCnt,
+				"contributor_count": contributorCnt,
+			}
+			if ownerProjectCnt != nil {
+				row["owner_project_count"] = *ownerProjectCnt
+			}
+			setAdminEcosystemLanguages(row, id, languagesJSON)
+			out = append(out, row)
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ecosystems": out})
 	}
 }
 
+// Get returns a single ecosystem by id in the same shape as a List row.
+func (h *EcosystemsAdminHandler) Get() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		ecoID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_id"})
+		}
+
+		var id uuid.UUID
+		var slug, name, status string
+		var desc, website *string
+		var createdAt, updatedAt time.Time
+		var languagesJSON []byte
+		var projectCnt int64
+		var userCnt int64
+		var contributorCnt int64
+		err = h.db.Pool.QueryRow(c.Context(), fmt.Sprintf(`
+SELECT
+  e.id,
+  e.slug,
+  e.name,
+  e.description,
+  e.website_url,
+  e.status,
+  e.created_at,
+  e.updated_at,
+  e.languages,
+  e.cached_project_count,
+  e.cached_user_count,
+  cc.contributor_count
+FROM ecosystems e
+%s
+WHERE e.id = $1 AND e.deleted_at IS NULL
+`, ecosystemContributorCountLateralSQL), ecoID).Scan(&id, &slug, &name, &desc, &website, &status, &createdAt, &updatedAt, &languagesJSON, &projectCnt, &userCnt, &contributorCnt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_lookup_failed"})
+		}
+
+		row := fiber.Map{
+			"id":                id.String(),
+			"slug":              slug,
+			"name":              name,
+			"description":       desc,
+			"website_url":       website,
+			"status":            status,
+			"created_at":        createdAt,
+			"updated_at":        updatedAt,
+			"project_count":     projectCnt,
+			"user_count":        userCnt,
+			"contributor_count": contributorCnt,
+		}
+		setAdminEcosystemLanguages(row, id, languagesJSON)
+		return c.Status(fiber.StatusOK).JSON(row)
+	}
+}
+
+// Language is one entry of an ecosystem's language breakdown.
+type Language struct {
+	Name       string  `json:"name"`
+	Percentage float64 `json:"percentage"`
+}
+
 type ecosystemUpsertRequest struct {
-	Slug       string `json:"slug"`
-	Name       string `json:"name"`
-	Description string `json:"description"`
-	WebsiteURL string `json:"website_url"`
-	Status     string `json:"status"` // active|inactive
+	Slug        string     `json:"slug"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	WebsiteURL  string     `json:"website_url"`
+	Status      string     `json:"status"`    // active|inactive
+	Languages   []Language `json:"languages"` // nil = empty list
 }
 
 func (h *EcosystemsAdminHandler) Create() fiber.Handler {
@@ -95,36 +284,210 @@ func (h *EcosystemsAdminHandler) Create() fiber.Handler {
 		if err := c.BodyParser(&req); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
 		}
-		name := strings.TrimSpace(req.Name)
-		if name == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name_required"})
+		in, errBody := prepareEcosystemInsert(req)
+		if errBody != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(errBody)
 		}
-		// Auto-generate slug from name (users never see/type slug)
-		slug := normalizeSlug(name)
-		if slug == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name_must_contain_valid_characters"})
-		}
-		status := strings.TrimSpace(req.Status)
-		if status == "" {
-			status = "active"
-		}
-		if status != "active" && status != "inactive" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+
+		ctx := c.Context()
+		tx, err := h.db.Pool.Begin(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_create_failed"})
 		}
+		defer func() { _ = tx.Rollback(ctx) }()
 
-		var id uuid.UUID
-		err := h.db.Pool.QueryRow(c.Context(), `
-INSERT INTO ecosystems (slug, name, description, website_url, status)
-VALUES ($1, $2, NULLIF($3,''), NULLIF($4,''), $5)
-RETURNING id
-`, slug, name, strings.TrimSpace(req.Description), strings.TrimSpace(req.WebsiteURL), status).Scan(&id)
+		id, err := insertEcosystem(ctx, tx, in)
+		if isUniqueViolation(err) {
+			return h.slugConflict(c, in.slug)
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_create_failed"})
 		}
+		if err := auditEcosystemCreate(ctx, tx, id, auditActor(c)); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_create_failed"})
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_create_failed"})
+		}
 		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": id.String()})
 	}
 }
 
+// BulkCreate inserts an array of ecosystems in a single transaction. By default the
+// whole batch rolls back if any item fails; with ?partial=true failing items are
+// skipped and the rest are committed. Results are reported per item either way.
+func (h *EcosystemsAdminHandler) BulkCreate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		var reqs []ecosystemUpsertRequest
+		if err := c.BodyParser(&reqs); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		if len(reqs) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystems_required"})
+		}
+		if len(reqs) > maxBulkEcosystems {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "too_many_ecosystems", "max": maxBulkEcosystems})
+		}
+		partial := c.QueryBool("partial", false)
+
+		ctx := c.Context()
+		tx, err := h.db.Pool.Begin(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_bulk_create_failed"})
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		actor := auditActor(c)
+		results := make([]fiber.Map, len(reqs))
+		seen := make(map[string]int, len(reqs))
+		failed := 0
+		for i, req := range reqs {
+			in, errBody := prepareEcosystemInsert(req)
+			if errBody != nil {
+				errBody["index"] = i
+				results[i] = errBody
+				failed++
+				continue
+			}
+			if j, dup := seen[in.slug]; dup {
+				results[i] = fiber.Map{"index": i, "error": "slug_already_exists", "slug": in.slug, "duplicate_of": j}
+				failed++
+				continue
+			}
+			seen[in.slug] = i
+
+			// Savepoint per item so one failed insert doesn't abort the transaction.
+			sp, err := tx.Begin(ctx)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_bulk_create_failed"})
+			}
+			id, err := insertEcosystem(ctx, sp, in)
+			if err == nil {
+				err = auditEcosystemCreate(ctx, sp, id, actor)
+			}
+			if err != nil {
+				_ = sp.Rollback(ctx)
+				code := "ecosystem_create_failed"
+				if isUniqueViolation(err) {
+					code = "slug_already_exists"
+				}
+				results[i] = fiber.Map{"index": i, "error": code, "slug": in.slug}
+				failed++
+				continue
+			}
+			if err := sp.Commit(ctx); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_bulk_create_failed"})
+			}
+			results[i] = fiber.Map{"index": i, "id": id.String(), "slug": in.slug}
+		}
+
+		if failed > 0 && !partial {
+			// Nothing was written; drop the ids of items that would have succeeded.
+			for _, r := range results {
+				if _, ok := r["id"]; ok {
+					delete(r, "id")
+					r["rolled_back"] = true
+				}
+			}
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "ecosystem_bulk_create_failed",
+				"results": results,
+				"summary": fiber.Map{"total": len(reqs), "created": 0, "failed": failed},
+			})
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_bulk_create_failed"})
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"results": results,
+			"summary": fiber.Map{"total": len(reqs), "created": len(reqs) - failed, "failed": failed},
+		})
+	}
+}
+
+// maxBulkEcosystems caps the size of a single BulkCreate request.
+const maxBulkEcosystems = 500
+
+// ecosystemInsert is a validated, normalized create request.
+type ecosystemInsert struct {
+	slug          string
+	name          string
+	description   string
+	websiteURL    string
+	status        string
+	languagesJSON string
+}
+
+// prepareEcosystemInsert validates a create request and normalizes its fields.
+// On failure it returns the JSON error body to send back.
+func prepareEcosystemInsert(req ecosystemUpsertRequest) (ecosystemInsert, fiber.Map) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ecosystemInsert{}, fiber.Map{"error": "name_required"}
+	}
+	// Auto-generate slug from name (users never see/type slug)
+	slug := normalizeSlug(name)
+	if slug == "" {
+		return ecosystemInsert{}, fiber.Map{"error": "name_must_contain_valid_characters"}
+	}
+	status := strings.TrimSpace(req.Status)
+	if status == "" {
+		status = "active"
+	}
+	if status != "active" && status != "inactive" {
+		return ecosystemInsert{}, fiber.Map{"error": "invalid_status"}
+	}
+	if err := validateLanguages(req.Languages); err != nil {
+		return ecosystemInsert{}, fiber.Map{"error": "invalid_language_percentages", "message": err.Error()}
+	}
+	languages := req.Languages
+	if languages == nil {
+		languages = []Language{}
+	}
+	languagesJSON, _ := json.Marshal(languages)
+
+	return ecosystemInsert{
+		slug:          slug,
+		name:          name,
+		description:   strings.TrimSpace(req.Description),
+		websiteURL:    strings.TrimSpace(req.WebsiteURL),
+		status:        status,
+		languagesJSON: string(languagesJSON),
+	}, nil
+}
+
+// rowQuerier is satisfied by both *pgxpool.Pool and pgx.Tx.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// auditEcosystemCreate records the freshly inserted row as a create entry.
+func auditEcosystemCreate(ctx context.Context, tx pgx.Tx, id uuid.UUID, actor *uuid.UUID) error {
+	after, err := snapshotEcosystem(ctx, tx, id, false)
+	if err != nil {
+		return err
+	}
+	return writeEcosystemAudit(ctx, tx, id, actor, ecosystemAuditCreate, nil, after)
+}
+
+func insertEcosystem(ctx context.Context, q rowQuerier, in ecosystemInsert) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := q.QueryRow(ctx, `
+INSERT INTO ecosystems (slug, name, description, website_url, status, languages)
+VALUES ($1, $2, NULLIF($3,''), NULLIF($4,''), $5, $6::jsonb)
+RETURNING id
+`, in.slug, in.name, in.description, in.websiteURL, in.status, in.languagesJSON).Scan(&id)
+	return id, err
+}
+
+// Update replaces every field of an ecosystem (PUT semantics), like Upsert does by
+// slug: omitted description/website_url are cleared, status defaults to active and
+// languages to an empty list. The slug follows the name. Use Patch to change only
+// some fields.
 func (h *EcosystemsAdminHandler) Update() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
@@ -138,45 +501,388 @@ func (h *EcosystemsAdminHandler) Update() fiber.Handler {
 		if err := c.BodyParser(&req); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
 		}
+		in, errBody := prepareEcosystemInsert(req)
+		if errBody != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(errBody)
+		}
 
-		name := strings.TrimSpace(req.Name)
-		status := strings.TrimSpace(req.Status)
+		return h.applyEcosystemUpdate(c, ecoID, ecosystemPatch{
+			sets: []string{
+				"slug = $2",
+				"name = $3",
+				"description = NULLIF($4,'')",
+				"website_url = NULLIF($5,'')",
+				"status = $6",
+				"languages = $7::jsonb",
+			},
+			args: []any{in.slug, in.name, in.description, in.websiteURL, in.status, in.languagesJSON},
+			slug: in.slug,
+		})
+	}
+}
 
-		if status != "" && status != "active" && status != "inactive" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+// Patch changes only the fields present in the JSON body (PATCH semantics). An explicit
+// empty string or null clears description/website_url, and an empty array or null
+// clears languages. name and status cannot be cleared; changing the name also changes
+// the slug, as in Update.
+func (h *EcosystemsAdminHandler) Patch() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		ecoID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_id"})
+		}
+		patch, errBody := parseEcosystemPatch(c.Body())
+		if errBody != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(errBody)
 		}
+		return h.applyEcosystemUpdate(c, ecoID, patch)
+	}
+}
 
-		// Auto-generate slug from name if name is provided
-		var slugVal *string
-		if name != "" {
-			slug := normalizeSlug(name)
-			if slug == "" {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name_must_contain_valid_characters"})
-			}
-			slugVal = &slug
+// ecosystemPatch is a validated set of column assignments for an ecosystem UPDATE.
+// $1 is reserved for the ecosystem id, so sets reference args starting at $2.
+type ecosystemPatch struct {
+	sets []string
+	args []any
+	slug string // new slug, if the update renames the ecosystem
+}
+
+func (p *ecosystemPatch) set(column, expr string, v any) {
+	p.args = append(p.args, v)
+	p.sets = append(p.sets, fmt.Sprintf("%s = "+expr, column, len(p.args)+1))
+}
+
+// parseEcosystemPatch validates a PATCH body. Presence is decided from the raw JSON
+// object, so an omitted field and a field set to "" or [] are told apart.
+func parseEcosystemPatch(body []byte) (ecosystemPatch, fiber.Map) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil || raw == nil {
+		return ecosystemPatch{}, fiber.Map{"error": "invalid_json"}
+	}
+
+	var p ecosystemPatch
+	decodeString := func(field string) (string, fiber.Map) {
+		var v *string
+		if err := json.Unmarshal(raw[field], &v); err != nil {
+			return "", fiber.Map{"error": "invalid_json", "field": field}
 		}
+		if v == nil {
+			return "", nil
+		}
+		return strings.TrimSpace(*v), nil
+	}
 
-		ct, err := h.db.Pool.Exec(c.Context(), `
-UPDATE ecosystems
-SET slug = COALESCE($2, slug),
-    name = COALESCE(NULLIF($3,''), name),
-    description = COALESCE(NULLIF($4,''), description),
-    website_url = COALESCE(NULLIF($5,''), website_url),
-    status = COALESCE(NULLIF($6,''), status),
+	if _, ok := raw["name"]; ok {
+		name, errBody := decodeString("name")
+		if errBody != nil {
+			return ecosystemPatch{}, errBody
+		}
+		if name == "" {
+			return ecosystemPatch{}, fiber.Map{"error": "name_required"}
+		}
+		slug := normalizeSlug(name)
+		if slug == "" {
+			return ecosystemPatch{}, fiber.Map{"error": "name_must_contain_valid_characters"}
+		}
+		p.set("slug", "$%d", slug)
+		p.set("name", "$%d", name)
+		p.slug = slug
+	}
+	for _, field := range []string{"description", "website_url"} {
+		if _, ok := raw[field]; !ok {
+			continue
+		}
+		value, errBody := decodeString(field)
+		if errBody != nil {
+			return ecosystemPatch{}, errBody
+		}
+		p.set(field, "NULLIF($%d,'')", value)
+	}
+	if _, ok := raw["status"]; ok {
+		status, errBody := decodeString("status")
+		if errBody != nil {
+			return ecosystemPatch{}, errBody
+		}
+		if status != "active" && status != "inactive" {
+			return ecosystemPatch{}, fiber.Map{"error": "invalid_status"}
+		}
+		p.set("status", "$%d", status)
+	}
+	if _, ok := raw["languages"]; ok {
+		var langs []Language
+		if err := json.Unmarshal(raw["languages"], &langs); err != nil {
+			return ecosystemPatch{}, fiber.Map{"error": "invalid_json", "field": "languages"}
+		}
+		if langs == nil {
+			langs = []Language{}
+		}
+		if err := validateLanguages(langs); err != nil {
+			return ecosystemPatch{}, fiber.Map{"error": "invalid_language_percentages", "message": err.Error()}
+		}
+		b, _ := json.Marshal(langs)
+		p.set("languages", "$%d::jsonb", string(b))
+	}
+
+	if len(p.sets) == 0 {
+		return ecosystemPatch{}, fiber.Map{"error": "no_fields_to_update"}
+	}
+	return p, nil
+}
+
+// applyEcosystemUpdate runs patch against a live ecosystem and audits the change.
+func (h *EcosystemsAdminHandler) applyEcosystemUpdate(c *fiber.Ctx, ecoID uuid.UUID, patch ecosystemPatch) error {
+	ctx := c.Context()
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_update_failed"})
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	before, err := snapshotEcosystem(ctx, tx, ecoID, false)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_update_failed"})
+	}
+
+	query := "UPDATE ecosystems SET " + strings.Join(patch.sets, ", ") + ", updated_at = now() WHERE id = $1"
+	_, err = tx.Exec(ctx, query, append([]any{ecoID}, patch.args...)...)
+	if isUniqueViolation(err) && patch.slug != "" {
+		return h.slugConflict(c, patch.slug)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_update_failed"})
+	}
+
+	after, err := snapshotEcosystem(ctx, tx, ecoID, false)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_update_failed"})
+	}
+	if oldSlug, _ := before["slug"].(string); patch.slug != "" && oldSlug != patch.slug {
+		if err := recordSlugAlias(ctx, tx, ecoID, oldSlug, patch.slug); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_update_failed"})
+		}
+	}
+	if err := writeEcosystemAudit(ctx, tx, ecoID, auditActor(c), ecosystemAuditUpdate, before, after); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_update_failed"})
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_update_failed"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+}
+
+// Upsert creates or replaces the ecosystem identified by the :slug path parameter in a
+// single INSERT ... ON CONFLICT statement. Unlike Update, every field is set exactly as
+// given: omitted description/website_url are cleared, status defaults to active and
+// languages to an empty list. Soft-deleted ecosystems must be restored first.
+func (h *EcosystemsAdminHandler) Upsert() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		// The slug must already be in normalized form so re-applies always hit the same row.
+		slug := c.Params("slug")
+		if slug == "" || normalizeSlug(slug) != slug {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_slug"})
+		}
+		var req ecosystemUpsertRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		in, errBody := prepareEcosystemInsert(req)
+		if errBody != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(errBody)
+		}
+		// The path is the identity here; the name does not rename the slug.
+		in.slug = slug
+
+		ctx := c.Context()
+		tx, err := h.db.Pool.Begin(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_upsert_failed"})
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		// Snapshot the existing row (if any) for the audit log.
+		var before map[string]any
+		var existingID uuid.UUID
+		err = tx.QueryRow(ctx, `SELECT id FROM ecosystems WHERE slug = $1 AND deleted_at IS NULL`, slug).Scan(&existingID)
+		if err == nil {
+			before, err = snapshotEcosystem(ctx, tx, existingID, false)
+		}
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_upsert_failed"})
+		}
+
+		var id uuid.UUID
+		var created bool
+		err = tx.QueryRow(ctx, `
+INSERT INTO ecosystems (slug, name, description, website_url, status, languages)
+VALUES ($1, $2, NULLIF($3,''), NULLIF($4,''), $5, $6::jsonb)
+ON CONFLICT (slug) DO UPDATE
+SET name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    website_url = EXCLUDED.website_url,
+    status = EXCLUDED.status,
+    languages = EXCLUDED.languages,
     updated_at = now()
+WHERE ecosystems.deleted_at IS NULL
+RETURNING id, (xmax = 0) AS created
+`, in.slug, in.name, in.description, in.websiteURL, in.status, in.languagesJSON).Scan(&id, &created)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The slug belongs to a soft-deleted ecosystem.
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "ecosystem_deleted", "slug": slug})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_upsert_failed"})
+		}
+
+		after, err := snapshotEcosystem(ctx, tx, id, false)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_upsert_failed"})
+		}
+		action := ecosystemAuditUpdate
+		if created {
+			action = ecosystemAuditCreate
+		}
+		if err := writeEcosystemAudit(ctx, tx, id, auditActor(c), action, before, after); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_upsert_failed"})
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_upsert_failed"})
+		}
+
+		status := fiber.StatusOK
+		if created {
+			status = fiber.StatusCreated
+		}
+		return c.Status(status).JSON(fiber.Map{"id": id.String(), "slug": slug, "created": created})
+	}
+}
+
+func (h *EcosystemsAdminHandler) Delete() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		ecoID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_id"})
+		}
+
+		force := c.QueryBool("force", false)
+
+		ctx := c.Context()
+		tx, err := h.db.Pool.Begin(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_failed"})
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		// A forced delete may also purge an already soft-deleted row.
+		before, err := snapshotEcosystem(ctx, tx, ecoID, force)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_failed"})
+		}
+
+		var after map[string]any
+		action := ecosystemAuditHardDelete
+		if !force {
+			// Default to a soft delete; projects keep their ecosystem_id so a restore is lossless.
+			if _, err := tx.Exec(ctx, `
+UPDATE ecosystems
+SET deleted_at = now(), updated_at = now()
 WHERE id = $1
-`, ecoID, slugVal, name, strings.TrimSpace(req.Description), strings.TrimSpace(req.WebsiteURL), status)
-		if errors.Is(err, pgx.ErrNoRows) || ct.RowsAffected() == 0 {
+`, ecoID); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_failed"})
+			}
+			if after, err = snapshotEcosystem(ctx, tx, ecoID, true); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_failed"})
+			}
+			action = ecosystemAuditDelete
+		} else {
+			// Check if ecosystem has any projects
+			var projectCount int64
+			if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM projects WHERE ecosystem_id = $1`, ecoID).Scan(&projectCount); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_check_failed"})
+			}
+			if projectCount > 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_has_projects", "message": "Cannot delete ecosystem with existing projects"})
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM ecosystems WHERE id = $1`, ecoID); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_failed"})
+			}
+		}
+
+		if err := writeEcosystemAudit(ctx, tx, ecoID, auditActor(c), action, before, after); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_failed"})
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_failed"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// Restore clears deleted_at on a soft-deleted ecosystem.
+func (h *EcosystemsAdminHandler) Restore() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		ecoID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_id"})
+		}
+
+		ctx := c.Context()
+		tx, err := h.db.Pool.Begin(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_restore_failed"})
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		before, err := snapshotEcosystem(ctx, tx, ecoID, true)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && before["deleted_at"] == nil) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
 		}
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_update_failed"})
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_restore_failed"})
+		}
+
+		if _, err := tx.Exec(ctx, `
+UPDATE ecosystems
+SET deleted_at = NULL, updated_at = now()
+WHERE id = $1
+`, ecoID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_restore_failed"})
+		}
+		after, err := snapshotEcosystem(ctx, tx, ecoID, false)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_restore_failed"})
+		}
+		if err := writeEcosystemAudit(ctx, tx, ecoID, auditActor(c), ecosystemAuditRestore, before, after); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_restore_failed"})
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_restore_failed"})
 		}
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
 	}
 }
 
-func (h *EcosystemsAdminHandler) Delete() fiber.Handler {
+// Recount recomputes an ecosystem's cached project_count and user_count from the
+// projects table (see recount_ecosystem in the migrations), repairing any drift in the
+// values the project trigger maintains. Responds with the counts before and after.
+func (h *EcosystemsAdminHandler) Recount() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
 			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
@@ -186,37 +892,383 @@ func (h *EcosystemsAdminHandler) Delete() fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_id"})
 		}
 
-		// Check if ecosystem has any projects
-		var projectCount int64
-		if err := h.db.Pool.QueryRow(c.Context(), `SELECT COUNT(*) FROM projects WHERE ecosystem_id = $1`, ecoID).Scan(&projectCount); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_check_failed"})
+		ctx := c.Context()
+		tx, err := h.db.Pool.Begin(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_recount_failed"})
 		}
-		if projectCount > 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_has_projects", "message": "Cannot delete ecosystem with existing projects"})
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		// Lock the row so a concurrent recount or trigger update waits for this one.
+		var beforeProjects, beforeUsers int64
+		err = tx.QueryRow(ctx, `
+SELECT cached_project_count, cached_user_count
+FROM ecosystems
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`, ecoID).Scan(&beforeProjects, &beforeUsers)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_recount_failed"})
 		}
 
-		ct, err := h.db.Pool.Exec(c.Context(), `DELETE FROM ecosystems WHERE id = $1`, ecoID)
-		if errors.Is(err, pgx.ErrNoRows) || ct.RowsAffected() == 0 {
+		var projectCnt, userCnt int64
+		if _, err := tx.Exec(ctx, `SELECT recount_ecosystem($1)`, ecoID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_recount_failed"})
+		}
+		if err := tx.QueryRow(ctx, `
+SELECT cached_project_count, cached_user_count FROM ecosystems WHERE id = $1
+`, ecoID).Scan(&projectCnt, &userCnt); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_recount_failed"})
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_recount_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"id":            ecoID.String(),
+			"project_count": projectCnt,
+			"user_count":    userCnt,
+			"previous": fiber.Map{
+				"project_count": beforeProjects,
+				"user_count":    beforeUsers,
+			},
+		})
+	}
+}
+
+// maxMoveProjects caps the number of projects a single MoveProjects request may move.
+const maxMoveProjects = 500
+
+type moveProjectsRequest struct {
+	ProjectIDs        []string `json:"project_ids"`
+	TargetEcosystemID string   `json:"target_ecosystem_id"`
+}
+
+// MoveProjects reassigns projects from the ecosystem in the path to target_ecosystem_id
+// in one transaction, then recounts both ecosystems. Both must exist, be active and not
+// be soft-deleted. Projects that aren't live members of the source ecosystem are left
+// alone and not counted as moved.
+func (h *EcosystemsAdminHandler) MoveProjects() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		sourceID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_id"})
+		}
+
+		var req moveProjectsRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		targetID, err := uuid.Parse(strings.TrimSpace(req.TargetEcosystemID))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_target_ecosystem_id"})
+		}
+		if targetID == sourceID {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "same_ecosystem"})
+		}
+		if len(req.ProjectIDs) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "project_ids_required"})
+		}
+		if len(req.ProjectIDs) > maxMoveProjects {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "too_many_projects", "max": maxMoveProjects})
+		}
+		projectIDs := make([]uuid.UUID, 0, len(req.ProjectIDs))
+		for _, raw := range req.ProjectIDs {
+			id, err := uuid.Parse(strings.TrimSpace(raw))
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id", "project_id": raw})
+			}
+			projectIDs = append(projectIDs, id)
+		}
+
+		ctx := c.Context()
+		tx, err := h.db.Pool.Begin(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_move_projects_failed"})
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		// Lock both ecosystems in id order so two opposite moves can't deadlock.
+		rows, err := tx.Query(ctx, `
+SELECT id, status, deleted_at IS NOT NULL
+FROM ecosystems
+WHERE id = ANY($1)
+ORDER BY id
+FOR UPDATE
+`, []uuid.UUID{sourceID, targetID})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_move_projects_failed"})
+		}
+		type ecosystemState struct {
+			status  string
+			deleted bool
+		}
+		states := make(map[uuid.UUID]ecosystemState, 2)
+		for rows.Next() {
+			var id uuid.UUID
+			var st ecosystemState
+			if err := rows.Scan(&id, &st.status, &st.deleted); err != nil {
+				rows.Close()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_move_projects_failed"})
+			}
+			states[id] = st
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_move_projects_failed"})
+		}
+
+		src, ok := states[sourceID]
+		if !ok || src.deleted {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
 		}
+		if src.status != "active" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_inactive"})
+		}
+		dst, ok := states[targetID]
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "target_ecosystem_not_found"})
+		}
+		if dst.deleted {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "target_ecosystem_deleted"})
+		}
+		if dst.status != "active" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "target_ecosystem_inactive"})
+		}
+
+		tag, err := tx.Exec(ctx, `
+UPDATE projects
+SET ecosystem_id = $2, updated_at = now()
+WHERE id = ANY($3) AND ecosystem_id = $1 AND deleted_at IS NULL
+`, sourceID, targetID, projectIDs)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_delete_failed"})
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_move_projects_failed"})
 		}
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+
+		counts := make(map[uuid.UUID]fiber.Map, 2)
+		for _, id := range []uuid.UUID{sourceID, targetID} {
+			var projectCnt, userCnt int64
+			if _, err := tx.Exec(ctx, `SELECT recount_ecosystem($1)`, id); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_move_projects_failed"})
+			}
+			if err := tx.QueryRow(ctx, `
+SELECT cached_project_count, cached_user_count FROM ecosystems WHERE id = $1
+`, id).Scan(&projectCnt, &userCnt); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_move_projects_failed"})
+			}
+			counts[id] = fiber.Map{"id": id.String(), "project_count": projectCnt, "user_count": userCnt}
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_move_projects_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"moved":  tag.RowsAffected(),
+			"source": counts[sourceID],
+			"target": counts[targetID],
+		})
 	}
 }
 
-func normalizeSlug(s string) string {
-	v := strings.ToLower(strings.TrimSpace(s))
-	v = strings.ReplaceAll(v, " ", "-")
-	// allow: a-z 0-9 - _
-	out := make([]rune, 0, len(v))
-	for _, r := range v {
-		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
-			out = append(out, r)
+// validateLanguages checks that each percentage is within 0-100 and that a
+// non-empty breakdown sums to 100, allowing +/-1 for rounding.
+func validateLanguages(langs []Language) error {
+	if len(langs) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, l := range langs {
+		if strings.TrimSpace(l.Name) == "" {
+			return fmt.Errorf("language name is required")
 		}
+		if l.Percentage < 0 || l.Percentage > 100 {
+			return fmt.Errorf("percentage for %s must be between 0 and 100", l.Name)
+		}
+		sum += l.Percentage
+	}
+	if sum < 99 || sum > 101 {
+		return fmt.Errorf("percentages must sum to 100, got %.2f", sum)
 	}
-	return strings.Trim(string(out), "-")
+	return nil
+}
+
+// parseLanguages decodes the languages JSONB column, defaulting to an empty list.
+func parseLanguages(b []byte) []Language {
+	langs, _ := decodeLanguages(b)
+	return langs
 }
 
+// decodeLanguages is parseLanguages reporting why a malformed value (e.g. an object
+// where a list belongs) decoded to an empty list.
+func decodeLanguages(b []byte) ([]Language, error) {
+	langs := []Language{}
+	if len(b) == 0 {
+		return langs, nil
+	}
+	if err := json.Unmarshal(b, &langs); err != nil {
+		return []Language{}, err
+	}
+	return langs, nil
+}
 
+// setAdminEcosystemLanguages decodes languagesJSON into row. A malformed value is
+// logged and listed under the row's "_warnings" so admins can find and fix it, rather
+// than failing the whole response.
+func setAdminEcosystemLanguages(row fiber.Map, id uuid.UUID, languagesJSON []byte) {
+	langs, err := decodeLanguages(languagesJSON)
+	row["languages"] = langs
+	if err != nil {
+		slog.Warn("malformed ecosystem jsonb column",
+			"ecosystem_id", id.String(),
+			"column", "languages",
+			"error", err,
+		)
+		row["_warnings"] = []string{"languages: " + err.Error()}
+	}
+}
+
+// recordSlugAlias keeps oldSlug resolving to a renamed ecosystem. An alias that was
+// already taken moves to this ecosystem, the latest rename wins, and newSlug stops
+// being an alias since it's now a current slug.
+func recordSlugAlias(ctx context.Context, tx pgx.Tx, ecoID uuid.UUID, oldSlug, newSlug string) error {
+	if _, err := tx.Exec(ctx, `
+INSERT INTO ecosystem_slug_aliases (slug, ecosystem_id)
+VALUES (LOWER($1), $2)
+ON CONFLICT (slug) DO UPDATE SET ecosystem_id = EXCLUDED.ecosystem_id, created_at = now()
+`, oldSlug, ecoID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `DELETE FROM ecosystem_slug_aliases WHERE slug = LOWER($1)`, newSlug)
+	return err
+}
+
+// slugConflict responds with 409 and, when possible, a free slug the client can retry with.
+func (h *EcosystemsAdminHandler) slugConflict(c *fiber.Ctx, slug string) error {
+	resp := fiber.Map{"error": "slug_already_exists", "slug": slug}
+	if taken, err := h.takenSlugs(c.Context(), slug); err == nil {
+		resp["suggested_slug"] = nextAvailableSlug(slug, taken)
+	}
+	return c.Status(fiber.StatusConflict).JSON(resp)
+}
+
+// takenSlugs returns the existing slugs among slug and slug-<suffix>, the ones
+// nextAvailableSlug has to avoid.
+func (h *EcosystemsAdminHandler) takenSlugs(ctx context.Context, slug string) ([]string, error) {
+	rows, err := h.db.Pool.Query(ctx, `SELECT slug FROM ecosystems WHERE slug = $1 OR slug LIKE $2`, slug, escapeLike(slug)+"-%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var taken []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		taken = append(taken, s)
+	}
+	return taken, rows.Err()
+}
+
+// SlugPreview returns the slug Create would generate for ?name= and whether it is
+// still free, so the create form can show the final URL before submitting. A taken
+// slug comes with the suggestion Create's 409 response would carry.
+func (h *EcosystemsAdminHandler) SlugPreview() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		name := strings.TrimSpace(c.Query("name"))
+		if name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name_required"})
+		}
+		slug := normalizeSlug(name)
+		if slug == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name_must_contain_valid_characters"})
+		}
+
+		taken, err := h.takenSlugs(c.Context(), slug)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "slug_preview_failed"})
+		}
+		available := true
+		for _, t := range taken {
+			if t == slug {
+				available = false
+				break
+			}
+		}
+
+		resp := fiber.Map{"slug": slug, "available": available, "suggestion": nil}
+		if !available {
+			resp["suggestion"] = nextAvailableSlug(slug, taken)
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
+	}
+}
+
+// nextAvailableSlug returns the first of base-2, base-3, ... not present in taken.
+func nextAvailableSlug(base string, taken []string) string {
+	used := make(map[string]struct{}, len(taken))
+	for _, t := range taken {
+		used[t] = struct{}{}
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", base, n)
+		if _, ok := used[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+// isUniqueViolation reports whether err is a Postgres unique_violation (SQLSTATE 23505).
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// slugTransliterations spells out Latin letters that NFKD doesn't split into a base
+// letter and accents.
+var slugTransliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d",
+	'ł': "l", 'þ': "th", 'ı': "i", 'ŋ': "ng",
+}
+
+// normalizeSlug derives a slug from a name: lower-case a-z, 0-9, '-' and '_', with
+// spaces as '-'. Accented Latin letters keep their base letter ("Café" -> "cafe") and
+// compatibility forms fold to ASCII (fullwidth letters, ligatures). A name written
+// only in a script without an ASCII spelling (e.g. "日本語") gets "eco-" and a short
+// hash of the name, so it is stable; "" means the name has no letters or digits.
+func normalizeSlug(s string) string {
+	name := strings.ToLower(strings.TrimSpace(s))
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(name) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		default:
+			// Accents (combining marks) and other scripts are dropped
+			b.WriteString(slugTransliterations[r])
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" && strings.IndexFunc(name, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+		sum := sha256.Sum256([]byte(name))
+		slug = "eco-" + hex.EncodeToString(sum[:4])
+	}
+	return slug
+}
+
+// escapeLike escapes LIKE/ILIKE wildcards so user input is matched literally.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
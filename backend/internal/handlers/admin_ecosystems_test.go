@@ -0,0 +1,1074 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+func TestNextAvailableSlug(t *testing.T) {
+	if got := nextAvailableSlug("stellar", []string{"stellar"}); got != "stellar-2" {
+		t.Errorf("expected stellar-2, got %s", got)
+	}
+	if got := nextAvailableSlug("stellar", []string{"stellar", "stellar-2", "stellar-3"}); got != "stellar-4" {
+		t.Errorf("expected stellar-4, got %s", got)
+	}
+	if got := nextAvailableSlug("stellar", []string{"stellar", "stellar-3"}); got != "stellar-2" {
+		t.Errorf("expected stellar-2, got %s", got)
+	}
+}
+
+func TestNormalizeSlug(t *testing.T) {
+	cases := map[string]string{
+		"Stellar":             "stellar",
+		"  Ethereum Classic ": "ethereum-classic",
+		"-Edge-":              "edge",
+		// Accented and special Latin letters keep their base form
+		"Café Network":   "cafe-network",
+		"Ünïcode_Chain!": "unicode_chain",
+		"Straße":         "strasse",
+		"Ørsted Æra":     "orsted-aera",
+		"Łódź":           "lodz",
+		// Compatibility forms fold to ASCII
+		"Ｆｕｌｌ Ｗｉｄｔｈ": "full-width",
+		"ﬁnance":     "finance",
+		// Mixed scripts keep the Latin part
+		"日本 Chain": "chain",
+		// Non-Latin scripts get a stable hash-based slug
+		"日本語":    "eco-77710aed",
+		"Москва": "eco-4ed06085",
+		// Nothing to build a slug from
+		"!!!": "",
+		"":    "",
+	}
+	for name, want := range cases {
+		if got := normalizeSlug(name); got != want {
+			t.Errorf("normalizeSlug(%q) = %q, want %q", name, got, want)
+		}
+		// Slugs are fixed points, which Upsert relies on
+		if got := normalizeSlug(want); got != want {
+			t.Errorf("normalizeSlug(%q) = %q, want it unchanged", want, got)
+		}
+	}
+}
+
+func TestValidateLanguages(t *testing.T) {
+	cases := []struct {
+		name    string
+		langs   []Language
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"exact", []Language{{"Rust", 70}, {"Go", 30}}, false},
+		{"rounding", []Language{{"Rust", 33.3}, {"Go", 33.3}, {"TypeScript", 33.3}}, false},
+		{"sum mismatch", []Language{{"Rust", 70}, {"Go", 80}}, true},
+		{"sum too low", []Language{{"Rust", 50}}, true},
+		{"negative", []Language{{"Rust", 110}, {"Go", -10}}, true},
+		{"over 100", []Language{{"Rust", 100.5}}, true},
+		{"missing name", []Language{{"", 100}}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateLanguages(tc.langs)
+			if tc.wantErr && err == nil {
+				t.Errorf("expected error for %v", tc.langs)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error for %v: %v", tc.langs, err)
+			}
+		})
+	}
+}
+
+func TestDecodeLanguages(t *testing.T) {
+	langs, err := decodeLanguages([]byte(`[{"name":"Go","percentage":100}]`))
+	if err != nil || len(langs) != 1 || langs[0].Name != "Go" {
+		t.Errorf("expected one language, got %v, %v", langs, err)
+	}
+	for _, raw := range []string{``, `[]`} {
+		if langs, err := decodeLanguages([]byte(raw)); err != nil || langs == nil || len(langs) != 0 {
+			t.Errorf("%q: expected an empty list, got %v, %v", raw, langs, err)
+		}
+	}
+	// Valid JSON of the wrong shape, as JSONB can hold
+	for _, raw := range []string{`{"name":"Go"}`, `[{"name":"Go","percentage":"a lot"}]`} {
+		if langs, err := decodeLanguages([]byte(raw)); err == nil || langs == nil || len(langs) != 0 {
+			t.Errorf("%q: expected an error and an empty list, got %v, %v", raw, langs, err)
+		}
+	}
+}
+
+// Integration tests for the ecosystems admin handler.
+// These tests require:
+// - TEST_DB_URL environment variable pointing at a migrated database
+
+func TestEcosystemListOrderBy(t *testing.T) {
+	cases := []struct {
+		sort, order string
+		want        string
+	}{
+		{"", "", "e.created_at DESC, LOWER(e.name) ASC, e.id ASC"},
+		{"created", "asc", "e.created_at ASC, LOWER(e.name) ASC, e.id ASC"},
+		{"name", "", "LOWER(e.name) ASC, e.id ASC"},
+		{"NAME", "DESC", "LOWER(e.name) DESC, e.id ASC"},
+		{"projects", "", "e.cached_project_count DESC, LOWER(e.name) ASC, e.id ASC"},
+		{"users", "asc", "e.cached_user_count ASC, LOWER(e.name) ASC, e.id ASC"},
+	}
+	for _, tc := range cases {
+		got, err := ecosystemListOrderBy(tc.sort, tc.order)
+		if err != nil {
+			t.Errorf("ecosystemListOrderBy(%q, %q) unexpected error: %v", tc.sort, tc.order, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ecosystemListOrderBy(%q, %q) = %q, want %q", tc.sort, tc.order, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct{ sort, order string }{
+		{"contributors", ""},
+		{"e.created_at; DROP TABLE ecosystems", ""},
+		{"name", "sideways"},
+	} {
+		if _, err := ecosystemListOrderBy(tc.sort, tc.order); err == nil {
+			t.Errorf("ecosystemListOrderBy(%q, %q) expected error", tc.sort, tc.order)
+		}
+	}
+}
+
+func TestParseEcosystemPatch(t *testing.T) {
+	// Omitted fields are left alone.
+	p, errBody := parseEcosystemPatch([]byte(`{"status":"inactive"}`))
+	if errBody != nil {
+		t.Fatalf("unexpected error: %v", errBody)
+	}
+	if len(p.sets) != 1 || p.sets[0] != "status = $2" {
+		t.Errorf("expected only status to be set, got %v", p.sets)
+	}
+
+	// Empty values are present and clear the field.
+	p, errBody = parseEcosystemPatch([]byte(`{"website_url":"","description":null,"languages":[]}`))
+	if errBody != nil {
+		t.Fatalf("unexpected error: %v", errBody)
+	}
+	want := []string{"description = NULLIF($2,'')", "website_url = NULLIF($3,'')", "languages = $4::jsonb"}
+	if strings.Join(p.sets, ", ") != strings.Join(want, ", ") {
+		t.Errorf("expected %v, got %v", want, p.sets)
+	}
+	if p.args[0] != "" || p.args[1] != "" || p.args[2] != "[]" {
+		t.Errorf("expected clearing values, got %v", p.args)
+	}
+
+	// Renaming also moves the slug.
+	p, _ = parseEcosystemPatch([]byte(`{"name":"New Name"}`))
+	if p.slug != "new-name" || len(p.sets) != 2 {
+		t.Errorf("expected slug and name to be set, got %q %v", p.slug, p.sets)
+	}
+
+	for body, code := range map[string]string{
+		`{}`:                 "no_fields_to_update",
+		`{"name":""}`:        "name_required",
+		`{"status":""}`:      "invalid_status",
+		`{"website_url":1}`:  "invalid_json",
+		`[1,2]`:              "invalid_json",
+		`{"languages":"go"}`: "invalid_json",
+	} {
+		if _, errBody := parseEcosystemPatch([]byte(body)); errBody == nil || errBody["error"] != code {
+			t.Errorf("%s: expected %s, got %v", body, code, errBody)
+		}
+	}
+}
+
+func newEcosystemsTestApp(t *testing.T) (*fiber.App, *db.DB) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL not set, skipping integration test")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	d, err := db.Connect(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	t.Cleanup(d.Close)
+
+	h := NewEcosystemsAdminHandler(d)
+	app := fiber.New()
+	app.Get("/admin/ecosystems", h.List())
+	app.Get("/admin/ecosystems/slug-preview", h.SlugPreview())
+	app.Get("/admin/ecosystems/:id", h.Get())
+	app.Post("/admin/ecosystems", h.Create())
+	app.Post("/admin/ecosystems/bulk", h.BulkCreate())
+	app.Put("/admin/ecosystems/by-slug/:slug", h.Upsert())
+	app.Put("/admin/ecosystems/:id", h.Update())
+	app.Patch("/admin/ecosystems/:id", h.Patch())
+	app.Delete("/admin/ecosystems/:id", h.Delete())
+	app.Post("/admin/ecosystems/:id/restore", h.Restore())
+	app.Post("/admin/ecosystems/:id/recount", h.Recount())
+	app.Post("/admin/ecosystems/:id/projects/move", h.MoveProjects())
+	return app, d
+}
+
+func doJSON(t *testing.T, app *fiber.App, method, path string, body any) (int, map[string]any) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("failed to encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	var out map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp.StatusCode, out
+}
+
+func TestCreateEcosystem_DuplicateSlug_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	t.Cleanup(func() {
+		_, _ = d.Pool.Exec(context.Background(), `DELETE FROM ecosystems WHERE slug = 'dup-slug-test'`)
+	})
+
+	status, body := doJSON(t, app, "POST", "/admin/ecosystems", map[string]any{"name": "Dup Slug Test"})
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201 on first create, got %d: %v", status, body)
+	}
+
+	// Different name, same normalized slug.
+	status, body = doJSON(t, app, "POST", "/admin/ecosystems", map[string]any{"name": "  dup slug TEST!! "})
+	if status != fiber.StatusConflict {
+		t.Fatalf("expected 409 on duplicate slug, got %d: %v", status, body)
+	}
+	if body["error"] != "slug_already_exists" {
+		t.Errorf("expected slug_already_exists, got %v", body["error"])
+	}
+	if body["slug"] != "dup-slug-test" {
+		t.Errorf("expected slug dup-slug-test, got %v", body["slug"])
+	}
+	if body["suggested_slug"] != "dup-slug-test-2" {
+		t.Errorf("expected suggested_slug dup-slug-test-2, got %v", body["suggested_slug"])
+	}
+}
+
+func TestPatchVsPutEcosystem_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	t.Cleanup(func() {
+		_, _ = d.Pool.Exec(context.Background(), `DELETE FROM ecosystems WHERE slug = 'patch-test'`)
+	})
+
+	status, body := doJSON(t, app, "POST", "/admin/ecosystems", map[string]any{
+		"name":        "Patch Test",
+		"description": "desc",
+		"website_url": "https://example.com",
+		"languages":   []map[string]any{{"name": "Go", "percentage": 100}},
+	})
+	if status != fiber.StatusCreated {
+		t.Fatalf("create failed: %d %v", status, body)
+	}
+	id := body["id"].(string)
+
+	load := func() (description, websiteURL *string, languages string) {
+		t.Helper()
+		if err := d.Pool.QueryRow(context.Background(),
+			`SELECT description, website_url, languages::text FROM ecosystems WHERE id = $1`, id,
+		).Scan(&description, &websiteURL, &languages); err != nil {
+			t.Fatalf("load ecosystem: %v", err)
+		}
+		return
+	}
+
+	// Field omitted: untouched.
+	if status, body := doJSON(t, app, "PATCH", "/admin/ecosystems/"+id, map[string]any{"status": "inactive"}); status != fiber.StatusOK {
+		t.Fatalf("patch status failed: %d %v", status, body)
+	}
+	desc, website, langs := load()
+	if desc == nil || website == nil || langs == "[]" {
+		t.Errorf("omitted fields should be unchanged, got %v %v %s", desc, website, langs)
+	}
+
+	// Field set to empty: cleared.
+	if status, body := doJSON(t, app, "PATCH", "/admin/ecosystems/"+id, map[string]any{"website_url": "", "languages": []any{}}); status != fiber.StatusOK {
+		t.Fatalf("patch clear failed: %d %v", status, body)
+	}
+	desc, website, langs = load()
+	if website != nil || langs != "[]" {
+		t.Errorf("expected website_url and languages to be cleared, got %v %s", website, langs)
+	}
+	if desc == nil || *desc != "desc" {
+		t.Errorf("description should be unchanged, got %v", desc)
+	}
+
+	// PUT replaces everything, so omitted fields are cleared too.
+	if status, body := doJSON(t, app, "PUT", "/admin/ecosystems/"+id, map[string]any{"name": "Patch Test"}); status != fiber.StatusOK {
+		t.Fatalf("put failed: %d %v", status, body)
+	}
+	if desc, _, _ = load(); desc != nil {
+		t.Errorf("PUT should clear the omitted description, got %v", *desc)
+	}
+
+	if status, body := doJSON(t, app, "PUT", "/admin/ecosystems/"+id, map[string]any{"description": "no name"}); status != fiber.StatusBadRequest || body["error"] != "name_required" {
+		t.Errorf("PUT without name: expected 400 name_required, got %d %v", status, body)
+	}
+}
+
+func TestUpsertEcosystem_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	t.Cleanup(func() {
+		_, _ = d.Pool.Exec(context.Background(), `DELETE FROM ecosystems WHERE slug = 'upsert-test'`)
+	})
+
+	status, body := doJSON(t, app, "PUT", "/admin/ecosystems/by-slug/upsert-test", map[string]any{
+		"name":        "Upsert Test",
+		"description": "first",
+		"website_url": "https://example.com",
+		"languages":   []map[string]any{{"name": "Rust", "percentage": 100}},
+	})
+	if status != fiber.StatusCreated || body["created"] != true {
+		t.Fatalf("expected 201 created, got %d: %v", status, body)
+	}
+	id, _ := body["id"].(string)
+
+	// Re-applying replaces every field, clearing the ones left out.
+	status, body = doJSON(t, app, "PUT", "/admin/ecosystems/by-slug/upsert-test", map[string]any{
+		"name":   "Upsert Test Renamed",
+		"status": "inactive",
+	})
+	if status != fiber.StatusOK || body["created"] != false {
+		t.Fatalf("expected 200 updated, got %d: %v", status, body)
+	}
+	if body["id"] != id {
+		t.Errorf("expected the same ecosystem %s, got %v", id, body["id"])
+	}
+
+	var name, ecoStatus, languages string
+	var description, websiteURL *string
+	if err := d.Pool.QueryRow(context.Background(), `
+SELECT name, description, website_url, status, languages::text FROM ecosystems WHERE slug = 'upsert-test'`,
+	).Scan(&name, &description, &websiteURL, &ecoStatus, &languages); err != nil {
+		t.Fatalf("load ecosystem: %v", err)
+	}
+	if name != "Upsert Test Renamed" || ecoStatus != "inactive" {
+		t.Errorf("expected name and status to be replaced, got %q %q", name, ecoStatus)
+	}
+	if description != nil || websiteURL != nil || languages != "[]" {
+		t.Errorf("expected omitted fields to be cleared, got %v %v %s", description, websiteURL, languages)
+	}
+
+	var audits int
+	if err := d.Pool.QueryRow(context.Background(), `SELECT COUNT(*) FROM ecosystem_audit_log WHERE ecosystem_id = $1`, id).Scan(&audits); err != nil {
+		t.Fatalf("count audit rows: %v", err)
+	}
+	if audits != 2 {
+		t.Errorf("expected a create and an update audit entry, got %d", audits)
+	}
+}
+
+func TestUpsertEcosystem_RejectsUnnormalizedSlug_Integration(t *testing.T) {
+	app, _ := newEcosystemsTestApp(t)
+
+	status, body := doJSON(t, app, "PUT", "/admin/ecosystems/by-slug/Upsert%20Test", map[string]any{"name": "Upsert Test"})
+	if status != fiber.StatusBadRequest || body["error"] != "invalid_slug" {
+		t.Errorf("expected 400 invalid_slug, got %d: %v", status, body)
+	}
+}
+
+func TestDeleteEcosystem_SoftDeleteAndRestore_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	t.Cleanup(func() {
+		_, _ = d.Pool.Exec(context.Background(), `DELETE FROM ecosystems WHERE slug = 'soft-delete-test'`)
+	})
+
+	status, body := doJSON(t, app, "POST", "/admin/ecosystems", map[string]any{"name": "Soft Delete Test"})
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", status, body)
+	}
+	id, _ := body["id"].(string)
+
+	listed := func(path string) bool {
+		t.Helper()
+		status, body := doJSON(t, app, "GET", path, nil)
+		if status != fiber.StatusOK {
+			t.Fatalf("expected 200 from %s, got %d: %v", path, status, body)
+		}
+		list, _ := body["ecosystems"].([]any)
+		for _, e := range list {
+			if m, ok := e.(map[string]any); ok && m["id"] == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	if status, body := doJSON(t, app, "DELETE", "/admin/ecosystems/"+id, nil); status != fiber.StatusOK {
+		t.Fatalf("expected 200 on soft delete, got %d: %v", status, body)
+	}
+	if listed("/admin/ecosystems?q=soft-delete-test") {
+		t.Error("soft-deleted ecosystem should not be listed")
+	}
+	if status, _ := doJSON(t, app, "GET", "/admin/ecosystems/"+id, nil); status != fiber.StatusNotFound {
+		t.Errorf("expected 404 for soft-deleted ecosystem, got %d", status)
+	}
+	if !listed("/admin/ecosystems?q=soft-delete-test&include_deleted=true") {
+		t.Error("soft-deleted ecosystem should be listed with include_deleted=true")
+	}
+
+	if status, body := doJSON(t, app, "POST", "/admin/ecosystems/"+id+"/restore", nil); status != fiber.StatusOK {
+		t.Fatalf("expected 200 on restore, got %d: %v", status, body)
+	}
+	if !listed("/admin/ecosystems?q=soft-delete-test") {
+		t.Error("restored ecosystem should be listed")
+	}
+}
+
+func TestEcosystemSlugAlias_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	app.Get("/ecosystems/:slug", NewEcosystemsPublicHandler(d).GetBySlug())
+	t.Cleanup(func() {
+		_, _ = d.Pool.Exec(context.Background(), `DELETE FROM ecosystems WHERE slug LIKE 'alias-test%'`)
+	})
+
+	status, body := doJSON(t, app, "POST", "/admin/ecosystems", map[string]any{"name": "Alias Test"})
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", status, body)
+	}
+	id, _ := body["id"].(string)
+
+	if status, body := doJSON(t, app, "PATCH", "/admin/ecosystems/"+id, map[string]any{"name": "Alias Test Renamed"}); status != fiber.StatusOK {
+		t.Fatalf("rename failed: %d %v", status, body)
+	}
+
+	// The old slug still resolves, with a hint pointing at the new one
+	status, body = doJSON(t, app, "GET", "/ecosystems/alias-test", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200 for the old slug, got %d: %v", status, body)
+	}
+	if body["id"] != id || body["slug"] != "alias-test-renamed" || body["moved_from"] != "alias-test" {
+		t.Errorf("unexpected response for the old slug: %v", body)
+	}
+	status, body = doJSON(t, app, "GET", "/ecosystems/alias-test-renamed", nil)
+	if status != fiber.StatusOK || body["moved_from"] != nil {
+		t.Errorf("expected the current slug without moved_from, got %d: %v", status, body)
+	}
+
+	// The ?ecosystem= filter resolves the old slug the same way
+	resolve := func(slug string) string {
+		t.Helper()
+		var resolved *string
+		if err := d.Pool.QueryRow(context.Background(), `SELECT `+ecosystemIDBySlugSQL(1)+`::text`, slug).Scan(&resolved); err != nil {
+			t.Fatalf("resolve %s: %v", slug, err)
+		}
+		if resolved == nil {
+			return ""
+		}
+		return *resolved
+	}
+	if got := resolve("Alias-Test"); got != id {
+		t.Errorf("expected the old slug to resolve to %s, got %q", id, got)
+	}
+
+	// A new ecosystem taking the old slug wins over the alias
+	status, body = doJSON(t, app, "POST", "/admin/ecosystems", map[string]any{"name": "Alias Test"})
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201 reusing the old slug, got %d: %v", status, body)
+	}
+	if got := resolve("alias-test"); got != body["id"] {
+		t.Errorf("expected the current slug to win over the alias, got %q", got)
+	}
+}
+
+func TestBulkCreateEcosystems_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	t.Cleanup(func() {
+		_, _ = d.Pool.Exec(context.Background(), `DELETE FROM ecosystems WHERE slug IN ('bulk-test-a', 'bulk-test-b')`)
+	})
+
+	batch := []map[string]any{
+		{"name": "Bulk Test A"},
+		{"name": "Bulk Test B"},
+		{"name": "bulk test a"}, // duplicate slug within the batch
+	}
+
+	// All-or-nothing by default.
+	status, body := doJSON(t, app, "POST", "/admin/ecosystems/bulk", batch)
+	if status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for failing batch, got %d: %v", status, body)
+	}
+	var n int64
+	if err := d.Pool.QueryRow(context.Background(), `SELECT COUNT(*) FROM ecosystems WHERE slug IN ('bulk-test-a', 'bulk-test-b')`).Scan(&n); err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected batch to roll back, found %d rows", n)
+	}
+
+	// Partial mode commits the valid items.
+	status, body = doJSON(t, app, "POST", "/admin/ecosystems/bulk?partial=true", batch)
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201 for partial batch, got %d: %v", status, body)
+	}
+	summary, _ := body["summary"].(map[string]any)
+	if summary["created"] != float64(2) || summary["failed"] != float64(1) {
+		t.Errorf("expected 2 created / 1 failed, got %v", summary)
+	}
+	results, _ := body["results"].([]any)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if r, _ := results[2].(map[string]any); r["error"] != "slug_already_exists" {
+		t.Errorf("expected slug_already_exists for item 2, got %v", r)
+	}
+}
+
+func TestEcosystemContributorCount_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: alice authors an issue and a PR in a verified project (counted once),
+	// bob authors a PR there too, and carol only contributes to a pending project.
+	var userID, ecosystemID, verifiedID, pendingID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('contrib-count-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('contrib-count-test', 'Contrib Count Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'contrib-count-test/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'contrib-count-test/verified', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&verifiedID); err != nil {
+		t.Fatalf("insert verified project: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'contrib-count-test/pending', 'pending_verification', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&pendingID); err != nil {
+		t.Fatalf("insert pending project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, 9201, 1, 'open', 'cc-alice'), ($2, 9202, 1, 'open', 'cc-carol')`, verifiedID, pendingID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, author_login)
+VALUES ($1, 9301, 2, 'open', 'cc-alice'), ($1, 9302, 3, 'open', 'cc-bob')`, verifiedID); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	status, body := doJSON(t, app, "GET", "/admin/ecosystems/"+ecosystemID, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	if body["contributor_count"] != float64(2) {
+		t.Errorf("expected contributor_count 2, got %v", body["contributor_count"])
+	}
+
+	status, body = doJSON(t, app, "GET", "/admin/ecosystems?q=contrib-count-test", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	list, _ := body["ecosystems"].([]any)
+	if len(list) != 1 {
+		t.Fatalf("expected 1 ecosystem, got %d", len(list))
+	}
+	if row, _ := list[0].(map[string]any); row["contributor_count"] != float64(2) {
+		t.Errorf("expected listed contributor_count 2, got %v", row["contributor_count"])
+	}
+}
+
+func TestEcosystemRecount_FixesDriftedCache_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: two live projects owned by two users and one soft-deleted project.
+	var aliceID, bobID, ecosystemID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('recount-alice') RETURNING id::text`).Scan(&aliceID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('recount-bob') RETURNING id::text`).Scan(&bobID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('recount-test', 'Recount Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'recount-test/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id IN ($1, $2)`, aliceID, bobID)
+	})
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id, deleted_at)
+VALUES
+  ($1, 'recount-test/a', 'verified', $3, NULL),
+  ($2, 'recount-test/b', 'verified', $3, NULL),
+  ($2, 'recount-test/gone', 'verified', $3, now())`, aliceID, bobID, ecosystemID); err != nil {
+		t.Fatalf("insert projects: %v", err)
+	}
+
+	listedCounts := func() (any, any) {
+		t.Helper()
+		status, body := doJSON(t, app, "GET", "/admin/ecosystems?q=recount-test", nil)
+		if status != fiber.StatusOK {
+			t.Fatalf("expected 200, got %d: %v", status, body)
+		}
+		list, _ := body["ecosystems"].([]any)
+		if len(list) != 1 {
+			t.Fatalf("expected 1 ecosystem, got %d", len(list))
+		}
+		row, _ := list[0].(map[string]any)
+		return row["project_count"], row["user_count"]
+	}
+
+	// The project trigger keeps the cache current
+	if p, u := listedCounts(); p != float64(2) || u != float64(2) {
+		t.Fatalf("expected 2 projects / 2 users from the trigger, got %v / %v", p, u)
+	}
+
+	// Simulate drift, then repair it
+	if _, err := d.Pool.Exec(ctx, `UPDATE ecosystems SET cached_project_count = 7, cached_user_count = 0 WHERE id = $1`, ecosystemID); err != nil {
+		t.Fatalf("drift cache: %v", err)
+	}
+	if p, u := listedCounts(); p != float64(7) || u != float64(0) {
+		t.Fatalf("expected List to read the cached values, got %v / %v", p, u)
+	}
+
+	status, body := doJSON(t, app, "POST", "/admin/ecosystems/"+ecosystemID+"/recount", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	if body["project_count"] != float64(2) || body["user_count"] != float64(2) {
+		t.Errorf("expected recount to return 2 / 2, got %v", body)
+	}
+	if prev, _ := body["previous"].(map[string]any); prev["project_count"] != float64(7) || prev["user_count"] != float64(0) {
+		t.Errorf("expected the drifted values as previous, got %v", body["previous"])
+	}
+	if p, u := listedCounts(); p != float64(2) || u != float64(2) {
+		t.Errorf("expected List to show the recounted values, got %v / %v", p, u)
+	}
+
+	// Soft-deleting a project updates the cache without a recount
+	if _, err := d.Pool.Exec(ctx, `UPDATE projects SET deleted_at = now() WHERE github_full_name = 'recount-test/a'`); err != nil {
+		t.Fatalf("delete project: %v", err)
+	}
+	if p, u := listedCounts(); p != float64(1) || u != float64(1) {
+		t.Errorf("expected 1 project / 1 user after the delete, got %v / %v", p, u)
+	}
+
+	if status, _ := doJSON(t, app, "POST", "/admin/ecosystems/00000000-0000-0000-0000-000000000000/recount", nil); status != fiber.StatusNotFound {
+		t.Errorf("expected 404 for an unknown ecosystem, got %d", status)
+	}
+}
+
+func TestMoveEcosystemProjects_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: source with three projects by two owners, an empty target, an inactive
+	// and a soft-deleted ecosystem.
+	var aliceID, bobID, sourceID, targetID, inactiveID, deletedID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('move-alice') RETURNING id::text`).Scan(&aliceID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('move-bob') RETURNING id::text`).Scan(&bobID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	for slug, id := range map[string]*string{"move-source": &sourceID, "move-target": &targetID} {
+		if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ($1, $1) RETURNING id::text`, slug).Scan(id); err != nil {
+			t.Fatalf("insert ecosystem: %v", err)
+		}
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name, status) VALUES ('move-inactive', 'Move Inactive', 'inactive') RETURNING id::text`).Scan(&inactiveID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name, deleted_at) VALUES ('move-deleted', 'Move Deleted', now()) RETURNING id::text`).Scan(&deletedID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'move-test/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id IN ($1, $2, $3, $4)`, sourceID, targetID, inactiveID, deletedID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id IN ($1, $2)`, aliceID, bobID)
+	})
+	var projA, projB, projC string
+	for _, p := range []struct {
+		name  string
+		owner string
+		id    *string
+	}{{"move-test/a", aliceID, &projA}, {"move-test/b", bobID, &projB}, {"move-test/c", bobID, &projC}} {
+		if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, $2, 'verified', $3)
+RETURNING id::text`, p.owner, p.name, sourceID).Scan(p.id); err != nil {
+			t.Fatalf("insert project: %v", err)
+		}
+	}
+
+	path := "/admin/ecosystems/" + sourceID + "/projects/move"
+	for _, tc := range []struct {
+		name   string
+		path   string
+		body   map[string]any
+		status int
+		err    string
+	}{
+		{"bad source id", "/admin/ecosystems/nope/projects/move", map[string]any{"project_ids": []string{projA}, "target_ecosystem_id": targetID}, fiber.StatusBadRequest, "invalid_ecosystem_id"},
+		{"bad target id", path, map[string]any{"project_ids": []string{projA}, "target_ecosystem_id": "nope"}, fiber.StatusBadRequest, "invalid_target_ecosystem_id"},
+		{"bad project id", path, map[string]any{"project_ids": []string{projA, "nope"}, "target_ecosystem_id": targetID}, fiber.StatusBadRequest, "invalid_project_id"},
+		{"no projects", path, map[string]any{"project_ids": []string{}, "target_ecosystem_id": targetID}, fiber.StatusBadRequest, "project_ids_required"},
+		{"same ecosystem", path, map[string]any{"project_ids": []string{projA}, "target_ecosystem_id": sourceID}, fiber.StatusBadRequest, "same_ecosystem"},
+		{"unknown target", path, map[string]any{"project_ids": []string{projA}, "target_ecosystem_id": "00000000-0000-0000-0000-000000000000"}, fiber.StatusNotFound, "target_ecosystem_not_found"},
+		{"deleted target", path, map[string]any{"project_ids": []string{projA}, "target_ecosystem_id": deletedID}, fiber.StatusBadRequest, "target_ecosystem_deleted"},
+		{"inactive target", path, map[string]any{"project_ids": []string{projA}, "target_ecosystem_id": inactiveID}, fiber.StatusBadRequest, "target_ecosystem_inactive"},
+		{"deleted source", "/admin/ecosystems/" + deletedID + "/projects/move", map[string]any{"project_ids": []string{projA}, "target_ecosystem_id": targetID}, fiber.StatusNotFound, "ecosystem_not_found"},
+	} {
+		status, body := doJSON(t, app, "POST", tc.path, tc.body)
+		if status != tc.status || body["error"] != tc.err {
+			t.Errorf("%s: expected %d %s, got %d %v", tc.name, tc.status, tc.err, status, body)
+		}
+	}
+
+	// Nothing moved on a rejected request
+	var inSource int
+	if err := d.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM projects WHERE ecosystem_id = $1`, sourceID).Scan(&inSource); err != nil {
+		t.Fatalf("count projects: %v", err)
+	}
+	if inSource != 3 {
+		t.Fatalf("expected 3 projects still in the source, got %d", inSource)
+	}
+
+	// Move b and c (bob's); a project that isn't in the source is skipped
+	status, body := doJSON(t, app, "POST", path, map[string]any{
+		"project_ids":         []string{projB, projC, "00000000-0000-0000-0000-000000000000"},
+		"target_ecosystem_id": targetID,
+	})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	if body["moved"] != float64(2) {
+		t.Errorf("expected 2 moved, got %v", body["moved"])
+	}
+	if src, _ := body["source"].(map[string]any); src["project_count"] != float64(1) || src["user_count"] != float64(1) {
+		t.Errorf("expected source 1 project / 1 user, got %v", body["source"])
+	}
+	if dst, _ := body["target"].(map[string]any); dst["project_count"] != float64(2) || dst["user_count"] != float64(1) {
+		t.Errorf("expected target 2 projects / 1 user, got %v", body["target"])
+	}
+
+	var inTarget int
+	if err := d.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM projects WHERE ecosystem_id = $1 AND id IN ($2, $3)`, targetID, projB, projC).Scan(&inTarget); err != nil {
+		t.Fatalf("count projects: %v", err)
+	}
+	if inTarget != 2 {
+		t.Errorf("expected both projects in the target, got %d", inTarget)
+	}
+
+	// Moving again finds nothing left in the source
+	status, body = doJSON(t, app, "POST", path, map[string]any{"project_ids": []string{projB}, "target_ecosystem_id": targetID})
+	if status != fiber.StatusOK || body["moved"] != float64(0) {
+		t.Errorf("expected 0 moved on a repeat, got %d %v", status, body)
+	}
+}
+
+func TestListEcosystems_MalformedLanguages_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// One good row and one whose languages column holds an object instead of a list
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO ecosystems (slug, name, languages) VALUES
+  ('bad-jsonb-test-ok', 'Bad JSONB Test OK', '[{"name":"Go","percentage":100}]'),
+  ('bad-jsonb-test-broken', 'Bad JSONB Test Broken', '{"name":"Go"}')`); err != nil {
+		t.Fatalf("insert ecosystems: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = d.Pool.Exec(context.Background(), `DELETE FROM ecosystems WHERE slug LIKE 'bad-jsonb-test-%'`)
+	})
+
+	status, body := doJSON(t, app, "GET", "/admin/ecosystems?q=bad-jsonb-test", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200 despite the malformed row, got %d: %v", status, body)
+	}
+	list, _ := body["ecosystems"].([]any)
+	if len(list) != 2 {
+		t.Fatalf("expected 2 ecosystems, got %d", len(list))
+	}
+	var brokenID string
+	for _, item := range list {
+		row, _ := item.(map[string]any)
+		langs, ok := row["languages"].([]any)
+		warnings, _ := row["_warnings"].([]any)
+		switch row["slug"] {
+		case "bad-jsonb-test-ok":
+			if len(langs) != 1 || row["_warnings"] != nil {
+				t.Errorf("expected the good row untouched, got %v", row)
+			}
+		case "bad-jsonb-test-broken":
+			brokenID, _ = row["id"].(string)
+			if !ok || len(langs) != 0 {
+				t.Errorf("expected empty languages for the broken row, got %v", row["languages"])
+			}
+			if len(warnings) != 1 || !strings.HasPrefix(warnings[0].(string), "languages: ") {
+				t.Errorf("expected a languages warning, got %v", row["_warnings"])
+			}
+		}
+	}
+
+	status, body = doJSON(t, app, "GET", "/admin/ecosystems/"+brokenID, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	if warnings, _ := body["_warnings"].([]any); len(warnings) != 1 {
+		t.Errorf("expected a languages warning on the single row, got %v", body["_warnings"])
+	}
+}
+
+func TestEcosystemSlugPreview_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	t.Cleanup(func() {
+		_, _ = d.Pool.Exec(context.Background(), `DELETE FROM ecosystems WHERE slug LIKE 'slug-preview-test%'`)
+	})
+
+	preview := func(name string) (int, map[string]any) {
+		t.Helper()
+		return doJSON(t, app, "GET", "/admin/ecosystems/slug-preview?name="+url.QueryEscape(name), nil)
+	}
+
+	// Free slug, with accents folded as Create would
+	status, body := preview("Slug Préview Test")
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	if body["slug"] != "slug-preview-test" || body["available"] != true || body["suggestion"] != nil {
+		t.Errorf("expected an available slug-preview-test, got %v", body)
+	}
+
+	// Taken slug: the suggestion skips variants that exist too
+	if _, err := d.Pool.Exec(context.Background(), `
+INSERT INTO ecosystems (slug, name) VALUES ('slug-preview-test', 'Slug Preview Test'), ('slug-preview-test-2', 'Slug Preview Test 2')`); err != nil {
+		t.Fatalf("insert ecosystems: %v", err)
+	}
+	status, body = preview("  Slug Preview TEST ")
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	if body["slug"] != "slug-preview-test" || body["available"] != false || body["suggestion"] != "slug-preview-test-3" {
+		t.Errorf("expected a taken slug with suggestion slug-preview-test-3, got %v", body)
+	}
+
+	// The preview matches what Create then reports
+	status, body = doJSON(t, app, "POST", "/admin/ecosystems", map[string]any{"name": "Slug Preview Test"})
+	if status != fiber.StatusConflict || body["suggested_slug"] != "slug-preview-test-3" {
+		t.Errorf("expected 409 suggesting slug-preview-test-3, got %d: %v", status, body)
+	}
+
+	if status, _ := preview("!!!"); status != fiber.StatusBadRequest {
+		t.Errorf("expected 400 for a name without valid characters, got %d", status)
+	}
+	if status, _ := preview(""); status != fiber.StatusBadRequest {
+		t.Errorf("expected 400 without a name, got %d", status)
+	}
+}
+
+func TestListEcosystems_OwnerFilter_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: alice owns two projects in -a and one in -b, bob owns one in -b and one
+	// in -c, and alice's project in -c is soft-deleted.
+	var aliceID, bobID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('owner-filter-alice') RETURNING id::text`).Scan(&aliceID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('owner-filter-bob') RETURNING id::text`).Scan(&bobID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	ecosystemIDs := map[string]string{}
+	for _, suffix := range []string{"a", "b", "c"} {
+		var id string
+		if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ($1, $2) RETURNING id::text`,
+			"owner-filter-test-"+suffix, "Owner Filter Test "+suffix).Scan(&id); err != nil {
+			t.Fatalf("insert ecosystem: %v", err)
+		}
+		ecosystemIDs[suffix] = id
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'owner-filter-test/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE slug LIKE 'owner-filter-test-%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id IN ($1, $2)`, aliceID, bobID)
+	})
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id, deleted_at)
+VALUES
+  ($1, 'owner-filter-test/a1', 'verified', $3, NULL),
+  ($1, 'owner-filter-test/a2', 'verified', $3, NULL),
+  ($1, 'owner-filter-test/b1', 'verified', $4, NULL),
+  ($2, 'owner-filter-test/b2', 'verified', $4, NULL),
+  ($2, 'owner-filter-test/c1', 'verified', $5, NULL),
+  ($1, 'owner-filter-test/c2', 'verified', $5, now())`,
+		aliceID, bobID, ecosystemIDs["a"], ecosystemIDs["b"], ecosystemIDs["c"]); err != nil {
+		t.Fatalf("insert projects: %v", err)
+	}
+
+	list := func(owner string) map[string]map[string]any {
+		t.Helper()
+		status, body := doJSON(t, app, "GET", "/admin/ecosystems?q=owner-filter-test&owner_user_id="+owner, nil)
+		if status != fiber.StatusOK {
+			t.Fatalf("expected 200, got %d: %v", status, body)
+		}
+		rows := map[string]map[string]any{}
+		items, _ := body["ecosystems"].([]any)
+		for _, item := range items {
+			row, _ := item.(map[string]any)
+			slug, _ := row["slug"].(string)
+			rows[slug] = row
+		}
+		return rows
+	}
+
+	alice := list(aliceID)
+	if len(alice) != 2 || alice["owner-filter-test-a"] == nil || alice["owner-filter-test-b"] == nil {
+		t.Fatalf("expected alice's ecosystems a and b, got %v", alice)
+	}
+	if got := alice["owner-filter-test-a"]["owner_project_count"]; got != float64(2) {
+		t.Errorf("expected alice to own 2 projects in a, got %v", got)
+	}
+	// The shared ecosystem keeps its ecosystem-wide counts
+	shared := alice["owner-filter-test-b"]
+	if shared["owner_project_count"] != float64(1) || shared["project_count"] != float64(2) || shared["user_count"] != float64(2) {
+		t.Errorf("expected b with 1 of alice's 2 projects and 2 owners, got %v", shared)
+	}
+
+	bob := list(bobID)
+	if len(bob) != 2 || bob["owner-filter-test-b"] == nil || bob["owner-filter-test-c"] == nil {
+		t.Fatalf("expected bob's ecosystems b and c, got %v", bob)
+	}
+	if got := bob["owner-filter-test-c"]["owner_project_count"]; got != float64(1) {
+		t.Errorf("expected bob to own 1 project in c, got %v", got)
+	}
+
+	// Without the filter rows carry no owner_project_count
+	status, body := doJSON(t, app, "GET", "/admin/ecosystems?q=owner-filter-test", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	items, _ := body["ecosystems"].([]any)
+	if len(items) != 3 {
+		t.Fatalf("expected 3 ecosystems, got %d", len(items))
+	}
+	if row, _ := items[0].(map[string]any); row["owner_project_count"] != nil {
+		t.Errorf("expected no owner_project_count without a filter, got %v", row["owner_project_count"])
+	}
+
+	status, body = doJSON(t, app, "GET", "/admin/ecosystems?owner_user_id=not-a-uuid", nil)
+	if status != fiber.StatusBadRequest || body["error"] != "invalid_owner_user_id" {
+		t.Errorf("expected 400 invalid_owner_user_id, got %d: %v", status, body)
+	}
+}
+
+func TestListEcosystems_Sort_Integration(t *testing.T) {
+	app, d := newEcosystemsTestApp(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: created in the order b, c, a, with 1, 3 and 2 live projects
+	var ownerID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('sort-test') RETURNING id::text`).Scan(&ownerID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'list-sort-test/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE slug LIKE 'list-sort-test-%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, ownerID)
+	})
+	for i, eco := range []struct {
+		suffix   string
+		projects int
+	}{{"b", 1}, {"c", 3}, {"a", 2}} {
+		var id string
+		if err := d.Pool.QueryRow(ctx, `
+INSERT INTO ecosystems (slug, name, created_at) VALUES ($1, $2, now() - make_interval(mins => $3))
+RETURNING id::text`, "list-sort-test-"+eco.suffix, "List Sort Test "+strings.ToUpper(eco.suffix), 10-i).Scan(&id); err != nil {
+			t.Fatalf("insert ecosystem: %v", err)
+		}
+		for p := 0; p < eco.projects; p++ {
+			if _, err := d.Pool.Exec(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id) VALUES ($1, $2, 'verified', $3)`,
+				ownerID, fmt.Sprintf("list-sort-test/%s%d", eco.suffix, p), id); err != nil {
+				t.Fatalf("insert project: %v", err)
+			}
+		}
+	}
+
+	slugs := func(query string) string {
+		t.Helper()
+		status, body := doJSON(t, app, "GET", "/admin/ecosystems?q=list-sort-test"+query, nil)
+		if status != fiber.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d: %v", query, status, body)
+		}
+		var out []string
+		items, _ := body["ecosystems"].([]any)
+		for _, item := range items {
+			row, _ := item.(map[string]any)
+			slug, _ := row["slug"].(string)
+			out = append(out, strings.TrimPrefix(slug, "list-sort-test-"))
+		}
+		return strings.Join(out, ",")
+	}
+
+	for query, want := range map[string]string{
+		"":                          "a,c,b", // newest first
+		"&sort=name":                "a,b,c",
+		"&sort=name&order=desc":     "c,b,a",
+		"&sort=projects":            "c,a,b",
+		"&sort=projects&order=asc":  "b,a,c",
+		"&sort=created&order=asc":   "b,c,a",
+		"&sort=Projects&order=DESC": "c,a,b",
+	} {
+		if got := slugs(query); got != want {
+			t.Errorf("GET ?q=list-sort-test%s: expected %s, got %s", query, want, got)
+		}
+	}
+
+	for _, query := range []string{"&sort=stars", "&sort=name&order=up", "&sort=e.name;DROP%20TABLE%20ecosystems"} {
+		status, body := doJSON(t, app, "GET", "/admin/ecosystems?q=list-sort-test"+query, nil)
+		if status != fiber.StatusBadRequest || body["error"] != "invalid_sort" {
+			t.Errorf("GET %s: expected 400 invalid_sort, got %d %v", query, status, body)
+		}
+	}
+}
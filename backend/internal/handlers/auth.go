@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log/slog"
 	"time"
 
@@ -16,10 +17,20 @@ import (
 type AuthHandler struct {
 	cfg config.Config
 	db  *db.DB
+
+	// Login attempts are throttled per client IP and per wallet address before any
+	// signature verification runs.
+	verifyIPLimiter   *auth.AttemptLimiter
+	verifyAddrLimiter *auth.AttemptLimiter
 }
 
 func NewAuthHandler(cfg config.Config, d *db.DB) *AuthHandler {
-	return &AuthHandler{cfg: cfg, db: d}
+	return &AuthHandler{
+		cfg:               cfg,
+		db:                d,
+		verifyIPLimiter:   auth.NewAttemptLimiter(cfg.AuthVerifyPerIPPerMinute),
+		verifyAddrLimiter: auth.NewAttemptLimiter(cfg.AuthVerifyPerAddressPerMinute),
+	}
 }
 
 type nonceRequest struct {
@@ -43,6 +54,9 @@ func (h *AuthHandler) Nonce() fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_wallet_type"})
 		}
 		addr, err := auth.NormalizeAddress(wType, req.Address)
+		if errors.Is(err, auth.ErrInvalidEVMChecksum) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_evm_checksum"})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
 		}
@@ -52,11 +66,17 @@ func (h *AuthHandler) Nonce() fiber.Handler {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "nonce_create_failed"})
 		}
 
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"nonce":      n.Nonce,
-			"message":    auth.LoginMessage(n.Nonce),
-			"expires_at": n.ExpiresAt,
-		})
+		resp := fiber.Map{
+			"nonce":            n.Nonce,
+			"message":          auth.LoginMessage(n.Nonce),
+			"expires_at":       n.ExpiresAt,
+			"checksum_address": auth.ChecksumAddress(wType, addr),
+		}
+		if chainID := h.evmChainID(wType); chainID != 0 {
+			resp["message"] = auth.EVMLoginMessage(n.Nonce, chainID)
+			resp["chain_id"] = chainID
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
 	}
 }
 
@@ -66,6 +86,21 @@ type verifyRequest struct {
 	Nonce      string `json:"nonce"`
 	Signature  string `json:"signature"`
 	PublicKey  string `json:"public_key,omitempty"`
+	// MessageHashing is what a Stellar signature covers, "raw" or "sha256"; empty uses
+	// the wallet type's default (see auth.VerifySignatureBytes).
+	MessageHashing string `json:"message_hashing,omitempty"`
+	// ChainID is the chain the EVM login message was signed for, when the server binds
+	// logins to a chain (config.AuthEVMChainID); it defaults to the expected chain.
+	ChainID *int64 `json:"chain_id,omitempty"`
+}
+
+// evmChainID returns the chain EVM logins are bound to, or 0 for other wallet types
+// and when logins aren't bound to a chain.
+func (h *AuthHandler) evmChainID(t auth.WalletType) int64 {
+	if t != auth.WalletTypeEVM {
+		return 0
+	}
+	return int64(h.cfg.AuthEVMChainID)
 }
 
 func (h *AuthHandler) Verify() fiber.Handler {
@@ -76,6 +111,9 @@ func (h *AuthHandler) Verify() fiber.Handler {
 		if h.cfg.JWTSecret == "" {
 			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "jwt_not_configured"})
 		}
+		if !h.verifyIPLimiter.Allow(c.IP()) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "too_many_attempts"})
+		}
 
 		var req verifyRequest
 		if err := c.BodyParser(&req); err != nil {
@@ -87,24 +125,48 @@ func (h *AuthHandler) Verify() fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_wallet_type"})
 		}
 		addr, err := auth.NormalizeAddress(wType, req.Address)
+		if errors.Is(err, auth.ErrInvalidEVMChecksum) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_evm_checksum"})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
 		}
 		if req.Nonce == "" || req.Signature == "" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing_nonce_or_signature"})
 		}
-
-		// Be tolerant during early dev: accept both the current canonical message and the
-		// legacy newline message (so signing tools that copied `\n` vs newline don't block you).
-		msgs := []string{
-			auth.LoginMessage(req.Nonce),
-			auth.LegacyLoginMessage(req.Nonce),
+		hashing, err := auth.ParseMessageHashing(req.MessageHashing)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_message_hashing"})
 		}
+		if !h.verifyAddrLimiter.Allow(string(wType) + ":" + addr) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "too_many_attempts"})
+		}
+
 		var sigOK bool
-		for _, msg := range msgs {
-			if err := auth.VerifySignature(wType, addr, msg, req.Signature, req.PublicKey); err == nil {
-				sigOK = true
-				break
+		if expected := h.evmChainID(wType); expected != 0 {
+			// Rebuild the message for the chain the client says it signed for, so a
+			// signature replayed from another chain is reported as such.
+			chainID := expected
+			if req.ChainID != nil {
+				chainID = *req.ChainID
+			}
+			err := auth.VerifyEVMLogin(addr, auth.EVMLoginMessage(req.Nonce, chainID), req.Signature, expected)
+			if errors.Is(err, auth.ErrChainIDMismatch) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "chain_id_mismatch", "expected_chain_id": expected})
+			}
+			sigOK = err == nil
+		} else {
+			// Be tolerant during early dev: accept both the current canonical message and the
+			// legacy newline message (so signing tools that copied `\n` vs newline don't block you).
+			msgs := []string{
+				auth.LoginMessage(req.Nonce),
+				auth.LegacyLoginMessage(req.Nonce),
+			}
+			for _, msg := range msgs {
+				if err := auth.VerifySignatureWithHashing(wType, addr, msg, req.Signature, req.PublicKey, hashing); err == nil {
+					sigOK = true
+					break
+				}
 			}
 		}
 		if !sigOK {
@@ -128,8 +190,9 @@ func (h *AuthHandler) Verify() fiber.Handler {
 			"token": token,
 			"user":  res.User,
 			"wallet": fiber.Map{
-				"wallet_type": res.Wallet.WalletType,
-				"address":     res.Wallet.Address,
+				"wallet_type":      res.Wallet.WalletType,
+				"address":          res.Wallet.Address,
+				"checksum_address": res.Wallet.ChecksumAddress,
 			},
 		})
 	}
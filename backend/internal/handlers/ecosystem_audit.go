@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+// Ecosystem audit actions.
+const (
+	ecosystemAuditCreate     = "create"
+	ecosystemAuditUpdate     = "update"
+	ecosystemAuditDelete     = "delete"
+	ecosystemAuditHardDelete = "hard_delete"
+	ecosystemAuditRestore    = "restore"
+)
+
+// History returns the audit trail for an ecosystem, newest first. It also works for
+// hard-deleted ecosystems since audit rows are not tied to the ecosystems table.
+func (h *EcosystemsAdminHandler) History() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		ecoID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_id"})
+		}
+
+		rows, err := h.db.QueryTimed(c.Context(), "ecosystem_history", `
+SELECT id, actor_user_id, action, changes, created_at
+FROM ecosystem_audit_log
+WHERE ecosystem_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT 200
+`, ecoID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_history_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var id uuid.UUID
+			var actor *uuid.UUID
+			var action string
+			var changesJSON []byte
+			var createdAt time.Time
+			if err := rows.Scan(&id, &actor, &action, &changesJSON, &createdAt); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_history_failed"})
+			}
+			var changes map[string]any
+			_ = json.Unmarshal(changesJSON, &changes)
+
+			var actorID *string
+			if actor != nil {
+				s := actor.String()
+				actorID = &s
+			}
+			out = append(out, fiber.Map{
+				"id":            id.String(),
+				"actor_user_id": actorID,
+				"action":        action,
+				"changes":       changes,
+				"created_at":    createdAt,
+			})
+		}
+		if err := rows.Err(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_history_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"history": out})
+	}
+}
+
+// auditActor returns the authenticated admin's user id, or nil if it is missing.
+func auditActor(c *fiber.Ctx) *uuid.UUID {
+	userIDStr, _ := c.Locals(auth.LocalUserID).(string)
+	id, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// snapshotEcosystem loads an ecosystem row as a JSON object and locks it for the
+// rest of the transaction. Returns pgx.ErrNoRows if it doesn't exist (or is
+// soft-deleted, unless includeDeleted is set).
+func snapshotEcosystem(ctx context.Context, tx pgx.Tx, id uuid.UUID, includeDeleted bool) (map[string]any, error) {
+	var raw []byte
+	err := tx.QueryRow(ctx, `
+SELECT to_jsonb(e)
+FROM ecosystems e
+WHERE e.id = $1 AND ($2::boolean OR e.deleted_at IS NULL)
+FOR UPDATE
+`, id, includeDeleted).Scan(&raw)
+	if err != nil {
+		return nil, err
+	}
+	var snap map[string]any
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// diffEcosystem returns {field: {"old": ..., "new": ...}} for every field that
+// differs between two snapshots. Either side may be nil (create / hard delete).
+// updated_at is ignored since it changes on every write.
+func diffEcosystem(before, after map[string]any) map[string]any {
+	changes := map[string]any{}
+	seen := map[string]struct{}{}
+	for _, snap := range []map[string]any{before, after} {
+		for k := range snap {
+			if k == "updated_at" {
+				continue
+			}
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			oldV, newV := before[k], after[k]
+			if !reflect.DeepEqual(oldV, newV) {
+				changes[k] = map[string]any{"old": oldV, "new": newV}
+			}
+		}
+	}
+	return changes
+}
+
+// writeEcosystemAudit records a change in the same transaction as the mutation.
+func writeEcosystemAudit(ctx context.Context, tx pgx.Tx, ecoID uuid.UUID, actor *uuid.UUID, action string, before, after map[string]any) error {
+	changes, err := json.Marshal(diffEcosystem(before, after))
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+INSERT INTO ecosystem_audit_log (ecosystem_id, actor_user_id, action, changes)
+VALUES ($1, $2, $3, $4::jsonb)
+`, ecoID, actor, action, string(changes))
+	return err
+}
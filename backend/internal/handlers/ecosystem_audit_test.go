@@ -0,0 +1,26 @@
+package handlers
+
+import "testing"
+
+func TestDiffEcosystem(t *testing.T) {
+	before := map[string]any{"name": "Stellar", "status": "active", "updated_at": "t1"}
+	after := map[string]any{"name": "Stellar", "status": "inactive", "updated_at": "t2"}
+
+	changes := diffEcosystem(before, after)
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %v", changes)
+	}
+	status, ok := changes["status"].(map[string]any)
+	if !ok || status["old"] != "active" || status["new"] != "inactive" {
+		t.Errorf("unexpected status change: %v", changes["status"])
+	}
+
+	// Create: every field (except updated_at) is new.
+	created := diffEcosystem(nil, after)
+	if len(created) != 2 {
+		t.Errorf("expected 2 fields on create, got %v", created)
+	}
+	if _, ok := created["updated_at"]; ok {
+		t.Error("updated_at should be ignored")
+	}
+}
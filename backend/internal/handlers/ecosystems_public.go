@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 )
 
@@ -17,16 +22,16 @@ func NewEcosystemsPublicHandler(d *db.DB) *EcosystemsPublicHandler {
 	return &EcosystemsPublicHandler{db: d}
 }
 
-// ListActive returns active ecosystems with computed counts:
-// - project_count: number of projects assigned to the ecosystem
-// - user_count: number of distinct project owners in the ecosystem
+// ListActive returns active ecosystems with their cached counts:
+// - project_count: number of live projects assigned to the ecosystem
+// - user_count: number of distinct owners of those projects
 func (h *EcosystemsPublicHandler) ListActive() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
 			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
 		}
 
-		rows, err := h.db.Pool.Query(c.Context(), `
+		rows, err := h.db.QueryTimed(c.Context(), "ecosystems_list", `
 SELECT
   e.id,
   e.slug,
@@ -36,12 +41,11 @@ SELECT
   e.status,
   e.created_at,
   e.updated_at,
-  COUNT(p.id) AS project_count,
-  COUNT(DISTINCT p.owner_user_id) AS user_count
+  e.languages,
+  e.cached_project_count,
+  e.cached_user_count
 FROM ecosystems e
-LEFT JOIN projects p ON p.ecosystem_id = e.id
-WHERE e.status = 'active'
-GROUP BY e.id
+WHERE e.status = 'active' AND e.deleted_at IS NULL
 ORDER BY e.created_at DESC
 LIMIT 200
 `)
@@ -53,18 +57,19 @@ LIMIT 200
 		var out []fiber.Map
 		for rows.Next() {
 			var (
-				id         uuid.UUID
-				slug       string
-				name       string
-				status     string
-				desc       *string
-				website    *string
-				createdAt  time.Time
-				updatedAt  time.Time
-				projectCnt int64
-				userCnt    int64
+				id            uuid.UUID
+				slug          string
+				name          string
+				status        string
+				desc          *string
+				website       *string
+				createdAt     time.Time
+				updatedAt     time.Time
+				languagesJSON []byte
+				projectCnt    int64
+				userCnt       int64
 			)
-			if err := rows.Scan(&id, &slug, &name, &desc, &website, &status, &createdAt, &updatedAt, &projectCnt, &userCnt); err != nil {
+			if err := rows.Scan(&id, &slug, &name, &desc, &website, &status, &createdAt, &updatedAt, &languagesJSON, &projectCnt, &userCnt); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystems_list_failed"})
 			}
 			out = append(out, fiber.Map{
@@ -76,6 +81,7 @@ LIMIT 200
 				"status":        status,
 				"created_at":    createdAt,
 				"updated_at":    updatedAt,
+				"languages":     parseLanguages(languagesJSON),
 				"project_count": projectCnt,
 				"user_count":    userCnt,
 			})
@@ -84,3 +90,316 @@ LIMIT 200
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ecosystems": out})
 	}
 }
+
+// GetBySlug returns a single active ecosystem by slug with the same cached
+// counts as ListActive, so detail pages don't need to fetch the whole list.
+// A slug the ecosystem had before a rename still resolves; the response then
+// carries moved_from, and slug is the one to link to from now on.
+func (h *EcosystemsPublicHandler) GetBySlug() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		slugParam := strings.TrimSpace(c.Params("slug"))
+		if slugParam == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_slug"})
+		}
+
+		var (
+			id            uuid.UUID
+			slug          string
+			name          string
+			status        string
+			desc          *string
+			website       *string
+			createdAt     time.Time
+			updatedAt     time.Time
+			languagesJSON []byte
+			projectCnt    int64
+			userCnt       int64
+		)
+		err := h.db.QueryRowTimed(c.Context(), "ecosystem_get", `
+SELECT
+  e.id,
+  e.slug,
+  e.name,
+  e.description,
+  e.website_url,
+  e.status,
+  e.created_at,
+  e.updated_at,
+  e.languages,
+  e.cached_project_count,
+  e.cached_user_count
+FROM ecosystems e
+WHERE e.id = `+ecosystemIDBySlugSQL(1)+` AND e.status = 'active' AND e.deleted_at IS NULL
+`, slugParam).Scan(&id, &slug, &name, &desc, &website, &status, &createdAt, &updatedAt, &languagesJSON, &projectCnt, &userCnt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_lookup_failed"})
+		}
+
+		resp := fiber.Map{
+			"id":            id.String(),
+			"slug":          slug,
+			"name":          name,
+			"description":   desc,
+			"website_url":   website,
+			"status":        status,
+			"created_at":    createdAt,
+			"updated_at":    updatedAt,
+			"languages":     parseLanguages(languagesJSON),
+			"project_count": projectCnt,
+			"user_count":    userCnt,
+		}
+		// Resolved through an old slug: tell the client where the ecosystem lives now
+		if !strings.EqualFold(slug, slugParam) {
+			resp["moved_from"] = slugParam
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
+	}
+}
+
+// projectStatuses are the values of projects.status
+var projectStatuses = map[string]bool{"pending_verification": true, "verified": true, "rejected": true}
+
+// Projects lists the projects in an ecosystem, most recently active first. Public
+// callers only see verified projects of an active ecosystem; admins (see
+// auth.OptionalAuth) see every status and inactive ecosystems too.
+// Query parameters:
+//   - status: only projects with this status (admins only unless "verified")
+//   - limit: max results (default 20, max 100)
+//   - offset: pagination offset (default 0)
+func (h *EcosystemsPublicHandler) Projects() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		slugParam := strings.TrimSpace(c.Params("slug"))
+		if slugParam == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_slug"})
+		}
+		role, _ := c.Locals(auth.LocalRole).(string)
+		isAdmin := role == "admin"
+
+		status := strings.TrimSpace(c.Query("status"))
+		if status != "" && !projectStatuses[status] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+		}
+		if !isAdmin {
+			if status != "" && status != "verified" {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "status_requires_admin"})
+			}
+			status = "verified"
+		}
+
+		limit := 20
+		if l := c.QueryInt("limit", 20); l > 0 && l <= 100 {
+			limit = l
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+
+		var ecosystemID uuid.UUID
+		err := h.db.QueryRowTimed(c.Context(), "ecosystem_lookup", `
+SELECT id FROM ecosystems
+WHERE id = `+ecosystemIDBySlugSQL(1)+` AND deleted_at IS NULL AND ($2 OR status = 'active')
+`, slugParam, isAdmin).Scan(&ecosystemID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_lookup_failed"})
+		}
+
+		where := `p.deleted_at IS NULL
+  AND EXISTS (SELECT 1 FROM project_ecosystems pe WHERE pe.project_id = p.id AND pe.ecosystem_id = $1)
+  AND ($2 = '' OR p.status = $2)`
+		args := []any{ecosystemID, status}
+
+		var total int64
+		if err := h.db.QueryRowTimed(c.Context(), "ecosystem_projects_count", `SELECT COUNT(*) FROM projects p WHERE `+where, args...).Scan(&total); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_projects_failed"})
+		}
+
+		// Contributors and last activity are computed as in the project leaderboard
+		rows, err := h.db.QueryTimed(c.Context(), "ecosystem_projects", fmt.Sprintf(`
+SELECT
+  p.id,
+  p.github_full_name,
+  p.status,
+  (
+    SELECT COUNT(DISTINCT LOWER(a.author_login))
+    FROM (
+      SELECT author_login FROM github_issues WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''
+      UNION
+      SELECT author_login FROM github_pull_requests WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''
+    ) a
+  ) AS contributors_count,
+  GREATEST(
+    (SELECT MAX(COALESCE(created_at_github, last_seen_at)) FROM github_issues WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''),
+    (SELECT MAX(COALESCE(created_at_github, last_seen_at)) FROM github_pull_requests WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != '')
+  ) AS last_activity
+FROM projects p
+WHERE %s
+ORDER BY last_activity DESC NULLS LAST, p.github_full_name ASC, p.id ASC
+LIMIT $3 OFFSET $4
+`, where), append(args, limit, offset)...)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_projects_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var (
+				id           uuid.UUID
+				fullName     string
+				projStatus   string
+				contributors int64
+				lastActivity *time.Time
+			)
+			if err := rows.Scan(&id, &fullName, &projStatus, &contributors, &lastActivity); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_projects_failed"})
+			}
+			out = append(out, fiber.Map{
+				"id":            id.String(),
+				"full_name":     fullName,
+				"status":        projStatus,
+				"contributors":  contributors,
+				"last_activity": lastActivity,
+			})
+		}
+		if err := rows.Err(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_projects_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"projects": out,
+			"total":    total,
+			"limit":    limit,
+			"offset":   offset,
+		})
+	}
+}
+
+// ecosystemStatsTopProjects is how many projects Stats lists under top_projects
+const ecosystemStatsTopProjects = 3
+
+// Stats returns headline numbers for an active ecosystem, over its verified projects:
+// - verified_projects: number of live verified projects in the ecosystem
+// - contributors: distinct issue/PR authors across those projects (case-insensitive)
+// - contributions: issues plus PRs with an author across those projects
+// - top_projects: the 3 projects with the most contributors, then contributions
+func (h *EcosystemsPublicHandler) Stats() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		slugParam := strings.TrimSpace(c.Params("slug"))
+		if slugParam == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_ecosystem_slug"})
+		}
+
+		var (
+			ecosystemID uuid.UUID
+			slug, name  string
+		)
+		err := h.db.QueryRowTimed(c.Context(), "ecosystem_lookup", `
+SELECT id, slug, name FROM ecosystems
+WHERE id = `+ecosystemIDBySlugSQL(1)+` AND status = 'active' AND deleted_at IS NULL
+`, slugParam).Scan(&ecosystemID, &slug, &name)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ecosystem_not_found"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_lookup_failed"})
+		}
+
+		const verifiedInEcosystem = `p.status = 'verified' AND p.deleted_at IS NULL
+  AND EXISTS (SELECT 1 FROM project_ecosystems pe WHERE pe.project_id = p.id AND pe.ecosystem_id = $1)`
+
+		var projectCnt, contributorCnt, contributionCnt int64
+		err = h.db.QueryRowTimed(c.Context(), "ecosystem_stats", `
+WITH eco_projects AS (
+  SELECT p.id FROM projects p WHERE `+verifiedInEcosystem+`
+),
+contributions AS (
+  SELECT gi.author_login AS login
+  FROM github_issues gi
+  INNER JOIN eco_projects ep ON ep.id = gi.project_id
+  WHERE gi.author_login IS NOT NULL AND gi.author_login != ''
+  UNION ALL
+  SELECT gpr.author_login AS login
+  FROM github_pull_requests gpr
+  INNER JOIN eco_projects ep ON ep.id = gpr.project_id
+  WHERE gpr.author_login IS NOT NULL AND gpr.author_login != ''
+)
+SELECT
+  (SELECT COUNT(*) FROM eco_projects),
+  (SELECT COUNT(DISTINCT LOWER(login)) FROM contributions),
+  (SELECT COUNT(*) FROM contributions)
+`, ecosystemID).Scan(&projectCnt, &contributorCnt, &contributionCnt)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_stats_failed"})
+		}
+
+		// Contributors per project are counted as in the ecosystem projects list
+		rows, err := h.db.QueryTimed(c.Context(), "ecosystem_stats_top_projects", `
+SELECT p.id, p.github_full_name, a.contributors, a.contributions
+FROM projects p
+CROSS JOIN LATERAL (
+  SELECT COUNT(DISTINCT LOWER(author_login)) AS contributors, COUNT(*) AS contributions
+  FROM (
+    SELECT author_login FROM github_issues WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''
+    UNION ALL
+    SELECT author_login FROM github_pull_requests WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''
+  ) x
+) a
+WHERE `+verifiedInEcosystem+`
+ORDER BY a.contributors DESC, a.contributions DESC, p.github_full_name ASC, p.id ASC
+LIMIT $2
+`, ecosystemID, ecosystemStatsTopProjects)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_stats_failed"})
+		}
+		defer rows.Close()
+
+		top := []fiber.Map{}
+		for rows.Next() {
+			var (
+				id            uuid.UUID
+				fullName      string
+				contributors  int64
+				contributions int64
+			)
+			if err := rows.Scan(&id, &fullName, &contributors, &contributions); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_stats_failed"})
+			}
+			top = append(top, fiber.Map{
+				"id":            id.String(),
+				"full_name":     fullName,
+				"contributors":  contributors,
+				"contributions": contributions,
+			})
+		}
+		if err := rows.Err(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ecosystem_stats_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ecosystem": fiber.Map{
+				"slug": slug,
+				"name": name,
+			},
+			"verified_projects": projectCnt,
+			"contributors":      contributorCnt,
+			"contributions":     contributionCnt,
+			"top_projects":      top,
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+func TestEcosystemProjects_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: three verified projects, one pending and one rejected
+	var userID, ecosystemID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('eco-projects') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('eco-projects-test', 'Eco Projects Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'eco-projects/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	var activeID string
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES
+  ($1, 'eco-projects/a', 'verified', $2),
+  ($1, 'eco-projects/b', 'verified', $2),
+  ($1, 'eco-projects/c', 'verified', $2),
+  ($1, 'eco-projects/pending', 'pending_verification', $2),
+  ($1, 'eco-projects/rejected', 'rejected', $2)`, userID, ecosystemID); err != nil {
+		t.Fatalf("insert projects: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `SELECT id::text FROM projects WHERE github_full_name = 'eco-projects/b'`).Scan(&activeID); err != nil {
+		t.Fatalf("lookup project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, 9301, 1, 'open', 'eco-projects-alice'), ($1, 9302, 2, 'open', 'eco-projects-bob')`, activeID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+
+	h := NewEcosystemsPublicHandler(d)
+	app := fiber.New()
+	// Stands in for auth.OptionalAuth
+	app.Use(func(c *fiber.Ctx) error {
+		if role := c.Get("X-Test-Role"); role != "" {
+			c.Locals(auth.LocalRole, role)
+		}
+		return c.Next()
+	})
+	app.Get("/ecosystems/:slug/projects", h.Projects())
+
+	get := func(path, role string) (int, map[string]any) {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-Test-Role", role)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var out map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	// Public callers see verified projects, most recently active first
+	status, body := get("/ecosystems/eco-projects-test/projects?limit=2", "")
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	projects, _ := body["projects"].([]any)
+	if body["total"] != float64(3) || len(projects) != 2 {
+		t.Fatalf("expected 2 of 3 verified projects, got %v", body)
+	}
+	first, _ := projects[0].(map[string]any)
+	if first["full_name"] != "eco-projects/b" || first["contributors"] != float64(2) || first["last_activity"] == nil {
+		t.Errorf("expected the active project first with 2 contributors, got %v", first)
+	}
+
+	status, body = get("/ecosystems/eco-projects-test/projects?limit=2&offset=2", "")
+	if projects, _ := body["projects"].([]any); status != fiber.StatusOK || len(projects) != 1 {
+		t.Errorf("expected the last verified project on page 2, got %d: %v", status, body)
+	}
+
+	if status, _ := get("/ecosystems/eco-projects-test/projects?status=pending_verification", ""); status != fiber.StatusForbidden {
+		t.Errorf("expected 403 for a public status filter, got %d", status)
+	}
+	if status, _ := get("/ecosystems/eco-projects-test/projects?status=bogus", "admin"); status != fiber.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown status, got %d", status)
+	}
+
+	// Admins see every status and can filter by it
+	if status, body := get("/ecosystems/eco-projects-test/projects", "admin"); status != fiber.StatusOK || body["total"] != float64(5) {
+		t.Errorf("expected all 5 projects for an admin, got %d: %v", status, body)
+	}
+	status, body = get("/ecosystems/eco-projects-test/projects?status=rejected", "admin")
+	projects, _ = body["projects"].([]any)
+	if status != fiber.StatusOK || body["total"] != float64(1) || len(projects) != 1 {
+		t.Fatalf("expected 1 rejected project, got %d: %v", status, body)
+	}
+	if row, _ := projects[0].(map[string]any); row["status"] != "rejected" {
+		t.Errorf("expected the rejected project, got %v", row)
+	}
+
+	// Inactive ecosystems are hidden from public callers only
+	if _, err := d.Pool.Exec(ctx, `UPDATE ecosystems SET status = 'inactive' WHERE id = $1`, ecosystemID); err != nil {
+		t.Fatalf("deactivate ecosystem: %v", err)
+	}
+	if status, _ := get("/ecosystems/eco-projects-test/projects", ""); status != fiber.StatusNotFound {
+		t.Errorf("expected 404 for an inactive ecosystem, got %d", status)
+	}
+	if status, _ := get("/ecosystems/eco-projects-test/projects", "admin"); status != fiber.StatusOK {
+		t.Errorf("expected admins to see an inactive ecosystem, got %d", status)
+	}
+}
+
+func TestEcosystemStats_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: four verified projects with known activity, plus a pending one whose
+	// contributions must not count
+	var userID, ecosystemID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('eco-stats') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('eco-stats-test', 'Eco Stats Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'eco-stats/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES
+  ($1, 'eco-stats/a', 'verified', $2),
+  ($1, 'eco-stats/b', 'verified', $2),
+  ($1, 'eco-stats/c', 'verified', $2),
+  ($1, 'eco-stats/d', 'verified', $2),
+  ($1, 'eco-stats/pending', 'pending_verification', $2)`, userID, ecosystemID); err != nil {
+		t.Fatalf("insert projects: %v", err)
+	}
+	projectIDs := map[string]string{}
+	rows, err := d.Pool.Query(ctx, `SELECT github_full_name, id::text FROM projects WHERE github_full_name LIKE 'eco-stats/%'`)
+	if err != nil {
+		t.Fatalf("lookup projects: %v", err)
+	}
+	for rows.Next() {
+		var name, id string
+		if err := rows.Scan(&name, &id); err != nil {
+			t.Fatalf("scan project: %v", err)
+		}
+		projectIDs[name] = id
+	}
+	rows.Close()
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES
+  ($1, 9501, 1, 'open', 'eco-stats-alice'),
+  ($1, 9502, 2, 'open', 'eco-stats-bob'),
+  ($2, 9503, 1, 'open', 'ECO-STATS-ALICE'),
+  ($3, 9504, 1, 'open', 'eco-stats-carol'),
+  ($4, 9505, 1, 'open', 'eco-stats-dave')`,
+		projectIDs["eco-stats/a"], projectIDs["eco-stats/b"], projectIDs["eco-stats/d"], projectIDs["eco-stats/pending"]); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, author_login)
+VALUES ($1, 9501, 3, 'open', 'eco-stats-alice'), ($2, 9502, 2, 'open', 'eco-stats-carol')`,
+		projectIDs["eco-stats/a"], projectIDs["eco-stats/d"]); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/ecosystems/:slug/stats", NewEcosystemsPublicHandler(d).Stats())
+	get := func(path string) (int, map[string]any) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var out map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	status, body := get("/ecosystems/ECO-STATS-TEST/stats")
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	// alice (in either case), bob and carol across a, b and d; dave only in the pending project
+	if body["verified_projects"] != float64(4) || body["contributors"] != float64(3) || body["contributions"] != float64(6) {
+		t.Errorf("expected 4 projects, 3 contributors and 6 contributions, got %v", body)
+	}
+	if eco, _ := body["ecosystem"].(map[string]any); eco["slug"] != "eco-stats-test" || eco["name"] != "Eco Stats Test" {
+		t.Errorf("unexpected ecosystem %v", body["ecosystem"])
+	}
+
+	top, _ := body["top_projects"].([]any)
+	want := []struct {
+		name                        string
+		contributors, contributions float64
+	}{
+		{"eco-stats/a", 2, 3},
+		{"eco-stats/d", 1, 2},
+		{"eco-stats/b", 1, 1},
+	}
+	if len(top) != len(want) {
+		t.Fatalf("expected %d top projects, got %v", len(want), top)
+	}
+	for i, w := range want {
+		row, _ := top[i].(map[string]any)
+		if row["full_name"] != w.name || row["contributors"] != w.contributors || row["contributions"] != w.contributions {
+			t.Errorf("top project %d: expected %s with %v contributors and %v contributions, got %v", i, w.name, w.contributors, w.contributions, row)
+		}
+	}
+
+	if status, _ := get("/ecosystems/eco-stats-unknown/stats"); status != fiber.StatusNotFound {
+		t.Errorf("expected 404 for an unknown ecosystem, got %d", status)
+	}
+	if _, err := d.Pool.Exec(ctx, `UPDATE ecosystems SET status = 'inactive' WHERE id = $1`, ecosystemID); err != nil {
+		t.Fatalf("deactivate ecosystem: %v", err)
+	}
+	if status, _ := get("/ecosystems/eco-stats-test/stats"); status != fiber.StatusNotFound {
+		t.Errorf("expected 404 for an inactive ecosystem, got %d", status)
+	}
+}
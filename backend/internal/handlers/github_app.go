@@ -295,7 +295,7 @@ func (h *GitHubAppHandler) syncInstallationRepositories(ctx context.Context, use
 	// Get default ecosystem (or use a fallback)
 	var defaultEcosystemID uuid.UUID
 	err = h.db.Pool.QueryRow(ctx, `
-SELECT id FROM ecosystems WHERE status = 'active' ORDER BY created_at ASC LIMIT 1
+SELECT id FROM ecosystems WHERE status = 'active' AND deleted_at IS NULL ORDER BY created_at ASC LIMIT 1
 `).Scan(&defaultEcosystemID)
 	if err != nil {
 		slog.Warn("no active ecosystem found, repositories will be created without ecosystem",
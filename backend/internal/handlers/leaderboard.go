@@ -1,192 +1,170 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/broadcast"
 	"github.com/jagadeesh/grainlify/backend/internal/db"
 )
 
 type LeaderboardHandler struct {
-	db *db.DB
+	db       *db.DB
+	cache    *leaderboardCache
+	scoring  ScoreConfig
+	tieBreak LeaderboardTieBreak
+	updates  *broadcast.Signal // notified whenever the cache is invalidated
+	// avatarProxy replaces github.com in fallback avatar URLs, see SetAvatarProxy
+	avatarProxy string
+	// compressMinBytes is the smallest body Compress encodes, see SetCompressionThreshold
+	compressMinBytes int
+	// warmConcurrency bounds WarmCache; negative disables warming after syncs
+	warmConcurrency int
+
+	streamsDone <-chan struct{} // closes open streams, see WatchSyncs
 }
 
-func NewLeaderboardHandler(d *db.DB) *LeaderboardHandler {
-	return &LeaderboardHandler{db: d}
+// NewLeaderboardHandler caches leaderboard pages for cacheTTL (0 uses the default; negative disables).
+func NewLeaderboardHandler(d *db.DB, cacheTTL time.Duration) *LeaderboardHandler {
+	if cacheTTL == 0 {
+		cacheTTL = defaultLeaderboardCacheTTL
+	}
+	return &LeaderboardHandler{
+		db:               d,
+		cache:            newLeaderboardCache(cacheTTL),
+		scoring:          DefaultScoreConfig(),
+		tieBreak:         TieBreakAlphabetical,
+		updates:          broadcast.NewSignal(),
+		compressMinBytes: defaultLeaderboardCompressMinBytes,
+		warmConcurrency:  defaultLeaderboardWarmConcurrency,
+	}
+}
+
+// InvalidateCache drops all cached leaderboard pages, e.g. after a contribution sync,
+// and tells open leaderboard streams to refresh.
+func (h *LeaderboardHandler) InvalidateCache() {
+	h.cache.invalidate()
+	h.updates.Notify()
+}
+
+// InvalidateCacheHandler lets admins flush the leaderboard cache.
+func (h *LeaderboardHandler) InvalidateCacheHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		h.InvalidateCache()
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
 }
 
-// Leaderboard returns top contributors ranked by contributions in verified projects
+// Leaderboard returns top contributors ranked by contributions in verified projects.
+// ?search= keeps only logins starting with that prefix, each with its global rank.
+// ?include_zero=true also lists signed-up users with no counted contribution, ranked last.
+// ?avatar_size= sizes the fallback avatars of contributors without a stored one.
+// ?snapshot_id= pages through a ranking frozen by CreateSnapshot instead of live data.
 func (h *LeaderboardHandler) Leaderboard() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if h.db == nil || h.db.Pool == nil {
 			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
 		}
 
-		// Get limit and offset from query params (default 10, max 100)
-		limit := c.QueryInt("limit", 10)
-		if limit < 1 {
-			limit = 10
+		q := contributorPageQueryFrom(c)
+		snapshotID, fromSnapshot, ok := parseSnapshotID(c)
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_snapshot_id"})
 		}
-		if limit > 100 {
-			limit = 100
+
+		var leaderboard []fiber.Map
+		var err error
+		if fromSnapshot {
+			leaderboard, err = h.snapshotPage(c.Context(), snapshotID, q)
+		} else {
+			leaderboard, err = h.contributorPage(c.Context(), q)
 		}
-		offset := c.QueryInt("offset", 0)
-		if offset < 0 {
-			offset = 0
+		if errors.Is(err, errSnapshotNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "snapshot_not_found"})
 		}
-
-		// Query top contributors by contribution count in verified projects
-		// This query:
-		// 1. Gets all unique author_logins from issues and PRs in verified projects
-		// 2. LEFT JOINs with github_accounts to get user info if they signed up
-		// 3. Shows ALL contributors, whether they signed up or not
-		// 4. Counts their contributions (issues + PRs) in verified projects
-		rows, err := h.db.Pool.Query(c.Context(), `
-WITH all_contributors AS (
-  -- Get all unique contributors from issues in verified projects
-  SELECT DISTINCT i.author_login as login
-  FROM github_issues i
-  INNER JOIN projects p ON i.project_id = p.id
-  WHERE i.author_login IS NOT NULL 
-    AND i.author_login != ''
-    AND p.status = 'verified'
-  
-  UNION
-  
-  -- Get all unique contributors from PRs in verified projects
-  SELECT DISTINCT pr.author_login as login
-  FROM github_pull_requests pr
-  INNER JOIN projects p ON pr.project_id = p.id
-  WHERE pr.author_login IS NOT NULL 
-    AND pr.author_login != ''
-    AND p.status = 'verified'
-)
-SELECT 
-  ac.login as username,
-  COALESCE(ga.avatar_url, '') as avatar_url,
-  COALESCE(u.id::text, '') as user_id,
-  (
-    SELECT COUNT(*) 
-    FROM github_issues i
-    INNER JOIN projects p ON i.project_id = p.id
-    WHERE LOWER(i.author_login) = LOWER(ac.login) AND p.status = 'verified'
-  ) +
-  (
-    SELECT COUNT(*) 
-    FROM github_pull_requests pr
-    INNER JOIN projects p ON pr.project_id = p.id
-    WHERE LOWER(pr.author_login) = LOWER(ac.login) AND p.status = 'verified'
-  ) as contribution_count,
-  COALESCE(
-    (
-      SELECT ARRAY_AGG(DISTINCT e.name)
-      FROM (
-        SELECT DISTINCT p.ecosystem_id
-        FROM github_issues i
-        INNER JOIN projects p ON i.project_id = p.id
-        WHERE LOWER(i.author_login) = LOWER(ac.login) AND p.status = 'verified'
-        UNION
-        SELECT DISTINCT p.ecosystem_id
-        FROM github_pull_requests pr
-        INNER JOIN projects p ON pr.project_id = p.id
-        WHERE LOWER(pr.author_login) = LOWER(ac.login) AND p.status = 'verified'
-      ) contrib_ecosystems
-      INNER JOIN ecosystems e ON contrib_ecosystems.ecosystem_id = e.id
-      WHERE e.status = 'active'
-    ),
-    ARRAY[]::TEXT[]
-  ) as ecosystems
-FROM all_contributors ac
-LEFT JOIN github_accounts ga ON LOWER(ga.login) = LOWER(ac.login)
-LEFT JOIN users u ON ga.user_id = u.id
-WHERE (
-  SELECT COUNT(*) 
-  FROM github_issues i
-  INNER JOIN projects p ON i.project_id = p.id
-  WHERE LOWER(i.author_login) = LOWER(ac.login) AND p.status = 'verified'
-) +
-(
-  SELECT COUNT(*) 
-  FROM github_pull_requests pr
-  INNER JOIN projects p ON pr.project_id = p.id
-  WHERE LOWER(pr.author_login) = LOWER(ac.login) AND p.status = 'verified'
-) > 0
-ORDER BY contribution_count DESC, ac.login ASC
-LIMIT $1 OFFSET $2
-`, limit, offset)
 		if err != nil {
-			slog.Error("failed to fetch leaderboard",
-				"error", err,
-			)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_fetch_failed"})
 		}
-		defer rows.Close()
 
-		var leaderboard []fiber.Map
-		rank := offset + 1 // Start rank from offset + 1 for pagination
-		for rows.Next() {
-			var username string
-			var avatarURL *string
-			var userID string
-			var contributionCount int
-			var ecosystems []string
-
-			if err := rows.Scan(&username, &avatarURL, &userID, &contributionCount, &ecosystems); err != nil {
-				slog.Error("failed to scan leaderboard row",
-					"error", err,
-				)
-				continue
-			}
-
-			// Default avatar if not set - use GitHub avatar URL as fallback
-			avatar := ""
-			if avatarURL != nil && *avatarURL != "" {
-				avatar = *avatarURL
-			} else {
-				// Fallback to GitHub avatar URL if not in database
-				avatar = fmt.Sprintf("https://github.com/%s.png?size=200", username)
-			}
-
-			// Ensure ecosystems is not nil
-			if ecosystems == nil {
-				ecosystems = []string{}
-			}
+		return c.Status(fiber.StatusOK).JSON(leaderboard)
+	}
+}
 
-			// Calculate rank tier based on position
-			rankTier := GetRankTier(rank)
-
-			leaderboard = append(leaderboard, fiber.Map{
-				"rank":           rank,
-				"rank_tier":      string(rankTier),
-				"rank_tier_name": GetRankTierDisplayName(rankTier),
-				"username":       username,
-				"avatar":         avatar,
-				"user_id":        userID,
-				"contributions":  contributionCount,
-				"ecosystems":     ecosystems,
-				// For now, set trend to 'same' and score to contribution count
-				// These can be enhanced later with historical data
-				"score":      contributionCount,
-				"trend":      "same",
-				"trendValue": 0,
-			})
-			rank++
-		}
+// contributorPageQuery is a parsed contributor leaderboard request.
+type contributorPageQuery struct {
+	limit, offset int
+	filter        leaderboardFilter
+	tierMode      RankTierMode
+	search        string
+	avatarSize    int // fallback avatar size, see clampAvatarSize
+}
 
-		// Always return an array, even if empty
-		if leaderboard == nil {
-			leaderboard = []fiber.Map{}
-		}
+func contributorPageQueryFrom(c *fiber.Ctx) contributorPageQuery {
+	// Get limit and offset from query params (default 10, max 100)
+	limit := c.QueryInt("limit", 10)
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+	return contributorPageQuery{
+		limit:      limit,
+		offset:     offset,
+		filter:     leaderboardFilterFromQuery(c),
+		tierMode:   ParseRankTierMode(c.Query("tier_mode", string(RankTierModeAbsolute))),
+		search:     strings.TrimSpace(c.Query("search")),
+		avatarSize: clampAvatarSize(c.QueryInt("avatar_size", defaultAvatarSize)),
+	}
+}
 
-		return c.Status(fiber.StatusOK).JSON(leaderboard)
+// contributorPage returns the (cached) contributor leaderboard page for q.
+func (h *LeaderboardHandler) contributorPage(ctx context.Context, q contributorPageQuery) ([]fiber.Map, error) {
+	q.filter.TieBreak = h.tieBreak
+	key := fmt.Sprintf("contributors|%d|%d|%s|%s|%s|%d", q.limit, q.offset, q.filter.cacheKey(), q.tierMode, strings.ToLower(q.search), q.avatarSize)
+	leaderboard, err := h.cache.get(key, func() (any, error) {
+		return h.fetchContributors(ctx, q)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return leaderboard.([]fiber.Map), nil
 }
 
-// ProjectsLeaderboard returns top projects ranked by contributor count in verified projects
+// ProjectsLeaderboard returns top verified projects, ranked by contributor count by default.
+// ?sort=contributors|name|recent and ?order=asc|desc choose another ranking.
+// ?technology= keeps projects in an ecosystem that lists that language (case-insensitive).
+// ?status=pending|rejected|all ranks other projects instead, for admins only (see
+// auth.OptionalAuth); the default is verified.
 func (h *LeaderboardHandler) ProjectsLeaderboard() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		orderBy, err := projectLeaderboardOrderBy(c.Query("sort"), c.Query("order"), h.tieBreak)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_sort"})
+		}
+
+		status := strings.ToLower(strings.TrimSpace(c.Query("status", "verified")))
+		if _, ok := projectLeaderboardStatuses[status]; !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_status"})
+		}
+		if role, _ := c.Locals(auth.LocalRole).(string); status != "verified" && role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "status_requires_admin"})
+		}
+
 		if h.db == nil || h.db.Pool == nil {
 			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
 		}
@@ -204,16 +182,153 @@ func (h *LeaderboardHandler) ProjectsLeaderboard() fiber.Handler {
 			offset = 0
 		}
 
-		// Get ecosystem filter (optional)
+		// Get ecosystem and technology filters (optional)
 		ecosystemSlug := c.Query("ecosystem", "")
+		technology := strings.TrimSpace(c.Query("technology", ""))
+
+		leaderboard, err := h.projectPage(c.Context(), ecosystemSlug, technology, status, orderBy, limit, offset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_leaderboard_fetch_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(leaderboard)
+	}
+}
+
+// projectPage returns the (cached) project leaderboard page, see fetchProjects.
+func (h *LeaderboardHandler) projectPage(ctx context.Context, ecosystemSlug, technology, status, orderBy string, limit, offset int) ([]fiber.Map, error) {
+	key := fmt.Sprintf("projects|%d|%d|%s|%s|%s|%s", limit, offset, strings.ToLower(strings.TrimSpace(ecosystemSlug)), strings.ToLower(technology), orderBy, status)
+	leaderboard, err := h.cache.get(key, func() (any, error) {
+		return h.fetchProjects(ctx, ecosystemSlug, technology, status, orderBy, limit, offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return leaderboard.([]fiber.Map), nil
+}
+
+// fetchContributors runs the contributor leaderboard query for one page. A non-empty
+// search pages through matching logins only, keeping their global ranks.
+func (h *LeaderboardHandler) fetchContributors(ctx context.Context, q contributorPageQuery) ([]fiber.Map, error) {
+	f, search := q.filter, q.search
+
+	// Percentile tiers need the size of the whole ranked population, not just this page.
+	total := 0
+	if q.tierMode == RankTierModePercentile {
+		countQuery, countArgs := contributorLeaderboardCountQuery(f)
+		if err := h.db.QueryRowTimed(ctx, "leaderboard_count", countQuery, countArgs...).Scan(&total); err != nil {
+			slog.Error("failed to count leaderboard contributors",
+				"error", err,
+			)
+			return nil, err
+		}
+	}
+
+	query, args, argPos := contributorRankedQuery(f, nil)
+	if search != "" {
+		query, args, argPos = contributorSearchQuery(f, search)
+	}
+	query += fmt.Sprintf("LIMIT $%d OFFSET $%d\n", argPos, argPos+1)
+	args = append(args, q.limit, q.offset)
+
+	rows, err := h.db.QueryTimed(ctx, "leaderboard", query, args...)
+	if err != nil {
+		slog.Error("failed to fetch leaderboard",
+			"error", err,
+		)
+		return nil, err
+	}
+	defer rows.Close()
+	return h.contributorRows(rows, q, total), nil
+}
+
+// contributorRows builds leaderboard entries from rows of contributorRankedQuery (or a
+// snapshot read in the same shape). total is the ranked population for percentile tiers.
+func (h *LeaderboardHandler) contributorRows(rows pgx.Rows, q contributorPageQuery, total int) []fiber.Map {
+	var leaderboard []fiber.Map
+	for rows.Next() {
+		var rank int
+		var username string
+		var avatarURL *string
+		var userID string
+		var contributionCount int
+		var ecosystems []string
+		var components contributorScoreComponents
+		var prsOpened int
+		var firstContributionAt *time.Time
+
+		// The rank is numbered by the query (see contributorRankedQuery), not derived from offset
+		if err := rows.Scan(&username, &avatarURL, &userID, &contributionCount, &ecosystems,
+			&components.Issues, &components.MergedPullRequests, &prsOpened, &components.RecentContributions, &firstContributionAt,
+			&rank); err != nil {
+			slog.Error("failed to scan leaderboard row",
+				"error", err,
+			)
+			continue
+		}
+
+		// Default avatar if not set - use GitHub avatar URL as fallback
+		avatar := ""
+		if avatarURL != nil && *avatarURL != "" {
+			avatar = *avatarURL
+		} else {
+			// Fallback to GitHub avatar URL (or its proxy) if not in database
+			avatar = fallbackAvatarURL(username, q.avatarSize, h.avatarProxy)
+		}
+
+		// Ensure ecosystems is not nil
+		if ecosystems == nil {
+			ecosystems = []string{}
+		}
+		components.PullRequests = contributionCount - components.Issues
+		components.Ecosystems = len(ecosystems)
+
+		// Calculate rank tier based on position (and population in percentile mode)
+		rankTier := GetRankTierForMode(rank, total, q.tierMode)
+
+		leaderboard = append(leaderboard, fiber.Map{
+			"rank":             rank,
+			"rank_tier":        string(rankTier),
+			"rank_tier_name":   GetRankTierDisplayName(rankTier),
+			"username":         username,
+			"avatar":           avatar,
+			"user_id":          userID,
+			"contributions":    contributionCount,
+			"issues_count":     components.Issues,
+			"prs_opened":       prsOpened, // Whatever ?pr_state=, unlike contributions
+			"prs_merged":       components.MergedPullRequests,
+			"ecosystems":       ecosystems,
+			"score":            components.score(h.scoring.Contributor),
+			"score_components": components,
+			// Orders ties when configured, see LeaderboardTieBreak
+			"first_contribution_at": firstContributionAt,
+			// For now, set trend to 'same'
+			// This can be enhanced later with historical data
+			"trend":      "same",
+			"trendValue": 0,
+		})
+	}
+
+	// Always return an array, even if empty
+	if leaderboard == nil {
+		leaderboard = []fiber.Map{}
+	}
+	return leaderboard
+}
 
-		// Build query with optional ecosystem filter
-		query := `
+// fetchProjects runs the project leaderboard query for one page. status must be a key
+// of projectLeaderboardStatuses and orderBy must come from projectLeaderboardOrderBy.
+// The per-project counts only read the project's own issues and PRs, so they follow
+// the same status filter as the project rows.
+func (h *LeaderboardHandler) fetchProjects(ctx context.Context, ecosystemSlug, technology, status, orderBy string, limit, offset int) ([]fiber.Map, error) {
+	// Build query with optional ecosystem and technology filters
+	query := `
 SELECT 
   p.id,
   p.github_full_name,
+  p.status,
   (
-    SELECT COUNT(DISTINCT a.author_login)
+    SELECT COUNT(DISTINCT LOWER(a.author_login))
     FROM (
       SELECT author_login FROM github_issues WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''
       UNION
@@ -222,19 +337,35 @@ SELECT
   ) AS contributors_count,
   COALESCE(
     (
-      SELECT ARRAY_AGG(DISTINCT e.name)
-      FROM ecosystems e
-      WHERE e.id = p.ecosystem_id AND e.status = 'active'
+      SELECT ARRAY_AGG(DISTINCT pe_e.name)
+      FROM project_ecosystems pe
+      INNER JOIN ecosystems pe_e ON pe_e.id = pe.ecosystem_id
+      WHERE pe.project_id = p.id AND pe_e.status = 'active' AND pe_e.deleted_at IS NULL
     ),
     ARRAY[]::TEXT[]
   ) as ecosystems,
-  COALESCE(e.slug, '') as ecosystem_slug
+  COALESCE(e.slug, '') as ecosystem_slug,
+  GREATEST(
+    (SELECT MAX(COALESCE(created_at_github, last_seen_at)) FROM github_issues WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''),
+    (SELECT MAX(COALESCE(created_at_github, last_seen_at)) FROM github_pull_requests WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != '')
+  ) AS last_contribution_at,
+  LEAST(
+    (SELECT MIN(COALESCE(created_at_github, last_seen_at)) FROM github_issues WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''),
+    (SELECT MIN(COALESCE(created_at_github, last_seen_at)) FROM github_pull_requests WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != '')
+  ) AS first_contribution_at,
+  -- Score components (see ScoreConfig)
+  (SELECT COUNT(*) FROM github_issues WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != '') +
+  (SELECT COUNT(*) FROM github_pull_requests WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != '') AS contributions_count,
+  (SELECT COUNT(*) FROM github_issues WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''
+     AND COALESCE(created_at_github, last_seen_at) >= now() - ` + scoreRecentWindowSQL + `) +
+  (SELECT COUNT(*) FROM github_pull_requests WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''
+     AND COALESCE(created_at_github, last_seen_at) >= now() - ` + scoreRecentWindowSQL + `) AS recent_contributions_count
 FROM projects p
-LEFT JOIN ecosystems e ON p.ecosystem_id = e.id
-WHERE p.status = 'verified' 
+LEFT JOIN ecosystems e ON p.ecosystem_id = e.id AND e.deleted_at IS NULL
+WHERE ` + projectLeaderboardStatuses[status] + `
   AND p.deleted_at IS NULL
   AND (
-    SELECT COUNT(DISTINCT a.author_login)
+    SELECT COUNT(DISTINCT LOWER(a.author_login))
     FROM (
       SELECT author_login FROM github_issues WHERE project_id = p.id AND author_login IS NOT NULL AND author_login != ''
       UNION
@@ -242,124 +373,586 @@ WHERE p.status = 'verified'
     ) a
   ) > 0
 `
-		args := []interface{}{}
-		argIndex := 1
+	args := []interface{}{}
+	argIndex := 1
+
+	// Add ecosystem filter if provided
+	if ecosystemSlug != "" {
+		query += " AND " + projectInEcosystemSQL("p.id", argIndex)
+		args = append(args, ecosystemSlug)
+		argIndex++
+	}
 
-		// Add ecosystem filter if provided
-		if ecosystemSlug != "" {
-			query += fmt.Sprintf(" AND LOWER(e.slug) = LOWER($%d)", argIndex)
-			args = append(args, ecosystemSlug)
-			argIndex++
-		}
+	// Add technology filter if provided
+	if technology != "" {
+		query += " AND " + projectEcosystemHasTechnologySQL("p.id", argIndex)
+		args = append(args, technology)
+		argIndex++
+	}
 
-		query += `
-ORDER BY contributors_count DESC, p.github_full_name ASC
-`
+	query += "\nORDER BY " + orderBy + "\n"
 
-		// Add limit and offset
-		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-		args = append(args, limit, offset)
+	// Add limit and offset
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, limit, offset)
 
-		rows, err := h.db.Pool.Query(c.Context(), query, args...)
-		if err != nil {
-			slog.Error("failed to fetch project leaderboard",
+	rows, err := h.db.QueryTimed(ctx, "projects_leaderboard", query, args...)
+	if err != nil {
+		slog.Error("failed to fetch project leaderboard",
+			"error", err,
+		)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var leaderboard []fiber.Map
+	rank := offset + 1 // Start rank from offset + 1 for pagination
+	for rows.Next() {
+		var id string
+		var fullName, projectStatus string
+		var contributorsCount int
+		var ecosystems []string
+		var ecosystemSlug string
+		var lastContributionAt, firstContributionAt *time.Time
+		var components projectScoreComponents
+
+		if err := rows.Scan(&id, &fullName, &projectStatus, &contributorsCount, &ecosystems, &ecosystemSlug, &lastContributionAt, &firstContributionAt,
+			&components.Contributions, &components.RecentContributions); err != nil {
+			slog.Error("failed to scan project leaderboard row",
 				"error", err,
 			)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_leaderboard_fetch_failed"})
+			continue
 		}
-		defer rows.Close()
 
-		var leaderboard []fiber.Map
-		rank := offset + 1 // Start rank from offset + 1 for pagination
-		for rows.Next() {
-			var id string
-			var fullName string
-			var contributorsCount int
-			var ecosystems []string
-			var ecosystemSlug string
-
-			if err := rows.Scan(&id, &fullName, &contributorsCount, &ecosystems, &ecosystemSlug); err != nil {
-				slog.Error("failed to scan project leaderboard row",
-					"error", err,
-				)
-				continue
-			}
+		// Ensure ecosystems is not nil
+		if ecosystems == nil {
+			ecosystems = []string{}
+		}
 
-			// Ensure ecosystems is not nil
-			if ecosystems == nil {
-				ecosystems = []string{}
-			}
+		projectName := projectShortName(fullName)
+		logo := projectLogo(projectName)
+
+		// Calculate activity level based on contributor count
+		activity := "Low"
+		if contributorsCount >= 200 {
+			activity = "Very High"
+		} else if contributorsCount >= 150 {
+			activity = "High"
+		} else if contributorsCount >= 100 {
+			activity = "Medium"
+		}
 
-			// Extract project name from github_full_name (owner/repo -> repo)
-			projectName := fullName
-			if idx := len(fullName) - 1; idx >= 0 {
-				if slashIdx := len(fullName) - 1; slashIdx >= 0 {
-					for i := len(fullName) - 1; i >= 0; i-- {
-						if fullName[i] == '/' {
-							projectName = fullName[i+1:]
-							break
-						}
-					}
-				}
+		components.Contributors = contributorsCount
+
+		leaderboard = append(leaderboard, fiber.Map{
+			"rank":                  rank,
+			"name":                  projectName,
+			"full_name":             fullName,
+			"logo":                  logo,
+			"score":                 components.score(h.scoring.Project),
+			"score_components":      components,
+			"trend":                 "same", // For now, set to 'same' (can be enhanced with historical data)
+			"trendValue":            0,
+			"contributors":          contributorsCount,
+			"ecosystems":            ecosystems,
+			"activity":              activity,
+			"project_id":            id,
+			"status":                projectStatus,
+			"last_contribution_at":  lastContributionAt,
+			"first_contribution_at": firstContributionAt,
+		})
+		rank++
+	}
+
+	// Always return an array, even if empty
+	if leaderboard == nil {
+		leaderboard = []fiber.Map{}
+	}
+	return leaderboard, nil
+}
+
+// projectShortName returns the repo part of a github_full_name (owner/repo -> repo).
+// Names without a slash are returned unchanged.
+func projectShortName(fullName string) string {
+	if i := strings.LastIndex(fullName, "/"); i >= 0 {
+		return fullName[i+1:]
+	}
+	return fullName
+}
+
+// projectLogoEmoji maps a project name's first letter to its placeholder logo.
+var projectLogoEmoji = map[byte]string{
+	'a': "🅰", 'b': "🅱", 'c': "©", 'd': "♦", 'e': "⚡",
+	'f': "⚡", 'g': "🎮", 'h': "🏠", 'i': "ℹ", 'j': "🎯",
+	'k': "🔑", 'l': "🔗", 'm': "📱", 'n': "🔢", 'o': "⭕",
+	'p': "📦", 'q': "❓", 'r': "🔴", 's': "⭐", 't': "🔧",
+	'u': "⬆", 'v': "✅", 'w': "🌐", 'x': "❌", 'y': "⚛",
+	'z': "⚡",
+}
+
+// projectLogo picks a placeholder emoji from the first letter of a project name,
+// falling back to a package icon for empty names or other characters. The repo
+// avatar from GitHub would be a better logo once we store it.
+func projectLogo(name string) string {
+	if name == "" {
+		return "📦"
+	}
+	c := name[0]
+	if c >= 'A' && c <= 'Z' {
+		c += 'a' - 'A'
+	}
+	if emoji, ok := projectLogoEmoji[c]; ok {
+		return emoji
+	}
+	return "📦"
+}
+
+// ContributorBreakdown returns how a contributor's verified contributions split across
+// ecosystems, sorted by contributions. The login is matched case-insensitively and a
+// contributor without verified contributions gets an empty array. ?pr_state=merged
+// counts only merged PRs, as on the leaderboard.
+func (h *LeaderboardHandler) ContributorBreakdown() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		username := strings.TrimSpace(c.Params("username"))
+		if username == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_username"})
+		}
+		f := leaderboardFilterFromQuery(c)
+
+		key := fmt.Sprintf("breakdown|%s|%s", strings.ToLower(username), f.cacheKey())
+		breakdown, err := h.cache.get(key, func() (any, error) {
+			return h.fetchContributorBreakdown(c.Context(), username, f)
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "breakdown_fetch_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(breakdown)
+	}
+}
+
+// ContributorProjects returns the verified projects a contributor contributed to, with
+// their issue, PR and total counts, largest total first, as
+// {"projects": [...], "total", "limit", "offset"} where total counts all their projects.
+// The login is matched case-insensitively. ?limit= (default 10, max 100) and ?offset=
+// page through the projects; ?pr_state=merged counts only merged PRs.
+func (h *LeaderboardHandler) ContributorProjects() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		username := strings.TrimSpace(c.Params("username"))
+		if username == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_username"})
+		}
+		page := contributorPageQueryFrom(c)
+
+		key := fmt.Sprintf("contributor_projects|%s|%d|%d|%s", strings.ToLower(username), page.limit, page.offset, page.filter.cacheKey())
+		projects, err := h.cache.get(key, func() (any, error) {
+			return h.fetchContributorProjects(c.Context(), username, page)
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contributor_projects_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(projects)
+	}
+}
+
+func (h *LeaderboardHandler) fetchContributorProjects(ctx context.Context, username string, q contributorPageQuery) (fiber.Map, error) {
+	var total int64
+	if err := h.db.QueryRowTimed(ctx, "contributor_projects_count", contributorProjectsCountQuery(q.filter), username).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	rows, err := h.db.QueryTimed(ctx, "contributor_projects", contributorProjectsQuery(q.filter), username, q.limit, q.offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []fiber.Map{}
+	for rows.Next() {
+		var id, fullName string
+		var ecosystemSlug, ecosystemName *string
+		var issues, prs, contributions int64
+		if err := rows.Scan(&id, &fullName, &ecosystemSlug, &ecosystemName, &issues, &prs, &contributions); err != nil {
+			return nil, err
+		}
+		var ecosystem any
+		if ecosystemSlug != nil {
+			ecosystem = fiber.Map{
+				"slug": *ecosystemSlug,
+				"name": *ecosystemName,
 			}
+		}
+		out = append(out, fiber.Map{
+			"project_id": id,
+			"full_name":  fullName,
+			"ecosystem":  ecosystem,
+			"issues":     issues,
+			"prs":        prs,
+			"total":      contributions,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-			// Generate a simple logo/icon based on project name (first letter or emoji)
-			// In a real implementation, you might want to fetch the actual repo avatar from GitHub
-			logo := "📦" // Default icon
-			if len(projectName) > 0 {
-				firstChar := projectName[0]
-				// Use emoji based on first letter (simple mapping)
-				emojiMap := map[byte]string{
-					'a': "🅰", 'b': "🅱", 'c': "©", 'd': "♦", 'e': "⚡",
-					'f': "⚡", 'g': "🎮", 'h': "🏠", 'i': "ℹ", 'j': "🎯",
-					'k': "🔑", 'l': "🔗", 'm': "📱", 'n': "🔢", 'o': "⭕",
-					'p': "📦", 'q': "❓", 'r': "🔴", 's': "⭐", 't': "🔧",
-					'u': "⬆", 'v': "✅", 'w': "🌐", 'x': "❌", 'y': "⚛",
-					'z': "⚡",
-				}
-				lowerChar := firstChar
-				if lowerChar >= 'A' && lowerChar <= 'Z' {
-					lowerChar = lowerChar + ('a' - 'A')
-				}
-				if emoji, ok := emojiMap[lowerChar]; ok {
-					logo = emoji
-				}
+	return fiber.Map{
+		"projects": out,
+		"total":    total,
+		"limit":    q.limit,
+		"offset":   q.offset,
+	}, nil
+}
+
+// MyEcosystems returns the ecosystems the caller contributed to, with a contribution
+// count for each, counted as GET /contributors/:username/breakdown does for the login of
+// their linked GitHub account. Callers without a linked account get an empty array.
+// Expects auth.RequireAuth; ?pr_state=merged counts only merged PRs.
+func (h *LeaderboardHandler) MyEcosystems() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		userIDStr, _ := c.Locals(auth.LocalUserID).(string)
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_user"})
+		}
+
+		var login string
+		err = h.db.QueryRowTimed(c.Context(), "my_ecosystems_login", `SELECT login FROM github_accounts WHERE user_id = $1`, userID).Scan(&login)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && strings.TrimSpace(login) == "") {
+			return c.Status(fiber.StatusOK).JSON([]fiber.Map{})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "my_ecosystems_fetch_failed"})
+		}
+
+		// Shares cache entries with the public breakdown of the same login
+		f := leaderboardFilterFromQuery(c)
+		key := fmt.Sprintf("breakdown|%s|%s", strings.ToLower(login), f.cacheKey())
+		breakdown, err := h.cache.get(key, func() (any, error) {
+			return h.fetchContributorBreakdown(c.Context(), login, f)
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "my_ecosystems_fetch_failed"})
+		}
+
+		rows := breakdown.([]fiber.Map)
+		out := make([]fiber.Map, 0, len(rows))
+		for _, row := range rows {
+			ecosystem, _ := row["ecosystem"].(fiber.Map)
+			out = append(out, fiber.Map{
+				"ecosystem":     ecosystem["name"],
+				"slug":          ecosystem["slug"],
+				"contributions": row["contributions"],
+			})
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
+
+func (h *LeaderboardHandler) fetchContributorBreakdown(ctx context.Context, username string, f leaderboardFilter) ([]fiber.Map, error) {
+	rows, err := h.db.QueryTimed(ctx, "contributor_breakdown", contributorBreakdownQuery(f), username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []fiber.Map{}
+	for rows.Next() {
+		var slug, name string
+		var contributions, issues, prs int64
+		if err := rows.Scan(&slug, &name, &contributions, &issues, &prs); err != nil {
+			return nil, err
+		}
+		out = append(out, fiber.Map{
+			"ecosystem": fiber.Map{
+				"slug": slug,
+				"name": name,
+			},
+			"contributions": contributions,
+			"issues":        issues,
+			"prs":           prs,
+		})
+	}
+	return out, rows.Err()
+}
+
+// maxRankLookupUsernames caps the usernames of a single Ranks request.
+const maxRankLookupUsernames = 100
+
+type rankLookupRequest struct {
+	Usernames []string `json:"usernames"`
+}
+
+// Ranks looks up the leaderboard standing of several contributors at once, e.g. for
+// profile badges, from a body of {"usernames": [...]} (at most 100). The response maps
+// each requested username to its global rank, absolute rank tier and contributions;
+// usernames that aren't ranked get a null rank. Logins are matched case-insensitively
+// and the leaderboard filters (?ecosystem=, ?pr_state=, ?min_contributions=) apply.
+func (h *LeaderboardHandler) Ranks() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		var req rankLookupRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_json"})
+		}
+		var usernames []string
+		seen := map[string]bool{}
+		for _, u := range req.Usernames {
+			u = strings.TrimSpace(u)
+			if u == "" || seen[strings.ToLower(u)] {
+				continue
 			}
+			seen[strings.ToLower(u)] = true
+			usernames = append(usernames, u)
+		}
+		if len(usernames) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "usernames_required"})
+		}
+		if len(usernames) > maxRankLookupUsernames {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "too_many_usernames", "max": maxRankLookupUsernames})
+		}
+
+		f := leaderboardFilterFromQuery(c)
+		f.TieBreak = h.tieBreak
+		logins := make([]string, len(usernames))
+		for i, u := range usernames {
+			logins[i] = strings.ToLower(u)
+		}
+		sort.Strings(logins)
+		key := fmt.Sprintf("ranks|%s|%s", strings.Join(logins, ","), f.cacheKey())
+		cached, err := h.cache.get(key, func() (any, error) {
+			return h.fetchContributorRanks(c.Context(), usernames, f)
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_ranks_failed"})
+		}
+		ranks := cached.(map[string]fiber.Map)
 
-			// Calculate activity level based on contributor count
-			activity := "Low"
-			if contributorsCount >= 200 {
-				activity = "Very High"
-			} else if contributorsCount >= 150 {
-				activity = "High"
-			} else if contributorsCount >= 100 {
-				activity = "Medium"
+		out := make(fiber.Map, len(usernames))
+		for _, u := range usernames {
+			rank, ok := ranks[strings.ToLower(u)]
+			if !ok {
+				rank = unrankedContributor()
 			}
+			out[u] = fiber.Map{
+				"rank":          rank["rank"],
+				"rank_tier":     rank["rank_tier"],
+				"contributions": rank["contributions"],
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
 
-			// Score is based on contributor count (can be enhanced with other metrics)
-			score := contributorsCount * 10 // Multiply by 10 to get a more meaningful score
-
-			leaderboard = append(leaderboard, fiber.Map{
-				"rank":        rank,
-				"name":        projectName,
-				"full_name":   fullName,
-				"logo":        logo,
-				"score":       score,
-				"trend":       "same", // For now, set to 'same' (can be enhanced with historical data)
-				"trendValue":  0,
-				"contributors": contributorsCount,
-				"ecosystems":   ecosystems,
-				"activity":    activity,
-				"project_id":  id,
-			})
-			rank++
+// fetchContributorRanks returns the global rank, absolute tier, contributions and
+// ecosystems of each ranked login under f, keyed by lowercase login, in one query.
+// Logins that aren't ranked are left out.
+func (h *LeaderboardHandler) fetchContributorRanks(ctx context.Context, logins []string, f leaderboardFilter) (map[string]fiber.Map, error) {
+	query, args := contributorRanksQuery(f, logins)
+	rows, err := h.db.QueryTimed(ctx, "leaderboard_ranks", query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]fiber.Map, len(logins))
+	for rows.Next() {
+		var (
+			rank                int
+			username            string
+			avatarURL           *string
+			userID              string
+			contributionCount   int
+			ecosystems          []string
+			components          contributorScoreComponents
+			prsOpened           int
+			firstContributionAt *time.Time
+		)
+		if err := rows.Scan(&username, &avatarURL, &userID, &contributionCount, &ecosystems,
+			&components.Issues, &components.MergedPullRequests, &prsOpened, &components.RecentContributions, &firstContributionAt,
+			&rank); err != nil {
+			return nil, err
+		}
+		if ecosystems == nil {
+			ecosystems = []string{}
+		}
+		tier := GetRankTier(rank)
+		out[strings.ToLower(username)] = fiber.Map{
+			"rank":           rank,
+			"rank_tier":      string(tier),
+			"rank_tier_name": GetRankTierDisplayName(tier),
+			"contributions":  contributionCount,
+			"ecosystems":     ecosystems,
+		}
+	}
+	return out, rows.Err()
+}
+
+// unrankedContributor is the standing reported for a login without counted contributions.
+func unrankedContributor() fiber.Map {
+	return fiber.Map{
+		"rank":           nil,
+		"rank_tier":      string(RankTierUnranked),
+		"rank_tier_name": GetRankTierDisplayName(RankTierUnranked),
+		"contributions":  0,
+		"ecosystems":     []string{},
+	}
+}
+
+// ContributorStats returns a contributor's tenure and recency: the first and last
+// verified contribution, the total count and the number of active ecosystems they
+// contributed to. Contributors with only issues or only PRs are covered; one without
+// verified contributions gets 404. ?pr_state=merged counts only merged PRs.
+func (h *LeaderboardHandler) ContributorStats() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
 		}
 
-		// Always return an array, even if empty
-		if leaderboard == nil {
-			leaderboard = []fiber.Map{}
+		username := strings.TrimSpace(c.Params("username"))
+		if username == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_username"})
 		}
+		f := leaderboardFilterFromQuery(c)
 
-		return c.Status(fiber.StatusOK).JSON(leaderboard)
+		key := fmt.Sprintf("stats|%s|%s", strings.ToLower(username), f.cacheKey())
+		stats, err := h.cache.get(key, func() (any, error) {
+			return h.fetchContributorStats(c.Context(), username, f)
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "contributor_stats_failed"})
+		}
+		if stats.(fiber.Map)["total_contributions"] == int64(0) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "contributor_not_found"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(stats)
 	}
-}
\ No newline at end of file
+}
+
+func (h *LeaderboardHandler) fetchContributorStats(ctx context.Context, username string, f leaderboardFilter) (fiber.Map, error) {
+	var total, ecosystems int64
+	var first, last *time.Time
+	if err := h.db.QueryRowTimed(ctx, "contributor_stats", contributorStatsQuery(f), username).Scan(&total, &first, &last, &ecosystems); err != nil {
+		return nil, err
+	}
+	return fiber.Map{
+		"first_contribution_at": first,
+		"last_contribution_at":  last,
+		"total_contributions":   total,
+		"active_ecosystems":     ecosystems,
+	}, nil
+}
+
+// TierDistribution returns how many contributors fall into each rank tier, over the
+// whole ranked population for the same filters as the leaderboard (?ecosystem=,
+// ?pr_state=, ?min_contributions=, ?tier_mode=). Every tier is listed, best first,
+// including empty ones.
+func (h *LeaderboardHandler) TierDistribution() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		f := leaderboardFilterFromQuery(c)
+		f.TieBreak = h.tieBreak
+		mode := ParseRankTierMode(c.Query("tier_mode", string(RankTierModeAbsolute)))
+
+		key := fmt.Sprintf("tiers|%s|%s", mode, f.cacheKey())
+		distribution, err := h.cache.get(key, func() (any, error) {
+			return h.fetchTierDistribution(c.Context(), f, mode)
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "tier_distribution_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(distribution)
+	}
+}
+
+func (h *LeaderboardHandler) fetchTierDistribution(ctx context.Context, f leaderboardFilter, mode RankTierMode) ([]fiber.Map, error) {
+	query, args := contributorTierDistributionQuery(f, mode)
+	rows, err := h.db.QueryTimed(ctx, "leaderboard_tiers", query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[RankTier]int64{}
+	for rows.Next() {
+		var tier string
+		var count int64
+		if err := rows.Scan(&tier, &count); err != nil {
+			return nil, err
+		}
+		counts[RankTier(tier)] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	bands := RankTierBands()
+	out := make([]fiber.Map, 0, len(bands))
+	for _, b := range bands {
+		out = append(out, fiber.Map{
+			"tier":      string(b.Tier),
+			"tier_name": GetRankTierDisplayName(b.Tier),
+			"count":     counts[b.Tier],
+		})
+	}
+	return out, nil
+}
+
+// TierDefinitions lists the rank tiers best first, as the tier tables currently define
+// them: {tier, display_name, color, min_rank, max_rank} by position (max_rank is null
+// for the open-ended last tier), or with ?tier_mode=percentile {tier, display_name,
+// color, min_percentile, max_percentile}, where a tier covers percentiles above
+// min_percentile up to and including max_percentile.
+func (h *LeaderboardHandler) TierDefinitions() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var out []fiber.Map
+		if ParseRankTierMode(c.Query("tier_mode", string(RankTierModeAbsolute))) == RankTierModePercentile {
+			prev := 0.0
+			for _, b := range RankTierPercentileBands() {
+				out = append(out, fiber.Map{
+					"tier":           string(b.Tier),
+					"display_name":   GetRankTierDisplayName(b.Tier),
+					"color":          GetRankTierColor(b.Tier),
+					"min_percentile": prev,
+					"max_percentile": b.MaxPercentile,
+				})
+				prev = b.MaxPercentile
+			}
+		} else {
+			for _, b := range RankTierBands() {
+				var maxRank any
+				if b.MaxPosition != 0 {
+					maxRank = b.MaxPosition
+				}
+				out = append(out, fiber.Map{
+					"tier":         string(b.Tier),
+					"display_name": GetRankTierDisplayName(b.Tier),
+					"color":        GetRankTierColor(b.Tier),
+					"min_rank":     b.MinPosition,
+					"max_rank":     maxRank,
+				})
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(out)
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Fallback avatar sizes in pixels (?avatar_size=). GitHub serves at most 460.
+const (
+	defaultAvatarSize = 200
+	minAvatarSize     = 16
+	maxAvatarSize     = 460
+)
+
+// clampAvatarSize bounds a requested avatar size; zero or less means the default.
+func clampAvatarSize(size int) int {
+	switch {
+	case size <= 0:
+		return defaultAvatarSize
+	case size < minAvatarSize:
+		return minAvatarSize
+	case size > maxAvatarSize:
+		return maxAvatarSize
+	default:
+		return size
+	}
+}
+
+// fallbackAvatarURL is the avatar for a contributor without a stored avatar_url:
+// GitHub's /<login>.png, or the same path under proxyBase when an image CDN fronts
+// GitHub, so clients don't hotlink github.com.
+func fallbackAvatarURL(login string, size int, proxyBase string) string {
+	base := "https://github.com"
+	if proxyBase != "" {
+		base = strings.TrimRight(proxyBase, "/")
+	}
+	return fmt.Sprintf("%s/%s.png?size=%d", base, url.PathEscape(login), clampAvatarSize(size))
+}
+
+// SetAvatarProxy routes fallback avatars through baseURL instead of github.com (see
+// fallbackAvatarURL); empty means GitHub directly. Cached pages are dropped.
+func (h *LeaderboardHandler) SetAvatarProxy(baseURL string) {
+	h.avatarProxy = strings.TrimSpace(baseURL)
+	h.cache.invalidate()
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import "testing"
+
+func TestClampAvatarSize(t *testing.T) {
+	cases := map[int]int{
+		-5:   defaultAvatarSize,
+		0:    defaultAvatarSize,
+		1:    minAvatarSize,
+		16:   16,
+		64:   64,
+		460:  460,
+		4096: maxAvatarSize,
+	}
+	for size, want := range cases {
+		if got := clampAvatarSize(size); got != want {
+			t.Errorf("clampAvatarSize(%d) = %d, want %d", size, got, want)
+		}
+	}
+}
+
+func TestFallbackAvatarURL(t *testing.T) {
+	cases := []struct {
+		login, proxy string
+		size         int
+		want         string
+	}{
+		{"octocat", "", 0, "https://github.com/octocat.png?size=200"},
+		{"octocat", "", 64, "https://github.com/octocat.png?size=64"},
+		{"octocat", "", 9000, "https://github.com/octocat.png?size=460"},
+		{"octocat", "https://img.example.com/gh", 64, "https://img.example.com/gh/octocat.png?size=64"},
+		{"octocat", "https://img.example.com/gh/", 64, "https://img.example.com/gh/octocat.png?size=64"},
+		// Logins are escaped so they can't break out of the path
+		{"a/../b?x", "https://img.example.com", 64, "https://img.example.com/a%2F..%2Fb%3Fx.png?size=64"},
+	}
+	for _, tc := range cases {
+		if got := fallbackAvatarURL(tc.login, tc.size, tc.proxy); got != tc.want {
+			t.Errorf("fallbackAvatarURL(%q, %d, %q) = %q, want %q", tc.login, tc.size, tc.proxy, got, tc.want)
+		}
+	}
+}
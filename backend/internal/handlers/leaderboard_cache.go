@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultLeaderboardCacheTTL is used when no TTL is configured.
+const defaultLeaderboardCacheTTL = 60 * time.Second
+
+// leaderboardCacheMaxEntries bounds the cache: every filter, page and search makes its
+// own key, so without a bound a crawler could grow it without limit.
+const leaderboardCacheMaxEntries = 2000
+
+// leaderboardCache is a small in-memory TTL cache for leaderboard responses.
+// Concurrent misses for the same key are coalesced so only one of them runs the
+// underlying query. A TTL <= 0 disables caching entirely. It holds at most maxEntries
+// responses: expired ones are swept when it fills up, then the oldest are evicted.
+type leaderboardCache struct {
+	ttl        time.Duration
+	now        func() time.Time
+	maxEntries int
+
+	mu       sync.Mutex
+	entries  map[string]leaderboardCacheEntry
+	inflight map[string]*leaderboardCacheCall
+	gen      uint64 // bumped on invalidate so in-flight loads don't repopulate stale data
+}
+
+type leaderboardCacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+type leaderboardCacheCall struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+func newLeaderboardCache(ttl time.Duration) *leaderboardCache {
+	return &leaderboardCache{
+		ttl:        ttl,
+		now:        time.Now,
+		maxEntries: leaderboardCacheMaxEntries,
+		entries:    map[string]leaderboardCacheEntry{},
+		inflight:   map[string]*leaderboardCacheCall{},
+	}
+}
+
+// get returns the cached value for key, calling load on a miss. Errors are never cached.
+func (lc *leaderboardCache) get(key string, load func() (any, error)) (any, error) {
+	if lc == nil || lc.ttl <= 0 {
+		return load()
+	}
+
+	lc.mu.Lock()
+	if e, ok := lc.entries[key]; ok {
+		if lc.now().Before(e.expiresAt) {
+			lc.mu.Unlock()
+			return e.value, nil
+		}
+		delete(lc.entries, key)
+	}
+	if call, ok := lc.inflight[key]; ok {
+		lc.mu.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+	call := &leaderboardCacheCall{done: make(chan struct{})}
+	lc.inflight[key] = call
+	gen := lc.gen
+	lc.mu.Unlock()
+
+	call.value, call.err = load()
+
+	lc.mu.Lock()
+	delete(lc.inflight, key)
+	if call.err == nil && gen == lc.gen {
+		lc.makeRoom()
+		lc.entries[key] = leaderboardCacheEntry{value: call.value, expiresAt: lc.now().Add(lc.ttl)}
+	}
+	lc.mu.Unlock()
+	close(call.done)
+
+	return call.value, call.err
+}
+
+// makeRoom frees a slot for a new entry when the cache is full, by sweeping the expired
+// entries or, if none has expired, evicting the one closest to expiry. The entries all
+// share one TTL, so that is the oldest. lc.mu must be held.
+func (lc *leaderboardCache) makeRoom() {
+	if len(lc.entries) < lc.maxEntries {
+		return
+	}
+	now := lc.now()
+	for key, e := range lc.entries {
+		if !now.Before(e.expiresAt) {
+			delete(lc.entries, key)
+		}
+	}
+	if len(lc.entries) < lc.maxEntries {
+		return
+	}
+	var oldestKey string
+	var oldest time.Time
+	for key, e := range lc.entries {
+		if oldestKey == "" || e.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, e.expiresAt
+		}
+	}
+	delete(lc.entries, oldestKey)
+}
+
+// invalidate drops every cached response.
+func (lc *leaderboardCache) invalidate() {
+	if lc == nil {
+		return
+	}
+	lc.mu.Lock()
+	lc.entries = map[string]leaderboardCacheEntry{}
+	lc.gen++
+	lc.mu.Unlock()
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLeaderboardCache_HitMissExpiry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	lc := newLeaderboardCache(time.Minute)
+	lc.now = func() time.Time { return now }
+
+	var loads int
+	load := func() (any, error) {
+		loads++
+		return loads, nil
+	}
+
+	if v, _ := lc.get("k", load); v != 1 {
+		t.Fatalf("first get = %v, want 1 (miss)", v)
+	}
+	if v, _ := lc.get("k", load); v != 1 {
+		t.Fatalf("second get = %v, want cached 1", v)
+	}
+	if v, _ := lc.get("other", load); v != 2 {
+		t.Fatalf("different key = %v, want 2 (miss)", v)
+	}
+
+	now = now.Add(time.Minute)
+	if v, _ := lc.get("k", load); v != 3 {
+		t.Fatalf("after expiry = %v, want 3 (reload)", v)
+	}
+}
+
+func TestLeaderboardCache_ErrorsNotCached(t *testing.T) {
+	lc := newLeaderboardCache(time.Minute)
+	boom := errors.New("boom")
+
+	if _, err := lc.get("k", func() (any, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Fatalf("expected load error, got %v", err)
+	}
+	if v, err := lc.get("k", func() (any, error) { return "ok", nil }); err != nil || v != "ok" {
+		t.Fatalf("expected reload after error, got %v, %v", v, err)
+	}
+}
+
+func TestLeaderboardCache_Invalidate(t *testing.T) {
+	lc := newLeaderboardCache(time.Minute)
+	var loads int
+	load := func() (any, error) {
+		loads++
+		return loads, nil
+	}
+
+	lc.get("k", load)
+	lc.invalidate()
+	if v, _ := lc.get("k", load); v != 2 {
+		t.Fatalf("after invalidate = %v, want 2 (reload)", v)
+	}
+}
+
+func TestLeaderboardCache_Disabled(t *testing.T) {
+	lc := newLeaderboardCache(-1)
+	var loads int
+	load := func() (any, error) {
+		loads++
+		return loads, nil
+	}
+
+	lc.get("k", load)
+	lc.get("k", load)
+	if loads != 2 {
+		t.Fatalf("disabled cache should always load, got %d loads", loads)
+	}
+}
+
+func TestLeaderboardCache_MaxEntries(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	lc := newLeaderboardCache(time.Minute)
+	lc.now = func() time.Time { return now }
+	lc.maxEntries = 3
+
+	load := func(v string) func() (any, error) {
+		return func() (any, error) { return v, nil }
+	}
+
+	// Full of live entries: the oldest is evicted
+	for _, k := range []string{"a", "b", "c", "d"} {
+		lc.get(k, load(k))
+		now = now.Add(time.Second)
+	}
+	if len(lc.entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(lc.entries))
+	}
+	if v, _ := lc.get("a", load("a2")); v != "a2" {
+		t.Fatalf("expected the oldest entry to be evicted, got %v", v)
+	}
+	if v, _ := lc.get("d", load("d2")); v != "d" {
+		t.Fatalf("expected the newest entry to stay cached, got %v", v)
+	}
+
+	// Once they expire, every stale entry is swept to make room
+	now = now.Add(time.Minute)
+	lc.get("e", load("e"))
+	if len(lc.entries) != 1 {
+		t.Fatalf("expected the expired entries to be swept, got %d entries", len(lc.entries))
+	}
+}
+
+func TestLeaderboardCache_CoalescesConcurrentMisses(t *testing.T) {
+	lc := newLeaderboardCache(time.Minute)
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func() (any, error) {
+		loads.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	const callers = 20
+	var started, wg sync.WaitGroup
+	started.Add(callers)
+	wg.Add(callers)
+	results := make([]any, callers)
+	for i := 0; i < callers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			started.Done()
+			results[i], _ = lc.get("k", load)
+		}(i)
+	}
+
+	started.Wait()
+	// Give the goroutines a moment to queue up behind the first load.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("expected 1 load for concurrent misses, got %d", n)
+	}
+	for i, v := range results {
+		if v != "value" {
+			t.Errorf("caller %d got %v", i, v)
+		}
+	}
+}
+
+func TestLeaderboardCache_InvalidateDuringLoad(t *testing.T) {
+	lc := newLeaderboardCache(time.Minute)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		lc.get("k", func() (any, error) {
+			<-release
+			return "stale", nil
+		})
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	lc.invalidate()
+	close(release)
+	<-done
+
+	if v, _ := lc.get("k", func() (any, error) { return "fresh", nil }); v != "fresh" {
+		t.Fatalf("load started before invalidate should not be cached, got %v", v)
+	}
+}
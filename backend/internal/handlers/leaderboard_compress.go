@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultLeaderboardCompressMinBytes is the smallest response body Compress encodes;
+// below it the encoding overhead outweighs the savings.
+const defaultLeaderboardCompressMinBytes = 1024
+
+// SetCompressionThreshold sets the smallest response body, in bytes, that Compress
+// encodes. Zero uses the default; negative disables compression.
+func (h *LeaderboardHandler) SetCompressionThreshold(minBytes int) {
+	if minBytes == 0 {
+		minBytes = defaultLeaderboardCompressMinBytes
+	}
+	h.compressMinBytes = minBytes
+}
+
+// Compress is middleware that gzip- or deflate-encodes the response of the handlers
+// after it, as negotiated by Accept-Encoding, once the body reaches the compression
+// threshold. Streamed bodies (exports, event streams) are passed through unchanged.
+func (h *LeaderboardHandler) Compress() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if h.compressMinBytes < 0 {
+			return nil
+		}
+		// Any response here could have been encoded, so caches must key on the header
+		c.Vary(fiber.HeaderAcceptEncoding)
+
+		resp := c.Response()
+		if resp.IsBodyStream() || len(resp.Header.Peek(fiber.HeaderContentEncoding)) > 0 {
+			return nil
+		}
+		body := resp.Body()
+		if len(body) < h.compressMinBytes || c.Get(fiber.HeaderAcceptEncoding) == "" {
+			return nil
+		}
+		encoding := c.AcceptsEncodings("gzip", "deflate")
+		if encoding == "" {
+			return nil
+		}
+
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		if encoding == "gzip" {
+			w = gzip.NewWriter(&buf)
+		} else {
+			w = zlib.NewWriter(&buf) // HTTP "deflate" is zlib-wrapped
+		}
+		if _, err := w.Write(body); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		resp.SetBodyRaw(buf.Bytes())
+		c.Set(fiber.HeaderContentEncoding, encoding)
+		return nil
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newCompressTestApp(minBytes int) (*fiber.App, []fiber.Map) {
+	h := NewLeaderboardHandler(nil, -1)
+	h.SetCompressionThreshold(minBytes)
+
+	page := make([]fiber.Map, 100)
+	for i := range page {
+		page[i] = fiber.Map{
+			"rank":       float64(i + 1),
+			"username":   fmt.Sprintf("contributor-%d", i),
+			"ecosystems": []any{"stellar", "ethereum"},
+		}
+	}
+	app := fiber.New()
+	app.Get("/leaderboard", h.Compress(), func(c *fiber.Ctx) error {
+		return c.JSON(page)
+	})
+	app.Get("/small", h.Compress(), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"ok": true})
+	})
+	return app, page
+}
+
+func TestLeaderboardCompress_Gzip(t *testing.T) {
+	app, page := newCompressTestApp(0)
+
+	req := httptest.NewRequest("GET", "/leaderboard", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate;q=0.5")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("GET /leaderboard failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected Content-Encoding gzip, got %q", got)
+	}
+	if got := resp.Header.Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("expected Vary: Accept-Encoding, got %q", got)
+	}
+
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("response is not gzip: %v", err)
+	}
+	var got []fiber.Map
+	if err := json.NewDecoder(zr).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != len(page) {
+		t.Fatalf("expected %d rows, got %d", len(page), len(got))
+	}
+	for i := range page {
+		if !reflect.DeepEqual(map[string]any(got[i]), map[string]any(page[i])) {
+			t.Fatalf("row %d: got %v, want %v", i, got[i], page[i])
+		}
+	}
+}
+
+func TestLeaderboardCompress_Negotiation(t *testing.T) {
+	app, _ := newCompressTestApp(0)
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{"deflate only", "/leaderboard", "deflate", "deflate"},
+		{"deflate preferred", "/leaderboard", "gzip;q=0.2, deflate", "deflate"},
+		{"no Accept-Encoding", "/leaderboard", "", ""},
+		{"unsupported encoding", "/leaderboard", "br", ""},
+		{"gzip refused", "/leaderboard", "gzip;q=0", ""},
+		{"below the threshold", "/small", "gzip", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("GET %s failed: %v", tt.path, err)
+			}
+			defer resp.Body.Close()
+			if got := resp.Header.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("expected Content-Encoding %q, got %q", tt.wantEncoding, got)
+			}
+			if got := resp.Header.Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("expected Vary: Accept-Encoding, got %q", got)
+			}
+
+			var body io.Reader = resp.Body
+			if tt.wantEncoding == "deflate" {
+				zr, err := zlib.NewReader(resp.Body)
+				if err != nil {
+					t.Fatalf("response is not deflate: %v", err)
+				}
+				body = zr
+			}
+			var out any
+			if err := json.NewDecoder(body).Decode(&out); err != nil {
+				t.Errorf("decode: %v", err)
+			}
+		})
+	}
+}
+
+func TestLeaderboardCompress_Disabled(t *testing.T) {
+	app, _ := newCompressTestApp(-1)
+
+	req := httptest.NewRequest("GET", "/leaderboard", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("GET /leaderboard failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("expected no compression, got Content-Encoding %q", got)
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+// leaderboardExportTimeout bounds how long a single export may hold a DB connection.
+const leaderboardExportTimeout = 2 * time.Minute
+
+// Export streams the full contributor leaderboard as CSV or JSON (?format=csv|json).
+// Rows are written as they are read from the query so large ecosystems are never
+// buffered in memory. ?snapshot_id= exports a ranking frozen by CreateSnapshot.
+func (h *LeaderboardHandler) Export() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		format := strings.ToLower(strings.TrimSpace(c.Query("format", "csv")))
+		if format != "csv" && format != "json" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_format"})
+		}
+		f := leaderboardFilterFromQuery(c)
+		f.TieBreak = h.tieBreak
+		query, args, _ := contributorLeaderboardQuery(f)
+
+		snapshotID, fromSnapshot, ok := parseSnapshotID(c)
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_snapshot_id"})
+		}
+		if fromSnapshot {
+			_, err := h.snapshotContributorCount(c.Context(), snapshotID)
+			if errors.Is(err, errSnapshotNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "snapshot_not_found"})
+			}
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_export_failed"})
+			}
+			query, args = snapshotExportQuery(snapshotID)
+		}
+
+		// The stream writer runs after this handler returns, so the query gets its own context.
+		ctx, cancel := context.WithTimeout(context.Background(), leaderboardExportTimeout)
+		rows, err := h.db.QueryTimed(ctx, "leaderboard_export", query, args...)
+		if err != nil {
+			cancel()
+			slog.Error("failed to export leaderboard",
+				"error", err,
+			)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_export_failed"})
+		}
+
+		filename := "leaderboard"
+		if f.EcosystemSlug != "" {
+			filename += "-" + normalizeSlug(f.EcosystemSlug)
+		}
+		if format == "csv" {
+			c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		} else {
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		}
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+"."+format+`"`)
+
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer cancel()
+			defer rows.Close()
+
+			var n int
+			var err error
+			if format == "csv" {
+				n, err = writeLeaderboardCSV(w, rows, h.scoring.Contributor)
+			} else {
+				n, err = writeLeaderboardJSON(w, rows, h.scoring.Contributor)
+			}
+			if err != nil {
+				// Headers are already sent; all we can do is log and truncate.
+				slog.Error("leaderboard export interrupted",
+					"error", err,
+					"rows_written", n,
+				)
+			}
+		})
+		return nil
+	}
+}
+
+// leaderboardExportRow is one contributor in an export.
+type leaderboardExportRow struct {
+	Rank          int      `json:"rank"`
+	Username      string   `json:"username"`
+	Contributions int      `json:"contributions"`
+	Score         int      `json:"score"`
+	Ecosystems    []string `json:"ecosystems"`
+}
+
+// scanLeaderboardExportRows calls fn for each row of a contributorLeaderboardSQL result,
+// scoring it with weights.
+func scanLeaderboardExportRows(rows pgx.Rows, weights ContributorScoreWeights, fn func(leaderboardExportRow) error) (int, error) {
+	rank := 1
+	for rows.Next() {
+		var username string
+		var avatarURL *string
+		var userID string
+		var contributionCount int
+		var ecosystems []string
+		var components contributorScoreComponents
+		var prsOpened int
+		var firstContributionAt *time.Time
+		if err := rows.Scan(&username, &avatarURL, &userID, &contributionCount, &ecosystems,
+			&components.Issues, &components.MergedPullRequests, &prsOpened, &components.RecentContributions, &firstContributionAt); err != nil {
+			return rank - 1, err
+		}
+		if ecosystems == nil {
+			ecosystems = []string{}
+		}
+		components.PullRequests = contributionCount - components.Issues
+		components.Ecosystems = len(ecosystems)
+		if err := fn(leaderboardExportRow{
+			Rank:          rank,
+			Username:      username,
+			Contributions: contributionCount,
+			Score:         components.score(weights),
+			Ecosystems:    ecosystems,
+		}); err != nil {
+			return rank - 1, err
+		}
+		rank++
+	}
+	return rank - 1, rows.Err()
+}
+
+func writeLeaderboardCSV(w *bufio.Writer, rows pgx.Rows, weights ContributorScoreWeights) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"rank", "username", "contributions", "score", "ecosystems"}); err != nil {
+		return 0, err
+	}
+	n, err := scanLeaderboardExportRows(rows, weights, func(r leaderboardExportRow) error {
+		if err := cw.Write([]string{
+			strconv.Itoa(r.Rank),
+			r.Username,
+			strconv.Itoa(r.Contributions),
+			strconv.Itoa(r.Score),
+			strings.Join(r.Ecosystems, ";"),
+		}); err != nil {
+			return err
+		}
+		// Flush periodically so the client starts receiving data early.
+		if r.Rank%500 == 0 {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+		return nil
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	return n, err
+}
+
+func writeLeaderboardJSON(w *bufio.Writer, rows pgx.Rows, weights ContributorScoreWeights) (int, error) {
+	if _, err := w.WriteString("["); err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(w)
+	n, err := scanLeaderboardExportRows(rows, weights, func(r leaderboardExportRow) error {
+		if r.Rank > 1 {
+			if _, err := w.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+		if r.Rank%500 == 0 {
+			return w.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Leave the array unterminated so clients can tell the export was cut short.
+		_ = w.Flush()
+		return n, err
+	}
+	if _, err := w.WriteString("]\n"); err != nil {
+		return n, err
+	}
+	return n, w.Flush()
+}
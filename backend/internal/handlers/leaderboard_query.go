@@ -0,0 +1,645 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// contributorLeaderboardSQL ranks contributors by contribution count in verified projects.
+// This query:
+//  1. Gets all unique author_logins from issues and PRs in verified projects, compared
+//     case-insensitively like GitHub does
+//  2. LEFT JOINs with github_accounts to get user info (and the login's current casing)
+//     if they signed up
+//  3. Shows ALL contributors, whether they signed up or not
+//  4. Counts their contributions (issues + PRs) in verified projects
+//  5. Keeps only contributors at or above the minimum contribution count
+//
+// %[1]s is the project filter, %[2]s the pull request filter, %[3]s the placeholder
+// for the minimum contribution count (see contributorLeaderboardQuery), %[4]s the
+// recent window for scoring, %[5]s the tie-breaking order (see contributorTieBreakSQL)
+// and %[6]s is empty or signedUpZeroContributorsSQL. Rows added by the latter bypass
+// the minimum and, counting 0, rank after everyone else.
+// The columns after ecosystems feed the score, except opened_pr_count which counts
+// every PR opened whatever %[2]s; first_contribution_at comes last.
+const contributorLeaderboardSQL = `
+
+WITH contributor_logins AS (
+  -- Author logins of issues in verified projects
+  SELECT i.author_login as login, COALESCE(i.created_at_github, i.last_seen_at) as seen_at
+  FROM github_issues i
+  INNER JOIN projects p ON i.project_id = p.id
+  WHERE i.author_login IS NOT NULL 
+    AND i.author_login != ''
+    AND %[1]s
+  
+  UNION ALL
+  
+  -- Author logins of PRs in verified projects
+  SELECT pr.author_login as login, COALESCE(pr.created_at_github, pr.last_seen_at) as seen_at
+  FROM github_pull_requests pr
+  INNER JOIN projects p ON pr.project_id = p.id
+  WHERE pr.author_login IS NOT NULL 
+    AND pr.author_login != ''
+    AND %[1]s AND %[2]s
+),
+active_contributors AS (
+  -- One row per contributor: GitHub logins are case-insensitive, so casing variants
+  -- (JohnDoe, johndoe) are the same person, kept in their most recent casing
+  SELECT DISTINCT ON (LOWER(login)) login
+  FROM contributor_logins
+  ORDER BY LOWER(login), seen_at DESC NULLS LAST, login
+),
+all_contributors AS (
+  SELECT login, FALSE AS signed_up_only FROM active_contributors
+  %[6]s
+)
+SELECT 
+  COALESCE(ga.login, ac.login) as username,
+  COALESCE(ga.avatar_url, '') as avatar_url,
+  COALESCE(u.id::text, '') as user_id,
+  (
+    SELECT COUNT(*) 
+    FROM github_issues i
+    INNER JOIN projects p ON i.project_id = p.id
+    WHERE LOWER(i.author_login) = LOWER(ac.login) AND %[1]s
+  ) +
+  (
+    SELECT COUNT(*) 
+    FROM github_pull_requests pr
+    INNER JOIN projects p ON pr.project_id = p.id
+    WHERE LOWER(pr.author_login) = LOWER(ac.login) AND %[1]s AND %[2]s
+  ) as contribution_count,
+  COALESCE(
+    (
+      SELECT ARRAY_AGG(DISTINCT e.name)
+      FROM (
+        SELECT DISTINCT pe.ecosystem_id
+        FROM github_issues i
+        INNER JOIN projects p ON i.project_id = p.id
+        INNER JOIN project_ecosystems pe ON pe.project_id = p.id
+        WHERE LOWER(i.author_login) = LOWER(ac.login) AND %[1]s
+        UNION
+        SELECT DISTINCT pe.ecosystem_id
+        FROM github_pull_requests pr
+        INNER JOIN projects p ON pr.project_id = p.id
+        INNER JOIN project_ecosystems pe ON pe.project_id = p.id
+        WHERE LOWER(pr.author_login) = LOWER(ac.login) AND %[1]s AND %[2]s
+      ) contrib_ecosystems
+      INNER JOIN ecosystems e ON contrib_ecosystems.ecosystem_id = e.id
+      WHERE e.status = 'active' AND e.deleted_at IS NULL
+    ),
+    ARRAY[]::TEXT[]
+  ) as ecosystems,
+  -- Score components (see ScoreConfig)
+  (
+    SELECT COUNT(*)
+    FROM github_issues i
+    INNER JOIN projects p ON i.project_id = p.id
+    WHERE LOWER(i.author_login) = LOWER(ac.login) AND %[1]s
+  ) as issue_count,
+  (
+    SELECT COUNT(*)
+    FROM github_pull_requests m
+    INNER JOIN projects p ON m.project_id = p.id
+    WHERE LOWER(m.author_login) = LOWER(ac.login) AND %[1]s AND m.merged IS TRUE
+  ) as merged_pr_count,
+  (
+    SELECT COUNT(*)
+    FROM github_pull_requests o
+    INNER JOIN projects p ON o.project_id = p.id
+    WHERE LOWER(o.author_login) = LOWER(ac.login) AND %[1]s
+  ) as opened_pr_count,
+  (
+    SELECT COUNT(*)
+    FROM github_issues i
+    INNER JOIN projects p ON i.project_id = p.id
+    WHERE LOWER(i.author_login) = LOWER(ac.login) AND %[1]s
+      AND COALESCE(i.created_at_github, i.last_seen_at) >= now() - %[4]s
+  ) +
+  (
+    SELECT COUNT(*)
+    FROM github_pull_requests pr
+    INNER JOIN projects p ON pr.project_id = p.id
+    WHERE LOWER(pr.author_login) = LOWER(ac.login) AND %[1]s AND %[2]s
+      AND COALESCE(pr.created_at_github, pr.last_seen_at) >= now() - %[4]s
+  ) as recent_count,
+  LEAST(
+    (
+      SELECT MIN(COALESCE(i.created_at_github, i.last_seen_at))
+      FROM github_issues i
+      INNER JOIN projects p ON i.project_id = p.id
+      WHERE LOWER(i.author_login) = LOWER(ac.login) AND %[1]s
+    ),
+    (
+      SELECT MIN(COALESCE(pr.created_at_github, pr.last_seen_at))
+      FROM github_pull_requests pr
+      INNER JOIN projects p ON pr.project_id = p.id
+      WHERE LOWER(pr.author_login) = LOWER(ac.login) AND %[1]s AND %[2]s
+    )
+  ) as first_contribution_at
+FROM all_contributors ac
+LEFT JOIN LATERAL (
+  -- The signed-up account's login is the current casing on GitHub
+  SELECT ga.login, ga.avatar_url, ga.user_id
+  FROM github_accounts ga
+  WHERE LOWER(ga.login) = LOWER(ac.login)
+  ORDER BY ga.updated_at DESC
+  LIMIT 1
+) ga ON TRUE
+LEFT JOIN users u ON ga.user_id = u.id
+WHERE ac.signed_up_only OR (
+  SELECT COUNT(*) 
+  FROM github_issues i
+  INNER JOIN projects p ON i.project_id = p.id
+  WHERE LOWER(i.author_login) = LOWER(ac.login) AND %[1]s
+) +
+(
+  SELECT COUNT(*) 
+  FROM github_pull_requests pr
+  INNER JOIN projects p ON pr.project_id = p.id
+  WHERE LOWER(pr.author_login) = LOWER(ac.login) AND %[1]s AND %[2]s
+) >= %[3]s
+ORDER BY contribution_count DESC, %[5]s
+`
+
+// signedUpZeroContributorsSQL extends all_contributors in contributorLeaderboardSQL with
+// the signed-up users who have no contribution counted by the current filters.
+const signedUpZeroContributorsSQL = `UNION ALL
+  -- Signed-up users without a counted contribution (?include_zero=true)
+  SELECT ga_z.login, TRUE
+  FROM github_accounts ga_z
+  WHERE ga_z.login != '' AND NOT EXISTS (
+    SELECT 1 FROM active_contributors a_z WHERE LOWER(a_z.login) = LOWER(ga_z.login)
+  )`
+
+// contributorBreakdownSQL groups one contributor's issues and PRs in verified projects
+// by ecosystem, matching the login case-insensitively like the leaderboard does.
+// $1 is the login; %[1]s is the project filter and %[2]s the pull request filter.
+const contributorBreakdownSQL = `
+WITH contributions AS (
+  SELECT i.project_id, 1 AS issues, 0 AS prs
+  FROM github_issues i
+  WHERE LOWER(i.author_login) = LOWER($1)
+
+  UNION ALL
+
+  SELECT pr.project_id, 0 AS issues, 1 AS prs
+  FROM github_pull_requests pr
+  WHERE LOWER(pr.author_login) = LOWER($1) AND %[2]s
+)
+SELECT
+  e.slug,
+  e.name,
+  SUM(c.issues + c.prs) AS contributions,
+  SUM(c.issues) AS issues,
+  SUM(c.prs) AS prs
+FROM contributions c
+INNER JOIN projects p ON p.id = c.project_id
+INNER JOIN project_ecosystems pe ON pe.project_id = p.id
+INNER JOIN ecosystems e ON e.id = pe.ecosystem_id
+WHERE %[1]s AND e.status = 'active' AND e.deleted_at IS NULL
+GROUP BY e.id, e.slug, e.name
+ORDER BY contributions DESC, e.name ASC
+`
+
+// contributorBreakdownQuery builds the per-ecosystem breakdown query for f. The
+// ecosystem filter is ignored: the breakdown always spans every ecosystem.
+func contributorBreakdownQuery(f leaderboardFilter) string {
+	prFilter := "TRUE"
+	if f.MergedPRsOnly {
+		prFilter = "pr.merged IS TRUE"
+	}
+	return fmt.Sprintf(contributorBreakdownSQL, "p.status = 'verified'", prFilter)
+}
+
+// contributorProjectsSQL groups one contributor's issues and PRs in verified projects
+// by project, like contributorBreakdownSQL does by ecosystem. Each project comes with
+// its primary ecosystem, if that is active. $1 is the login; %[1]s is the project
+// filter and %[2]s the pull request filter. contributorProjectsQuery and
+// contributorProjectsCountQuery complete it.
+const contributorProjectsSQL = `
+WITH contributions AS (
+  SELECT i.project_id, 1 AS issues, 0 AS prs
+  FROM github_issues i
+  WHERE LOWER(i.author_login) = LOWER($1)
+
+  UNION ALL
+
+  SELECT pr.project_id, 0 AS issues, 1 AS prs
+  FROM github_pull_requests pr
+  WHERE LOWER(pr.author_login) = LOWER($1) AND %[2]s
+),
+per_project AS (
+  SELECT
+    p.id,
+    p.github_full_name,
+    p.ecosystem_id,
+    SUM(c.issues) AS issues,
+    SUM(c.prs) AS prs,
+    SUM(c.issues + c.prs) AS total
+  FROM contributions c
+  INNER JOIN projects p ON p.id = c.project_id
+  WHERE %[1]s
+  GROUP BY p.id, p.github_full_name, p.ecosystem_id
+)
+`
+
+// contributorProjectsQuery builds the query for one page of a contributor's projects
+// for f, largest total first. $2 and $3 are the limit and offset. Like the breakdown,
+// it ignores the ecosystem filter.
+func contributorProjectsQuery(f leaderboardFilter) string {
+	return contributorProjectsCTE(f) + `SELECT
+  pp.id::text,
+  pp.github_full_name,
+  e.slug,
+  e.name,
+  pp.issues,
+  pp.prs,
+  pp.total
+FROM per_project pp
+LEFT JOIN ecosystems e ON e.id = pp.ecosystem_id AND e.status = 'active' AND e.deleted_at IS NULL
+ORDER BY pp.total DESC, pp.github_full_name ASC
+LIMIT $2 OFFSET $3
+`
+}
+
+// contributorProjectsCountQuery counts the projects contributorProjectsQuery pages through.
+func contributorProjectsCountQuery(f leaderboardFilter) string {
+	return contributorProjectsCTE(f) + "SELECT COUNT(*) FROM per_project\n"
+}
+
+func contributorProjectsCTE(f leaderboardFilter) string {
+	prFilter := "TRUE"
+	if f.MergedPRsOnly {
+		prFilter = "pr.merged IS TRUE"
+	}
+	return fmt.Sprintf(contributorProjectsSQL, "p.status = 'verified'", prFilter)
+}
+
+// contributorStatsSQL summarizes one contributor's issues and PRs in verified projects:
+// their count, the first and last contribution times and how many active ecosystems
+// they reach. Contributions are dated like on the leaderboard, by the GitHub creation
+// time or else when the sync first saw them. $1 is the login; %[1]s is the project
+// filter and %[2]s the pull request filter.
+const contributorStatsSQL = `
+WITH contributions AS (
+  SELECT i.project_id, COALESCE(i.created_at_github, i.last_seen_at) AS at
+  FROM github_issues i
+  WHERE LOWER(i.author_login) = LOWER($1)
+
+  UNION ALL
+
+  SELECT pr.project_id, COALESCE(pr.created_at_github, pr.last_seen_at) AS at
+  FROM github_pull_requests pr
+  WHERE LOWER(pr.author_login) = LOWER($1) AND %[2]s
+),
+verified AS (
+  SELECT c.project_id, c.at
+  FROM contributions c
+  INNER JOIN projects p ON p.id = c.project_id
+  WHERE %[1]s
+)
+SELECT
+  COUNT(*) AS total_contributions,
+  MIN(v.at) AS first_contribution_at,
+  MAX(v.at) AS last_contribution_at,
+  (
+    SELECT COUNT(DISTINCT pe.ecosystem_id)
+    FROM project_ecosystems pe
+    INNER JOIN ecosystems e ON e.id = pe.ecosystem_id
+    WHERE pe.project_id IN (SELECT project_id FROM verified)
+      AND e.status = 'active' AND e.deleted_at IS NULL
+  ) AS active_ecosystems
+FROM verified v
+`
+
+// contributorStatsQuery builds the contributor stats query for f. Like the breakdown,
+// it ignores the ecosystem filter.
+func contributorStatsQuery(f leaderboardFilter) string {
+	prFilter := "TRUE"
+	if f.MergedPRsOnly {
+		prFilter = "pr.merged IS TRUE"
+	}
+	return fmt.Sprintf(contributorStatsSQL, "p.status = 'verified'", prFilter)
+}
+
+// leaderboardFilter narrows which contributions count toward the contributor leaderboard.
+type leaderboardFilter struct {
+	EcosystemSlug string // only count contributions to projects in this ecosystem
+	MergedPRsOnly bool   // only count merged pull requests (?pr_state=merged)
+	// MinContributions drops contributors below this many counted contributions
+	// (?min_contributions=, default and minimum 1).
+	MinContributions int
+	// IncludeZero also lists signed-up users with no counted contribution, ranked last
+	// with a count of 0 (?include_zero=true).
+	IncludeZero bool
+	// TieBreak orders contributors with equal counts. Set by the handler from its
+	// configuration, not from the request.
+	TieBreak LeaderboardTieBreak
+}
+
+func leaderboardFilterFromQuery(c *fiber.Ctx) leaderboardFilter {
+	minContributions := c.QueryInt("min_contributions", 1)
+	if minContributions < 1 {
+		minContributions = 1
+	}
+	return leaderboardFilter{
+		EcosystemSlug:    strings.TrimSpace(c.Query("ecosystem")),
+		MergedPRsOnly:    strings.EqualFold(strings.TrimSpace(c.Query("pr_state")), "merged"),
+		MinContributions: minContributions,
+		IncludeZero:      c.QueryBool("include_zero", false),
+	}
+}
+
+// cacheKey identifies f in the leaderboard cache.
+func (f leaderboardFilter) cacheKey() string {
+	return fmt.Sprintf("%s|%t|%d|%t|%s", strings.ToLower(f.EcosystemSlug), f.MergedPRsOnly, f.minContributions(), f.IncludeZero, f.TieBreak)
+}
+
+// minContributions is MinContributions with the zero value treated as 1.
+func (f leaderboardFilter) minContributions() int {
+	if f.MinContributions < 1 {
+		return 1
+	}
+	return f.MinContributions
+}
+
+// ecosystemContributorCountLateralSQL is a LEFT JOIN LATERAL counting an ecosystem's
+// contributors with the leaderboard's definition: distinct issue/PR authors, compared
+// case-insensitively, across the verified, non-deleted projects associated with e.id.
+// Exposes cc.contributor_count.
+const ecosystemContributorCountLateralSQL = `LEFT JOIN LATERAL (
+  SELECT COUNT(*) AS contributor_count
+  FROM (
+    SELECT LOWER(i.author_login) AS login
+    FROM project_ecosystems pe_c
+    INNER JOIN projects p_c ON p_c.id = pe_c.project_id
+    INNER JOIN github_issues i ON i.project_id = p_c.id
+    WHERE pe_c.ecosystem_id = e.id
+      AND p_c.status = 'verified' AND p_c.deleted_at IS NULL
+      AND i.author_login IS NOT NULL AND i.author_login != ''
+
+    UNION
+
+    SELECT LOWER(pr.author_login) AS login
+    FROM project_ecosystems pe_c
+    INNER JOIN projects p_c ON p_c.id = pe_c.project_id
+    INNER JOIN github_pull_requests pr ON pr.project_id = p_c.id
+    WHERE pe_c.ecosystem_id = e.id
+      AND p_c.status = 'verified' AND p_c.deleted_at IS NULL
+      AND pr.author_login IS NOT NULL AND pr.author_login != ''
+  ) authors
+) cc ON TRUE`
+
+// ecosystemIDBySlugSQL is a scalar subquery resolving the slug bound to placeholder
+// argPos to an ecosystem id: the live ecosystem currently using the slug, otherwise
+// the ecosystem it was renamed away from (see ecosystem_slug_aliases), otherwise NULL.
+func ecosystemIDBySlugSQL(argPos int) string {
+	return fmt.Sprintf(`(
+  SELECT resolved.id FROM (
+    SELECT e_s.id, 0 AS pri FROM ecosystems e_s WHERE LOWER(e_s.slug) = LOWER($%[1]d) AND e_s.deleted_at IS NULL
+    UNION ALL
+    SELECT a_s.ecosystem_id, 1 FROM ecosystem_slug_aliases a_s WHERE a_s.slug = LOWER($%[1]d)
+  ) resolved
+  ORDER BY resolved.pri
+  LIMIT 1
+)`, argPos)
+}
+
+// projectInEcosystemSQL matches projects associated (via project_ecosystems) with the
+// live ecosystem whose slug, current or old, is bound to placeholder argPos.
+func projectInEcosystemSQL(projectIDExpr string, argPos int) string {
+	return fmt.Sprintf(`EXISTS (
+  SELECT 1 FROM project_ecosystems pe_f
+  INNER JOIN ecosystems e_f ON e_f.id = pe_f.ecosystem_id
+  WHERE pe_f.project_id = %s AND e_f.id = %s AND e_f.deleted_at IS NULL
+)`, projectIDExpr, ecosystemIDBySlugSQL(argPos))
+}
+
+// projectEcosystemHasTechnologySQL matches projects associated (via project_ecosystems)
+// with a live ecosystem whose languages list names the technology bound to placeholder
+// argPos, compared case-insensitively.
+func projectEcosystemHasTechnologySQL(projectIDExpr string, argPos int) string {
+	return fmt.Sprintf(`EXISTS (
+  SELECT 1 FROM project_ecosystems pe_t
+  INNER JOIN ecosystems e_t ON e_t.id = pe_t.ecosystem_id
+  CROSS JOIN LATERAL jsonb_array_elements(e_t.languages) lang
+  WHERE pe_t.project_id = %s AND e_t.deleted_at IS NULL
+    AND jsonb_typeof(lang) = 'object' AND LOWER(lang->>'name') = LOWER($%d)
+)`, projectIDExpr, argPos)
+}
+
+// contributorLeaderboardQuery builds the ranked contributor query for f, without
+// LIMIT/OFFSET. It returns the filter args and the next free placeholder index.
+func contributorLeaderboardQuery(f leaderboardFilter) (string, []any, int) {
+	projectFilter := "p.status = 'verified'"
+	var args []any
+	argPos := 1
+
+	if f.EcosystemSlug != "" {
+		projectFilter += " AND " + projectInEcosystemSQL("p.id", argPos)
+		args = append(args, f.EcosystemSlug)
+		argPos++
+	}
+
+	prFilter := "TRUE"
+	if f.MergedPRsOnly {
+		prFilter = "pr.merged IS TRUE"
+	}
+
+	minContributions := fmt.Sprintf("$%d", argPos)
+	args = append(args, f.minContributions())
+	argPos++
+
+	zeroContributors := ""
+	if f.IncludeZero {
+		zeroContributors = signedUpZeroContributorsSQL
+	}
+
+	return fmt.Sprintf(contributorLeaderboardSQL, projectFilter, prFilter, minContributions, scoreRecentWindowSQL,
+		contributorTieBreakSQL(f.TieBreak, "username"), zeroContributors), args, argPos
+}
+
+// contributorRankedQuery wraps the ranked contributor query for f with a trailing
+// global_rank column numbered by the database, so a row's rank comes from the same
+// snapshot as its count instead of from the page offset, which goes stale when a sync
+// lands between page requests. The tie break makes the order total, so RANK() gives
+// every row its own position. where, if not nil, filters the ranked rows g; it is given
+// the next free placeholder index, which is also returned.
+func contributorRankedQuery(f leaderboardFilter, where func(argPos int) string) (string, []any, int) {
+	query, args, argPos := contributorLeaderboardQuery(f)
+	filter := ""
+	if where != nil {
+		filter = "WHERE " + where(argPos) + "\n"
+	}
+	return fmt.Sprintf(`SELECT * FROM (
+  SELECT ranked.*, RANK() OVER (ORDER BY ranked.contribution_count DESC, %s) AS global_rank
+  FROM (%s) ranked
+) g
+%sORDER BY g.global_rank
+`, contributorTieBreakSQL(f.TieBreak, "ranked.username"), query, filter), args, argPos
+}
+
+// contributorSearchQuery narrows the ranked contributor query for f to logins starting
+// with search (case-insensitive, wildcards matched literally). Ranks are numbered over
+// the unfiltered ranking first (see contributorRankedQuery), so each match carries its
+// global position rather than its position among the matches.
+func contributorSearchQuery(f leaderboardFilter, search string) (string, []any, int) {
+	query, args, argPos := contributorRankedQuery(f, func(argPos int) string {
+		return fmt.Sprintf("g.username ILIKE $%d || '%%'", argPos)
+	})
+	args = append(args, escapeLike(search))
+	return query, args, argPos + 1
+}
+
+// contributorRanksQuery narrows the ranked contributor query for f to the given logins,
+// compared case-insensitively. As with contributorSearchQuery, ranks are global.
+func contributorRanksQuery(f leaderboardFilter, logins []string) (string, []any) {
+	query, args, _ := contributorRankedQuery(f, func(argPos int) string {
+		return fmt.Sprintf("LOWER(g.username) = ANY($%d)", argPos)
+	})
+	lowered := make([]string, len(logins))
+	for i, login := range logins {
+		lowered[i] = strings.ToLower(login)
+	}
+	return query, append(args, lowered)
+}
+
+// contributorLeaderboardCountQuery counts every ranked contributor for f.
+func contributorLeaderboardCountQuery(f leaderboardFilter) (string, []any) {
+	query, args, _ := contributorLeaderboardQuery(f)
+	return "SELECT COUNT(*) FROM (" + query + ") ranked", args
+}
+
+// rankTierCaseSQL is a CASE expression naming the tier of the 1-indexed position
+// rankExpr out of totalExpr ranked contributors. It is built from the same bands as
+// GetRankTierForMode, so SQL and Go bucketing agree.
+func rankTierCaseSQL(mode RankTierMode, rankExpr, totalExpr string) string {
+	var b strings.Builder
+	b.WriteString("CASE")
+	if mode == RankTierModePercentile {
+		for _, band := range rankTierPercentileBands {
+			fmt.Fprintf(&b, " WHEN %s * 100.0 / %s <= %g THEN '%s'", rankExpr, totalExpr, band.MaxPercentile, band.Tier)
+		}
+	} else {
+		for _, band := range rankTierBands {
+			if band.MaxPosition == 0 {
+				fmt.Fprintf(&b, " WHEN %s >= %d THEN '%s'", rankExpr, band.MinPosition, band.Tier)
+			} else {
+				fmt.Fprintf(&b, " WHEN %s BETWEEN %d AND %d THEN '%s'", rankExpr, band.MinPosition, band.MaxPosition, band.Tier)
+			}
+		}
+	}
+	fmt.Fprintf(&b, " ELSE '%s' END", RankBronze)
+	return b.String()
+}
+
+// contributorTierDistributionQuery counts the ranked contributors for f in each tier.
+// Every contributor is ranked (see contributorRankedQuery) and bucketed in the
+// database; tiers without contributors are left out.
+func contributorTierDistributionQuery(f leaderboardFilter, mode RankTierMode) (string, []any) {
+	query, args, _ := contributorRankedQuery(f, nil)
+	return fmt.Sprintf(`SELECT t.tier, COUNT(*) FROM (
+  SELECT %s AS tier
+  FROM (SELECT r.global_rank, COUNT(*) OVER () AS total FROM (%s) r) positions
+) t
+GROUP BY t.tier
+`, rankTierCaseSQL(mode, "positions.global_rank", "positions.total"), query), args
+}
+
+// LeaderboardTieBreak chooses how leaderboard rows with equal counts are ordered. Either
+// way a unique key (login or project id) comes last, so pages never overlap.
+type LeaderboardTieBreak string
+
+const (
+	// TieBreakAlphabetical orders ties by login / repository name (the default).
+	TieBreakAlphabetical LeaderboardTieBreak = "alphabetical"
+	// TieBreakFirstContribution puts whoever contributed first ahead.
+	TieBreakFirstContribution LeaderboardTieBreak = "first_contribution"
+)
+
+// ParseLeaderboardTieBreak parses a tie-break setting; empty means alphabetical.
+func ParseLeaderboardTieBreak(s string) (LeaderboardTieBreak, error) {
+	switch tb := LeaderboardTieBreak(strings.ToLower(strings.TrimSpace(s))); tb {
+	case "", TieBreakAlphabetical:
+		return TieBreakAlphabetical, nil
+	case TieBreakFirstContribution:
+		return tb, nil
+	default:
+		return TieBreakAlphabetical, fmt.Errorf("invalid tie break %q", s)
+	}
+}
+
+// SetTieBreak changes how ties are ordered and drops cached pages ordered the old way.
+func (h *LeaderboardHandler) SetTieBreak(tb LeaderboardTieBreak) {
+	h.tieBreak = tb
+	h.cache.invalidate()
+}
+
+// contributorTieBreakSQL orders contributors with equal counts, ending with login, which
+// is unique per row (user_id is empty for contributors who haven't signed up).
+func contributorTieBreakSQL(tb LeaderboardTieBreak, login string) string {
+	if tb == TieBreakFirstContribution {
+		return "first_contribution_at ASC NULLS LAST, " + login + " ASC"
+	}
+	return login + " ASC"
+}
+
+// projectLeaderboardStatuses maps ?status= values of the project leaderboard to
+// whitelisted project filters. Anything but "verified" is for admins only.
+var projectLeaderboardStatuses = map[string]string{
+	"verified":             "p.status = 'verified'",
+	"pending":              "p.status = 'pending_verification'",
+	"pending_verification": "p.status = 'pending_verification'",
+	"rejected":             "p.status = 'rejected'",
+	"all":                  "TRUE",
+}
+
+// projectLeaderboardSorts maps ?sort= values to whitelisted ORDER BY expressions
+// and their default direction. User input never reaches the SQL directly.
+var projectLeaderboardSorts = map[string]struct {
+	expr         string
+	defaultOrder string
+}{
+	"contributors": {"contributors_count", "desc"},
+	"name":         {"LOWER(p.github_full_name)", "asc"},
+	"recent":       {"last_contribution_at", "desc"},
+}
+
+// projectLeaderboardOrderBy validates sort/order and returns the ORDER BY clause
+// (without the keyword), breaking ties with tb and then the project id. Empty values
+// fall back to the defaults.
+func projectLeaderboardOrderBy(sort, order string, tb LeaderboardTieBreak) (string, error) {
+	sort = strings.ToLower(strings.TrimSpace(sort))
+	order = strings.ToLower(strings.TrimSpace(order))
+	if sort == "" {
+		sort = "contributors"
+	}
+	spec, ok := projectLeaderboardSorts[sort]
+	if !ok {
+		return "", fmt.Errorf("invalid sort %q", sort)
+	}
+	if order == "" {
+		order = spec.defaultOrder
+	}
+	if order != "asc" && order != "desc" {
+		return "", fmt.Errorf("invalid order %q", order)
+	}
+
+	clause := spec.expr + " " + strings.ToUpper(order)
+	if sort == "recent" {
+		// Projects without any timestamped contribution always sort last.
+		clause += " NULLS LAST"
+	}
+	switch {
+	case tb == TieBreakFirstContribution:
+		clause += ", first_contribution_at ASC NULLS LAST"
+	case sort != "name":
+		clause += ", p.github_full_name ASC"
+	}
+	// Unique final key so pagination doesn't shuffle equal rows.
+	return clause + ", p.id ASC", nil
+}
@@ -0,0 +1,1891 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+)
+
+func TestProjectLeaderboardOrderBy(t *testing.T) {
+	cases := []struct {
+		sort, order string
+		tieBreak    LeaderboardTieBreak
+		want        string
+	}{
+		{"", "", TieBreakAlphabetical, "contributors_count DESC, p.github_full_name ASC, p.id ASC"},
+		{"contributors", "", TieBreakAlphabetical, "contributors_count DESC, p.github_full_name ASC, p.id ASC"},
+		{"contributors", "asc", TieBreakAlphabetical, "contributors_count ASC, p.github_full_name ASC, p.id ASC"},
+		{"name", "", TieBreakAlphabetical, "LOWER(p.github_full_name) ASC, p.id ASC"},
+		{"name", "desc", TieBreakAlphabetical, "LOWER(p.github_full_name) DESC, p.id ASC"},
+		{"recent", "", TieBreakAlphabetical, "last_contribution_at DESC NULLS LAST, p.github_full_name ASC, p.id ASC"},
+		{"RECENT", "ASC", TieBreakAlphabetical, "last_contribution_at ASC NULLS LAST, p.github_full_name ASC, p.id ASC"},
+		{"", "", TieBreakFirstContribution, "contributors_count DESC, first_contribution_at ASC NULLS LAST, p.id ASC"},
+		{"name", "", TieBreakFirstContribution, "LOWER(p.github_full_name) ASC, first_contribution_at ASC NULLS LAST, p.id ASC"},
+	}
+	for _, tc := range cases {
+		got, err := projectLeaderboardOrderBy(tc.sort, tc.order, tc.tieBreak)
+		if err != nil {
+			t.Errorf("projectLeaderboardOrderBy(%q, %q, %q) unexpected error: %v", tc.sort, tc.order, tc.tieBreak, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("projectLeaderboardOrderBy(%q, %q, %q) = %q, want %q", tc.sort, tc.order, tc.tieBreak, got, tc.want)
+		}
+	}
+}
+
+func TestProjectLeaderboardOrderBy_Invalid(t *testing.T) {
+	for _, tc := range []struct{ sort, order string }{
+		{"stars", ""},
+		{"contributors_count; DROP TABLE projects", ""},
+		{"name", "sideways"},
+	} {
+		if _, err := projectLeaderboardOrderBy(tc.sort, tc.order, TieBreakAlphabetical); err == nil {
+			t.Errorf("projectLeaderboardOrderBy(%q, %q) expected error", tc.sort, tc.order)
+		}
+	}
+}
+
+func TestParseLeaderboardTieBreak(t *testing.T) {
+	for in, want := range map[string]LeaderboardTieBreak{
+		"":                     TieBreakAlphabetical,
+		"alphabetical":         TieBreakAlphabetical,
+		" First_Contribution ": TieBreakFirstContribution,
+	} {
+		if got, err := ParseLeaderboardTieBreak(in); err != nil || got != want {
+			t.Errorf("ParseLeaderboardTieBreak(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseLeaderboardTieBreak("random"); err == nil {
+		t.Error("expected an error for an unknown tie break")
+	}
+}
+
+func TestContributorLeaderboardQuery_TieBreak(t *testing.T) {
+	alpha, _, _ := contributorLeaderboardQuery(leaderboardFilter{})
+	if !strings.Contains(alpha, "ORDER BY contribution_count DESC, username ASC") {
+		t.Errorf("expected ties ordered by login:\n%s", alpha)
+	}
+
+	first, _, _ := contributorLeaderboardQuery(leaderboardFilter{TieBreak: TieBreakFirstContribution})
+	if !strings.Contains(first, "ORDER BY contribution_count DESC, first_contribution_at ASC NULLS LAST, username ASC") {
+		t.Errorf("expected ties ordered by first contribution, then login:\n%s", first)
+	}
+
+	// Search numbers global ranks with the same ordering
+	search, _, _ := contributorSearchQuery(leaderboardFilter{TieBreak: TieBreakFirstContribution}, "a")
+	if !strings.Contains(search, "ORDER BY ranked.contribution_count DESC, first_contribution_at ASC NULLS LAST, ranked.username ASC") {
+		t.Errorf("expected the search ranking to use the tie break:\n%s", search)
+	}
+
+	if (leaderboardFilter{}).cacheKey() == (leaderboardFilter{TieBreak: TieBreakFirstContribution}).cacheKey() {
+		t.Error("cache key should include the tie break")
+	}
+}
+
+func TestProjectsLeaderboard_InvalidSortReturns400(t *testing.T) {
+	app := fiber.New()
+	app.Get("/leaderboard/projects", NewLeaderboardHandler(nil, 0).ProjectsLeaderboard())
+
+	for _, path := range []string{
+		"/leaderboard/projects?sort=stars",
+		"/leaderboard/projects?sort=name&order=up",
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("GET %s: expected 400, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestContributorLeaderboardQuery_PRState(t *testing.T) {
+	all, _, _ := contributorLeaderboardQuery(leaderboardFilter{})
+	if strings.Contains(all, "pr.merged") {
+		t.Error("default query should not filter on pr.merged")
+	}
+
+	merged, _, _ := contributorLeaderboardQuery(leaderboardFilter{MergedPRsOnly: true})
+	// The CTE, the contribution count, the ecosystems list, the recent count, the first
+	// contribution and the WHERE filter all read PRs.
+	if n := strings.Count(merged, "pr.merged IS TRUE"); n != 6 {
+		t.Errorf("expected merged filter on all 6 PR subqueries, got %d", n)
+	}
+	if strings.Contains(merged, "i.merged") {
+		t.Error("merged filter must not apply to issues")
+	}
+}
+
+func TestContributorLeaderboardQuery_MinContributions(t *testing.T) {
+	query, args, next := contributorLeaderboardQuery(leaderboardFilter{})
+	if !strings.Contains(query, ">= $1") || len(args) != 1 || args[0] != 1 || next != 2 {
+		t.Errorf("default threshold should bind 1 as $1, got args %v next %d", args, next)
+	}
+
+	query, args, next = contributorLeaderboardQuery(leaderboardFilter{EcosystemSlug: "stellar", MinContributions: 5})
+	if !strings.Contains(query, ">= $2") || len(args) != 2 || args[1] != 5 || next != 3 {
+		t.Errorf("threshold should follow the ecosystem arg, got args %v next %d", args, next)
+	}
+
+	if a, b := (leaderboardFilter{}).cacheKey(), (leaderboardFilter{MinContributions: 5}).cacheKey(); a == b {
+		t.Error("cache key should include the threshold")
+	}
+}
+
+func TestContributorLeaderboardQuery_IncludeZero(t *testing.T) {
+	query, args, next := contributorLeaderboardQuery(leaderboardFilter{})
+	if strings.Contains(query, "ga_z") {
+		t.Error("signed-up users without contributions should be left out by default")
+	}
+
+	withZero, zeroArgs, zeroNext := contributorLeaderboardQuery(leaderboardFilter{IncludeZero: true})
+	if !strings.Contains(withZero, "FROM github_accounts ga_z") {
+		t.Errorf("expected signed-up users to be added:\n%s", withZero)
+	}
+	// The threshold still applies to everyone else
+	if !strings.Contains(withZero, "WHERE ac.signed_up_only OR (") || !strings.Contains(withZero, ">= $1") {
+		t.Errorf("expected only the zero rows to bypass the threshold:\n%s", withZero)
+	}
+	if len(zeroArgs) != len(args) || zeroNext != next {
+		t.Errorf("include_zero should bind no args, got %v next %d", zeroArgs, zeroNext)
+	}
+
+	if (leaderboardFilter{}).cacheKey() == (leaderboardFilter{IncludeZero: true}).cacheKey() {
+		t.Error("cache key should include include_zero")
+	}
+}
+
+func TestContributorSearchQuery(t *testing.T) {
+	query, args, next := contributorSearchQuery(leaderboardFilter{EcosystemSlug: "stellar"}, "a_b%")
+	// $1 ecosystem, $2 threshold, $3 search prefix
+	if !strings.Contains(query, "g.username ILIKE $3 || '%'") || next != 4 {
+		t.Errorf("expected the prefix bound as $3, got next %d:\n%s", next, query)
+	}
+	if len(args) != 3 || args[2] != `a\_b\%` {
+		t.Errorf("expected wildcards to be escaped, got %v", args)
+	}
+	if !strings.Contains(query, "RANK() OVER") || !strings.Contains(query, "WHERE g.username ILIKE $3 || '%'\nORDER BY g.global_rank") {
+		t.Errorf("expected ranks to be numbered before filtering:\n%s", query)
+	}
+}
+
+func TestContributorRankedQuery(t *testing.T) {
+	query, args, next := contributorRankedQuery(leaderboardFilter{}, nil)
+	if !strings.Contains(query, "RANK() OVER (ORDER BY ranked.contribution_count DESC, ranked.username ASC) AS global_rank") {
+		t.Errorf("expected ranks numbered by count, then login:\n%s", query)
+	}
+	if strings.Contains(query, "WHERE g.") || !strings.HasSuffix(query, "ORDER BY g.global_rank\n") {
+		t.Errorf("expected unfiltered rows ordered by rank:\n%s", query)
+	}
+	if len(args) != 1 || next != 2 {
+		t.Errorf("expected only the threshold arg, got %v next %d", args, next)
+	}
+}
+
+func TestContributorRanksQuery(t *testing.T) {
+	query, args := contributorRanksQuery(leaderboardFilter{EcosystemSlug: "stellar"}, []string{"Alice", "bob"})
+	// $1 ecosystem, $2 threshold, $3 logins
+	if !strings.Contains(query, "WHERE LOWER(g.username) = ANY($3)\nORDER BY g.global_rank") {
+		t.Errorf("expected the logins bound as $3 after ranking:\n%s", query)
+	}
+	if len(args) != 3 {
+		t.Fatalf("expected 3 args, got %v", args)
+	}
+	if logins, ok := args[2].([]string); !ok || len(logins) != 2 || logins[0] != "alice" || logins[1] != "bob" {
+		t.Errorf("expected lowercase logins, got %v", args[2])
+	}
+}
+
+// Integration tests below require TEST_DB_URL pointing at a migrated database.
+func newLeaderboardTestDB(t *testing.T) *db.DB {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL not set, skipping integration test")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	d, err := db.Connect(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	t.Cleanup(d.Close)
+	return d
+}
+
+// getLeaderboardJSON decodes the JSON array returned by GET path into out.
+func getLeaderboardJSON(t *testing.T, app *fiber.App, path string, out any) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("GET %s: expected 200, got %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+}
+
+func TestLeaderboard_PRState_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: one verified project in its own ecosystem so other data can't leak in.
+	//   alice: 1 issue, 2 merged PRs, 1 open PR, 1 closed-unmerged PR
+	//   bob:   1 open PR only
+	var userID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('pr-state-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('pr-state-test', 'PR State Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'pr-state-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'pr-state-test/repo', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, 9001, 1, 'open', 'pr-state-alice')`, projectID); err != nil {
+		t.Fatalf("insert issue: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, merged, author_login)
+VALUES
+  ($1, 9101, 2, 'closed', true,  'pr-state-alice'),
+  ($1, 9102, 3, 'closed', true,  'pr-state-alice'),
+  ($1, 9103, 4, 'open',   false, 'pr-state-alice'),
+  ($1, 9104, 5, 'closed', false, 'pr-state-alice'),
+  ($1, 9105, 6, 'open',   NULL,  'pr-state-bob')`, projectID); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/leaderboard", NewLeaderboardHandler(d, -1).Leaderboard())
+
+	fetch := func(path string) map[string]int {
+		t.Helper()
+		var rows []struct {
+			Username      string `json:"username"`
+			Contributions int    `json:"contributions"`
+		}
+		getLeaderboardJSON(t, app, path, &rows)
+		out := map[string]int{}
+		for _, r := range rows {
+			out[r.Username] = r.Contributions
+		}
+		return out
+	}
+
+	all := fetch("/leaderboard?ecosystem=pr-state-test")
+	if all["pr-state-alice"] != 5 || all["pr-state-bob"] != 1 {
+		t.Errorf("pr_state=all: expected alice=5 bob=1, got %v", all)
+	}
+	if def := fetch("/leaderboard?ecosystem=pr-state-test&pr_state=all"); def["pr-state-alice"] != 5 {
+		t.Errorf("explicit pr_state=all should match default, got %v", def)
+	}
+
+	merged := fetch("/leaderboard?ecosystem=pr-state-test&pr_state=merged")
+	if merged["pr-state-alice"] != 3 {
+		t.Errorf("pr_state=merged: expected alice=3 (1 issue + 2 merged PRs), got %d", merged["pr-state-alice"])
+	}
+	if _, ok := merged["pr-state-bob"]; ok {
+		t.Errorf("pr_state=merged: bob has no merged PRs and should be excluded, got %v", merged)
+	}
+
+	// Each row splits its contributions into issues and PRs, plus how many PRs merged.
+	// PRs opened are counted whatever pr_state, so they only add up to the contributions
+	// when every PR counts.
+	for _, path := range []string{"/leaderboard?ecosystem=pr-state-test", "/leaderboard?ecosystem=pr-state-test&pr_state=merged"} {
+		var rows []struct {
+			Username      string `json:"username"`
+			Contributions int    `json:"contributions"`
+			IssuesCount   int    `json:"issues_count"`
+			PRsOpened     int    `json:"prs_opened"`
+			PRsMerged     int    `json:"prs_merged"`
+		}
+		getLeaderboardJSON(t, app, path, &rows)
+		mergedOnly := strings.HasSuffix(path, "pr_state=merged")
+		for _, r := range rows {
+			if (!mergedOnly && r.IssuesCount+r.PRsOpened != r.Contributions) || r.PRsMerged > r.PRsOpened {
+				t.Errorf("%s: inconsistent counts for %s: %+v", path, r.Username, r)
+			}
+			if r.Username == "pr-state-alice" && (r.IssuesCount != 1 || r.PRsOpened != 4 || r.PRsMerged != 2) {
+				t.Errorf("%s: expected alice to have 1 issue, 4 opened and 2 merged PRs, got %+v", path, r)
+			}
+		}
+	}
+}
+
+func TestLeaderboard_MultipleEcosystems_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: a verified project whose primary ecosystem is "multi-eco-a" and which is
+	// also associated with "multi-eco-b" through project_ecosystems.
+	var userID, ecoA, ecoB, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('multi-eco-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('multi-eco-a', 'Multi Eco A') RETURNING id::text`).Scan(&ecoA); err != nil {
+		t.Fatalf("insert ecosystem a: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('multi-eco-b', 'Multi Eco B') RETURNING id::text`).Scan(&ecoB); err != nil {
+		t.Fatalf("insert ecosystem b: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'multi-eco-test/sdk'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id IN ($1, $2)`, ecoA, ecoB)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'multi-eco-test/sdk', 'verified', $2)
+RETURNING id::text`, userID, ecoA).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+
+	// The primary ecosystem is mirrored into the join table by the migration's trigger.
+	var mirrored bool
+	if err := d.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM project_ecosystems WHERE project_id = $1 AND ecosystem_id = $2)`, projectID, ecoA).Scan(&mirrored); err != nil {
+		t.Fatalf("check join table: %v", err)
+	}
+	if !mirrored {
+		t.Fatal("expected primary ecosystem to be mirrored into project_ecosystems")
+	}
+
+	if _, err := d.Pool.Exec(ctx, `INSERT INTO project_ecosystems (project_id, ecosystem_id) VALUES ($1, $2)`, projectID, ecoB); err != nil {
+		t.Fatalf("associate second ecosystem: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, merged, author_login)
+VALUES ($1, 9201, 1, 'closed', true, 'multi-eco-carol')`, projectID); err != nil {
+		t.Fatalf("insert pr: %v", err)
+	}
+
+	h := NewLeaderboardHandler(d, -1)
+	app := fiber.New()
+	app.Get("/leaderboard", h.Leaderboard())
+	app.Get("/leaderboard/projects", h.ProjectsLeaderboard())
+
+	type row struct {
+		Username   string   `json:"username"`
+		FullName   string   `json:"full_name"`
+		Ecosystems []string `json:"ecosystems"`
+	}
+	hasBoth := func(ecosystems []string) bool {
+		var a, b bool
+		for _, e := range ecosystems {
+			a = a || e == "Multi Eco A"
+			b = b || e == "Multi Eco B"
+		}
+		return a && b
+	}
+
+	// Filtering by the secondary ecosystem still finds the contributor and project.
+	var contributors []row
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=multi-eco-b", &contributors)
+	if len(contributors) != 1 || contributors[0].Username != "multi-eco-carol" {
+		t.Fatalf("expected multi-eco-carol under multi-eco-b, got %+v", contributors)
+	}
+	if !hasBoth(contributors[0].Ecosystems) {
+		t.Errorf("contributor ecosystems should include both associations, got %v", contributors[0].Ecosystems)
+	}
+
+	var projects []row
+	getLeaderboardJSON(t, app, "/leaderboard/projects?ecosystem=multi-eco-b", &projects)
+	if len(projects) != 1 || projects[0].FullName != "multi-eco-test/sdk" {
+		t.Fatalf("expected multi-eco-test/sdk under multi-eco-b, got %+v", projects)
+	}
+	if !hasBoth(projects[0].Ecosystems) {
+		t.Errorf("project ecosystems should include both associations, got %v", projects[0].Ecosystems)
+	}
+
+	getLeaderboardJSON(t, app, "/leaderboard/projects?ecosystem=multi-eco-a", &projects)
+	if len(projects) != 1 {
+		t.Errorf("expected project under its primary ecosystem too, got %+v", projects)
+	}
+}
+
+func TestContributorBreakdownQuery_PRState(t *testing.T) {
+	if q := contributorBreakdownQuery(leaderboardFilter{}); strings.Contains(q, "pr.merged") {
+		t.Error("default breakdown should count all PRs")
+	}
+	if q := contributorBreakdownQuery(leaderboardFilter{MergedPRsOnly: true}); !strings.Contains(q, "pr.merged IS TRUE") {
+		t.Error("merged filter should restrict PRs")
+	}
+}
+
+func TestContributorBreakdown_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture:
+	//   breakdown-x/repo (verified, ecosystem X): bd-alice 2 issues + 1 PR
+	//   breakdown-y/repo (verified, ecosystem Y): bd-alice 1 PR
+	//   breakdown-y/pending (unverified, ecosystem Y): bd-alice 1 issue, not counted
+	var userID, ecoX, ecoY string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('breakdown-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('breakdown-x', 'Breakdown X') RETURNING id::text`).Scan(&ecoX); err != nil {
+		t.Fatalf("insert ecosystem x: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('breakdown-y', 'Breakdown Y') RETURNING id::text`).Scan(&ecoY); err != nil {
+		t.Fatalf("insert ecosystem y: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'breakdown-%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id IN ($1, $2)`, ecoX, ecoY)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	insertProject := func(name, status, ecosystemID string) string {
+		t.Helper()
+		var id string
+		if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id::text`, userID, name, status, ecosystemID).Scan(&id); err != nil {
+			t.Fatalf("insert project %s: %v", name, err)
+		}
+		return id
+	}
+	projX := insertProject("breakdown-x/repo", "verified", ecoX)
+	projY := insertProject("breakdown-y/repo", "verified", ecoY)
+	pending := insertProject("breakdown-y/pending", "pending_verification", ecoY)
+
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, 9401, 1, 'open', 'bd-alice'), ($1, 9402, 2, 'open', 'BD-Alice'), ($2, 9403, 1, 'open', 'bd-alice')`, projX, pending); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, author_login)
+VALUES ($1, 9501, 3, 'open', 'bd-alice'), ($2, 9502, 1, 'open', 'bd-alice')`, projX, projY); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/contributors/:username/breakdown", NewLeaderboardHandler(d, -1).ContributorBreakdown())
+
+	type entry struct {
+		Ecosystem struct {
+			Slug string `json:"slug"`
+		} `json:"ecosystem"`
+		Contributions int `json:"contributions"`
+		Issues        int `json:"issues"`
+		PRs           int `json:"prs"`
+	}
+	var breakdown []entry
+	getLeaderboardJSON(t, app, "/contributors/Bd-ALICE/breakdown", &breakdown)
+	if len(breakdown) != 2 {
+		t.Fatalf("expected 2 ecosystems, got %+v", breakdown)
+	}
+	if b := breakdown[0]; b.Ecosystem.Slug != "breakdown-x" || b.Contributions != 3 || b.Issues != 2 || b.PRs != 1 {
+		t.Errorf("unexpected first entry %+v", b)
+	}
+	if b := breakdown[1]; b.Ecosystem.Slug != "breakdown-y" || b.Contributions != 1 || b.Issues != 0 || b.PRs != 1 {
+		t.Errorf("unexpected second entry %+v", b)
+	}
+
+	var empty []entry
+	getLeaderboardJSON(t, app, "/contributors/bd-nobody/breakdown", &empty)
+	if empty == nil || len(empty) != 0 {
+		t.Errorf("expected an empty array for an unknown contributor, got %+v", empty)
+	}
+}
+
+func TestContributorStatsQuery_PRState(t *testing.T) {
+	if q := contributorStatsQuery(leaderboardFilter{}); strings.Contains(q, "pr.merged") {
+		t.Error("default stats should count all PRs")
+	}
+	if q := contributorStatsQuery(leaderboardFilter{MergedPRsOnly: true}); !strings.Contains(q, "pr.merged IS TRUE") {
+		t.Error("merged filter should restrict PRs")
+	}
+}
+
+func TestContributorStats_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture:
+	//   stats-x/repo (verified, ecosystem X): st-issues 2 issues, st-prs 1 PR
+	//   stats-x/pending (unverified, ecosystem X): st-pending 1 issue, not counted
+	var userID, ecosystemID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('stats-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('stats-x', 'Stats X') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'stats-x/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	var verified, pending string
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'stats-x/repo', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&verified); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'stats-x/pending', 'pending_verification', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&pending); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login, created_at_github)
+VALUES
+  ($1, 9451, 1, 'open', 'st-issues', '2024-01-10T00:00:00Z'),
+  ($1, 9452, 2, 'open', 'ST-Issues', '2024-06-20T00:00:00Z'),
+  ($2, 9453, 1, 'open', 'st-pending', '2024-02-01T00:00:00Z')`, verified, pending); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, author_login, created_at_github)
+VALUES ($1, 9551, 3, 'open', 'st-prs', '2024-03-05T00:00:00Z')`, verified); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/contributors/:username/stats", NewLeaderboardHandler(d, -1).ContributorStats())
+
+	type stats struct {
+		First            time.Time `json:"first_contribution_at"`
+		Last             time.Time `json:"last_contribution_at"`
+		Total            int       `json:"total_contributions"`
+		ActiveEcosystems int       `json:"active_ecosystems"`
+	}
+	day := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return d
+	}
+
+	// Only issues, login matched case-insensitively
+	var got stats
+	getLeaderboardJSON(t, app, "/contributors/St-Issues/stats", &got)
+	if got.Total != 2 || !got.First.Equal(day("2024-01-10")) || !got.Last.Equal(day("2024-06-20")) || got.ActiveEcosystems != 1 {
+		t.Errorf("unexpected stats for st-issues: %+v", got)
+	}
+
+	// Only PRs
+	getLeaderboardJSON(t, app, "/contributors/st-prs/stats", &got)
+	if got.Total != 1 || !got.First.Equal(day("2024-03-05")) || !got.Last.Equal(got.First) {
+		t.Errorf("unexpected stats for st-prs: %+v", got)
+	}
+
+	// Unverified-only and unknown contributors are not found, also when only merged PRs count
+	for _, path := range []string{
+		"/contributors/st-pending/stats",
+		"/contributors/st-nobody/stats",
+		"/contributors/st-prs/stats?pr_state=merged",
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Errorf("GET %s: expected 404, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestContributorProjectsQuery_PRState(t *testing.T) {
+	for _, q := range []string{contributorProjectsQuery(leaderboardFilter{}), contributorProjectsCountQuery(leaderboardFilter{})} {
+		if strings.Contains(q, "pr.merged") {
+			t.Error("default project split should count all PRs")
+		}
+	}
+	for _, q := range []string{contributorProjectsQuery(leaderboardFilter{MergedPRsOnly: true}), contributorProjectsCountQuery(leaderboardFilter{MergedPRsOnly: true})} {
+		if !strings.Contains(q, "pr.merged IS TRUE") {
+			t.Error("merged filter should restrict PRs")
+		}
+	}
+}
+
+func TestContributorProjects_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture, all by cp-alice under varying casings:
+	//   cp-x/big (verified, ecosystem X): 2 issues + 2 PRs
+	//   cp-x/small (verified, ecosystem X): 1 issue
+	//   cp-y/mid (verified, inactive ecosystem Y): 1 issue + 1 PR
+	//   cp-x/pending (unverified, ecosystem X): 1 issue, not counted
+	var userID, ecoX, ecoY string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('cp-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('cp-x', 'CP X') RETURNING id::text`).Scan(&ecoX); err != nil {
+		t.Fatalf("insert ecosystem x: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name, status) VALUES ('cp-y', 'CP Y', 'inactive') RETURNING id::text`).Scan(&ecoY); err != nil {
+		t.Fatalf("insert ecosystem y: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'cp-%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id IN ($1, $2)`, ecoX, ecoY)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	insertProject := func(name, status, ecosystemID string) string {
+		t.Helper()
+		var id string
+		if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id::text`, userID, name, status, ecosystemID).Scan(&id); err != nil {
+			t.Fatalf("insert project %s: %v", name, err)
+		}
+		return id
+	}
+	big := insertProject("cp-x/big", "verified", ecoX)
+	small := insertProject("cp-x/small", "verified", ecoX)
+	mid := insertProject("cp-y/mid", "verified", ecoY)
+	pending := insertProject("cp-x/pending", "pending_verification", ecoX)
+
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES
+  ($1, 9601, 1, 'open', 'cp-alice'), ($1, 9602, 2, 'open', 'CP-Alice'),
+  ($2, 9603, 1, 'open', 'cp-alice'),
+  ($3, 9604, 1, 'open', 'CP-ALICE'),
+  ($4, 9605, 1, 'open', 'cp-alice')`, big, small, mid, pending); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, author_login)
+VALUES ($1, 9611, 3, 'open', 'cp-alice'), ($1, 9612, 4, 'open', 'Cp-Alice'), ($2, 9613, 2, 'open', 'cp-alice')`, big, mid); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	app := fiber.New()
+	h := NewLeaderboardHandler(d, -1)
+	app.Get("/contributors/:username/projects", h.ContributorProjects())
+	app.Get("/contributors/:username/stats", h.ContributorStats())
+
+	type project struct {
+		ProjectID string `json:"project_id"`
+		FullName  string `json:"full_name"`
+		Ecosystem *struct {
+			Slug string `json:"slug"`
+		} `json:"ecosystem"`
+		Issues int `json:"issues"`
+		PRs    int `json:"prs"`
+		Total  int `json:"total"`
+	}
+	type page struct {
+		Projects []project `json:"projects"`
+		Total    int       `json:"total"`
+	}
+
+	var all page
+	getLeaderboardJSON(t, app, "/contributors/CP-alice/projects", &all)
+	if all.Total != 3 || len(all.Projects) != 3 {
+		t.Fatalf("expected 3 verified projects, got %+v", all)
+	}
+	want := []struct {
+		id, name, ecosystem string
+		issues, prs         int
+	}{
+		{big, "cp-x/big", "cp-x", 2, 2},
+		{mid, "cp-y/mid", "", 1, 1},
+		{small, "cp-x/small", "cp-x", 1, 0},
+	}
+	sum := 0
+	for i, p := range all.Projects {
+		w := want[i]
+		if p.ProjectID != w.id || p.FullName != w.name || p.Issues != w.issues || p.PRs != w.prs || p.Total != w.issues+w.prs {
+			t.Errorf("project %d: expected %+v, got %+v", i, w, p)
+		}
+		if (p.Ecosystem == nil && w.ecosystem != "") || (p.Ecosystem != nil && p.Ecosystem.Slug != w.ecosystem) {
+			t.Errorf("project %d: expected ecosystem %q, got %+v", i, w.ecosystem, p.Ecosystem)
+		}
+		sum += p.Total
+	}
+
+	// The split adds up to the contributor's overall count
+	var stats struct {
+		Total int `json:"total_contributions"`
+	}
+	getLeaderboardJSON(t, app, "/contributors/cp-alice/stats", &stats)
+	if sum != stats.Total || sum != 7 {
+		t.Errorf("expected project totals to sum to the overall %d (7), got %d", stats.Total, sum)
+	}
+
+	// Paging keeps the total
+	var second page
+	getLeaderboardJSON(t, app, "/contributors/cp-alice/projects?limit=2&offset=2", &second)
+	if second.Total != 3 || len(second.Projects) != 1 || second.Projects[0].FullName != "cp-x/small" {
+		t.Errorf("expected the last project on the second page, got %+v", second)
+	}
+
+	var empty page
+	getLeaderboardJSON(t, app, "/contributors/cp-nobody/projects", &empty)
+	if empty.Projects == nil || len(empty.Projects) != 0 || empty.Total != 0 {
+		t.Errorf("expected no projects for an unknown contributor, got %+v", empty)
+	}
+}
+
+func TestLeaderboard_MinContributions_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture in its own ecosystem: min-alice has 5 contributions, min-bob has 1.
+	var userID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('min-contrib-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('min-contrib-test', 'Min Contrib Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'min-contrib-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'min-contrib-test/repo', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES
+  ($1, 9601, 1, 'open', 'min-alice'),
+  ($1, 9602, 2, 'open', 'min-alice'),
+  ($1, 9603, 3, 'open', 'min-alice'),
+  ($1, 9604, 4, 'open', 'min-bob')`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, author_login)
+VALUES ($1, 9701, 5, 'open', 'min-alice'), ($1, 9702, 6, 'open', 'min-alice')`, projectID); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/leaderboard", NewLeaderboardHandler(d, -1).Leaderboard())
+
+	usernames := func(path string) []string {
+		t.Helper()
+		var rows []struct {
+			Username string `json:"username"`
+		}
+		getLeaderboardJSON(t, app, path, &rows)
+		out := make([]string, 0, len(rows))
+		for _, r := range rows {
+			out = append(out, r.Username)
+		}
+		return out
+	}
+
+	if got := usernames("/leaderboard?ecosystem=min-contrib-test"); len(got) != 2 {
+		t.Errorf("default threshold should include both contributors, got %v", got)
+	}
+	if got := usernames("/leaderboard?ecosystem=min-contrib-test&min_contributions=5"); len(got) != 1 || got[0] != "min-alice" {
+		t.Errorf("expected only min-alice at 5+, got %v", got)
+	}
+	if got := usernames("/leaderboard?ecosystem=min-contrib-test&min_contributions=6"); len(got) != 0 {
+		t.Errorf("expected nobody at 6+, got %v", got)
+	}
+
+	// The ranked population used for percentile tiers is the filtered one.
+	query, args := contributorLeaderboardCountQuery(leaderboardFilter{EcosystemSlug: "min-contrib-test", MinContributions: 5})
+	var total int
+	if err := d.Pool.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		t.Fatalf("count query: %v", err)
+	}
+	if total != 1 {
+		t.Errorf("expected filtered total 1, got %d", total)
+	}
+}
+
+func TestLeaderboard_IncludeZero_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture in its own ecosystem: zero-alice (signed up as Zero-Alice) has 2
+	// contributions, zero-bob has 1 and never signed up, zero-carol and zero-dave signed
+	// up without contributing.
+	var ownerID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('zero-test') RETURNING id::text`).Scan(&ownerID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('zero-test', 'Zero Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	var signedUp []string
+	for i, login := range []string{"Zero-Alice", "zero-carol", "zero-dave"} {
+		var userID string
+		if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ($1) RETURNING id::text`, login).Scan(&userID); err != nil {
+			t.Fatalf("insert user: %v", err)
+		}
+		signedUp = append(signedUp, userID)
+		if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_accounts (user_id, github_user_id, login, access_token)
+VALUES ($1, $2, $3, '\x00')`, userID, 9_860_001+i, login); err != nil {
+			t.Fatalf("insert github account: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'zero-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = ANY($1::uuid[])`, append(signedUp, ownerID))
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'zero-test/repo', 'verified', $2)
+RETURNING id::text`, ownerID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, 9861, 1, 'open', 'zero-alice'), ($1, 9862, 2, 'open', 'zero-bob')`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, author_login)
+VALUES ($1, 9863, 3, 'open', 'zero-alice')`, projectID); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/leaderboard", NewLeaderboardHandler(d, -1).Leaderboard())
+
+	type row struct {
+		Rank          int    `json:"rank"`
+		Username      string `json:"username"`
+		UserID        string `json:"user_id"`
+		Contributions int    `json:"contributions"`
+	}
+
+	var rows []row
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=zero-test&limit=100", &rows)
+	if len(rows) != 2 {
+		t.Fatalf("expected only the 2 contributors by default, got %+v", rows)
+	}
+
+	// Other signed-up users in the database also join the zero tail, so narrow to the
+	// fixture with search; ranks stay global.
+	rows = nil
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=zero-test&include_zero=true&search=zero-&limit=100", &rows)
+	if len(rows) != 4 {
+		t.Fatalf("expected 2 contributors and 2 zero rows, got %+v", rows)
+	}
+	// Shown in the signed-up account's casing
+	if rows[0].Username != "Zero-Alice" || rows[0].Rank != 1 || rows[0].Contributions != 2 || rows[0].UserID == "" {
+		t.Errorf("expected Zero-Alice first with the signed-up account, got %+v", rows[0])
+	}
+	if rows[1].Username != "zero-bob" || rows[1].Rank != 2 || rows[1].Contributions != 1 {
+		t.Errorf("expected zero-bob second, got %+v", rows[1])
+	}
+	for i, want := range []string{"zero-carol", "zero-dave"} {
+		r := rows[2+i]
+		if r.Username != want || r.Contributions != 0 || r.UserID == "" || r.Rank <= rows[1].Rank {
+			t.Errorf("expected %s ranked after the contributors with 0 contributions, got %+v", want, r)
+		}
+	}
+
+	// The threshold still drops low contributors, but not the zero rows
+	rows = nil
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=zero-test&include_zero=true&min_contributions=2&search=zero-&limit=100", &rows)
+	got := make([]string, 0, len(rows))
+	for _, r := range rows {
+		got = append(got, r.Username)
+	}
+	if strings.Join(got, ",") != "Zero-Alice,zero-carol,zero-dave" {
+		t.Errorf("expected zero-alice and the zero rows, got %v", got)
+	}
+}
+
+func TestLeaderboard_Search_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture in its own ecosystem: srch-bob 3 contributions, find-carl 2, find-amy 1,
+	// so the global ranks are 1, 2, 3.
+	var userID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('search-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('search-test', 'Search Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'search-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'search-test/repo', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES
+  ($1, 9801, 1, 'open', 'srch-bob'),
+  ($1, 9802, 2, 'open', 'srch-bob'),
+  ($1, 9803, 3, 'open', 'srch-bob'),
+  ($1, 9804, 4, 'open', 'find-carl'),
+  ($1, 9805, 5, 'open', 'find-carl'),
+  ($1, 9806, 6, 'open', 'find-amy')`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/leaderboard", NewLeaderboardHandler(d, -1).Leaderboard())
+
+	type row struct {
+		Rank     int    `json:"rank"`
+		Username string `json:"username"`
+	}
+
+	var rows []row
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=search-test&search=FIND-", &rows)
+	if len(rows) != 2 || rows[0].Username != "find-carl" || rows[1].Username != "find-amy" {
+		t.Fatalf("expected find-carl and find-amy, got %+v", rows)
+	}
+	if rows[0].Rank != 2 || rows[1].Rank != 3 {
+		t.Errorf("expected global ranks 2 and 3, not filtered positions, got %+v", rows)
+	}
+
+	// Paging within the matches keeps the global rank too
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=search-test&search=find&offset=1", &rows)
+	if len(rows) != 1 || rows[0].Username != "find-amy" || rows[0].Rank != 3 {
+		t.Errorf("expected find-amy at rank 3 on the second page, got %+v", rows)
+	}
+
+	// LIKE wildcards in the prefix are matched literally
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=search-test&search=find_", &rows)
+	if len(rows) != 0 {
+		t.Errorf("expected no match for a literal underscore, got %+v", rows)
+	}
+
+	// Without a search the ranking is unchanged
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=search-test", &rows)
+	if len(rows) != 3 || rows[0].Username != "srch-bob" || rows[0].Rank != 1 {
+		t.Errorf("expected the full ranking, got %+v", rows)
+	}
+}
+
+func TestLeaderboard_RankFromWindow_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture in its own ecosystem: rank-u01 .. rank-u12 with 12 down to 1 contributions
+	var userID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('rank-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('rank-test', 'Rank Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'rank-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'rank-test/repo', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+SELECT $1, 9600 + n * 100 + k, n * 100 + k, 'open', 'rank-u' || LPAD(n::text, 2, '0')
+FROM generate_series(1, 12) n, generate_series(1, 13 - n) k`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/leaderboard", NewLeaderboardHandler(d, -1).Leaderboard())
+
+	type row struct {
+		Rank     int    `json:"rank"`
+		Username string `json:"username"`
+	}
+	// checkAgainstFull compares a page at offset 10 with the whole ranking
+	checkAgainstFull := func(want ...string) {
+		t.Helper()
+		var all, page []row
+		getLeaderboardJSON(t, app, "/leaderboard?ecosystem=rank-test&limit=100", &all)
+		getLeaderboardJSON(t, app, "/leaderboard?ecosystem=rank-test&limit=5&offset=10", &page)
+		if len(page) != len(want) {
+			t.Fatalf("expected %v on the page, got %+v", want, page)
+		}
+		for i, r := range page {
+			if r.Username != want[i] {
+				t.Errorf("row %d: expected %s, got %+v", i, want[i], r)
+			}
+			pos := -1
+			for j, a := range all {
+				if a.Username == r.Username {
+					pos = j + 1
+				}
+			}
+			if r.Rank != pos {
+				t.Errorf("%s: rank %d on the page, but at position %d of the full ranking", r.Username, r.Rank, pos)
+			}
+		}
+	}
+	checkAgainstFull("rank-u11", "rank-u12")
+
+	// A sync between page requests moves rank-u12 to the top; the next page still
+	// reports ranks from the current data rather than from its offset.
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+SELECT $1, 9500 + k, 9500 + k, 'open', 'rank-u12' FROM generate_series(1, 20) k`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	checkAgainstFull("rank-u10", "rank-u11")
+}
+
+func TestLeaderboard_TieBreak_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Five contributors with one contribution each. tie-e contributed first and tie-a last.
+	var userID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('tie-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('tie-test', 'Tie Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'tie-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'tie-test/repo', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login, created_at_github)
+VALUES
+  ($1, 9701, 1, 'open', 'tie-c', now() - interval '3 days'),
+  ($1, 9702, 2, 'open', 'tie-a', now() - interval '1 day'),
+  ($1, 9703, 3, 'open', 'tie-e', now() - interval '5 days'),
+  ($1, 9704, 4, 'open', 'tie-b', now() - interval '2 days'),
+  ($1, 9705, 5, 'open', 'tie-d', now() - interval '4 days')`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+
+	// pages fetches two consecutive pages of three and returns the usernames in order.
+	pages := func(h *LeaderboardHandler) []string {
+		app := fiber.New()
+		app.Get("/leaderboard", h.Leaderboard())
+		var out []string
+		for _, offset := range []string{"0", "3"} {
+			var rows []struct {
+				Username string `json:"username"`
+			}
+			getLeaderboardJSON(t, app, "/leaderboard?ecosystem=tie-test&limit=3&offset="+offset, &rows)
+			for _, r := range rows {
+				out = append(out, r.Username)
+			}
+		}
+		return out
+	}
+
+	h := NewLeaderboardHandler(d, -1)
+	if got := strings.Join(pages(h), ","); got != "tie-a,tie-b,tie-c,tie-d,tie-e" {
+		t.Errorf("alphabetical: expected every contributor exactly once in login order, got %s", got)
+	}
+	// Repeated requests return the same order
+	if got := strings.Join(pages(h), ","); got != "tie-a,tie-b,tie-c,tie-d,tie-e" {
+		t.Errorf("alphabetical: order changed between requests, got %s", got)
+	}
+
+	h.SetTieBreak(TieBreakFirstContribution)
+	if got := strings.Join(pages(h), ","); got != "tie-e,tie-d,tie-c,tie-b,tie-a" {
+		t.Errorf("first_contribution: expected earliest contributors first, got %s", got)
+	}
+}
+
+func TestLeaderboard_CaseVariantLogins_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// One person under three casings of their login (4 contributions, most recently as
+	// CASE-DEV), and case-other with 2.
+	var ownerID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('case-test') RETURNING id::text`).Scan(&ownerID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('case-test', 'Case Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	var devID string
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'case-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, ownerID)
+		if devID != "" {
+			_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, devID)
+		}
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'case-test/repo', 'verified', $2)
+RETURNING id::text`, ownerID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login, created_at_github)
+VALUES
+  ($1, 9881, 1, 'open', 'Case-Dev', now() - interval '30 days'),
+  ($1, 9882, 2, 'open', 'case-dev', now() - interval '20 days'),
+  ($1, 9883, 3, 'open', 'case-other', now() - interval '10 days'),
+  ($1, 9884, 4, 'open', 'case-other', now() - interval '9 days')`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, author_login, created_at_github)
+VALUES
+  ($1, 9885, 5, 'open', 'case-dev', now() - interval '15 days'),
+  ($1, 9886, 6, 'open', 'CASE-DEV', now() - interval '1 day')`, projectID); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/leaderboard", NewLeaderboardHandler(d, -1).Leaderboard())
+
+	type row struct {
+		Rank          int    `json:"rank"`
+		Username      string `json:"username"`
+		UserID        string `json:"user_id"`
+		Contributions int    `json:"contributions"`
+	}
+	want := func(rows []row, username, userID string) {
+		t.Helper()
+		if len(rows) != 2 {
+			t.Fatalf("expected each person once, got %+v", rows)
+		}
+		if rows[0] != (row{Rank: 1, Username: username, UserID: userID, Contributions: 4}) {
+			t.Errorf("expected %s first with all 4 contributions, got %+v", username, rows[0])
+		}
+		if rows[1] != (row{Rank: 2, Username: "case-other", Contributions: 2}) {
+			t.Errorf("expected case-other second, got %+v", rows[1])
+		}
+	}
+
+	// Not signed up: the most recent casing is shown
+	var rows []row
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=case-test", &rows)
+	want(rows, "CASE-DEV", "")
+
+	// Signed up: the account's casing is shown
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('case-dev') RETURNING id::text`).Scan(&devID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_accounts (user_id, github_user_id, login, access_token)
+VALUES ($1, 9880001, 'Case-Dev', '\x00')`, devID); err != nil {
+		t.Fatalf("insert github account: %v", err)
+	}
+	rows = nil
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=case-test", &rows)
+	want(rows, "Case-Dev", devID)
+}
+
+func TestProjectEcosystemHasTechnologySQL(t *testing.T) {
+	q := projectEcosystemHasTechnologySQL("p.id", 3)
+	if !strings.Contains(q, "pe_t.project_id = p.id") {
+		t.Errorf("expected the project join, got %s", q)
+	}
+	if !strings.Contains(q, "LOWER(lang->>'name') = LOWER($3)") {
+		t.Errorf("expected a case-insensitive match on placeholder $3, got %s", q)
+	}
+}
+
+func TestProjectsLeaderboard_Technology_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture:
+	//   tech-test/rusty (verified, ecosystem listing Rust and Go)
+	//   tech-test/plain (verified, ecosystem listing TypeScript)
+	var userID, ecoRust, ecoTS string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('technology-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO ecosystems (slug, name, languages)
+VALUES ('tech-rust', 'Tech Rust', '[{"name":"Rust","percentage":80},{"name":"Go","percentage":20}]')
+RETURNING id::text`).Scan(&ecoRust); err != nil {
+		t.Fatalf("insert rust ecosystem: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO ecosystems (slug, name, languages)
+VALUES ('tech-ts', 'Tech TS', '[{"name":"TypeScript","percentage":100}]')
+RETURNING id::text`).Scan(&ecoTS); err != nil {
+		t.Fatalf("insert ts ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'tech-test/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id IN ($1, $2)`, ecoRust, ecoTS)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	for i, p := range []struct{ name, ecosystemID string }{
+		{"tech-test/rusty", ecoRust},
+		{"tech-test/plain", ecoTS},
+	} {
+		var projectID string
+		if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, $2, 'verified', $3)
+RETURNING id::text`, userID, p.name, p.ecosystemID).Scan(&projectID); err != nil {
+			t.Fatalf("insert project %s: %v", p.name, err)
+		}
+		if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, $2, 1, 'open', 'tech-dave')`, projectID, 9301+i); err != nil {
+			t.Fatalf("insert issue for %s: %v", p.name, err)
+		}
+	}
+
+	h := NewLeaderboardHandler(d, -1)
+	app := fiber.New()
+	app.Get("/leaderboard/projects", h.ProjectsLeaderboard())
+
+	type row struct {
+		FullName string `json:"full_name"`
+	}
+	names := func(path string) []string {
+		var rows []row
+		getLeaderboardJSON(t, app, path, &rows)
+		var out []string
+		for _, r := range rows {
+			if strings.HasPrefix(r.FullName, "tech-test/") {
+				out = append(out, r.FullName)
+			}
+		}
+		return out
+	}
+
+	for _, tech := range []string{"rust", "RUST", "go"} {
+		if got := names("/leaderboard/projects?limit=100&technology=" + tech); len(got) != 1 || got[0] != "tech-test/rusty" {
+			t.Errorf("technology=%s: expected only tech-test/rusty, got %v", tech, got)
+		}
+	}
+	if got := names("/leaderboard/projects?limit=100&technology=typescript"); len(got) != 1 || got[0] != "tech-test/plain" {
+		t.Errorf("technology=typescript: expected only tech-test/plain, got %v", got)
+	}
+	// Containment is per language name, not a substring match
+	if got := names("/leaderboard/projects?limit=100&technology=rus"); len(got) != 0 {
+		t.Errorf("technology=rus: expected no projects, got %v", got)
+	}
+
+	var rows []row
+	getLeaderboardJSON(t, app, "/leaderboard/projects?technology=cobol", &rows)
+	if rows == nil || len(rows) != 0 {
+		t.Errorf("expected an empty array for an unknown technology, got %+v", rows)
+	}
+}
+
+func TestRankTierCaseSQL(t *testing.T) {
+	absolute := rankTierCaseSQL(RankTierModeAbsolute, "r", "n")
+	for _, want := range []string{"WHEN r BETWEEN 1 AND 5 THEN 'conqueror'", "WHEN r BETWEEN 101 AND 500 THEN 'silver'", "WHEN r >= 501 THEN 'bronze'"} {
+		if !strings.Contains(absolute, want) {
+			t.Errorf("expected %q in:\n%s", want, absolute)
+		}
+	}
+	percentile := rankTierCaseSQL(RankTierModePercentile, "r", "n")
+	for _, want := range []string{"WHEN r * 100.0 / n <= 1 THEN 'conqueror'", "WHEN r * 100.0 / n <= 100 THEN 'bronze'"} {
+		if !strings.Contains(percentile, want) {
+			t.Errorf("expected %q in:\n%s", want, percentile)
+		}
+	}
+}
+
+func TestLeaderboard_TierDistribution_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture in its own ecosystem: tier-u01 .. tier-u25 with 25 down to 1 contributions
+	var userID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('tier-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('tier-test', 'Tier Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'tier-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'tier-test/repo', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+SELECT $1, 30000 + n * 100 + k, n * 100 + k, 'open', 'tier-u' || LPAD(n::text, 2, '0')
+FROM generate_series(1, 25) n, generate_series(1, 26 - n) k`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/leaderboard/tiers", NewLeaderboardHandler(d, -1).TierDistribution())
+
+	type bucket struct {
+		Tier     string `json:"tier"`
+		TierName string `json:"tier_name"`
+		Count    int    `json:"count"`
+	}
+	check := func(path string, want map[RankTier]int) {
+		t.Helper()
+		var got []bucket
+		getLeaderboardJSON(t, app, path, &got)
+		bands := RankTierBands()
+		if len(got) != len(bands) {
+			t.Fatalf("%s: expected %d tiers, got %+v", path, len(bands), got)
+		}
+		for i, b := range got {
+			if b.Tier != string(bands[i].Tier) || b.TierName != GetRankTierDisplayName(bands[i].Tier) {
+				t.Errorf("%s: tier %d: expected %s, got %+v", path, i, bands[i].Tier, b)
+			}
+			if b.Count != want[bands[i].Tier] {
+				t.Errorf("%s: %s: expected %d contributors, got %d", path, b.Tier, want[bands[i].Tier], b.Count)
+			}
+		}
+	}
+
+	check("/leaderboard/tiers?ecosystem=tier-test", map[RankTier]int{
+		RankConqueror: 5, RankAce: 5, RankCrown: 10, RankDiamond: 5,
+	})
+	// Positions 1..25 are 4%, 8%, ... of the population
+	check("/leaderboard/tiers?ecosystem=tier-test&tier_mode=percentile", map[RankTier]int{
+		RankAce: 1, RankCrown: 1, RankDiamond: 4, RankGold: 6, RankSilver: 6, RankBronze: 7,
+	})
+	// Only tier-u01 .. tier-u06 have at least 20 contributions
+	check("/leaderboard/tiers?ecosystem=tier-test&min_contributions=20", map[RankTier]int{
+		RankConqueror: 5, RankAce: 1,
+	})
+}
+
+// withTestRole stands in for auth.OptionalAuth, taking the role from X-Test-Role
+func withTestRole(c *fiber.Ctx) error {
+	if role := c.Get("X-Test-Role"); role != "" {
+		c.Locals(auth.LocalRole, role)
+	}
+	return c.Next()
+}
+
+func TestProjectsLeaderboard_StatusRequiresAdmin(t *testing.T) {
+	app := fiber.New()
+	app.Get("/leaderboard/projects", withTestRole, NewLeaderboardHandler(nil, 0).ProjectsLeaderboard())
+
+	for _, tc := range []struct {
+		path, role string
+		want       int
+	}{
+		{"/leaderboard/projects?status=bogus", "admin", fiber.StatusBadRequest},
+		{"/leaderboard/projects?status=all", "", fiber.StatusForbidden},
+		{"/leaderboard/projects?status=pending", "user", fiber.StatusForbidden},
+		// Past the status checks, the missing DB answers
+		{"/leaderboard/projects?status=verified", "", fiber.StatusServiceUnavailable},
+		{"/leaderboard/projects?status=ALL", "admin", fiber.StatusServiceUnavailable},
+	} {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Header.Set("X-Test-Role", tc.role)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", tc.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("GET %s as %q: expected %d, got %d", tc.path, tc.role, tc.want, resp.StatusCode)
+		}
+	}
+}
+
+func TestProjectsLeaderboard_Status_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture in its own ecosystem: one verified, one pending and one rejected project.
+	// The pending one has two contributors, the others one each.
+	var userID, ecosystemID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('status-lb-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('status-lb-test', 'Status LB Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'status-lb-test/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	for i, p := range []struct {
+		status  string
+		authors []string
+	}{
+		{"verified", []string{"status-lb-alice"}},
+		{"pending_verification", []string{"status-lb-alice", "status-lb-bob"}},
+		{"rejected", []string{"status-lb-carol"}},
+	} {
+		var projectID string
+		if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id::text`, userID, "status-lb-test/"+p.status, p.status, ecosystemID).Scan(&projectID); err != nil {
+			t.Fatalf("insert %s project: %v", p.status, err)
+		}
+		for j, author := range p.authors {
+			if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, $2, $3, 'open', $4)`, projectID, 9870+10*i+j, j+1, author); err != nil {
+				t.Fatalf("insert issue for %s: %v", p.status, err)
+			}
+		}
+	}
+
+	app := fiber.New()
+	app.Get("/leaderboard/projects", withTestRole, NewLeaderboardHandler(d, -1).ProjectsLeaderboard())
+
+	type row struct {
+		Rank         int    `json:"rank"`
+		FullName     string `json:"full_name"`
+		Status       string `json:"status"`
+		Contributors int    `json:"contributors"`
+	}
+	get := func(path, role string) []row {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-Test-Role", role)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("GET %s as %q: expected 200, got %d", path, role, resp.StatusCode)
+		}
+		var rows []row
+		if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		return rows
+	}
+
+	// Public callers get the verified projects, as before
+	rows := get("/leaderboard/projects?ecosystem=status-lb-test", "")
+	if len(rows) != 1 || rows[0].FullName != "status-lb-test/verified" || rows[0].Status != "verified" {
+		t.Errorf("expected only the verified project, got %+v", rows)
+	}
+
+	// Admins can rank every status; counts come from each project's own contributions
+	rows = get("/leaderboard/projects?ecosystem=status-lb-test&status=all", "admin")
+	if len(rows) != 3 {
+		t.Fatalf("expected all 3 projects, got %+v", rows)
+	}
+	if rows[0].FullName != "status-lb-test/pending_verification" || rows[0].Rank != 1 || rows[0].Contributors != 2 || rows[0].Status != "pending_verification" {
+		t.Errorf("expected the pending project first with 2 contributors, got %+v", rows[0])
+	}
+	for _, r := range rows[1:] {
+		if r.Contributors != 1 {
+			t.Errorf("expected 1 contributor for %s, got %d", r.FullName, r.Contributors)
+		}
+	}
+
+	rows = get("/leaderboard/projects?ecosystem=status-lb-test&status=pending", "admin")
+	if len(rows) != 1 || rows[0].Status != "pending_verification" {
+		t.Errorf("expected only the pending project, got %+v", rows)
+	}
+}
+
+func TestMyEcosystems_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: a user linked to GitHub as Me-Alice, who has 2 contributions in me-eco-x
+	// and 1 in me-eco-y, and a user without a linked account.
+	var linkedID, unlinkedID, ecoX, ecoY string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('me-eco-linked') RETURNING id::text`).Scan(&linkedID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('me-eco-unlinked') RETURNING id::text`).Scan(&unlinkedID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('me-eco-x', 'Me Eco X') RETURNING id::text`).Scan(&ecoX); err != nil {
+		t.Fatalf("insert ecosystem x: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('me-eco-y', 'Me Eco Y') RETURNING id::text`).Scan(&ecoY); err != nil {
+		t.Fatalf("insert ecosystem y: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'me-eco-test/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id IN ($1, $2)`, ecoX, ecoY)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id IN ($1, $2)`, linkedID, unlinkedID)
+	})
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_accounts (user_id, github_user_id, login, access_token)
+VALUES ($1, 9880001, 'Me-Alice', '\x00')`, linkedID); err != nil {
+		t.Fatalf("insert github account: %v", err)
+	}
+	var projX, projY string
+	for _, p := range []struct {
+		name, ecosystemID string
+		id                *string
+	}{
+		{"me-eco-test/x", ecoX, &projX},
+		{"me-eco-test/y", ecoY, &projY},
+	} {
+		if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, $2, 'verified', $3)
+RETURNING id::text`, linkedID, p.name, p.ecosystemID).Scan(p.id); err != nil {
+			t.Fatalf("insert project %s: %v", p.name, err)
+		}
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, 9881, 1, 'open', 'me-alice'), ($2, 9882, 1, 'open', 'me-alice')`, projX, projY); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, author_login)
+VALUES ($1, 9883, 2, 'open', 'me-alice')`, projX); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	app := fiber.New()
+	// Stands in for auth.RequireAuth
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(auth.LocalUserID, c.Get("X-Test-User"))
+		return c.Next()
+	})
+	app.Get("/me/ecosystems", NewLeaderboardHandler(d, -1).MyEcosystems())
+
+	get := func(userID string) (int, []map[string]any) {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/me/ecosystems", nil)
+		req.Header.Set("X-Test-User", userID)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("GET /me/ecosystems failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var out []map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	status, rows := get(linkedID)
+	if status != fiber.StatusOK || len(rows) != 2 {
+		t.Fatalf("expected 2 ecosystems, got %d: %v", status, rows)
+	}
+	if rows[0]["slug"] != "me-eco-x" || rows[0]["ecosystem"] != "Me Eco X" || rows[0]["contributions"] != float64(2) {
+		t.Errorf("expected me-eco-x with 2 contributions first, got %v", rows[0])
+	}
+	if rows[1]["slug"] != "me-eco-y" || rows[1]["contributions"] != float64(1) {
+		t.Errorf("expected me-eco-y with 1 contribution, got %v", rows[1])
+	}
+
+	// No linked GitHub account: an empty array, not an error
+	status, rows = get(unlinkedID)
+	if status != fiber.StatusOK || rows == nil || len(rows) != 0 {
+		t.Errorf("expected 200 with an empty array, got %d: %v", status, rows)
+	}
+
+	if status, _ := get("not-a-uuid"); status != fiber.StatusUnauthorized {
+		t.Errorf("expected 401 for an invalid session user, got %d", status)
+	}
+}
+
+func TestWalletProfile_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: an EVM wallet whose user is linked to GitHub as Wallet-Alice, with 2
+	// contributions in wallet-eco, and a Stellar wallet whose user has no GitHub account
+	const (
+		evmAddress     = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+		stellarAddress = "GWALLETPROFILETESTSTELLARKEY"
+	)
+	var linkedID, noGitHubID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('wallet-profile-linked') RETURNING id::text`).Scan(&linkedID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('wallet-profile-no-github') RETURNING id::text`).Scan(&noGitHubID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('wallet-eco', 'Wallet Eco') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'wallet-profile/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id IN ($1, $2)`, linkedID, noGitHubID)
+	})
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO wallets (user_id, wallet_type, address)
+VALUES ($1, 'evm', $2), ($3, 'stellar_ed25519', $4)`,
+		linkedID, strings.ToLower(evmAddress), noGitHubID, strings.ToLower(stellarAddress)); err != nil {
+		t.Fatalf("insert wallets: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_accounts (user_id, github_user_id, login, access_token)
+VALUES ($1, 9890001, 'Wallet-Alice', '\x00')`, linkedID); err != nil {
+		t.Fatalf("insert github account: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'wallet-profile/x', 'verified', $2)
+RETURNING id::text`, linkedID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, 9891, 1, 'open', 'wallet-alice'), ($1, 9892, 2, 'open', 'wallet-alice')`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/wallets/:address/profile", NewLeaderboardHandler(d, -1).WalletProfile())
+	get := func(path string) (int, map[string]any) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var out map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	// A checksummed address resolves to the lowercase one stored on login
+	status, body := get("/wallets/" + evmAddress + "/profile")
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200 for a linked wallet, got %d: %v", status, body)
+	}
+	if body["user_id"] != linkedID || body["wallet_type"] != "evm" || body["github_login"] != "Wallet-Alice" {
+		t.Errorf("expected the linked user and login, got %v", body)
+	}
+	if rank, _ := body["rank"].(float64); rank < 1 || body["contributions"] != float64(2) || body["rank_tier"] == string(RankTierUnranked) {
+		t.Errorf("expected a ranked contributor with 2 contributions, got %v", body)
+	}
+	if ecosystems, _ := body["ecosystems"].([]any); len(ecosystems) != 1 || ecosystems[0] != "Wallet Eco" {
+		t.Errorf("expected ecosystems [Wallet Eco], got %v", body["ecosystems"])
+	}
+
+	// Linked to a user, but there is no login to rank
+	status, body = get("/wallets/" + stellarAddress + "/profile")
+	if status != fiber.StatusOK || body["user_id"] != noGitHubID || body["github_login"] != nil || body["rank"] != nil {
+		t.Errorf("expected an unranked profile without a login, got %d: %v", status, body)
+	}
+	if body["wallet_type"] != "stellar_ed25519" || body["rank_tier"] != string(RankTierUnranked) || body["contributions"] != float64(0) {
+		t.Errorf("expected an unranked stellar wallet, got %v", body)
+	}
+
+	if status, body := get("/wallets/0x0000000000000000000000000000000000000001/profile"); status != fiber.StatusNotFound || body["error"] != "wallet_not_linked" {
+		t.Errorf("expected 404 for an unlinked wallet, got %d: %v", status, body)
+	}
+	if status, body := get("/wallets/0x1234/profile"); status != fiber.StatusBadRequest || body["error"] != "invalid_address" {
+		t.Errorf("expected 400 for an invalid address, got %d: %v", status, body)
+	}
+	if status, body := get("/wallets/0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD/profile"); status != fiber.StatusBadRequest || body["error"] != "invalid_evm_checksum" {
+		t.Errorf("expected 400 for a bad checksum, got %d: %v", status, body)
+	}
+	if status, _ := get("/wallets/" + evmAddress + "/profile?wallet_type=bogus"); status != fiber.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown wallet type, got %d", status)
+	}
+}
+
+func TestLeaderboardRanks_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture in its own ecosystem: ranks-bob 3 contributions, ranks-carl 2, ranks-amy 1.
+	var userID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('ranks-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('ranks-test', 'Ranks Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'ranks-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'ranks-test/repo', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES
+  ($1, 9901, 1, 'open', 'ranks-bob'),
+  ($1, 9902, 2, 'open', 'ranks-bob'),
+  ($1, 9903, 3, 'open', 'ranks-bob'),
+  ($1, 9904, 4, 'open', 'ranks-carl'),
+  ($1, 9905, 5, 'open', 'ranks-carl'),
+  ($1, 9906, 6, 'open', 'ranks-amy')`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+
+	h := NewLeaderboardHandler(d, -1)
+	app := fiber.New()
+	app.Get("/leaderboard", h.Leaderboard())
+	app.Post("/leaderboard/ranks", h.Ranks())
+
+	type standing struct {
+		Rank          *int   `json:"rank"`
+		RankTier      string `json:"rank_tier"`
+		Contributions int    `json:"contributions"`
+	}
+	body := `{"usernames": ["ranks-amy", "RANKS-CARL", "ranks-nobody"]}`
+	req := httptest.NewRequest("POST", "/leaderboard/ranks?ecosystem=ranks-test", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("POST /leaderboard/ranks failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var got map[string]standing
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected an entry per username, got %+v", got)
+	}
+
+	// Each rank matches the one the leaderboard reports for that contributor alone
+	type row struct {
+		Rank          int    `json:"rank"`
+		Username      string `json:"username"`
+		RankTier      string `json:"rank_tier"`
+		Contributions int    `json:"contributions"`
+	}
+	for username, login := range map[string]string{"ranks-amy": "ranks-amy", "RANKS-CARL": "ranks-carl"} {
+		var rows []row
+		getLeaderboardJSON(t, app, "/leaderboard?ecosystem=ranks-test&search="+login, &rows)
+		if len(rows) != 1 || rows[0].Username != login {
+			t.Fatalf("expected %s alone on the leaderboard, got %+v", login, rows)
+		}
+		s := got[username]
+		if s.Rank == nil || *s.Rank != rows[0].Rank || s.RankTier != rows[0].RankTier || s.Contributions != rows[0].Contributions {
+			t.Errorf("%s: expected rank %d (%s, %d contributions), got %+v", username, rows[0].Rank, rows[0].RankTier, rows[0].Contributions, s)
+		}
+	}
+	if s := got["ranks-nobody"]; s.Rank != nil || s.RankTier != "unranked" || s.Contributions != 0 {
+		t.Errorf("expected ranks-nobody unranked, got %+v", s)
+	}
+
+	tooMany := make([]string, maxRankLookupUsernames+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf(`"ranks-%d"`, i)
+	}
+	for _, tc := range []struct {
+		body string
+		want string
+	}{
+		{`{"usernames": []}`, "usernames_required"},
+		{`{"usernames": [" "]}`, "usernames_required"},
+		{`{"usernames": "ranks-amy"}`, "invalid_json"},
+		{`{"usernames": [` + strings.Join(tooMany, ",") + `]}`, "too_many_usernames"},
+	} {
+		req := httptest.NewRequest("POST", "/leaderboard/ranks", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("POST /leaderboard/ranks failed: %v", err)
+		}
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusBadRequest || e.Error != tc.want {
+			t.Errorf("expected 400 %s, got %d %q", tc.want, resp.StatusCode, e.Error)
+		}
+	}
+}
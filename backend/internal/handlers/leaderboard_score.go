@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// scoreRecentWindowSQL is how far back a contribution counts as recent for scoring.
+const scoreRecentWindowSQL = "interval '30 days'"
+
+// scoreRecentWindowDays mirrors scoreRecentWindowSQL for API responses.
+const scoreRecentWindowDays = 30
+
+// ScoreConfig weights the signals combined into leaderboard scores. Scores are reported
+// next to the ranking but don't reorder it: contributors stay ranked by contribution
+// count and projects by their ?sort= key.
+type ScoreConfig struct {
+	Contributor ContributorScoreWeights `json:"contributor"`
+	Project     ProjectScoreWeights     `json:"project"`
+}
+
+// ContributorScoreWeights weights a contributor's counted issues and PRs (under the
+// leaderboard filter), merged PRs (a bonus on top of PullRequests), distinct
+// ecosystems and contributions in the recent window.
+type ContributorScoreWeights struct {
+	Issues              float64 `json:"issues"`
+	PullRequests        float64 `json:"pull_requests"`
+	MergedPullRequests  float64 `json:"merged_pull_requests"`
+	Ecosystems          float64 `json:"ecosystems"`
+	RecentContributions float64 `json:"recent_contributions"`
+}
+
+// ProjectScoreWeights weights a project's distinct contributors, total issues and PRs,
+// and contributions in the recent window.
+type ProjectScoreWeights struct {
+	Contributors        float64 `json:"contributors"`
+	Contributions       float64 `json:"contributions"`
+	RecentContributions float64 `json:"recent_contributions"`
+}
+
+// DefaultScoreConfig reproduces the original scores: a contributor's contribution
+// count and ten points per project contributor.
+func DefaultScoreConfig() ScoreConfig {
+	return ScoreConfig{
+		Contributor: ContributorScoreWeights{Issues: 1, PullRequests: 1},
+		Project:     ProjectScoreWeights{Contributors: 10},
+	}
+}
+
+// ParseScoreConfig reads a JSON ScoreConfig (e.g. from LEADERBOARD_SCORE_WEIGHTS).
+// Weights left out keep their defaults; an empty string yields DefaultScoreConfig.
+func ParseScoreConfig(s string) (ScoreConfig, error) {
+	cfg := DefaultScoreConfig()
+	if strings.TrimSpace(s) == "" {
+		return cfg, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return DefaultScoreConfig(), err
+	}
+	return cfg, nil
+}
+
+// contributorScoreComponents are the raw signals behind a contributor's score.
+type contributorScoreComponents struct {
+	Issues              int `json:"issues"`
+	PullRequests        int `json:"pull_requests"`
+	MergedPullRequests  int `json:"merged_pull_requests"`
+	Ecosystems          int `json:"ecosystems"`
+	RecentContributions int `json:"recent_contributions"`
+}
+
+func (c contributorScoreComponents) score(w ContributorScoreWeights) int {
+	return int(math.Round(w.Issues*float64(c.Issues) +
+		w.PullRequests*float64(c.PullRequests) +
+		w.MergedPullRequests*float64(c.MergedPullRequests) +
+		w.Ecosystems*float64(c.Ecosystems) +
+		w.RecentContributions*float64(c.RecentContributions)))
+}
+
+// projectScoreComponents are the raw signals behind a project's score.
+type projectScoreComponents struct {
+	Contributors        int `json:"contributors"`
+	Contributions       int `json:"contributions"`
+	RecentContributions int `json:"recent_contributions"`
+}
+
+func (c projectScoreComponents) score(w ProjectScoreWeights) int {
+	return int(math.Round(w.Contributors*float64(c.Contributors) +
+		w.Contributions*float64(c.Contributions) +
+		w.RecentContributions*float64(c.RecentContributions)))
+}
+
+// SetScoreConfig changes the score weights and drops cached pages scored with the old ones.
+func (h *LeaderboardHandler) SetScoreConfig(cfg ScoreConfig) {
+	h.scoring = cfg
+	h.cache.invalidate()
+}
+
+// Scoring returns the score weights in use, so clients can explain a score from the
+// score_components reported on each leaderboard row.
+func (h *LeaderboardHandler) Scoring() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"contributor":        h.scoring.Contributor,
+			"project":            h.scoring.Project,
+			"recent_window_days": scoreRecentWindowDays,
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestDefaultScoreConfig_ReproducesLegacyScores(t *testing.T) {
+	cfg := DefaultScoreConfig()
+
+	// Contributors used to score their contribution count (issues + counted PRs).
+	c := contributorScoreComponents{Issues: 3, PullRequests: 4, MergedPullRequests: 2, Ecosystems: 2, RecentContributions: 5}
+	if got := c.score(cfg.Contributor); got != 7 {
+		t.Errorf("contributor score = %d, want 7", got)
+	}
+
+	// Projects used to score ten points per contributor.
+	p := projectScoreComponents{Contributors: 12, Contributions: 80, RecentContributions: 9}
+	if got := p.score(cfg.Project); got != 120 {
+		t.Errorf("project score = %d, want 120", got)
+	}
+}
+
+func TestScoreComponents_CustomWeights(t *testing.T) {
+	c := contributorScoreComponents{Issues: 3, PullRequests: 4, MergedPullRequests: 2, Ecosystems: 2, RecentContributions: 5}
+	w := ContributorScoreWeights{Issues: 1, PullRequests: 2, MergedPullRequests: 3, Ecosystems: 5, RecentContributions: 0.5}
+	// 3 + 8 + 6 + 10 + 2.5 = 29.5, rounded half away from zero
+	if got := c.score(w); got != 30 {
+		t.Errorf("contributor score = %d, want 30", got)
+	}
+
+	p := projectScoreComponents{Contributors: 12, Contributions: 80, RecentContributions: 9}
+	pw := ProjectScoreWeights{Contributors: 5, Contributions: 0.25, RecentContributions: 2}
+	// 60 + 20 + 18
+	if got := p.score(pw); got != 98 {
+		t.Errorf("project score = %d, want 98", got)
+	}
+}
+
+func TestParseScoreConfig(t *testing.T) {
+	cfg, err := ParseScoreConfig("")
+	if err != nil || cfg != DefaultScoreConfig() {
+		t.Errorf("empty config should be the default, got %+v, %v", cfg, err)
+	}
+
+	cfg, err = ParseScoreConfig(`{"contributor":{"merged_pull_requests":2},"project":{"contributors":1}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := DefaultScoreConfig()
+	want.Contributor.MergedPullRequests = 2
+	want.Project.Contributors = 1
+	if cfg != want {
+		t.Errorf("expected omitted weights to keep their defaults, got %+v", cfg)
+	}
+
+	for _, bad := range []string{`{"contributor":{"stars":1}}`, `not json`} {
+		if cfg, err := ParseScoreConfig(bad); err == nil || cfg != DefaultScoreConfig() {
+			t.Errorf("%s: expected an error and the default config, got %+v, %v", bad, cfg, err)
+		}
+	}
+}
+
+func TestScoring_ReportsWeights(t *testing.T) {
+	h := NewLeaderboardHandler(nil, -1)
+	cfg := DefaultScoreConfig()
+	cfg.Contributor.Ecosystems = 3
+	h.SetScoreConfig(cfg)
+
+	app := fiber.New()
+	app.Get("/leaderboard/scoring", h.Scoring())
+
+	var out struct {
+		Contributor      ContributorScoreWeights `json:"contributor"`
+		Project          ProjectScoreWeights     `json:"project"`
+		RecentWindowDays int                     `json:"recent_window_days"`
+	}
+	getLeaderboardJSON(t, app, "/leaderboard/scoring", &out)
+	if out.Contributor != cfg.Contributor || out.Project != cfg.Project || out.RecentWindowDays != scoreRecentWindowDays {
+		t.Errorf("unexpected scoring response %+v", out)
+	}
+}
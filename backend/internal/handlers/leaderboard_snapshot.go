@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// leaderboardSnapshotTTL is how long a snapshot stays readable. Expired snapshots are
+// pruned whenever a new one is taken.
+const leaderboardSnapshotTTL = 24 * time.Hour
+
+// maxLeaderboardSnapshots caps the snapshots alive at once. Each one copies the whole
+// ranking, so creation is refused past the cap until older snapshots expire.
+const maxLeaderboardSnapshots = 50
+
+// errSnapshotNotFound is returned for a snapshot id that doesn't exist or has expired.
+var errSnapshotNotFound = errors.New("leaderboard snapshot not found")
+
+// contributorSnapshotColumns are the leaderboard_snapshot_entries columns in the order
+// of contributorLeaderboardSQL, so snapshot rows scan like live ones.
+const contributorSnapshotColumns = `username, avatar_url, user_id, contribution_count, ecosystems,
+  issues, merged_pull_requests, opened_pull_requests, recent_contributions, first_contribution_at`
+
+// CreateSnapshot freezes the contributor ranking for the request's filters (?ecosystem=,
+// ?pr_state=, ?min_contributions=, ?include_zero=) and returns its id. Passing it as
+// ?snapshot_id= to GET /leaderboard or /leaderboard/export reads that frozen ranking, so
+// every page of a long session agrees even while syncs change the live data. It is
+// mounted for admins only, and refuses with 429 once maxLeaderboardSnapshots are alive.
+func (h *LeaderboardHandler) CreateSnapshot() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		f := leaderboardFilterFromQuery(c)
+		f.TieBreak = h.tieBreak
+
+		ctx := c.Context()
+		tx, err := h.db.Pool.Begin(ctx)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_snapshot_failed"})
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		if _, err := tx.Exec(ctx, `DELETE FROM leaderboard_snapshots WHERE created_at < $1`, time.Now().Add(-leaderboardSnapshotTTL)); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_snapshot_failed"})
+		}
+
+		// Serialize creations so concurrent requests can't overshoot the cap
+		if _, err := tx.Exec(ctx, `LOCK TABLE leaderboard_snapshots IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_snapshot_failed"})
+		}
+		var live int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM leaderboard_snapshots`).Scan(&live); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_snapshot_failed"})
+		}
+		if live >= maxLeaderboardSnapshots {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "snapshot_limit_reached"})
+		}
+
+		var id uuid.UUID
+		var createdAt time.Time
+		if err := tx.QueryRow(ctx, `
+INSERT INTO leaderboard_snapshots (filter_key) VALUES ($1)
+RETURNING id, created_at`, f.cacheKey()).Scan(&id, &createdAt); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_snapshot_failed"})
+		}
+
+		query, args, argPos := contributorRankedQuery(f, nil)
+		tag, err := tx.Exec(ctx, fmt.Sprintf(`
+INSERT INTO leaderboard_snapshot_entries (snapshot_id, %s, global_rank)
+SELECT $%d::uuid, r.* FROM (%s) r`, contributorSnapshotColumns, argPos, query), append(args, id)...)
+		if err != nil {
+			slog.Error("failed to snapshot leaderboard",
+				"error", err,
+			)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_snapshot_failed"})
+		}
+		contributors := int(tag.RowsAffected())
+		if _, err := tx.Exec(ctx, `UPDATE leaderboard_snapshots SET contributors = $2 WHERE id = $1`, id, contributors); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_snapshot_failed"})
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "leaderboard_snapshot_failed"})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"snapshot_id":  id.String(),
+			"created_at":   createdAt,
+			"expires_at":   createdAt.Add(leaderboardSnapshotTTL),
+			"contributors": contributors,
+		})
+	}
+}
+
+// parseSnapshotID reads ?snapshot_id=. ok is false when it is set but not a UUID.
+func parseSnapshotID(c *fiber.Ctx) (id uuid.UUID, set, ok bool) {
+	raw := strings.TrimSpace(c.Query("snapshot_id"))
+	if raw == "" {
+		return uuid.Nil, false, true
+	}
+	id, err := uuid.Parse(raw)
+	return id, true, err == nil
+}
+
+// snapshotContributorCount returns how many contributors snapshot id holds, or
+// errSnapshotNotFound.
+func (h *LeaderboardHandler) snapshotContributorCount(ctx context.Context, id uuid.UUID) (int, error) {
+	var total int
+	err := h.db.QueryRowTimed(ctx, "leaderboard_snapshot", `
+SELECT contributors FROM leaderboard_snapshots
+WHERE id = $1 AND created_at >= $2`, id, time.Now().Add(-leaderboardSnapshotTTL)).Scan(&total)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, errSnapshotNotFound
+	}
+	return total, err
+}
+
+// snapshotPage returns one leaderboard page read from snapshot id. Snapshots never
+// change, so pages are cached like live ones; the filters of q are those the snapshot
+// was taken with and are ignored here.
+func (h *LeaderboardHandler) snapshotPage(ctx context.Context, id uuid.UUID, q contributorPageQuery) ([]fiber.Map, error) {
+	key := fmt.Sprintf("snapshot|%s|%d|%d|%s|%s|%d", id, q.limit, q.offset, q.tierMode, strings.ToLower(q.search), q.avatarSize)
+	leaderboard, err := h.cache.get(key, func() (any, error) {
+		return h.fetchSnapshotContributors(ctx, id, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return leaderboard.([]fiber.Map), nil
+}
+
+// fetchSnapshotContributors reads one page of snapshot id, ranked and searched like
+// fetchContributors reads the live leaderboard.
+func (h *LeaderboardHandler) fetchSnapshotContributors(ctx context.Context, id uuid.UUID, q contributorPageQuery) ([]fiber.Map, error) {
+	total, err := h.snapshotContributorCount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	where := "snapshot_id = $1"
+	args := []any{id}
+	if q.search != "" {
+		where += " AND username ILIKE $2 || '%'"
+		args = append(args, escapeLike(q.search))
+	}
+	query := fmt.Sprintf(`SELECT %s, global_rank
+FROM leaderboard_snapshot_entries
+WHERE %s
+ORDER BY global_rank, username
+LIMIT $%d OFFSET $%d`, contributorSnapshotColumns, where, len(args)+1, len(args)+2)
+	args = append(args, q.limit, q.offset)
+
+	rows, err := h.db.QueryTimed(ctx, "leaderboard_snapshot_page", query, args...)
+	if err != nil {
+		slog.Error("failed to read leaderboard snapshot",
+			"error", err,
+			"snapshot_id", id,
+		)
+		return nil, err
+	}
+	defer rows.Close()
+	return h.contributorRows(rows, q, total), nil
+}
+
+// snapshotExportQuery reads every row of snapshot id in rank order, shaped like
+// contributorLeaderboardSQL for the export writers.
+func snapshotExportQuery(id uuid.UUID) (string, []any) {
+	return fmt.Sprintf(`SELECT %s
+FROM leaderboard_snapshot_entries
+WHERE snapshot_id = $1
+ORDER BY global_rank, username`, contributorSnapshotColumns), []any{id}
+}
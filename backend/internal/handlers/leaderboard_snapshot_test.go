@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestSnapshotExportQuery(t *testing.T) {
+	id := uuid.New()
+	query, args := snapshotExportQuery(id)
+	if !strings.Contains(query, "WHERE snapshot_id = $1\nORDER BY global_rank, username") {
+		t.Errorf("expected rows of one snapshot in rank order:\n%s", query)
+	}
+	if strings.Contains(query, "global_rank\nFROM") {
+		t.Errorf("export rows must not carry the rank column:\n%s", query)
+	}
+	if len(args) != 1 || args[0] != id {
+		t.Errorf("expected the snapshot id as the only arg, got %v", args)
+	}
+}
+
+func TestLeaderboardSnapshot_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture in its own ecosystem: snap-bob 3 contributions, snap-carl 2, snap-amy 1.
+	var userID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('snapshot-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('snapshot-test', 'Snapshot Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM leaderboard_snapshots WHERE filter_key LIKE 'snapshot-test|%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'snapshot-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'snapshot-test/repo', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES
+  ($1, 9701, 1, 'open', 'snap-bob'),
+  ($1, 9702, 2, 'open', 'snap-bob'),
+  ($1, 9703, 3, 'open', 'snap-bob'),
+  ($1, 9704, 4, 'open', 'snap-carl'),
+  ($1, 9705, 5, 'open', 'snap-carl'),
+  ($1, 9706, 6, 'open', 'snap-amy')`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+
+	h := NewLeaderboardHandler(d, -1)
+	app := fiber.New()
+	app.Get("/leaderboard", h.Leaderboard())
+	app.Post("/leaderboard/snapshots", h.CreateSnapshot())
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/leaderboard/snapshots?ecosystem=snapshot-test", nil), -1)
+	if err != nil {
+		t.Fatalf("POST /leaderboard/snapshots failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	var created struct {
+		SnapshotID   string `json:"snapshot_id"`
+		Contributors int    `json:"contributors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.Contributors != 3 {
+		t.Fatalf("expected 3 contributors in the snapshot, got %+v", created)
+	}
+
+	// The live data changes between pages: snap-amy overtakes everyone
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES
+  ($1, 9707, 7, 'open', 'snap-amy'),
+  ($1, 9708, 8, 'open', 'snap-amy'),
+  ($1, 9709, 9, 'open', 'snap-amy'),
+  ($1, 9710, 10, 'open', 'snap-amy')`, projectID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+
+	type row struct {
+		Rank          int    `json:"rank"`
+		Username      string `json:"username"`
+		Contributions int    `json:"contributions"`
+	}
+
+	// Paging one row at a time through the snapshot sees the frozen ranking
+	want := []row{{1, "snap-bob", 3}, {2, "snap-carl", 2}, {3, "snap-amy", 1}}
+	for offset, w := range want {
+		var rows []row
+		getLeaderboardJSON(t, app, fmt.Sprintf("/leaderboard?limit=1&offset=%d&snapshot_id=%s", offset, created.SnapshotID), &rows)
+		if len(rows) != 1 || rows[0] != w {
+			t.Errorf("page %d: expected %+v, got %+v", offset, w, rows)
+		}
+	}
+
+	// Search keeps the snapshot's ranks
+	var rows []row
+	getLeaderboardJSON(t, app, "/leaderboard?search=SNAP-A&snapshot_id="+created.SnapshotID, &rows)
+	if len(rows) != 1 || rows[0] != (row{3, "snap-amy", 1}) {
+		t.Errorf("expected snap-amy at rank 3 in the snapshot, got %+v", rows)
+	}
+
+	// Without the snapshot the live ranking is read
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=snapshot-test", &rows)
+	if len(rows) != 3 || rows[0] != (row{1, "snap-amy", 5}) {
+		t.Errorf("expected snap-amy first on the live leaderboard, got %+v", rows)
+	}
+
+	for path, want := range map[string]int{
+		"/leaderboard?snapshot_id=not-a-uuid":             fiber.StatusBadRequest,
+		"/leaderboard?snapshot_id=" + uuid.New().String(): fiber.StatusNotFound,
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s: expected %d, got %d", path, want, resp.StatusCode)
+		}
+	}
+}
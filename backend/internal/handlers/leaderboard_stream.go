@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/broadcast"
+)
+
+// leaderboardStreamDebounce groups sync completions arriving close together into a
+// single refresh. A var so tests can shorten it.
+var leaderboardStreamDebounce = 2 * time.Second
+
+// leaderboardStreamHeartbeat is how often an idle stream sends a comment line. It keeps
+// proxies from closing the connection and notices disconnected clients.
+var leaderboardStreamHeartbeat = 15 * time.Second
+
+// leaderboardStreamFetchTimeout bounds each page query made for a stream.
+const leaderboardStreamFetchTimeout = 30 * time.Second
+
+// WatchSyncs refreshes the leaderboard (see InvalidateCache) after syncs notifies, until
+// ctx ends, then warms the cache again (see WarmCache). Notifications arriving within
+// leaderboardStreamDebounce of the first one are handled by the same refresh. Open
+// streams are closed when ctx ends, so call this before serving; syncs may be nil.
+func (h *LeaderboardHandler) WatchSyncs(ctx context.Context, syncs *broadcast.Signal) {
+	h.streamsDone = ctx.Done()
+	if syncs == nil {
+		return
+	}
+	completed, unsubscribe := syncs.Subscribe()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-completed:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(leaderboardStreamDebounce):
+			}
+			// Completions during the wait are covered by this refresh
+			select {
+			case <-completed:
+			default:
+			}
+			h.InvalidateCache()
+			h.warmAfterSync(ctx)
+		}
+	}()
+}
+
+// Stream serves the contributor leaderboard as Server-Sent Events. It accepts the same
+// query parameters as Leaderboard. On connect it sends the page as a "snapshot" event;
+// after each refresh it sends a "delta" event with the rows that are new or changed and
+// the usernames that left the page. Unchanged refreshes send nothing.
+func (h *LeaderboardHandler) Stream() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		q := contributorPageQueryFrom(c)
+		conn := c.Context().Conn()
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		c.Set("X-Accel-Buffering", "no") // don't let nginx buffer the stream
+
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			// Subscribe before the snapshot so a refresh in between isn't missed.
+			updates, unsubscribe := h.updates.Subscribe()
+			defer unsubscribe()
+
+			write := func(msg string) error {
+				// The server's write timeout is meant for ordinary responses; give each
+				// stream write its own deadline instead.
+				_ = conn.SetWriteDeadline(time.Now().Add(leaderboardStreamHeartbeat))
+				if _, err := w.WriteString(msg); err != nil {
+					return err
+				}
+				return w.Flush()
+			}
+			send := func(event string, payload any) error {
+				b, err := json.Marshal(payload)
+				if err != nil {
+					return err
+				}
+				return write(fmt.Sprintf("event: %s\ndata: %s\n\n", event, b))
+			}
+
+			page, err := h.streamPage(q)
+			if err != nil {
+				_ = send("error", fiber.Map{"error": "leaderboard_fetch_failed"})
+				return
+			}
+			if err := send("snapshot", page); err != nil {
+				return
+			}
+
+			heartbeat := time.NewTicker(leaderboardStreamHeartbeat)
+			defer heartbeat.Stop()
+			for {
+				select {
+				case <-h.streamsDone:
+					return
+				case <-updates:
+					next, err := h.streamPage(q)
+					if err != nil {
+						slog.Warn("failed to refresh leaderboard stream", "error", err)
+						continue
+					}
+					delta := diffLeaderboardPages(page, next)
+					page = next
+					if len(delta.Updated) == 0 && len(delta.Removed) == 0 {
+						continue
+					}
+					if err := send("delta", delta); err != nil {
+						return
+					}
+				case <-heartbeat.C:
+					if err := write(": ping\n\n"); err != nil {
+						return
+					}
+				}
+			}
+		})
+		return nil
+	}
+}
+
+func (h *LeaderboardHandler) streamPage(q contributorPageQuery) ([]fiber.Map, error) {
+	// The stream outlives the request context, so each query gets its own.
+	ctx, cancel := context.WithTimeout(context.Background(), leaderboardStreamFetchTimeout)
+	defer cancel()
+	return h.contributorPage(ctx, q)
+}
+
+// leaderboardDelta is the change between two versions of a leaderboard page.
+type leaderboardDelta struct {
+	Updated []fiber.Map `json:"updated"` // rows that are new or differ, in rank order
+	Removed []string    `json:"removed"` // usernames no longer on the page
+}
+
+// diffLeaderboardPages compares two contributor pages row by row, keyed by username.
+func diffLeaderboardPages(prev, next []fiber.Map) leaderboardDelta {
+	delta := leaderboardDelta{Updated: []fiber.Map{}, Removed: []string{}}
+
+	before := make(map[any]fiber.Map, len(prev))
+	for _, row := range prev {
+		before[row["username"]] = row
+	}
+	for _, row := range next {
+		old, ok := before[row["username"]]
+		if !ok || !reflect.DeepEqual(old, row) {
+			delta.Updated = append(delta.Updated, row)
+		}
+		delete(before, row["username"])
+	}
+	for _, row := range prev {
+		if _, gone := before[row["username"]]; gone {
+			delta.Removed = append(delta.Removed, fmt.Sprint(row["username"]))
+		}
+	}
+	return delta
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/broadcast"
+)
+
+func TestDiffLeaderboardPages(t *testing.T) {
+	prev := []fiber.Map{
+		{"username": "alice", "rank": 1, "contributions": 5},
+		{"username": "bob", "rank": 2, "contributions": 3},
+		{"username": "carol", "rank": 3, "contributions": 1},
+	}
+	next := []fiber.Map{
+		{"username": "bob", "rank": 1, "contributions": 6},
+		{"username": "alice", "rank": 2, "contributions": 5},
+		{"username": "dave", "rank": 3, "contributions": 2},
+	}
+
+	delta := diffLeaderboardPages(prev, next)
+	var updated []string
+	for _, row := range delta.Updated {
+		updated = append(updated, row["username"].(string))
+	}
+	if strings.Join(updated, ",") != "bob,alice,dave" {
+		t.Errorf("expected bob, alice and dave to be updated in rank order, got %v", updated)
+	}
+	if len(delta.Removed) != 1 || delta.Removed[0] != "carol" {
+		t.Errorf("expected carol to be removed, got %v", delta.Removed)
+	}
+
+	if d := diffLeaderboardPages(next, next); len(d.Updated) != 0 || len(d.Removed) != 0 {
+		t.Errorf("expected an empty delta for identical pages, got %+v", d)
+	}
+}
+
+func TestWatchSyncs_DebouncesAndStops(t *testing.T) {
+	old := leaderboardStreamDebounce
+	leaderboardStreamDebounce = 20 * time.Millisecond
+	t.Cleanup(func() { leaderboardStreamDebounce = old })
+
+	h := NewLeaderboardHandler(nil, -1)
+	updates, unsubscribe := h.updates.Subscribe()
+	defer unsubscribe()
+
+	syncs := broadcast.NewSignal()
+	ctx, cancel := context.WithCancel(context.Background())
+	h.WatchSyncs(ctx, syncs)
+
+	// A burst of completions produces a single refresh.
+	for i := 0; i < 5; i++ {
+		syncs.Notify()
+	}
+	select {
+	case <-updates:
+	case <-time.After(time.Second):
+		t.Fatal("expected a refresh after sync completions")
+	}
+	select {
+	case <-updates:
+		t.Error("expected the burst to be debounced into one refresh")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for syncs.Subscribers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("watcher goroutine did not stop after ctx was cancelled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStream_NoDBReturns503(t *testing.T) {
+	app := fiber.New()
+	app.Get("/leaderboard/stream", NewLeaderboardHandler(nil, 0).Stream())
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/leaderboard/stream", nil), -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+}
+
+// readSSEEvent reads lines until the next event of the given type and returns its data.
+func readSSEEvent(t *testing.T, r *bufio.Reader, event string) string {
+	t.Helper()
+	current := ""
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading %q event: %v", event, err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: ") && current == event:
+			return strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStream_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	oldHeartbeat := leaderboardStreamHeartbeat
+	leaderboardStreamHeartbeat = 20 * time.Millisecond
+	t.Cleanup(func() { leaderboardStreamHeartbeat = oldHeartbeat })
+
+	var userID, ecosystemID, projectID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('stream-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name) VALUES ('stream-test', 'Stream Test') RETURNING id::text`).Scan(&ecosystemID); err != nil {
+		t.Fatalf("insert ecosystem: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name = 'stream-test/repo'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE id = $1`, ecosystemID)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, 'stream-test/repo', 'verified', $2)
+RETURNING id::text`, userID, ecosystemID).Scan(&projectID); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, 9901, 1, 'open', 'stream-alice')`, projectID); err != nil {
+		t.Fatalf("insert issue: %v", err)
+	}
+
+	h := NewLeaderboardHandler(d, 0)
+	streamsCtx, stopStreams := context.WithCancel(context.Background())
+	defer stopStreams()
+	h.WatchSyncs(streamsCtx, nil)
+
+	app := fiber.New()
+	app.Get("/leaderboard/stream", h.Stream())
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/leaderboard/stream?ecosystem=stream-test")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %q", ct)
+	}
+	r := bufio.NewReader(resp.Body)
+
+	var snapshot []struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal([]byte(readSSEEvent(t, r, "snapshot")), &snapshot); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if len(snapshot) != 1 || snapshot[0].Username != "stream-alice" {
+		t.Fatalf("expected stream-alice in the snapshot, got %+v", snapshot)
+	}
+
+	// A new contributor shows up as a delta after the next refresh.
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, 9902, 2, 'open', 'stream-bob')`, projectID); err != nil {
+		t.Fatalf("insert issue: %v", err)
+	}
+	h.InvalidateCache()
+
+	var delta struct {
+		Updated []struct {
+			Username string `json:"username"`
+		} `json:"updated"`
+		Removed []string `json:"removed"`
+	}
+	if err := json.Unmarshal([]byte(readSSEEvent(t, r, "delta")), &delta); err != nil {
+		t.Fatalf("decode delta: %v", err)
+	}
+	if len(delta.Updated) != 1 || delta.Updated[0].Username != "stream-bob" || len(delta.Removed) != 0 {
+		t.Errorf("expected only stream-bob in the delta, got %+v", delta)
+	}
+
+	// Disconnecting ends the stream and its subscription.
+	resp.Body.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for h.updates.Subscribers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream did not unsubscribe after the client disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestProjectShortName(t *testing.T) {
+	cases := map[string]string{
+		"stellar/go":      "go",
+		"org/group/repo":  "repo",
+		"repo":            "repo",
+		"":                "",
+		"trailing-slash/": "",
+	}
+	for fullName, want := range cases {
+		if got := projectShortName(fullName); got != want {
+			t.Errorf("projectShortName(%q) = %q, want %q", fullName, got, want)
+		}
+	}
+}
+
+func TestProjectLogo(t *testing.T) {
+	cases := []struct {
+		fullName, want string
+	}{
+		{"owner/repo", "🔴"},
+		{"owner/Stellar", "⭐"},
+		{"gamekit", "🎮"},
+		{"", "📦"},
+		{"owner/42-tools", "📦"},
+	}
+	for _, tc := range cases {
+		if got := projectLogo(projectShortName(tc.fullName)); got != tc.want {
+			t.Errorf("projectLogo for %q = %q, want %q", tc.fullName, got, tc.want)
+		}
+	}
+}
+
+func TestTierDefinitions_Contiguous(t *testing.T) {
+	app := fiber.New()
+	app.Get("/leaderboard/tier-definitions", (&LeaderboardHandler{}).TierDefinitions())
+
+	get := func(path string, out any) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", path, resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+	}
+
+	var ranks []struct {
+		Tier        string `json:"tier"`
+		DisplayName string `json:"display_name"`
+		MinRank     int    `json:"min_rank"`
+		MaxRank     *int   `json:"max_rank"`
+	}
+	get("/leaderboard/tier-definitions", &ranks)
+	if len(ranks) != len(RankTierBands()) {
+		t.Fatalf("expected every tier band, got %+v", ranks)
+	}
+	next := 1
+	for i, d := range ranks {
+		if d.Tier != string(RankTierBands()[i].Tier) || d.DisplayName == "" {
+			t.Errorf("tier %d: expected %s with a display name, got %+v", i, RankTierBands()[i].Tier, d)
+		}
+		if d.MinRank != next {
+			t.Errorf("%s: expected to start at rank %d, got %d", d.Tier, next, d.MinRank)
+		}
+		if i == len(ranks)-1 {
+			if d.MaxRank != nil {
+				t.Errorf("%s: expected the last tier to be open-ended, got max %d", d.Tier, *d.MaxRank)
+			}
+			break
+		}
+		if d.MaxRank == nil || *d.MaxRank < d.MinRank {
+			t.Fatalf("%s: expected a max rank of at least %d, got %v", d.Tier, d.MinRank, d.MaxRank)
+		}
+		next = *d.MaxRank + 1
+	}
+
+	var percentiles []struct {
+		Tier          string  `json:"tier"`
+		MinPercentile float64 `json:"min_percentile"`
+		MaxPercentile float64 `json:"max_percentile"`
+	}
+	get("/leaderboard/tier-definitions?tier_mode=percentile", &percentiles)
+	if len(percentiles) != len(RankTierPercentileBands()) {
+		t.Fatalf("expected every percentile band, got %+v", percentiles)
+	}
+	prev := 0.0
+	for _, d := range percentiles {
+		if d.MinPercentile != prev || d.MaxPercentile <= d.MinPercentile {
+			t.Errorf("%s: expected (%g, >%g], got (%g, %g]", d.Tier, prev, prev, d.MinPercentile, d.MaxPercentile)
+		}
+		prev = d.MaxPercentile
+	}
+	if prev != 100 {
+		t.Errorf("expected the percentile tiers to reach 100, got %g", prev)
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// defaultLeaderboardWarmConcurrency is how many pages WarmCache computes at once when
+// no concurrency is configured.
+const defaultLeaderboardWarmConcurrency = 4
+
+// leaderboardWarmTimeout bounds the warming that follows a sync.
+const leaderboardWarmTimeout = 2 * time.Minute
+
+// leaderboardWarmPageLimit is the page size warmed: the default ?limit= of both leaderboards.
+const leaderboardWarmPageLimit = 10
+
+// SetWarmConcurrency sets how many leaderboard pages WarmCache computes at once. Zero
+// uses the default; negative disables warming after syncs (see WatchSyncs).
+func (h *LeaderboardHandler) SetWarmConcurrency(n int) {
+	if n == 0 {
+		n = defaultLeaderboardWarmConcurrency
+	}
+	h.warmConcurrency = n
+}
+
+// WarmCache computes and caches the first page of the contributor and project
+// leaderboards, as requested without any other parameter, globally and for each active
+// ecosystem, so landing pages don't pay for a cold cache after a sync. Pages are
+// computed at most warmConcurrency at a time. It returns the errors of the pages that
+// failed; the others stay cached.
+func (h *LeaderboardHandler) WarmCache(ctx context.Context) error {
+	if h.db == nil || h.db.Pool == nil {
+		return nil
+	}
+	slugs, err := h.activeEcosystemSlugs(ctx)
+	if err != nil {
+		return fmt.Errorf("list active ecosystems: %w", err)
+	}
+	orderBy, err := projectLeaderboardOrderBy("", "", h.tieBreak)
+	if err != nil {
+		return err
+	}
+
+	var pages []func() error
+	for _, slug := range append([]string{""}, slugs...) {
+		pages = append(pages,
+			func() error {
+				_, err := h.contributorPage(ctx, contributorPageQuery{
+					limit:      leaderboardWarmPageLimit,
+					filter:     leaderboardFilter{EcosystemSlug: slug, MinContributions: 1},
+					tierMode:   RankTierModeAbsolute,
+					avatarSize: defaultAvatarSize,
+				})
+				if err != nil {
+					return fmt.Errorf("contributors %q: %w", slug, err)
+				}
+				return nil
+			},
+			func() error {
+				if _, err := h.projectPage(ctx, slug, "", "verified", orderBy, leaderboardWarmPageLimit, 0); err != nil {
+					return fmt.Errorf("projects %q: %w", slug, err)
+				}
+				return nil
+			},
+		)
+	}
+
+	concurrency := h.warmConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	errs := make([]error, len(pages))
+	var wg sync.WaitGroup
+	for i, page := range pages {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = page()
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// warmAfterSync runs WarmCache after the cache was dropped for a sync, unless warming
+// is disabled.
+func (h *LeaderboardHandler) warmAfterSync(ctx context.Context) {
+	if h.warmConcurrency < 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, leaderboardWarmTimeout)
+	defer cancel()
+	start := time.Now()
+	if err := h.WarmCache(ctx); err != nil {
+		slog.Warn("failed to warm leaderboard cache",
+			"error", err,
+		)
+		return
+	}
+	slog.Info("leaderboard cache warmed",
+		"duration", time.Since(start),
+	)
+}
+
+// activeEcosystemSlugs lists the slugs of the ecosystems with public landing pages.
+func (h *LeaderboardHandler) activeEcosystemSlugs(ctx context.Context) ([]string, error) {
+	rows, err := h.db.QueryTimed(ctx, "leaderboard_warm_ecosystems", `
+SELECT slug
+FROM ecosystems
+WHERE status = 'active' AND deleted_at IS NULL
+ORDER BY slug
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, slug)
+	}
+	return slugs, rows.Err()
+}
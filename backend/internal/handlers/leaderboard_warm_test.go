@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWarmCache_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Fixture: two active ecosystems with a verified project each, and an inactive one
+	var userID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('warm-test') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'warm-test-%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM ecosystems WHERE slug LIKE 'warm-test-%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	for i, eco := range []struct{ slug, status string }{
+		{"warm-test-a", "active"},
+		{"warm-test-b", "active"},
+		{"warm-test-off", "inactive"},
+	} {
+		var ecosystemID, projectID string
+		if err := d.Pool.QueryRow(ctx, `INSERT INTO ecosystems (slug, name, status) VALUES ($1, $1, $2) RETURNING id::text`, eco.slug, eco.status).Scan(&ecosystemID); err != nil {
+			t.Fatalf("insert ecosystem %s: %v", eco.slug, err)
+		}
+		if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status, ecosystem_id)
+VALUES ($1, $2, 'verified', $3)
+RETURNING id::text`, userID, eco.slug+"/repo", ecosystemID).Scan(&projectID); err != nil {
+			t.Fatalf("insert project %s: %v", eco.slug, err)
+		}
+		if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, $2, 1, 'open', $3)`, projectID, 9400+i, eco.slug+"-alice"); err != nil {
+			t.Fatalf("insert issue %s: %v", eco.slug, err)
+		}
+	}
+
+	h := NewLeaderboardHandler(d, time.Minute)
+	h.SetWarmConcurrency(2)
+	if err := h.WarmCache(ctx); err != nil {
+		t.Fatalf("WarmCache failed: %v", err)
+	}
+
+	// Both leaderboards are cached for the global scope and each active ecosystem only
+	cached := func(prefix, slug string) bool {
+		h.cache.mu.Lock()
+		defer h.cache.mu.Unlock()
+		for key := range h.cache.entries {
+			if strings.HasPrefix(key, prefix+"|10|0|"+slug+"|") {
+				return true
+			}
+		}
+		return false
+	}
+	for _, slug := range []string{"", "warm-test-a", "warm-test-b"} {
+		for _, prefix := range []string{"contributors", "projects"} {
+			if !cached(prefix, slug) {
+				t.Errorf("expected a warmed %s page for ecosystem %q", prefix, slug)
+			}
+		}
+	}
+	for _, prefix := range []string{"contributors", "projects"} {
+		if cached(prefix, "warm-test-off") {
+			t.Errorf("expected no warmed %s page for the inactive ecosystem", prefix)
+		}
+	}
+
+	// The warmed pages are the ones default requests read: with the data gone, they
+	// are still served from the cache
+	if _, err := d.Pool.Exec(ctx, `DELETE FROM projects WHERE github_full_name LIKE 'warm-test-%'`); err != nil {
+		t.Fatalf("delete projects: %v", err)
+	}
+	app := fiber.New()
+	app.Get("/leaderboard", h.Leaderboard())
+	app.Get("/leaderboard/projects", h.ProjectsLeaderboard())
+
+	var contributors []struct {
+		Username string `json:"username"`
+	}
+	getLeaderboardJSON(t, app, "/leaderboard?ecosystem=warm-test-a", &contributors)
+	if len(contributors) != 1 || contributors[0].Username != "warm-test-a-alice" {
+		t.Errorf("expected the warmed contributor page, got %+v", contributors)
+	}
+	var projects []fiber.Map
+	getLeaderboardJSON(t, app, "/leaderboard/projects?ecosystem=warm-test-b", &projects)
+	if len(projects) != 1 {
+		t.Errorf("expected the warmed project page, got %+v", projects)
+	}
+}
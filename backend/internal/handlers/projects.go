@@ -71,6 +71,7 @@ SELECT id
 FROM ecosystems
 WHERE LOWER(TRIM(name)) = LOWER(TRIM($1))
   AND status = 'active'
+  AND deleted_at IS NULL
 `, ecosystemName).Scan(&ecosystemID)
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ecosystem_not_found", "message": "No active ecosystem found with that name. Please select from available ecosystems."})
@@ -432,6 +432,117 @@ LIMIT 50
 	}
 }
 
+// projectContributionsSQL lists one contribution row per issue and PR authored in
+// project $1, counted the same way as on the leaderboard.
+const projectContributionsSQL = `
+SELECT i.author_login AS login, 1 AS issues, 0 AS prs
+FROM github_issues i
+WHERE i.project_id = $1 AND i.author_login IS NOT NULL AND i.author_login != ''
+
+UNION ALL
+
+SELECT pr.author_login, 0, 1
+FROM github_pull_requests pr
+WHERE pr.project_id = $1 AND pr.author_login IS NOT NULL AND pr.author_login != ''
+`
+
+// Contributors ranks the people who opened issues or PRs in a verified project, the
+// per-project counterpart of the contributor leaderboard. Logins are matched
+// case-insensitively; contributors with equal counts share a rank.
+// Query parameters:
+//   - limit: max results (default 20, max 100)
+//   - offset: pagination offset (default 0)
+func (h *ProjectsPublicHandler) Contributors() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+		projectID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_project_id"})
+		}
+
+		limit := 20
+		if l := c.QueryInt("limit", 20); l > 0 && l <= 100 {
+			limit = l
+		}
+		offset := c.QueryInt("offset", 0)
+		if offset < 0 {
+			offset = 0
+		}
+
+		// Ensure project is verified and not deleted
+		var ok bool
+		if err := h.db.Pool.QueryRow(c.Context(), `
+SELECT EXISTS(
+  SELECT 1 FROM projects WHERE id=$1 AND status='verified' AND deleted_at IS NULL
+)
+`, projectID).Scan(&ok); err != nil || !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "project_not_found"})
+		}
+
+		var total int64
+		if err := h.db.QueryRowTimed(c.Context(), "project_contributors_count", `
+SELECT COUNT(DISTINCT LOWER(c.login)) FROM (`+projectContributionsSQL+`) c
+`, projectID).Scan(&total); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_contributors_failed"})
+		}
+
+		rows, err := h.db.QueryTimed(c.Context(), "project_contributors", `
+WITH per_login AS (
+  SELECT MIN(c.login) AS login, SUM(c.issues) AS issues, SUM(c.prs) AS prs
+  FROM (`+projectContributionsSQL+`) c
+  GROUP BY LOWER(c.login)
+)
+SELECT
+  pl.login,
+  COALESCE(ga.avatar_url, ''),
+  COALESCE(u.id::text, ''),
+  pl.issues + pl.prs AS contributions,
+  pl.issues,
+  pl.prs,
+  RANK() OVER (ORDER BY pl.issues + pl.prs DESC) AS rank
+FROM per_login pl
+LEFT JOIN github_accounts ga ON LOWER(ga.login) = LOWER(pl.login)
+LEFT JOIN users u ON ga.user_id = u.id
+ORDER BY contributions DESC, LOWER(pl.login) ASC
+LIMIT $2 OFFSET $3
+`, projectID, limit, offset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_contributors_failed"})
+		}
+		defer rows.Close()
+
+		out := []fiber.Map{}
+		for rows.Next() {
+			var login, avatarURL, userID string
+			var contributions, issues, prs, rank int64
+			if err := rows.Scan(&login, &avatarURL, &userID, &contributions, &issues, &prs, &rank); err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_contributors_failed"})
+			}
+			out = append(out, fiber.Map{
+				"rank":          rank,
+				"username":      login,
+				"avatar_url":    avatarURL,
+				"user_id":       userID,
+				"contributions": contributions,
+				"issues":        issues,
+				"prs":           prs,
+			})
+		}
+		if err := rows.Err(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "project_contributors_failed"})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"contributors": out,
+			"total":        total,
+			"limit":        limit,
+			"offset":       offset,
+		})
+	}
+}
+
 // List returns a filtered list of verified projects.
 // Query parameters:
 //   - ecosystem: filter by ecosystem name (case-insensitive)
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/config"
+)
+
+func TestProjectContributors_Integration(t *testing.T) {
+	d := newLeaderboardTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Fixture: one verified and one pending project owned by the same user
+	var userID, verifiedID, pendingID string
+	if err := d.Pool.QueryRow(ctx, `INSERT INTO users (display_name) VALUES ('project-contributors') RETURNING id::text`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = d.Pool.Exec(bg, `DELETE FROM projects WHERE github_full_name LIKE 'project-contributors/%'`)
+		_, _ = d.Pool.Exec(bg, `DELETE FROM users WHERE id = $1`, userID)
+	})
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status)
+VALUES ($1, 'project-contributors/verified', 'verified') RETURNING id::text`, userID).Scan(&verifiedID); err != nil {
+		t.Fatalf("insert verified project: %v", err)
+	}
+	if err := d.Pool.QueryRow(ctx, `
+INSERT INTO projects (owner_user_id, github_full_name, status)
+VALUES ($1, 'project-contributors/pending', 'pending_verification') RETURNING id::text`, userID).Scan(&pendingID); err != nil {
+		t.Fatalf("insert pending project: %v", err)
+	}
+
+	// alice: 2 issues + 1 PR (under two spellings), bob: 1 PR, carol: 1 issue
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_issues (project_id, github_issue_id, number, state, author_login)
+VALUES ($1, 9601, 1, 'open', 'pc-alice'), ($1, 9602, 2, 'open', 'PC-Alice'), ($1, 9603, 3, 'open', 'pc-carol')`, verifiedID); err != nil {
+		t.Fatalf("insert issues: %v", err)
+	}
+	if _, err := d.Pool.Exec(ctx, `
+INSERT INTO github_pull_requests (project_id, github_pr_id, number, state, author_login)
+VALUES ($1, 9601, 4, 'open', 'pc-alice'), ($1, 9602, 5, 'open', 'pc-bob')`, verifiedID); err != nil {
+		t.Fatalf("insert prs: %v", err)
+	}
+
+	h := NewProjectsPublicHandler(config.Config{}, d)
+	app := fiber.New()
+	app.Get("/projects/:id/contributors", h.Contributors())
+
+	get := func(path string) (int, map[string]any) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var out map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	status, body := get("/projects/" + verifiedID + "/contributors?limit=2")
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, body)
+	}
+	if body["total"] != float64(3) {
+		t.Errorf("expected total 3, got %v", body["total"])
+	}
+	list, _ := body["contributors"].([]any)
+	if len(list) != 2 {
+		t.Fatalf("expected 2 contributors on the page, got %v", body["contributors"])
+	}
+	first, _ := list[0].(map[string]any)
+	if first["username"] != "PC-Alice" && first["username"] != "pc-alice" {
+		t.Errorf("expected alice first, got %v", first)
+	}
+	if first["rank"] != float64(1) || first["contributions"] != float64(3) || first["issues"] != float64(2) || first["prs"] != float64(1) {
+		t.Errorf("unexpected counts for alice: %v", first)
+	}
+	// bob and carol tie on one contribution each and share rank 2
+	second, _ := list[1].(map[string]any)
+	if second["username"] != "pc-bob" || second["rank"] != float64(2) || second["prs"] != float64(1) {
+		t.Errorf("unexpected second contributor: %v", second)
+	}
+
+	status, body = get("/projects/" + verifiedID + "/contributors?limit=2&offset=2")
+	if list, _ := body["contributors"].([]any); status != fiber.StatusOK || len(list) != 1 {
+		t.Fatalf("expected the last contributor on page 2, got %d: %v", status, body)
+	}
+	if third, _ := body["contributors"].([]any)[0].(map[string]any); third["username"] != "pc-carol" || third["rank"] != float64(2) {
+		t.Errorf("expected carol sharing rank 2, got %v", third)
+	}
+
+	if status, body := get("/projects/" + pendingID + "/contributors"); status != fiber.StatusNotFound || body["error"] != "project_not_found" {
+		t.Errorf("unverified project: expected 404 project_not_found, got %d %v", status, body)
+	}
+	if status, body := get("/projects/not-a-uuid/contributors"); status != fiber.StatusBadRequest || body["error"] != "invalid_project_id" {
+		t.Errorf("bad id: expected 400 invalid_project_id, got %d %v", status, body)
+	}
+}
@@ -14,29 +14,103 @@ const (
 	RankTierUnranked RankTier = "unranked" // No contributions or not in ranking
 )
 
+// RankTierBand assigns a tier to a contiguous range of leaderboard positions.
+type RankTierBand struct {
+	Tier        RankTier `json:"tier"`
+	MinPosition int      `json:"min_position"` // inclusive
+	MaxPosition int      `json:"max_position"` // inclusive; 0 means no upper bound
+}
+
+// rankTierBands is the single source of truth for tier boundaries, ordered from
+// best to worst. Bands must be contiguous and start at position 1.
+var rankTierBands = []RankTierBand{
+	{Tier: RankConqueror, MinPosition: 1, MaxPosition: 5},
+	{Tier: RankAce, MinPosition: 6, MaxPosition: 10},
+	{Tier: RankCrown, MinPosition: 11, MaxPosition: 20},
+	{Tier: RankDiamond, MinPosition: 21, MaxPosition: 50},
+	{Tier: RankGold, MinPosition: 51, MaxPosition: 100},
+	{Tier: RankSilver, MinPosition: 101, MaxPosition: 500},
+	{Tier: RankBronze, MinPosition: 501, MaxPosition: 0},
+}
+
+// RankTierBands returns a copy of the tier band definitions (e.g. for a UI legend).
+func RankTierBands() []RankTierBand {
+	out := make([]RankTierBand, len(rankTierBands))
+	copy(out, rankTierBands)
+	return out
+}
+
 // GetRankTier returns the rank tier based on leaderboard position
 // Position is 1-indexed (1 = first place)
 func GetRankTier(position int) RankTier {
 	if position <= 0 {
 		return RankBronze
 	}
-	if position <= 5 {
-		return RankConqueror
+	for _, band := range rankTierBands {
+		if position >= band.MinPosition && (band.MaxPosition == 0 || position <= band.MaxPosition) {
+			return band.Tier
+		}
 	}
-	if position <= 10 {
-		return RankAce
-	}
-	if position <= 20 {
-		return RankCrown
+	return RankBronze
+}
+
+// RankTierMode selects how a leaderboard position is mapped to a tier.
+type RankTierMode string
+
+const (
+	RankTierModeAbsolute   RankTierMode = "absolute"   // fixed position cutoffs (rankTierBands)
+	RankTierModePercentile RankTierMode = "percentile" // share of the total contributor population
+)
+
+// ParseRankTierMode returns the mode named by s, falling back to absolute for anything unknown.
+func ParseRankTierMode(s string) RankTierMode {
+	if RankTierMode(s) == RankTierModePercentile {
+		return RankTierModePercentile
 	}
-	if position <= 50 {
-		return RankDiamond
+	return RankTierModeAbsolute
+}
+
+// RankTierPercentileBand assigns a tier to everyone within the top MaxPercentile
+// percent of contributors.
+type RankTierPercentileBand struct {
+	Tier          RankTier `json:"tier"`
+	MaxPercentile float64  `json:"max_percentile"` // inclusive; 100 covers everyone
+}
+
+// rankTierPercentileBands are the percentile cutoffs, ordered from best to worst.
+// The last band must reach 100.
+var rankTierPercentileBands = []RankTierPercentileBand{
+	{Tier: RankConqueror, MaxPercentile: 1},
+	{Tier: RankAce, MaxPercentile: 5},
+	{Tier: RankCrown, MaxPercentile: 10},
+	{Tier: RankDiamond, MaxPercentile: 25},
+	{Tier: RankGold, MaxPercentile: 50},
+	{Tier: RankSilver, MaxPercentile: 75},
+	{Tier: RankBronze, MaxPercentile: 100},
+}
+
+// RankTierPercentileBands returns a copy of the percentile band definitions.
+func RankTierPercentileBands() []RankTierPercentileBand {
+	out := make([]RankTierPercentileBand, len(rankTierPercentileBands))
+	copy(out, rankTierPercentileBands)
+	return out
+}
+
+// GetRankTierForMode returns the rank tier for a 1-indexed position out of total
+// ranked contributors. Percentile mode falls back to absolute cutoffs when the
+// population is unknown (total <= 0).
+func GetRankTierForMode(position, total int, mode RankTierMode) RankTier {
+	if mode != RankTierModePercentile || total <= 0 {
+		return GetRankTier(position)
 	}
-	if position <= 100 {
-		return RankGold
+	if position <= 0 || position > total {
+		return RankBronze
 	}
-	if position <= 500 {
-		return RankSilver
+	percentile := float64(position) * 100 / float64(total)
+	for _, band := range rankTierPercentileBands {
+		if percentile <= band.MaxPercentile {
+			return band.Tier
+		}
 	}
 	return RankBronze
 }
@@ -0,0 +1,106 @@
+package handlers
+
+import "testing"
+
+func TestGetRankTier_Boundaries(t *testing.T) {
+	cases := []struct {
+		position int
+		want     RankTier
+	}{
+		{-1, RankBronze},
+		{0, RankBronze},
+		{1, RankConqueror},
+		{5, RankConqueror},
+		{6, RankAce},
+		{10, RankAce},
+		{11, RankCrown},
+		{20, RankCrown},
+		{21, RankDiamond},
+		{50, RankDiamond},
+		{51, RankGold},
+		{100, RankGold},
+		{101, RankSilver},
+		{500, RankSilver},
+		{501, RankBronze},
+		{100000, RankBronze},
+	}
+	for _, tc := range cases {
+		if got := GetRankTier(tc.position); got != tc.want {
+			t.Errorf("GetRankTier(%d) = %s, want %s", tc.position, got, tc.want)
+		}
+	}
+}
+
+func TestRankTierBands_Contiguous(t *testing.T) {
+	bands := RankTierBands()
+	if len(bands) == 0 {
+		t.Fatal("expected at least one band")
+	}
+	if bands[0].MinPosition != 1 {
+		t.Errorf("first band should start at 1, got %d", bands[0].MinPosition)
+	}
+	for i := 1; i < len(bands); i++ {
+		if bands[i].MinPosition != bands[i-1].MaxPosition+1 {
+			t.Errorf("band %d (%s) starts at %d, expected %d", i, bands[i].Tier, bands[i].MinPosition, bands[i-1].MaxPosition+1)
+		}
+	}
+	if last := bands[len(bands)-1]; last.MaxPosition != 0 {
+		t.Errorf("last band should be open-ended, got max %d", last.MaxPosition)
+	}
+
+	// Callers get a copy, not the package table.
+	bands[0].MaxPosition = 999
+	if GetRankTier(6) != RankAce {
+		t.Error("mutating RankTierBands() result should not affect GetRankTier")
+	}
+}
+
+func TestGetRankTierForMode_PercentileDependsOnPopulation(t *testing.T) {
+	cases := []struct {
+		position int
+		total    int
+		want     RankTier
+	}{
+		// Rank 10 is Conqueror among 1000 contributors but only Gold among 20.
+		{10, 1000, RankConqueror},
+		{10, 20, RankGold},
+		{10, 10, RankBronze},
+		// A small ecosystem still has someone in the top tiers.
+		{1, 20, RankAce},
+		{1, 100, RankConqueror},
+		{2, 100, RankAce},
+		{50, 100, RankGold},
+		{51, 100, RankSilver},
+		{100, 100, RankBronze},
+		// Out-of-range positions.
+		{0, 100, RankBronze},
+		{101, 100, RankBronze},
+	}
+	for _, tc := range cases {
+		if got := GetRankTierForMode(tc.position, tc.total, RankTierModePercentile); got != tc.want {
+			t.Errorf("GetRankTierForMode(%d, %d, percentile) = %s, want %s", tc.position, tc.total, got, tc.want)
+		}
+	}
+}
+
+func TestGetRankTierForMode_AbsoluteFallback(t *testing.T) {
+	// Absolute mode ignores the population.
+	if got := GetRankTierForMode(10, 20, RankTierModeAbsolute); got != RankAce {
+		t.Errorf("absolute mode: got %s, want %s", got, RankAce)
+	}
+	// Percentile mode without a known population falls back to absolute cutoffs.
+	if got := GetRankTierForMode(10, 0, RankTierModePercentile); got != RankAce {
+		t.Errorf("percentile mode with unknown total: got %s, want %s", got, RankAce)
+	}
+}
+
+func TestParseRankTierMode(t *testing.T) {
+	if ParseRankTierMode("percentile") != RankTierModePercentile {
+		t.Error("expected percentile mode")
+	}
+	for _, s := range []string{"", "absolute", "bogus"} {
+		if ParseRankTierMode(s) != RankTierModeAbsolute {
+			t.Errorf("ParseRankTierMode(%q) should fall back to absolute", s)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/db"
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+)
+
+const (
+	readyzDBTimeout  = 1 * time.Second
+	readyzRPCTimeout = 2 * time.Second
+	// RPC results are reused for this long so frequent load balancer probes don't hammer the node.
+	readyzRPCCacheTTL = 10 * time.Second
+)
+
+// ledgerGetter is the part of soroban.Client the readiness probe needs.
+type ledgerGetter interface {
+	GetLatestLedger(ctx context.Context) (map[string]interface{}, error)
+	BreakerState() soroban.BreakerState
+}
+
+// ReadinessHandler reports whether the process and its dependencies (Postgres, Soroban RPC) are usable.
+type ReadinessHandler struct {
+	db  *db.DB
+	rpc ledgerGetter
+	now func() time.Time
+
+	mu           sync.Mutex
+	rpcCheckedAt time.Time
+	rpcErr       error
+
+	// rpcNetworkMismatch is set when the configured RPC serves another network
+	rpcNetworkMismatch bool
+}
+
+// NewReadinessHandler builds the probe handler. rpc may be nil when Soroban is not configured,
+// in which case the RPC check is reported as not_configured and does not fail readiness.
+func NewReadinessHandler(d *db.DB, rpc *soroban.Client) *ReadinessHandler {
+	h := &ReadinessHandler{db: d, now: time.Now}
+	if rpc != nil {
+		h.rpc = rpc
+	}
+	return h
+}
+
+// SetRPCNetworkMismatch marks the Soroban RPC as serving a different network than
+// configured (see soroban.ErrNetworkMismatch). The RPC check then reports
+// network_mismatch and fails readiness, since the client was refused rather than left
+// unconfigured.
+func (h *ReadinessHandler) SetRPCNetworkMismatch() {
+	h.rpcNetworkMismatch = true
+}
+
+// Healthz reports that the process is alive. It never touches dependencies.
+func (h *ReadinessHandler) Healthz() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"ok": true})
+	}
+}
+
+// Readyz returns 200 only when every configured dependency is reachable, otherwise 503.
+// Either way the body carries a per-dependency status map, plus the Soroban RPC circuit
+// breaker state (see soroban.CircuitBreakerConfig).
+func (h *ReadinessHandler) Readyz() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		dbStatus := h.checkDB(c.Context())
+		rpcStatus := h.checkRPC(c.Context())
+
+		ok := dbStatus == "ok" && (rpcStatus == "ok" || rpcStatus == "not_configured")
+		status := fiber.StatusOK
+		if !ok {
+			status = fiber.StatusServiceUnavailable
+		}
+		checks := fiber.Map{
+			"db":  dbStatus,
+			"rpc": rpcStatus,
+		}
+		if h.rpc != nil {
+			checks["rpc_circuit"] = string(h.rpc.BreakerState())
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"ok":     ok,
+			"checks": checks,
+		})
+	}
+}
+
+func (h *ReadinessHandler) checkDB(ctx context.Context) string {
+	if h.db == nil || h.db.Pool == nil {
+		return "not_configured"
+	}
+	ctx, cancel := context.WithTimeout(ctx, readyzDBTimeout)
+	defer cancel()
+
+	var one int
+	if err := h.db.Pool.QueryRow(ctx, "SELECT 1").Scan(&one); err != nil {
+		return "unreachable"
+	}
+	return "ok"
+}
+
+func (h *ReadinessHandler) checkRPC(ctx context.Context) string {
+	if h.rpcNetworkMismatch {
+		return "network_mismatch"
+	}
+	if h.rpc == nil {
+		return "not_configured"
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.rpcCheckedAt.IsZero() || h.now().Sub(h.rpcCheckedAt) >= readyzRPCCacheTTL {
+		ctx, cancel := context.WithTimeout(ctx, readyzRPCTimeout)
+		_, h.rpcErr = h.rpc.GetLatestLedger(ctx)
+		cancel()
+		h.rpcCheckedAt = h.now()
+	}
+	if h.rpcErr != nil {
+		return "unreachable"
+	}
+	return "ok"
+}
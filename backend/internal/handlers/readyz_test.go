@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/jagadeesh/grainlify/backend/internal/soroban"
+)
+
+type fakeLedgerGetter struct {
+	calls int
+	err   error
+	state soroban.BreakerState
+}
+
+func (f *fakeLedgerGetter) GetLatestLedger(ctx context.Context) (map[string]interface{}, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return map[string]interface{}{"sequence": 1}, nil
+}
+
+func (f *fakeLedgerGetter) BreakerState() soroban.BreakerState {
+	if f.state == "" {
+		return soroban.BreakerClosed
+	}
+	return f.state
+}
+
+func getReadyz(t *testing.T, h *ReadinessHandler) (int, map[string]any) {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/readyz", h.Readyz())
+	resp, err := app.Test(httptest.NewRequest("GET", "/readyz", nil), -1)
+	if err != nil {
+		t.Fatalf("GET /readyz failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var body map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	return resp.StatusCode, body
+}
+
+func TestHealthz(t *testing.T) {
+	app := fiber.New()
+	app.Get("/healthz", NewReadinessHandler(nil, nil).Healthz())
+	resp, err := app.Test(httptest.NewRequest("GET", "/healthz", nil), -1)
+	if err != nil {
+		t.Fatalf("GET /healthz failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestReadyz_ReportsEachDependency(t *testing.T) {
+	rpc := &fakeLedgerGetter{err: errors.New("connection refused")}
+	h := &ReadinessHandler{rpc: rpc, now: time.Now}
+
+	status, body := getReadyz(t, h)
+	if status != fiber.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", status)
+	}
+	checks, _ := body["checks"].(map[string]any)
+	if checks["db"] != "not_configured" {
+		t.Errorf("expected db not_configured, got %v", checks["db"])
+	}
+	if checks["rpc"] != "unreachable" {
+		t.Errorf("expected rpc unreachable, got %v", checks["rpc"])
+	}
+}
+
+func TestReadyz_ReportsRPCCircuit(t *testing.T) {
+	rpc := &fakeLedgerGetter{err: soroban.ErrCircuitOpen, state: soroban.BreakerOpen}
+	h := &ReadinessHandler{rpc: rpc, now: time.Now}
+
+	status, body := getReadyz(t, h)
+	if status != fiber.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", status)
+	}
+	checks, _ := body["checks"].(map[string]any)
+	if checks["rpc_circuit"] != "open" {
+		t.Errorf("expected rpc_circuit open, got %v", checks["rpc_circuit"])
+	}
+
+	// Without Soroban there is no circuit to report
+	_, body = getReadyz(t, NewReadinessHandler(nil, nil))
+	if checks, _ := body["checks"].(map[string]any); checks["rpc_circuit"] != nil {
+		t.Errorf("expected no rpc_circuit, got %v", checks["rpc_circuit"])
+	}
+}
+
+func TestReadyz_CachesRPCResult(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	rpc := &fakeLedgerGetter{}
+	h := &ReadinessHandler{rpc: rpc, now: func() time.Time { return now }}
+
+	ctx := context.Background()
+	if got := h.checkRPC(ctx); got != "ok" {
+		t.Fatalf("expected ok, got %s", got)
+	}
+	h.checkRPC(ctx)
+	if rpc.calls != 1 {
+		t.Errorf("expected cached RPC result within TTL, got %d calls", rpc.calls)
+	}
+
+	now = now.Add(readyzRPCCacheTTL)
+	rpc.err = errors.New("timeout")
+	if got := h.checkRPC(ctx); got != "unreachable" {
+		t.Errorf("expected unreachable after TTL, got %s", got)
+	}
+	if rpc.calls != 2 {
+		t.Errorf("expected RPC to be re-checked after TTL, got %d calls", rpc.calls)
+	}
+}
+
+func TestReadyz_RPCNotConfigured(t *testing.T) {
+	h := NewReadinessHandler(nil, nil)
+	if got := h.checkRPC(context.Background()); got != "not_configured" {
+		t.Errorf("expected not_configured, got %s", got)
+	}
+}
+
+// An RPC refused at startup for serving another network is a misconfiguration, not a
+// missing dependency: readiness fails
+func TestReadyz_RPCNetworkMismatch(t *testing.T) {
+	h := NewReadinessHandler(nil, nil)
+	h.SetRPCNetworkMismatch()
+
+	status, body := getReadyz(t, h)
+	if status != fiber.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", status)
+	}
+	if checks, _ := body["checks"].(map[string]any); checks["rpc"] != "network_mismatch" {
+		t.Errorf("expected rpc network_mismatch, got %v", checks["rpc"])
+	}
+}
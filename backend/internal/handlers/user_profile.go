@@ -140,7 +140,7 @@ FROM (
 ) contributions
 INNER JOIN projects p ON contributions.project_id = p.id
 INNER JOIN ecosystems e ON p.ecosystem_id = e.id
-WHERE p.status = 'verified' AND e.status = 'active'
+WHERE p.status = 'verified' AND e.status = 'active' AND e.deleted_at IS NULL
 GROUP BY e.id, e.name
 ORDER BY contribution_count DESC, e.name ASC
 LIMIT 10
@@ -926,7 +926,7 @@ FROM (
   WHERE pr.author_login = $1 AND p.status = 'verified' AND p.ecosystem_id IS NOT NULL
 ) contrib_ecosystems
 INNER JOIN ecosystems e ON contrib_ecosystems.ecosystem_id = e.id
-WHERE e.status = 'active'
+WHERE e.status = 'active' AND e.deleted_at IS NULL
 GROUP BY e.name
 ORDER BY contribution_count DESC
 LIMIT 10
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jagadeesh/grainlify/backend/internal/auth"
+)
+
+// walletTypesForAddress returns the wallet types an address given without a wallet_type
+// may belong to: 0x-prefixed addresses are EVM, anything else is a Stellar key.
+func walletTypesForAddress(address string) []auth.WalletType {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(address)), "0x") {
+		return []auth.WalletType{auth.WalletTypeEVM}
+	}
+	return []auth.WalletType{auth.WalletTypeStellarEd25519, auth.WalletTypeStellarSecp256k1}
+}
+
+// WalletProfile resolves a wallet address to the contributor behind it: the user the
+// wallet is linked to, their GitHub login, and that login's place on the contributor
+// leaderboard (rank, contributions, ecosystems), e.g. to show "your rank" after a
+// wallet login. ?wallet_type= names the wallet type; without it 0x addresses are
+// looked up as EVM and others as Stellar. The address is normalized as on login.
+// Leaderboard filters (?ecosystem=, ?pr_state=, ...) apply to the rank.
+//
+// A wallet linked to a user without a GitHub account, or a login without counted
+// contributions, is unranked: rank is null. 404 when no user has the wallet.
+func (h *LeaderboardHandler) WalletProfile() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if h.db == nil || h.db.Pool == nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "db_not_configured"})
+		}
+
+		address := c.Params("address")
+		candidates := walletTypesForAddress(address)
+		if wt := c.Query("wallet_type"); wt != "" {
+			wType, err := auth.NormalizeWalletType(wt)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_wallet_type"})
+			}
+			candidates = []auth.WalletType{wType}
+		}
+		// Stellar addresses normalize the same way for both key types
+		addr, err := auth.NormalizeAddress(candidates[0], address)
+		if errors.Is(err, auth.ErrInvalidEVMChecksum) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_evm_checksum"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_address"})
+		}
+		types := make([]string, len(candidates))
+		for i, t := range candidates {
+			types[i] = string(t)
+		}
+
+		var (
+			userID     string
+			walletType string
+			login      *string
+		)
+		err = h.db.QueryRowTimed(c.Context(), "wallet_profile_lookup", `
+SELECT w.user_id::text, w.wallet_type, ga.login
+FROM wallets w
+LEFT JOIN github_accounts ga ON ga.user_id = w.user_id
+WHERE w.wallet_type = ANY($1) AND w.address = $2
+ORDER BY w.created_at ASC
+LIMIT 1
+`, types, addr).Scan(&userID, &walletType, &login)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "wallet_not_linked"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "wallet_profile_failed"})
+		}
+
+		resp := unrankedContributor()
+		resp["user_id"] = userID
+		resp["wallet_type"] = walletType
+		resp["address"] = addr
+		resp["github_login"] = login
+		if login == nil || strings.TrimSpace(*login) == "" {
+			return c.Status(fiber.StatusOK).JSON(resp)
+		}
+
+		f := leaderboardFilterFromQuery(c)
+		f.TieBreak = h.tieBreak
+		key := fmt.Sprintf("wallet_rank|%s|%s", strings.ToLower(*login), f.cacheKey())
+		ranks, err := h.cache.get(key, func() (any, error) {
+			return h.fetchContributorRanks(c.Context(), []string{*login}, f)
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "wallet_profile_failed"})
+		}
+		for k, v := range ranks.(map[string]fiber.Map)[strings.ToLower(*login)] {
+			resp[k] = v
+		}
+		return c.Status(fiber.StatusOK).JSON(resp)
+	}
+}
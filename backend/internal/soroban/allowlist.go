@@ -0,0 +1,80 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RecipientAllowlist decides whether an address may receive payouts, e.g. because it
+// passed KYC. Muxed recipients are checked by their underlying G... account.
+type RecipientAllowlist interface {
+	IsAllowed(ctx context.Context, address string) (bool, error)
+}
+
+// ErrRecipientNotAllowlisted is returned (wrapped in a *RecipientsNotAllowlistedError)
+// for payouts to recipients the configured allowlist doesn't approve
+var ErrRecipientNotAllowlisted = errors.New("recipient_not_allowlisted")
+
+// RecipientsNotAllowlistedError names every recipient of a payout that isn't on the
+// allowlist. It matches ErrRecipientNotAllowlisted with errors.Is.
+type RecipientsNotAllowlistedError struct {
+	Addresses []string
+}
+
+func (e *RecipientsNotAllowlistedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrRecipientNotAllowlisted, strings.Join(e.Addresses, ", "))
+}
+
+func (e *RecipientsNotAllowlistedError) Unwrap() error {
+	return ErrRecipientNotAllowlisted
+}
+
+// SetRecipientAllowlist makes SinglePayout, BatchPayout and PreparePayout refuse
+// recipients that list doesn't approve; nil (the default) skips the check.
+func (pec *ProgramEscrowContract) SetRecipientAllowlist(list RecipientAllowlist) {
+	pec.allowlist = list
+}
+
+// checkAllowlist asks the allowlist about every distinct recipient and fails with a
+// *RecipientsNotAllowlistedError naming all that aren't approved
+func (pec *ProgramEscrowContract) checkAllowlist(ctx context.Context, recipients []string) error {
+	if pec.allowlist == nil {
+		return nil
+	}
+
+	var denied []string
+	checked := make(map[string]bool, len(recipients))
+	for _, recipient := range recipients {
+		account, _, err := SplitMuxedAddress(canonicalRecipient(recipient))
+		if err != nil {
+			return fmt.Errorf("invalid recipient address: %w", err)
+		}
+		if checked[account] {
+			continue
+		}
+		checked[account] = true
+
+		ok, err := pec.allowlist.IsAllowed(ctx, account)
+		if err != nil {
+			return fmt.Errorf("failed to check recipient allowlist: %w", err)
+		}
+		if !ok {
+			denied = append(denied, account)
+		}
+	}
+	if len(denied) > 0 {
+		return &RecipientsNotAllowlistedError{Addresses: denied}
+	}
+	return nil
+}
+
+// payoutRecipients lists the recipients of payouts, for checkAllowlist
+func payoutRecipients(payouts []PayoutItem) []string {
+	recipients := make([]string, len(payouts))
+	for i, payout := range payouts {
+		recipients[i] = payout.Recipient
+	}
+	return recipients
+}
@@ -0,0 +1,121 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeAllowlist approves the addresses it holds and records what it was asked
+type fakeAllowlist struct {
+	allowed map[string]bool
+	asked   []string
+	err     error
+}
+
+func (f *fakeAllowlist) IsAllowed(_ context.Context, address string) (bool, error) {
+	f.asked = append(f.asked, address)
+	return f.allowed[address], f.err
+}
+
+func newAllowlistTestContract(t *testing.T, fake *sequenceHorizon, list RecipientAllowlist) *ProgramEscrowContract {
+	t.Helper()
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(NoConfirmation())
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+	pec.SetRecipientAllowlist(list)
+	return pec
+}
+
+func TestSinglePayout_AllowedRecipient(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	list := &fakeAllowlist{allowed: map[string]bool{testPayoutAlice: true}}
+	pec := newAllowlistTestContract(t, fake, list)
+
+	// Checked in canonical form, whatever the caller's casing
+	if _, err := pec.SinglePayout(context.Background(), strings.ToLower(testPayoutAlice), 10); err != nil {
+		t.Fatalf("SinglePayout failed: %v", err)
+	}
+	if len(fake.submissions) != 1 {
+		t.Errorf("expected 1 submission, got %d", len(fake.submissions))
+	}
+	if !reflect.DeepEqual(list.asked, []string{testPayoutAlice}) {
+		t.Errorf("expected the allowlist to be asked about %s, got %v", testPayoutAlice, list.asked)
+	}
+}
+
+func TestSinglePayout_DisallowedRecipient(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	pec := newAllowlistTestContract(t, fake, &fakeAllowlist{allowed: map[string]bool{testPayoutAlice: true}})
+
+	_, err := pec.SinglePayout(context.Background(), testPayoutBob, 10)
+	if !errors.Is(err, ErrRecipientNotAllowlisted) {
+		t.Fatalf("expected ErrRecipientNotAllowlisted, got %v", err)
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be submitted, got %d submissions", len(fake.submissions))
+	}
+
+	// A muxed address is judged by its underlying account
+	if _, err := pec.SinglePayout(context.Background(), testMuxedAddress, 10); !errors.Is(err, ErrRecipientNotAllowlisted) {
+		t.Errorf("expected a muxed recipient of an unlisted account to be rejected, got %v", err)
+	}
+}
+
+func TestBatchPayout_MixedRecipientsNamesEveryOffender(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	list := &fakeAllowlist{allowed: map[string]bool{testPayoutAlice: true}}
+	pec := newAllowlistTestContract(t, fake, list)
+
+	_, err := pec.BatchPayout(context.Background(), []PayoutItem{
+		{Recipient: testPayoutBob, Amount: 1},
+		{Recipient: testPayoutAlice, Amount: 1},
+		{Recipient: testPayoutCarol, Amount: 1},
+	})
+	var notAllowed *RecipientsNotAllowlistedError
+	if !errors.As(err, &notAllowed) || !errors.Is(err, ErrRecipientNotAllowlisted) {
+		t.Fatalf("expected a RecipientsNotAllowlistedError, got %v", err)
+	}
+	if want := []string{testPayoutBob, testPayoutCarol}; !reflect.DeepEqual(notAllowed.Addresses, want) {
+		t.Errorf("expected offenders %v, got %v", want, notAllowed.Addresses)
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be submitted, got %d submissions", len(fake.submissions))
+	}
+}
+
+func TestBatchPayout_AllAllowed(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	list := &fakeAllowlist{allowed: map[string]bool{testPayoutAlice: true, testPayoutBob: true}}
+	pec := newAllowlistTestContract(t, fake, list)
+
+	if _, err := pec.BatchPayout(context.Background(), []PayoutItem{
+		{Recipient: testPayoutAlice, Amount: 1},
+		{Recipient: testPayoutBob, Amount: 1},
+		{Recipient: testPayoutAlice, Amount: 1},
+	}); err != nil {
+		t.Fatalf("BatchPayout failed: %v", err)
+	}
+	// Each recipient is looked up once
+	if len(list.asked) != 2 || len(fake.submissions) != 1 {
+		t.Errorf("expected 2 lookups and 1 submission, got %v and %d", list.asked, len(fake.submissions))
+	}
+}
+
+func TestCheckAllowlist(t *testing.T) {
+	// No allowlist configured: nothing is checked
+	pec := &ProgramEscrowContract{}
+	if err := pec.checkAllowlist(context.Background(), []string{testPayoutBob}); err != nil {
+		t.Errorf("expected no check without an allowlist, got %v", err)
+	}
+
+	// Lookup failures are not reported as a denial
+	lookupErr := errors.New("db down")
+	pec.SetRecipientAllowlist(&fakeAllowlist{err: lookupErr})
+	err := pec.checkAllowlist(context.Background(), []string{testPayoutBob})
+	if !errors.Is(err, lookupErr) || errors.Is(err, ErrRecipientNotAllowlisted) {
+		t.Errorf("expected the lookup error, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package soroban
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/stellar/go/clients/horizonclient"
+)
+
+// Backend is a network service a Client talks to. Contract simulation and reads go to
+// Soroban RPC; the account sequence and transaction submission go to Horizon.
+// Confirmations can use either, see Config.ConfirmVia.
+type Backend string
+
+const (
+	BackendRPC     Backend = "rpc"     // Soroban RPC; confirms with getTransaction
+	BackendHorizon Backend = "horizon" // Horizon; confirms with TransactionDetail
+)
+
+// ErrBackendNotConfigured is returned (wrapped) by NewClient when Config names a
+// backend it has no URL for, and by calls that need a backend the client doesn't have.
+var ErrBackendNotConfigured = errors.New("soroban backend not configured")
+
+// ErrTransactionFailed is returned (wrapped) by WaitForConfirmationRPC, and so by
+// WaitForConfirmation via RPC, when the transaction was included in a ledger but failed
+var ErrTransactionFailed = errors.New("transaction failed")
+
+// defaultHorizonURL returns the public Horizon of n. An empty network is treated as
+// testnet; other networks have no default.
+func defaultHorizonURL(n Network) string {
+	switch n {
+	case NetworkMainnet:
+		return "https://horizon.stellar.org"
+	case NetworkTestnet, "":
+		return "https://horizon-testnet.stellar.org"
+	}
+	return ""
+}
+
+// resolveBackends returns the backends of cfg and the one confirmations use, given
+// the Horizon URL in effect
+func resolveBackends(cfg Config, horizonURL string) (map[Backend]bool, Backend, error) {
+	urls := map[Backend]string{BackendRPC: cfg.RPCURL, BackendHorizon: horizonURL}
+
+	backends := make(map[Backend]bool, len(urls))
+	if len(cfg.Backends) == 0 {
+		for b, url := range urls {
+			backends[b] = url != ""
+		}
+	} else {
+		for _, b := range cfg.Backends {
+			url, known := urls[b]
+			if !known {
+				return nil, "", fmt.Errorf("unknown soroban backend %q", b)
+			}
+			if url == "" {
+				return nil, "", fmt.Errorf("%w: no URL for %s", ErrBackendNotConfigured, b)
+			}
+			backends[b] = true
+		}
+	}
+
+	confirmVia := cfg.ConfirmVia
+	switch {
+	case confirmVia == "" && backends[BackendHorizon]:
+		confirmVia = BackendHorizon
+	case confirmVia == "":
+		confirmVia = BackendRPC
+	case !backends[confirmVia]:
+		return nil, "", fmt.Errorf("%w: confirmations via %s", ErrBackendNotConfigured, confirmVia)
+	}
+	return backends, confirmVia, nil
+}
+
+// HasBackend reports whether the client was configured with b
+func (c *Client) HasBackend(b Backend) bool {
+	return c.backends[b]
+}
+
+// ConfirmationBackend returns the backend WaitForConfirmation polls
+func (c *Client) ConfirmationBackend() Backend {
+	return c.confirmVia
+}
+
+// requireBackend fails with ErrBackendNotConfigured unless the client has b
+func (c *Client) requireBackend(b Backend) error {
+	if !c.HasBackend(b) {
+		return fmt.Errorf("%w: %s", ErrBackendNotConfigured, b)
+	}
+	return nil
+}
+
+// horizon returns the Horizon client, or ErrBackendNotConfigured without one
+func (c *Client) horizon() (*horizonclient.Client, error) {
+	if err := c.requireBackend(BackendHorizon); err != nil {
+		return nil, err
+	}
+	return c.horizonClient, nil
+}
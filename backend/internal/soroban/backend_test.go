@@ -0,0 +1,325 @@
+package soroban
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+func TestNewClient_Backends(t *testing.T) {
+	const rpcURL, horizonURL = "http://127.0.0.1:1", "http://127.0.0.1:2"
+	standalone := "Standalone Network ; February 2017"
+
+	tests := []struct {
+		name        string
+		cfg         Config
+		wantRPC     bool
+		wantHorizon bool
+		wantConfirm Backend
+		wantErr     error
+	}{
+		{
+			name:    "default testnet has both, confirms via Horizon",
+			cfg:     Config{RPCURL: rpcURL, Network: NetworkTestnet},
+			wantRPC: true, wantHorizon: true, wantConfirm: BackendHorizon,
+		},
+		{
+			name:    "custom network without Horizon URL confirms via RPC",
+			cfg:     Config{RPCURL: rpcURL, Network: "standalone", NetworkPassphrase: standalone},
+			wantRPC: true, wantConfirm: BackendRPC,
+		},
+		{
+			name:    "both, confirming via RPC",
+			cfg:     Config{RPCURL: rpcURL, Network: NetworkTestnet, ConfirmVia: BackendRPC},
+			wantRPC: true, wantHorizon: true, wantConfirm: BackendRPC,
+		},
+		{
+			name:    "RPC only",
+			cfg:     Config{RPCURL: rpcURL, Network: NetworkTestnet, Backends: []Backend{BackendRPC}},
+			wantRPC: true, wantConfirm: BackendRPC,
+		},
+		{
+			name:        "Horizon only, without an RPC URL",
+			cfg:         Config{HorizonURL: horizonURL, Network: NetworkTestnet, Backends: []Backend{BackendHorizon}},
+			wantHorizon: true, wantConfirm: BackendHorizon,
+		},
+		{
+			name:    "Horizon listed without a URL",
+			cfg:     Config{RPCURL: rpcURL, Network: "standalone", NetworkPassphrase: standalone, Backends: []Backend{BackendRPC, BackendHorizon}},
+			wantErr: ErrBackendNotConfigured,
+		},
+		{
+			name:    "confirming via a missing backend",
+			cfg:     Config{RPCURL: rpcURL, Network: NetworkTestnet, Backends: []Backend{BackendRPC}, ConfirmVia: BackendHorizon},
+			wantErr: ErrBackendNotConfigured,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.cfg)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			if client.HasBackend(BackendRPC) != tt.wantRPC || client.HasBackend(BackendHorizon) != tt.wantHorizon {
+				t.Errorf("expected rpc=%v horizon=%v, got rpc=%v horizon=%v", tt.wantRPC, tt.wantHorizon,
+					client.HasBackend(BackendRPC), client.HasBackend(BackendHorizon))
+			}
+			if (client.GetHorizonClient() != nil) != tt.wantHorizon {
+				t.Errorf("expected a Horizon client only with Horizon, got %v", client.GetHorizonClient())
+			}
+			if got := client.ConfirmationBackend(); got != tt.wantConfirm {
+				t.Errorf("expected confirmations via %s, got %s", tt.wantConfirm, got)
+			}
+		})
+	}
+
+	if _, err := NewClient(Config{Network: NetworkTestnet}); err == nil {
+		t.Error("expected an RPC URL to be required when no backends are listed")
+	}
+}
+
+func TestClient_MissingBackendFailsClearly(t *testing.T) {
+	rpcOnly, err := NewClient(Config{RPCURL: "http://127.0.0.1:1", Network: NetworkTestnet, Backends: []Backend{BackendRPC}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	tb := &TransactionBuilder{client: rpcOnly, sourceKP: keypair.MustRandom()}
+	if _, err := tb.BuildAndSubmit(context.Background(), nil); !errors.Is(err, ErrBackendNotConfigured) {
+		t.Errorf("expected submission without Horizon to fail with ErrBackendNotConfigured, got %v", err)
+	}
+
+	horizonOnly, err := NewClient(Config{Network: NetworkTestnet, Backends: []Backend{BackendHorizon}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := horizonOnly.GetLatestLedger(context.Background()); !errors.Is(err, ErrBackendNotConfigured) {
+		t.Errorf("expected an RPC call without RPC to fail with ErrBackendNotConfigured, got %v", err)
+	}
+}
+
+// newRPCConfirmServer answers getTransaction with NOT_FOUND until landed is set, then
+// with finalStatus in ledger 77
+func newRPCConfirmServer(t *testing.T, landed *atomic.Bool, finalStatus string) string {
+	t.Helper()
+	resultXDR, err := xdr.MarshalBase64(xdr.TransactionResult{
+		FeeCharged: 321,
+		Result: xdr.TransactionResultResult{
+			Code:    xdr.TransactionResultCodeTxSuccess,
+			Results: &[]xdr.OperationResult{},
+		},
+	})
+	if err != nil {
+		t.Fatalf("encode result: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := `{"status":"NOT_FOUND"}`
+		if landed.Load() {
+			result = fmt.Sprintf(`{"status":%q,"ledger":77,"resultXdr":%q}`, finalStatus, resultXDR)
+		}
+		_ = json.NewEncoder(w).Encode(RPCResponse{JSONRPC: "2.0", ID: 1, Result: json.RawMessage(result)})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestWaitForConfirmation_ViaRPC(t *testing.T) {
+	fastConfirmationPolling(t)
+	var landed atomic.Bool
+	client, err := NewClient(Config{
+		RPCURL:   newRPCConfirmServer(t, &landed, "SUCCESS"),
+		Network:  NetworkTestnet,
+		Backends: []Backend{BackendRPC},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	tb := &TransactionBuilder{client: client}
+
+	time.AfterFunc(30*time.Millisecond, func() { landed.Store(true) })
+	result, err := tb.WaitForConfirmation(context.Background(), "abc", 5*time.Second)
+	if err != nil {
+		t.Fatalf("WaitForConfirmation failed: %v", err)
+	}
+	if !result.IsConfirmed() || result.Ledger != 77 || result.FeeCharged != 321 {
+		t.Errorf("expected a confirmed result in ledger 77 with fee 321, got %+v", result)
+	}
+}
+
+func TestWaitForConfirmation_ViaRPCFailedTransaction(t *testing.T) {
+	fastConfirmationPolling(t)
+	var landed atomic.Bool
+	landed.Store(true)
+	client, err := NewClient(Config{
+		RPCURL:   newRPCConfirmServer(t, &landed, "FAILED"),
+		Network:  NetworkTestnet,
+		Backends: []Backend{BackendRPC},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	tb := &TransactionBuilder{client: client}
+
+	if _, err := tb.WaitForConfirmation(context.Background(), "abc", 5*time.Second); !errors.Is(err, ErrTransactionFailed) {
+		t.Errorf("expected ErrTransactionFailed, got %v", err)
+	}
+}
+
+// A transaction that lands but fails must reach SubmitAndConfirm's caller as an error,
+// unlike a confirmation timeout
+func TestSubmitAndConfirm_ViaRPCFailedTransaction(t *testing.T) {
+	fastConfirmationPolling(t)
+	var landed atomic.Bool
+	landed.Store(true)
+	source := keypair.MustRandom()
+	horizon := newFakeHorizon(t, source.Address())
+	client, err := NewClient(Config{
+		RPCURL:     newRPCConfirmServer(t, &landed, "FAILED"),
+		HorizonURL: horizon.URL,
+		Network:    NetworkTestnet,
+		ConfirmVia: BackendRPC,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	tb, err := NewTransactionBuilder(client, source.Seed(), RetryConfig{
+		MaxRetries:        2,
+		InitialDelay:      time.Millisecond,
+		MaxDelay:          time.Millisecond,
+		BackoffMultiplier: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewTransactionBuilder failed: %v", err)
+	}
+	tb.SetConfirmationPolicy(WaitForConfirmationPolicy(5 * time.Second))
+
+	result, err := tb.SubmitAndConfirm(context.Background(), []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 0}})
+	if !errors.Is(err, ErrTransactionFailed) {
+		t.Fatalf("expected ErrTransactionFailed, got %v", err)
+	}
+	if result == nil || result.Status != "failed" || result.Ledger != 77 {
+		t.Errorf("expected the failed result in ledger 77, got %+v", result)
+	}
+}
+
+// newRPCStatusServer answers getTransaction with each of responses in turn, repeating
+// the last one
+func newRPCStatusServer(t *testing.T, responses ...string) *Client {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(calls.Add(1)) - 1
+		if i >= len(responses) {
+			i = len(responses) - 1
+		}
+		_ = json.NewEncoder(w).Encode(RPCResponse{JSONRPC: "2.0", ID: 1, Result: json.RawMessage(responses[i])})
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(Config{RPCURL: srv.URL, Network: NetworkTestnet, Backends: []Backend{BackendRPC}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return client
+}
+
+func TestWaitForConfirmationRPC_DecodesReturnValue(t *testing.T) {
+	fastConfirmationPolling(t)
+	want, err := EncodeScValInt64(4200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metaXDR, err := xdr.MarshalBase64(xdr.TransactionMeta{
+		V: 3,
+		V3: &xdr.TransactionMetaV3{
+			SorobanMeta: &xdr.SorobanTransactionMeta{ReturnValue: want},
+		},
+	})
+	if err != nil {
+		t.Fatalf("encode meta: %v", err)
+	}
+	client := newRPCStatusServer(t,
+		`{"status":"NOT_FOUND"}`,
+		fmt.Sprintf(`{"status":"SUCCESS","ledger":88,"resultMetaXdr":%q}`, metaXDR),
+	)
+	tb := &TransactionBuilder{client: client}
+
+	result, err := tb.WaitForConfirmationRPC(context.Background(), "abc", 5*time.Second)
+	if err != nil {
+		t.Fatalf("WaitForConfirmationRPC failed: %v", err)
+	}
+	if !result.IsConfirmed() || result.Hash != "abc" || result.Ledger != 88 {
+		t.Errorf("expected a confirmed result in ledger 88, got %+v", result)
+	}
+	if result.ReturnValue == nil {
+		t.Fatal("expected a decoded return value")
+	}
+	if got, ok := result.ReturnValue.GetI64(); !ok || got != 4200 {
+		t.Errorf("expected return value 4200, got %+v", result.ReturnValue)
+	}
+}
+
+func TestWaitForConfirmationRPC_Failed(t *testing.T) {
+	fastConfirmationPolling(t)
+	client := newRPCStatusServer(t, `{"status":"FAILED","ledger":89}`)
+	tb := &TransactionBuilder{client: client}
+
+	result, err := tb.WaitForConfirmationRPC(context.Background(), "abc", 5*time.Second)
+	if !errors.Is(err, ErrTransactionFailed) {
+		t.Fatalf("expected ErrTransactionFailed, got %v", err)
+	}
+	if result == nil || result.Status != "failed" || result.Ledger != 89 || result.IsConfirmed() {
+		t.Errorf("expected a failed result in ledger 89, got %+v", result)
+	}
+}
+
+func TestWaitForConfirmationRPC_RequiresRPC(t *testing.T) {
+	client, err := NewClient(Config{HorizonURL: "http://horizon.invalid", Network: NetworkTestnet, Backends: []Backend{BackendHorizon}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	tb := &TransactionBuilder{client: client}
+	if _, err := tb.WaitForConfirmationRPC(context.Background(), "abc", time.Second); !errors.Is(err, ErrBackendNotConfigured) {
+		t.Errorf("expected ErrBackendNotConfigured, got %v", err)
+	}
+}
+
+func TestWaitForConfirmation_ViaHorizonOnly(t *testing.T) {
+	fastConfirmationPolling(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"abc","hash":"abc","ledger":1234,"successful":true,"fee_charged":"100"}`))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(Config{
+		HorizonURL: srv.URL,
+		Network:    NetworkTestnet,
+		Backends:   []Backend{BackendHorizon},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	tb := &TransactionBuilder{client: client}
+
+	result, err := tb.WaitForConfirmation(context.Background(), "abc", 5*time.Second)
+	if err != nil {
+		t.Fatalf("WaitForConfirmation failed: %v", err)
+	}
+	if !result.IsConfirmed() || result.Ledger != 1234 || result.FeeCharged != 100 {
+		t.Errorf("expected a confirmed result in ledger 1234 with fee 100, got %+v", result)
+	}
+}
@@ -0,0 +1,91 @@
+package soroban
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// JitterStrategy randomizes retry delays so many workers retrying the same outage
+// don't hit the RPC in lockstep
+type JitterStrategy int
+
+const (
+	// JitterNone uses the plain exponential delay
+	JitterNone JitterStrategy = iota
+	// JitterFull waits a random duration between zero and the exponential delay
+	JitterFull
+	// JitterDecorrelated waits a random duration between InitialDelay and three
+	// times the previous wait, so each worker drifts away from the others
+	JitterDecorrelated
+)
+
+// decorrelatedJitterFactor bounds a decorrelated wait relative to the previous one
+const decorrelatedJitterFactor = 3
+
+// backoff produces the successive retry delays for a RetryConfig. Every delay is capped
+// at MaxDelay.
+type backoff struct {
+	cfg   RetryConfig
+	delay time.Duration // un-jittered exponential delay for the next retry
+	prev  time.Duration // last returned delay, for decorrelated jitter
+	rand  func(n int64) int64
+}
+
+func newBackoff(cfg RetryConfig) *backoff {
+	return &backoff{
+		cfg:   cfg,
+		delay: cfg.InitialDelay,
+		prev:  cfg.InitialDelay,
+		rand:  rand.Int63n,
+	}
+}
+
+// next returns the delay to wait before the next retry
+func (b *backoff) next() time.Duration {
+	var wait time.Duration
+	switch b.cfg.Jitter {
+	case JitterFull:
+		wait = b.between(0, b.capped(b.delay))
+	case JitterDecorrelated:
+		wait = b.capped(b.between(b.cfg.InitialDelay, b.prev*decorrelatedJitterFactor))
+	default:
+		wait = b.delay
+	}
+
+	b.prev = wait
+	b.delay = b.capped(time.Duration(float64(b.delay) * b.cfg.BackoffMultiplier))
+	return wait
+}
+
+func (b *backoff) capped(d time.Duration) time.Duration {
+	if d > b.cfg.MaxDelay {
+		return b.cfg.MaxDelay
+	}
+	return d
+}
+
+// between returns a random duration in [lo, hi]
+func (b *backoff) between(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + time.Duration(b.rand(int64(hi-lo)+1))
+}
+
+// waitRetry waits delay before a retry. It gives up at once, without sleeping, when
+// ctx's deadline would pass before the retry starts, and returns early if ctx ends.
+func waitRetry(ctx context.Context, delay time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return fmt.Errorf("next retry in %s is past the deadline: %w", delay, context.DeadlineExceeded)
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,98 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func jitterTestConfig(jitter JitterStrategy) RetryConfig {
+	return RetryConfig{
+		MaxRetries:        5,
+		InitialDelay:      100 * time.Millisecond,
+		MaxDelay:          time.Second,
+		BackoffMultiplier: 2,
+		Jitter:            jitter,
+	}
+}
+
+func TestBackoff_NoJitterIsDeterministic(t *testing.T) {
+	b := newBackoff(jitterTestConfig(JitterNone))
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, w := range want {
+		if got := b.next(); got != w*time.Millisecond {
+			t.Errorf("retry %d: expected %v, got %v", i+1, w*time.Millisecond, got)
+		}
+	}
+}
+
+// spread samples the nth delay many times and checks every sample lies in [lo, hi]
+// and that each quarter of the range is hit, so the delays aren't clustered.
+func spread(t *testing.T, jitter JitterStrategy, n int, lo, hi time.Duration) {
+	t.Helper()
+	const samples = 2000
+	var buckets [4]int
+	var sum time.Duration
+	for i := 0; i < samples; i++ {
+		b := newBackoff(jitterTestConfig(jitter))
+		var d time.Duration
+		for j := 0; j < n; j++ {
+			d = b.next()
+		}
+		if d < lo || d > hi {
+			t.Fatalf("retry %d: delay %v outside [%v, %v]", n, d, lo, hi)
+		}
+		sum += d
+		q := int(4 * (d - lo) / (hi - lo + 1))
+		buckets[q]++
+	}
+	for q, count := range buckets {
+		// A uniform draw puts ~500 samples in each quarter; 300 is far outside chance.
+		if count < 300 {
+			t.Errorf("retry %d: quarter %d of [%v, %v] got only %d/%d samples: %v", n, q, lo, hi, count, samples, buckets)
+		}
+	}
+	if mean, mid := sum/samples, (lo+hi)/2; mean < mid*9/10 || mean > mid*11/10 {
+		t.Errorf("retry %d: mean delay %v not near the midpoint %v", n, mean, mid)
+	}
+}
+
+func TestBackoff_FullJitterSpreadsUpToExponentialDelay(t *testing.T) {
+	spread(t, JitterFull, 1, 0, 100*time.Millisecond)
+	spread(t, JitterFull, 3, 0, 400*time.Millisecond)
+	// Capped by MaxDelay
+	spread(t, JitterFull, 6, 0, time.Second)
+}
+
+func TestBackoff_DecorrelatedJitterStaysWithinBounds(t *testing.T) {
+	// The first wait is drawn from [InitialDelay, 3*InitialDelay]
+	spread(t, JitterDecorrelated, 1, 100*time.Millisecond, 300*time.Millisecond)
+
+	for i := 0; i < 500; i++ {
+		b := newBackoff(jitterTestConfig(JitterDecorrelated))
+		for n := 1; n <= 10; n++ {
+			if d := b.next(); d < 100*time.Millisecond || d > time.Second {
+				t.Fatalf("retry %d: delay %v outside [InitialDelay, MaxDelay]", n, d)
+			}
+		}
+	}
+}
+
+func TestWaitRetry_RespectsDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// A retry that would start after the deadline fails at once instead of sleeping
+	start := time.Now()
+	if err := waitRetry(ctx, time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected no wait, took %v", elapsed)
+	}
+
+	if err := waitRetry(ctx, 10*time.Millisecond); err != nil {
+		t.Errorf("expected a short retry within the deadline to wait, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned (wrapped) by RPC calls while the circuit breaker is open,
+// without contacting the provider.
+var ErrCircuitOpen = errors.New("soroban RPC circuit open")
+
+// Circuit breaker defaults, used for zero fields of CircuitBreakerConfig
+const (
+	DefaultBreakerFailureThreshold = 5
+	DefaultBreakerWindow           = 30 * time.Second
+	DefaultBreakerCooldown         = 30 * time.Second
+)
+
+// BreakerState is the state of the RPC circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // calls go through
+	BreakerOpen     BreakerState = "open"      // calls fail fast with ErrCircuitOpen
+	BreakerHalfOpen BreakerState = "half_open" // one trial call decides whether to close
+	BreakerDisabled BreakerState = "disabled"  // no breaker configured
+)
+
+// CircuitBreakerConfig stops RPC calls from piling onto a provider that is down. After
+// FailureThreshold consecutive failures, each within Window of the first, the circuit
+// opens for Cooldown; then a single trial call closes it again or reopens it.
+// Zero fields use the defaults; a negative FailureThreshold disables the breaker.
+type CircuitBreakerConfig struct {
+	FailureThreshold int
+	Window           time.Duration
+	Cooldown         time.Duration
+}
+
+// circuitBreaker guards Client.post. Only transport-level failures (network errors,
+// timeouts, non-200 responses) count; a JSON-RPC error means the provider is up.
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu           sync.Mutex
+	state        BreakerState
+	failures     int       // consecutive failures in the current streak
+	firstFailure time.Time // start of the current streak
+	openedAt     time.Time
+	probing      bool // the half-open trial call is in flight
+}
+
+// newCircuitBreaker returns nil for a disabled breaker; a nil breaker allows every call.
+func newCircuitBreaker(cfg CircuitBreakerConfig) *circuitBreaker {
+	if cfg.FailureThreshold < 0 {
+		return nil
+	}
+	if cfg.FailureThreshold == 0 {
+		cfg.FailureThreshold = DefaultBreakerFailureThreshold
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultBreakerWindow
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultBreakerCooldown
+	}
+	return &circuitBreaker{cfg: cfg, now: time.Now, state: BreakerClosed}
+}
+
+// allow reports whether a call may go out. Once the cooldown has passed, an open
+// circuit turns half-open and lets exactly one call through.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.Cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record notes the outcome of an allowed call: nil closes the circuit, an error adds
+// to the failure streak or, for the trial call, reopens it.
+func (b *circuitBreaker) record(ctx context.Context, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if err == nil {
+		if b.state != BreakerClosed {
+			loggerFrom(ctx).Info("soroban RPC circuit closed")
+		}
+		b.state, b.failures, b.probing = BreakerClosed, 0, false
+		return
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		// A call let through before the circuit opened; the cooldown stands
+		return
+	case BreakerHalfOpen:
+		b.state, b.openedAt, b.probing = BreakerOpen, now, false
+		loggerFrom(ctx).Warn("soroban RPC circuit reopened", "error", err)
+		return
+	}
+	if b.failures == 0 || now.Sub(b.firstFailure) > b.cfg.Window {
+		b.failures, b.firstFailure = 0, now
+	}
+	b.failures++
+	if b.failures >= b.cfg.FailureThreshold {
+		b.state, b.openedAt = BreakerOpen, now
+		loggerFrom(ctx).Warn("soroban RPC circuit opened",
+			"failures", b.failures,
+			"cooldown", b.cfg.Cooldown,
+			"error", err,
+		)
+	}
+}
+
+// release gives back an allowed call that ended without telling us anything about the
+// provider, e.g. because the caller's context was cancelled.
+func (b *circuitBreaker) release() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerHalfOpen {
+		b.probing = false
+	}
+}
+
+// State returns the current breaker state. An open circuit whose cooldown has passed is
+// reported as half-open, since the next call will be the trial.
+func (b *circuitBreaker) State() BreakerState {
+	if b == nil {
+		return BreakerDisabled
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cfg.Cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
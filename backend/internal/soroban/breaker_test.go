@@ -0,0 +1,189 @@
+package soroban
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestBreaker returns a breaker on a fake clock advanced through *now
+func newTestBreaker(cfg CircuitBreakerConfig) (*circuitBreaker, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	b := newCircuitBreaker(cfg)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestCircuitBreaker_ClosedOpenHalfOpenClosed(t *testing.T) {
+	b, now := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 3, Window: time.Minute, Cooldown: 10 * time.Second})
+	ctx := context.Background()
+	failure := errors.New("connection refused")
+
+	for i := 0; i < 3; i++ {
+		if b.State() != BreakerClosed {
+			t.Fatalf("failure %d: expected closed, got %s", i, b.State())
+		}
+		if err := b.allow(); err != nil {
+			t.Fatalf("failure %d: expected the call to be allowed, got %v", i, err)
+		}
+		b.record(ctx, failure)
+	}
+	if b.State() != BreakerOpen {
+		t.Fatalf("expected open after 3 failures, got %s", b.State())
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen during the cooldown, got %v", err)
+	}
+
+	// After the cooldown one trial call goes through; others still fail fast
+	*now = now.Add(10 * time.Second)
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("expected half-open after the cooldown, got %s", b.State())
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected the trial call to be allowed, got %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected a second call to fail fast while the trial runs, got %v", err)
+	}
+
+	// A failed trial reopens for another cooldown
+	b.record(ctx, failure)
+	if b.State() != BreakerOpen {
+		t.Fatalf("expected open after a failed trial, got %s", b.State())
+	}
+	*now = now.Add(10 * time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected a new trial call, got %v", err)
+	}
+	b.record(ctx, nil)
+	if b.State() != BreakerClosed {
+		t.Fatalf("expected closed after a successful trial, got %s", b.State())
+	}
+	if err := b.allow(); err != nil {
+		t.Errorf("expected calls to flow again, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_FailuresOutsideWindowDontAddUp(t *testing.T) {
+	b, now := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 3, Window: time.Minute, Cooldown: time.Second})
+	ctx := context.Background()
+	failure := errors.New("timeout")
+
+	b.record(ctx, failure)
+	b.record(ctx, failure)
+	*now = now.Add(2 * time.Minute)
+	b.record(ctx, failure) // starts a new streak
+	if b.State() != BreakerClosed {
+		t.Fatalf("expected closed, got %s", b.State())
+	}
+
+	// A success also resets the streak
+	b.record(ctx, failure)
+	b.record(ctx, nil)
+	b.record(ctx, failure)
+	b.record(ctx, failure)
+	if b.State() != BreakerClosed {
+		t.Fatalf("expected closed, got %s", b.State())
+	}
+}
+
+func TestCircuitBreaker_ReleasedTrialFreesTheSlot(t *testing.T) {
+	b, now := newTestBreaker(CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Second})
+	b.record(context.Background(), errors.New("down"))
+	*now = now.Add(time.Second)
+
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected the trial call, got %v", err)
+	}
+	b.release()
+	if err := b.allow(); err != nil {
+		t.Errorf("expected another trial after a cancelled one, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: -1})
+	if b.State() != BreakerDisabled {
+		t.Fatalf("expected disabled, got %s", b.State())
+	}
+	for i := 0; i < 10; i++ {
+		b.record(context.Background(), errors.New("down"))
+	}
+	if err := b.allow(); err != nil {
+		t.Errorf("expected a disabled breaker to allow every call, got %v", err)
+	}
+}
+
+func TestCall_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	var healthy, rpcErrors atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch {
+		case rpcErrors.Load():
+			_ = json.NewEncoder(w).Encode(RPCResponse{JSONRPC: "2.0", ID: 1, Error: &RPCError{Code: RPCErrorCodeInvalidParams, Message: "bad"}})
+		case healthy.Load():
+			_ = json.NewEncoder(w).Encode(RPCResponse{JSONRPC: "2.0", ID: 1, Result: json.RawMessage(`{"sequence":7}`)})
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(Config{
+		RPCURL:         srv.URL,
+		Network:        NetworkTestnet,
+		CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	client.breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// JSON-RPC errors mean the provider is up and don't trip the breaker
+	rpcErrors.Store(true)
+	for i := 0; i < 3; i++ {
+		_, _ = client.Call(ctx, "getLatestLedger", nil)
+	}
+	if client.BreakerState() != BreakerClosed {
+		t.Fatalf("expected closed after RPC errors, got %s", client.BreakerState())
+	}
+	rpcErrors.Store(false)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Call(ctx, "getLatestLedger", nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: expected a provider error, got %v", i, err)
+		}
+	}
+	if client.BreakerState() != BreakerOpen {
+		t.Fatalf("expected open, got %s", client.BreakerState())
+	}
+	sent := calls.Load()
+	if _, err := client.Call(ctx, "getLatestLedger", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls.Load() != sent {
+		t.Error("expected no request while the circuit is open")
+	}
+
+	// The provider recovers; after the cooldown the trial call closes the circuit
+	healthy.Store(true)
+	now = now.Add(time.Hour)
+	if client.BreakerState() != BreakerHalfOpen {
+		t.Fatalf("expected half-open, got %s", client.BreakerState())
+	}
+	if _, err := client.Call(ctx, "getLatestLedger", nil); err != nil {
+		t.Fatalf("expected the trial call to succeed, got %v", err)
+	}
+	if client.BreakerState() != BreakerClosed {
+		t.Errorf("expected closed, got %s", client.BreakerState())
+	}
+}
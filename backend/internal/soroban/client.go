@@ -1,6 +1,9 @@
 package soroban
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -17,70 +20,199 @@ type Client struct {
 	horizonClient     *horizonclient.Client
 	httpClient        *http.Client
 	network           Network
+	timeouts          rpcTimeouts
+	breaker           *circuitBreaker // nil when disabled
+	logRedactor       argRedactor
+	backends          map[Backend]bool
+	confirmVia        Backend
 }
 
 // Config holds configuration for Soroban client
 type Config struct {
 	RPCURL           string // Soroban RPC endpoint
 	NetworkPassphrase string // Network passphrase
-	Network         Network // "testnet" or "mainnet"
-	HTTPTimeout     time.Duration
+	Network         Network // "testnet", "mainnet", or a custom network name
+	HTTPTimeout     time.Duration // deadline of each RPC and Horizon request (default 30s)
+
+	// Per-method overrides of HTTPTimeout for RPC calls. A shorter deadline on the
+	// caller's ctx still wins.
+	SimulateTimeout     time.Duration // simulateTransaction, which executes the contract
+	LatestLedgerTimeout time.Duration // getLatestLedger, used for cheap liveness checks
+
+	// CircuitBreaker fails RPC calls fast with ErrCircuitOpen while the provider is down.
+	// The zero value uses the defaults.
+	CircuitBreaker CircuitBreakerConfig
+
+	// LogRedaction masks and shortens contract call args before they are logged.
+	// The zero value uses the defaults.
+	LogRedaction LogRedactionConfig
+
+	// HorizonURL is the Horizon endpoint. Empty uses the public Horizon of testnet and
+	// mainnet; other networks then have no Horizon unless it is set.
+	HorizonURL string
+	// Backends lists the backends this deployment has, e.g. only BackendRPC where no
+	// Horizon is reachable. Empty means RPC (RPCURL is then required) plus Horizon if
+	// it has a URL. Calls needing a missing backend fail with ErrBackendNotConfigured.
+	Backends []Backend
+	// ConfirmVia is the backend WaitForConfirmation polls. Empty means Horizon when
+	// available, otherwise RPC.
+	ConfirmVia Backend
+}
+
+// rpcTimeouts holds the per-call deadlines of a Client
+type rpcTimeouts struct {
+	defaultTimeout time.Duration
+	byMethod       map[string]time.Duration
+}
+
+// forMethod returns the deadline for one call of method
+func (t rpcTimeouts) forMethod(method string) time.Duration {
+	if d, ok := t.byMethod[method]; ok && d > 0 {
+		return d
+	}
+	return t.defaultTimeout
 }
 
-// NewClient creates a new Soroban client
+// ErrPassphraseRequired is returned by NewClient when a custom (standalone or private)
+// network is configured without a passphrase.
+var ErrPassphraseRequired = errors.New("network passphrase is required for custom networks")
+
+// wellKnownPassphrase returns the public passphrase of n. An empty network is
+// treated as testnet.
+func wellKnownPassphrase(n Network) (string, bool) {
+	switch n {
+	case NetworkMainnet:
+		return network.PublicNetworkPassphrase, true
+	case NetworkTestnet, "":
+		return network.TestNetworkPassphrase, true
+	}
+	return "", false
+}
+
+// NewClient creates a new Soroban client. Testnet and mainnet default to their
+// public passphrases and Horizon; any other network must set NetworkPassphrase, and
+// HorizonURL to use Horizon.
 func NewClient(cfg Config) (*Client, error) {
-	if cfg.RPCURL == "" {
+	if cfg.RPCURL == "" && len(cfg.Backends) == 0 {
 		return nil, fmt.Errorf("RPC URL is required")
 	}
 
-	if cfg.NetworkPassphrase == "" {
-		// Set default based on network
-		if cfg.Network == NetworkMainnet {
-			cfg.NetworkPassphrase = network.PublicNetworkPassphrase
-		} else {
-			cfg.NetworkPassphrase = network.TestNetworkPassphrase
-		}
+	known, isKnown := wellKnownPassphrase(cfg.Network)
+	switch {
+	case cfg.NetworkPassphrase == "" && isKnown:
+		cfg.NetworkPassphrase = known
+	case cfg.NetworkPassphrase == "":
+		return nil, fmt.Errorf("%w: network %q", ErrPassphraseRequired, cfg.Network)
+	case isKnown && cfg.NetworkPassphrase != known:
+		// Allowed for forks that reuse the network name, but usually a typo that
+		// makes every signature invalid.
+		slog.Warn("soroban network passphrase does not match configured network",
+			"network", cfg.Network,
+			"passphrase", cfg.NetworkPassphrase,
+			"expected", known,
+		)
 	}
 
 	if cfg.HTTPTimeout == 0 {
 		cfg.HTTPTimeout = 30 * time.Second
 	}
 
-	// Create Horizon client
-	horizonURL := "https://horizon-testnet.stellar.org"
-	if cfg.Network == NetworkMainnet {
-		horizonURL = "https://horizon.stellar.org"
+	horizonURL := cfg.HorizonURL
+	if horizonURL == "" {
+		horizonURL = defaultHorizonURL(cfg.Network)
+	}
+	backends, confirmVia, err := resolveBackends(cfg, horizonURL)
+	if err != nil {
+		return nil, err
 	}
 
-	horizonClient := &horizonclient.Client{
-		HorizonURL: horizonURL,
-		HTTP: &http.Client{
-			Timeout: cfg.HTTPTimeout,
-		},
+	// Create Horizon client
+	var horizonClient *horizonclient.Client
+	if backends[BackendHorizon] {
+		horizonClient = &horizonclient.Client{
+			HorizonURL: horizonURL,
+			HTTP: &http.Client{
+				Timeout: cfg.HTTPTimeout,
+			},
+		}
 	}
 
 	return &Client{
 		rpcURL:            cfg.RPCURL,
 		networkPassphrase: cfg.NetworkPassphrase,
 		horizonClient:     horizonClient,
-		httpClient: &http.Client{
-			Timeout: cfg.HTTPTimeout,
+		// Deadlines are applied per call from timeouts, see Client.post
+		httpClient: &http.Client{},
+		network:    cfg.Network,
+		timeouts: rpcTimeouts{
+			defaultTimeout: cfg.HTTPTimeout,
+			byMethod: map[string]time.Duration{
+				"simulateTransaction": cfg.SimulateTimeout,
+				"getLatestLedger":     cfg.LatestLedgerTimeout,
+			},
 		},
-		network: cfg.Network,
+		breaker:     newCircuitBreaker(cfg.CircuitBreaker),
+		logRedactor: newArgRedactor(cfg.LogRedaction),
+		backends:    backends,
+		confirmVia:  confirmVia,
 	}, nil
 }
 
-// GetNetwork returns the network type
-func (c *Client) GetNetwork() Network {
+// Network returns the configured network type
+func (c *Client) Network() Network {
 	return c.network
 }
 
+// BreakerState reports the RPC circuit breaker's state, e.g. for health checks
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+// ErrNetworkMismatch is returned by Verify when the RPC endpoint serves a different
+// network than the client was configured for.
+var ErrNetworkMismatch = errors.New("soroban RPC network mismatch")
+
+// NetworkInfo is the result of the RPC getNetwork method
+type NetworkInfo struct {
+	Passphrase      string `json:"passphrase"`
+	ProtocolVersion int    `json:"protocolVersion"`
+	FriendbotURL    string `json:"friendbotUrl,omitempty"` // only set on test networks
+}
+
+// GetNetwork asks the RPC endpoint which network it serves
+func (c *Client) GetNetwork(ctx context.Context) (*NetworkInfo, error) {
+	resp, err := c.Call(ctx, "getNetwork", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var info NetworkInfo
+	if err := json.Unmarshal(resp.Result, &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return &info, nil
+}
+
+// Verify checks that the RPC endpoint serves the configured network passphrase.
+// A mismatch is reported as ErrNetworkMismatch.
+func (c *Client) Verify(ctx context.Context) error {
+	info, err := c.GetNetwork(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+	if info.Passphrase != c.networkPassphrase {
+		return fmt.Errorf("%w: configured %q, RPC serves %q", ErrNetworkMismatch, c.networkPassphrase, info.Passphrase)
+	}
+	return nil
+}
+
 // GetNetworkPassphrase returns the network passphrase
 func (c *Client) GetNetworkPassphrase() string {
 	return c.networkPassphrase
 }
 
-// GetHorizonClient returns the Horizon client
+// GetHorizonClient returns the Horizon client, or nil when the client has no Horizon
 func (c *Client) GetHorizonClient() *horizonclient.Client {
 	return c.horizonClient
 }
@@ -90,12 +222,13 @@ func (c *Client) GetRPCURL() string {
 	return c.rpcURL
 }
 
-// LogContractInteraction logs a contract interaction for debugging
-func (c *Client) LogContractInteraction(contractID, function string, args map[string]interface{}) {
-	slog.Info("contract interaction",
+// LogContractInteraction logs a contract interaction for debugging. args are redacted
+// first, see LogRedactionConfig.
+func (c *Client) LogContractInteraction(ctx context.Context, contractID, function string, args map[string]interface{}) {
+	loggerFrom(ctx).Info("contract interaction",
 		"contract_id", contractID,
 		"function", function,
 		"network", c.network,
-		"args", args,
+		"args", c.logRedactor.redact(args),
 	)
 }
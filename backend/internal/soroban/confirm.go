@@ -0,0 +1,193 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// DefaultConfirmationTimeout is how long ConfirmWait polls before giving up
+const DefaultConfirmationTimeout = 60 * time.Second
+
+// ConfirmationMode selects what happens after a transaction is submitted
+type ConfirmationMode int
+
+const (
+	// ConfirmWait polls until the transaction is confirmed or the timeout elapses
+	ConfirmWait ConfirmationMode = iota
+	// ConfirmNone returns the submission result without polling
+	ConfirmNone
+	// ConfirmAsync returns the submission result immediately and polls in the background,
+	// reporting the outcome to OnConfirmed
+	ConfirmAsync
+)
+
+// ConfirmationPolicy controls how SubmitAndConfirm waits for a submitted transaction.
+// With FeeConfig.DynamicFee, a wait that times out is retried once with a fee bump.
+//
+// A confirmation timeout is not a submission failure: the transaction was accepted and
+// may still land. In that case ConfirmWait returns the submission result (Status
+// "pending", see TransactionResult.IsConfirmed) with a nil error, and ConfirmAsync
+// passes the pending result together with the timeout error to OnConfirmed. Callers
+// must not resubmit on timeout; check the hash later instead. A transaction that
+// landed but failed is an error: ConfirmWait returns the failed result (Status
+// "failed") with an error wrapping ErrTransactionFailed.
+type ConfirmationPolicy struct {
+	Mode    ConfirmationMode
+	Timeout time.Duration // defaults to DefaultConfirmationTimeout
+
+	// OnConfirmed is called once from a background goroutine in ConfirmAsync mode.
+	// err is non-nil if confirmation timed out, polling failed or Shutdown interrupted
+	// it (wrapping ErrShuttingDown); result is then the pending submission result.
+	OnConfirmed func(result *TransactionResult, err error)
+}
+
+// NoConfirmation returns a policy that does not wait for confirmation
+func NoConfirmation() ConfirmationPolicy {
+	return ConfirmationPolicy{Mode: ConfirmNone}
+}
+
+// WaitForConfirmationPolicy returns a policy that blocks for up to timeout
+func WaitForConfirmationPolicy(timeout time.Duration) ConfirmationPolicy {
+	return ConfirmationPolicy{Mode: ConfirmWait, Timeout: timeout}
+}
+
+// AsyncConfirmationPolicy returns a policy that confirms in the background for up to
+// timeout and reports the outcome to onConfirmed
+func AsyncConfirmationPolicy(timeout time.Duration, onConfirmed func(*TransactionResult, error)) ConfirmationPolicy {
+	return ConfirmationPolicy{Mode: ConfirmAsync, Timeout: timeout, OnConfirmed: onConfirmed}
+}
+
+func (p ConfirmationPolicy) timeout() time.Duration {
+	if p.Timeout <= 0 {
+		return DefaultConfirmationTimeout
+	}
+	return p.Timeout
+}
+
+// SetConfirmationPolicy changes how SubmitAndConfirm handles confirmation for all
+// subsequent calls made through this builder. Like the other setters it isn't
+// synchronized: set the policy before the builder is used concurrently.
+func (tb *TransactionBuilder) SetConfirmationPolicy(p ConfirmationPolicy) {
+	tb.confirmation = p
+}
+
+// SubmitAndConfirm builds, signs and submits operations, then applies the builder's
+// confirmation policy. extraSigners co-sign as with BuildAndSubmit. A transaction that
+// failed on-ledger comes back with an error wrapping ErrTransactionFailed, together
+// with its failed result.
+func (tb *TransactionBuilder) SubmitAndConfirm(ctx context.Context, operations []txnbuild.Operation, extraSigners ...*keypair.Full) (*TransactionResult, error) {
+	return tb.SubmitAndConfirmWithMemo(ctx, operations, nil, extraSigners...)
+}
+
+// SubmitAndConfirmWithMemo is SubmitAndConfirm with a transaction memo; nil means none.
+func (tb *TransactionBuilder) SubmitAndConfirmWithMemo(ctx context.Context, operations []txnbuild.Operation, memo txnbuild.Memo, extraSigners ...*keypair.Full) (*TransactionResult, error) {
+	ctx = ensureTraceID(ctx)
+	policy := tb.confirmation
+
+	result, err := tb.BuildAndSubmitWithMemo(ctx, operations, memo, extraSigners...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit transaction: %w", err)
+	}
+	return tb.confirm(ctx, policy, contractFunctionName(operations), result)
+}
+
+// confirm applies the confirmation policy p, taken by the caller when it submitted, to
+// a submitted transaction that invoked function, and reports the outcome to the result
+// sink. The only error it returns is an on-ledger failure in ConfirmWait mode, wrapping
+// ErrTransactionFailed; a timeout returns the pending result with a nil error.
+func (tb *TransactionBuilder) confirm(ctx context.Context, p ConfirmationPolicy, function string, submitted *TransactionResult) (*TransactionResult, error) {
+	switch p.Mode {
+	case ConfirmNone:
+		tb.recordResult(ctx, function, submitted)
+		return submitted, nil
+
+	case ConfirmAsync:
+		// The caller's context usually ends with its request; keep polling regardless.
+		bg := context.WithoutCancel(ctx)
+		tb.recordResult(ctx, function, submitted)
+		waitCtx, done := tb.inflight.track(bg)
+		go func() {
+			defer done()
+			confirmed, err := tb.awaitConfirmation(waitCtx, submitted, p.timeout())
+			err = shutdownCause(waitCtx, err)
+			if err != nil {
+				loggerFrom(bg).Warn("failed to wait for confirmation", "error", err, "tx_hash", confirmed.Hash)
+			}
+			tb.recordResult(bg, function, confirmed)
+			if p.OnConfirmed != nil {
+				p.OnConfirmed(confirmed, err)
+			}
+		}()
+		return submitted, nil
+
+	default:
+		waitCtx, done := tb.inflight.track(ctx)
+		defer done()
+		confirmed, err := tb.awaitConfirmation(waitCtx, submitted, p.timeout())
+		err = shutdownCause(waitCtx, err)
+		tb.recordResult(ctx, function, confirmed)
+		if errors.Is(err, ErrTransactionFailed) {
+			return confirmed, err
+		}
+		if err != nil {
+			// Return the pending result even if confirmation times out; the tx may still land
+			loggerFrom(ctx).Warn("failed to wait for confirmation", "error", err, "tx_hash", confirmed.Hash)
+		}
+		return confirmed, nil
+	}
+}
+
+// awaitConfirmation waits up to timeout for submitted to land. With FeeConfig.DynamicFee
+// a timeout escalates once: the transaction is fee-bumped and waited on again. On error
+// the latest pending result (the fee bump, if one was submitted) is returned with it, or
+// the failed result when the transaction failed on-ledger.
+func (tb *TransactionBuilder) awaitConfirmation(ctx context.Context, submitted *TransactionResult, timeout time.Duration) (*TransactionResult, error) {
+	confirmed, err := tb.WaitForConfirmation(ctx, submitted.Hash, timeout)
+	if err == nil {
+		return withEnvelope(confirmed, submitted), nil
+	}
+	// A fee bump only helps a transaction that is stuck, not one that failed on-ledger
+	if errors.Is(err, ErrTransactionFailed) && confirmed != nil {
+		return confirmed, err
+	}
+	if !tb.feeConfig.DynamicFee || submitted.EnvelopeXDR == "" || ctx.Err() != nil || errors.Is(err, ErrTransactionFailed) {
+		return submitted, err
+	}
+
+	newFee, feeErr := escalatedBaseFee(submitted.EnvelopeXDR, tb.feeConfig)
+	if feeErr != nil {
+		loggerFrom(ctx).Warn("not fee-bumping stuck transaction", "error", feeErr, "tx_hash", submitted.Hash)
+		return submitted, err
+	}
+
+	loggerFrom(ctx).Warn("confirmation timed out, fee-bumping transaction",
+		"tx_hash", submitted.Hash,
+		"base_fee", newFee,
+	)
+	bumped, bumpErr := tb.FeeBump(ctx, submitted.EnvelopeXDR, newFee)
+	if bumpErr != nil {
+		loggerFrom(ctx).Warn("fee-bump submission failed", "error", bumpErr, "tx_hash", submitted.Hash)
+		return submitted, err
+	}
+
+	confirmed, err = tb.WaitForConfirmation(ctx, bumped.Hash, timeout)
+	if err != nil {
+		return bumped, err
+	}
+	return withEnvelope(confirmed, bumped), nil
+}
+
+// withEnvelope carries the envelope of submitted over to its confirmed result, which the
+// RPC doesn't return, so result sinks can tell what the transaction did (see
+// RequestedPayouts)
+func withEnvelope(confirmed, submitted *TransactionResult) *TransactionResult {
+	if confirmed.EnvelopeXDR == "" {
+		confirmed.EnvelopeXDR = submitted.EnvelopeXDR
+	}
+	return confirmed
+}
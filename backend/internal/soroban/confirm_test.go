@@ -0,0 +1,155 @@
+package soroban
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+)
+
+// newConfirmTestBuilder returns a builder whose Horizon client talks to a fake server.
+// The transaction is reported as missing until `landed` is set.
+func newConfirmTestBuilder(t *testing.T, landed *atomic.Bool) *TransactionBuilder {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !landed.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"status":404,"title":"Resource Missing"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"abc","hash":"abc","ledger":1234,"successful":true}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(Config{RPCURL: srv.URL, Network: NetworkTestnet})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	client.horizonClient = &horizonclient.Client{HorizonURL: srv.URL, HTTP: srv.Client()}
+	return &TransactionBuilder{client: client}
+}
+
+func fastConfirmationPolling(t *testing.T) {
+	t.Helper()
+	prev := transactionPollInterval
+	transactionPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { transactionPollInterval = prev })
+}
+
+func submittedResult() *TransactionResult {
+	return &TransactionResult{Hash: "abc", Status: "pending", Submitted: time.Now()}
+}
+
+func TestConfirm_None(t *testing.T) {
+	var landed atomic.Bool
+	tb := newConfirmTestBuilder(t, &landed)
+	tb.SetConfirmationPolicy(NoConfirmation())
+
+	result, err := tb.confirm(context.Background(), tb.confirmation, "", submittedResult())
+	if err != nil || result.IsConfirmed() {
+		t.Errorf("ConfirmNone should return the unconfirmed submission result, got %+v, %v", result, err)
+	}
+}
+
+func TestConfirm_WaitConfirmed(t *testing.T) {
+	fastConfirmationPolling(t)
+	var landed atomic.Bool
+	landed.Store(true)
+	tb := newConfirmTestBuilder(t, &landed)
+	tb.SetConfirmationPolicy(WaitForConfirmationPolicy(time.Second))
+
+	result, err := tb.confirm(context.Background(), tb.confirmation, "", submittedResult())
+	if err != nil {
+		t.Fatalf("confirm failed: %v", err)
+	}
+	if !result.IsConfirmed() {
+		t.Fatalf("expected confirmed result, got status %q", result.Status)
+	}
+	if result.Ledger != 1234 {
+		t.Errorf("expected ledger 1234, got %d", result.Ledger)
+	}
+}
+
+// A timeout means "not seen yet", not "failed": the submission result comes back
+// unconfirmed so the caller can check the hash later rather than resubmitting.
+func TestConfirm_WaitTimeoutReturnsSubmittedResult(t *testing.T) {
+	fastConfirmationPolling(t)
+	var landed atomic.Bool
+	tb := newConfirmTestBuilder(t, &landed)
+	tb.SetConfirmationPolicy(WaitForConfirmationPolicy(50 * time.Millisecond))
+
+	submitted := submittedResult()
+	result, err := tb.confirm(context.Background(), tb.confirmation, "", submitted)
+	if err != nil || result != submitted {
+		t.Fatalf("expected the submission result and no error on timeout, got %+v, %v", result, err)
+	}
+	if result.IsConfirmed() {
+		t.Error("timed-out result must not be reported as confirmed")
+	}
+}
+
+func TestConfirm_AsyncCallback(t *testing.T) {
+	fastConfirmationPolling(t)
+	var landed atomic.Bool
+	tb := newConfirmTestBuilder(t, &landed)
+
+	type outcome struct {
+		result *TransactionResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	tb.SetConfirmationPolicy(AsyncConfirmationPolicy(5*time.Second, func(r *TransactionResult, err error) {
+		done <- outcome{r, err}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result, _ := tb.confirm(ctx, tb.confirmation, "", submittedResult())
+	if result.IsConfirmed() {
+		t.Fatal("async confirmation should return the pending result immediately")
+	}
+	// Background polling must outlive the caller's context.
+	cancel()
+	landed.Store(true)
+
+	select {
+	case o := <-done:
+		if o.err != nil {
+			t.Fatalf("unexpected error: %v", o.err)
+		}
+		if !o.result.IsConfirmed() {
+			t.Errorf("expected confirmed result in callback, got status %q", o.result.Status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not invoked")
+	}
+}
+
+func TestConfirm_AsyncTimeoutReportsPendingResult(t *testing.T) {
+	fastConfirmationPolling(t)
+	var landed atomic.Bool
+	tb := newConfirmTestBuilder(t, &landed)
+
+	done := make(chan error, 1)
+	submitted := submittedResult()
+	tb.SetConfirmationPolicy(AsyncConfirmationPolicy(50*time.Millisecond, func(r *TransactionResult, err error) {
+		if r != submitted {
+			t.Errorf("expected the submission result on timeout, got %+v", r)
+		}
+		done <- err
+	}))
+
+	tb.confirm(context.Background(), tb.confirmation, "", submitted)
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected a timeout error in the callback")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not invoked")
+	}
+}
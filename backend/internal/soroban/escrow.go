@@ -3,8 +3,6 @@ package soroban
 import (
 	"context"
 	"fmt"
-	"log/slog"
-	"time"
 
 	"github.com/stellar/go/txnbuild"
 	"github.com/stellar/go/xdr"
@@ -15,6 +13,7 @@ type EscrowContract struct {
 	client          *Client
 	txBuilder       *TransactionBuilder
 	contractAddress string
+	limits          PayoutLimits // see SetPayoutLimits
 }
 
 // NewEscrowContract creates a new escrow contract client
@@ -28,7 +27,8 @@ func NewEscrowContract(client *Client, txBuilder *TransactionBuilder, contractAd
 
 // Init initializes the escrow contract with admin and token addresses
 func (ec *EscrowContract) Init(ctx context.Context, adminAddress, tokenAddress string) (*TransactionResult, error) {
-	ec.client.LogContractInteraction(ec.contractAddress, "init", map[string]interface{}{
+	ctx = ensureTraceID(ctx)
+	ec.client.LogContractInteraction(ctx, ec.contractAddress, "init", map[string]interface{}{
 		"admin": adminAddress,
 		"token": tokenAddress,
 	})
@@ -58,18 +58,14 @@ func (ec *EscrowContract) Init(ctx context.Context, adminAddress, tokenAddress s
 		return nil, fmt.Errorf("failed to build operation: %w", err)
 	}
 
-	// Build and submit transaction
-	result, err := ec.txBuilder.BuildAndSubmit(ctx, []txnbuild.Operation{op})
-	if err != nil {
-		return nil, fmt.Errorf("failed to submit transaction: %w", err)
-	}
-
-	return result, nil
+	// Submit and apply the builder's confirmation policy
+	return ec.txBuilder.SubmitAndConfirm(ctx, []txnbuild.Operation{op})
 }
 
-// LockFunds locks funds for a specific bounty
-func (ec *EscrowContract) LockFunds(ctx context.Context, depositorAddress string, bountyID uint64, amount int64, deadline int64) (*TransactionResult, error) {
-	ec.client.LogContractInteraction(ec.contractAddress, "lock_funds", map[string]interface{}{
+// LockFunds locks funds for a specific bounty. Deadline is a unix timestamp.
+func (ec *EscrowContract) LockFunds(ctx context.Context, depositorAddress string, bountyID uint64, amount int64, deadline uint64) (*TransactionResult, error) {
+	ctx = ensureTraceID(ctx)
+	ec.client.LogContractInteraction(ctx, ec.contractAddress, "lock_funds", map[string]interface{}{
 		"depositor": depositorAddress,
 		"bounty_id": bountyID,
 		"amount":    amount,
@@ -93,12 +89,12 @@ func (ec *EscrowContract) LockFunds(ctx context.Context, depositorAddress string
 		return nil, fmt.Errorf("failed to encode bounty_id: %w", err)
 	}
 
-	amountVal, err := EncodeScValInt64(amount)
+	amountVal, err := EncodeScValInt128(amount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode amount: %w", err)
 	}
 
-	deadlineVal, err := EncodeScValInt64(deadline)
+	deadlineVal, err := EncodeScValUint64(deadline)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode deadline: %w", err)
 	}
@@ -111,28 +107,22 @@ func (ec *EscrowContract) LockFunds(ctx context.Context, depositorAddress string
 		return nil, fmt.Errorf("failed to build operation: %w", err)
 	}
 
-	// Build and submit transaction
-	result, err := ec.txBuilder.BuildAndSubmit(ctx, []txnbuild.Operation{op})
-	if err != nil {
-		return nil, fmt.Errorf("failed to submit transaction: %w", err)
-	}
-
-	// Wait for confirmation
-	confirmed, err := ec.txBuilder.WaitForConfirmation(ctx, result.Hash, 60*time.Second)
-	if err != nil {
-		slog.Warn("failed to wait for confirmation", "error", err, "tx_hash", result.Hash)
-		// Return the initial result even if confirmation times out
-		return result, nil
-	}
-
-	return confirmed, nil
+	// Submit and apply the builder's confirmation policy
+	return ec.txBuilder.SubmitAndConfirm(ctx, []txnbuild.Operation{op})
 }
 
-// ReleaseFunds releases funds to a contributor (admin only)
-func (ec *EscrowContract) ReleaseFunds(ctx context.Context, bountyID uint64, contributorAddress string) (*TransactionResult, error) {
-	ec.client.LogContractInteraction(ec.contractAddress, "release_funds", map[string]interface{}{
+// ReleaseFunds releases funds to a contributor (admin only). A nil amount releases
+// everything remaining; otherwise only that amount is paid out.
+func (ec *EscrowContract) ReleaseFunds(ctx context.Context, bountyID uint64, contributorAddress string, amount *int64) (*TransactionResult, error) {
+	ctx = ensureTraceID(ctx)
+	var loggedAmount interface{} = "all"
+	if amount != nil {
+		loggedAmount = *amount
+	}
+	ec.client.LogContractInteraction(ctx, ec.contractAddress, "release_funds", map[string]interface{}{
 		"bounty_id":   bountyID,
 		"contributor": contributorAddress,
+		"amount":      loggedAmount,
 	})
 
 	// Encode contract address
@@ -147,12 +137,28 @@ func (ec *EscrowContract) ReleaseFunds(ctx context.Context, bountyID uint64, con
 		return nil, fmt.Errorf("failed to encode bounty_id: %w", err)
 	}
 
-	contributorVal, err := EncodeScValAddress(contributorAddress)
+	// A muxed contributor is paid at its underlying account, with its ID as the memo
+	contributor, memo, err := payoutRecipient(contributorAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contributor address: %w", err)
+	}
+	contributorVal, err := EncodeScValAddress(contributor)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode contributor address: %w", err)
 	}
 
-	args := []xdr.ScVal{bountyIDVal, contributorVal}
+	amountVal := EncodeScValVoid()
+	if amount != nil {
+		if err := ec.limits.checkPayoutAmount(*amount); err != nil {
+			return nil, err
+		}
+		amountVal, err = EncodeScValInt128(*amount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode amount: %w", err)
+		}
+	}
+
+	args := []xdr.ScVal{bountyIDVal, contributorVal, amountVal}
 
 	// Build InvokeHostFunction operation
 	op, err := BuildInvokeHostFunctionOp(contractAddr, "release_funds", args)
@@ -160,26 +166,17 @@ func (ec *EscrowContract) ReleaseFunds(ctx context.Context, bountyID uint64, con
 		return nil, fmt.Errorf("failed to build operation: %w", err)
 	}
 
-	// Build and submit transaction
-	result, err := ec.txBuilder.BuildAndSubmit(ctx, []txnbuild.Operation{op})
-	if err != nil {
-		return nil, fmt.Errorf("failed to submit transaction: %w", err)
-	}
-
-	// Wait for confirmation
-	confirmed, err := ec.txBuilder.WaitForConfirmation(ctx, result.Hash, 60*time.Second)
-	if err != nil {
-		slog.Warn("failed to wait for confirmation", "error", err, "tx_hash", result.Hash)
-		return result, nil
-	}
-
-	return confirmed, nil
+	// Submit and apply the builder's confirmation policy
+	return ec.txBuilder.SubmitAndConfirmWithMemo(ctx, []txnbuild.Operation{op}, memo)
 }
 
-// Refund refunds funds to the original depositor if deadline has passed
+// Refund refunds all remaining funds to the original depositor (RefundMode::Full)
+// if the deadline has passed
 func (ec *EscrowContract) Refund(ctx context.Context, bountyID uint64) (*TransactionResult, error) {
-	ec.client.LogContractInteraction(ec.contractAddress, "refund", map[string]interface{}{
+	ctx = ensureTraceID(ctx)
+	ec.client.LogContractInteraction(ctx, ec.contractAddress, "refund", map[string]interface{}{
 		"bounty_id": bountyID,
+		"mode":      "Full",
 	})
 
 	// Encode contract address
@@ -194,28 +191,22 @@ func (ec *EscrowContract) Refund(ctx context.Context, bountyID uint64) (*Transac
 		return nil, fmt.Errorf("failed to encode bounty_id: %w", err)
 	}
 
-	args := []xdr.ScVal{bountyIDVal}
-
-	// Build InvokeHostFunction operation
-	op, err := BuildInvokeHostFunctionOp(contractAddr, "refund", args)
+	modeVal, err := EncodeScValEnumVariant("Full")
 	if err != nil {
-		return nil, fmt.Errorf("failed to build operation: %w", err)
+		return nil, fmt.Errorf("failed to encode refund mode: %w", err)
 	}
 
-	// Build and submit transaction
-	result, err := ec.txBuilder.BuildAndSubmit(ctx, []txnbuild.Operation{op})
-	if err != nil {
-		return nil, fmt.Errorf("failed to submit transaction: %w", err)
-	}
+	// refund(bounty_id, amount: Option<i128>, recipient: Option<Address>, mode)
+	args := []xdr.ScVal{bountyIDVal, EncodeScValVoid(), EncodeScValVoid(), modeVal}
 
-	// Wait for confirmation
-	confirmed, err := ec.txBuilder.WaitForConfirmation(ctx, result.Hash, 60*time.Second)
+	// Build InvokeHostFunction operation
+	op, err := BuildInvokeHostFunctionOp(contractAddr, "refund", args)
 	if err != nil {
-		slog.Warn("failed to wait for confirmation", "error", err, "tx_hash", result.Hash)
-		return result, nil
+		return nil, fmt.Errorf("failed to build operation: %w", err)
 	}
 
-	return confirmed, nil
+	// Submit and apply the builder's confirmation policy
+	return ec.txBuilder.SubmitAndConfirm(ctx, []txnbuild.Operation{op})
 }
 
 // GetEscrowInfo retrieves escrow information (read-only, uses RPC simulation)
@@ -255,7 +246,7 @@ func (ec *EscrowContract) getEscrowInfoRPC(ctx context.Context, bountyID uint64)
 	// 3. Decoding the ScVal return value
 	// 4. Converting to EscrowData struct
 
-	slog.Warn("GetEscrowInfo requires transaction building and XDR decoding")
+	loggerFrom(ctx).Warn("GetEscrowInfo requires transaction building and XDR decoding")
 	return nil, fmt.Errorf("GetEscrowInfo requires transaction building - use RPC simulateTransaction")
 }
 
@@ -268,6 +259,6 @@ func (ec *EscrowContract) GetBalance(ctx context.Context) (int64, error) {
 // getBalanceRPC uses Soroban RPC to get contract balance
 func (ec *EscrowContract) getBalanceRPC(ctx context.Context) (int64, error) {
 	// Similar to getEscrowInfoRPC - requires transaction building and XDR decoding
-	slog.Warn("GetBalance requires transaction building and XDR decoding")
+	loggerFrom(ctx).Warn("GetBalance requires transaction building and XDR decoding")
 	return 0, fmt.Errorf("GetBalance requires transaction building - use RPC simulateTransaction")
 }
@@ -0,0 +1,227 @@
+package soroban
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+// Event topics of the escrow contracts' payout events
+const (
+	// bounty_escrow: topics (Symbol "f_rel", u64 bounty_id), data FundsReleased struct.
+	// batch_release_funds emits one per item, followed by a "b_rel" summary.
+	bountyReleaseTopic = "f_rel"
+	// program-escrow: topics (Symbol "Payout"), data (program_id, recipient, amount,
+	// remaining_balance). Batch payouts only emit a "BatchPay" summary without the
+	// recipients, so they can't be decoded into payouts.
+	programPayoutTopic = "Payout"
+	// program-escrow: topics (Symbol "BatchPay"), data (program_id, u32 count, total,
+	// remaining_balance)
+	programBatchPayoutTopic = "BatchPay"
+)
+
+// ContractEvent is a contract event as returned by the RPC getEvents method. Topic and
+// Value hold base64-encoded ScVal XDR.
+type ContractEvent struct {
+	Type           string   `json:"type"`
+	Ledger         uint32   `json:"ledger"`
+	LedgerClosedAt string   `json:"ledgerClosedAt"`
+	ContractID     string   `json:"contractId"`
+	ID             string   `json:"id"`
+	TxHash         string   `json:"txHash"`
+	Topic          []string `json:"topic"`
+	Value          string   `json:"value"`
+}
+
+// PayoutEvent is a payout made by an escrow contract, decoded from its event
+type PayoutEvent struct {
+	ContractID string    `json:"contract_id"`
+	Recipient  string    `json:"recipient"` // G... account or C... contract strkey
+	Amount     int64     `json:"amount"`    // net amount received, in the token's base units
+	Timestamp  time.Time `json:"timestamp"`
+	TxHash     string    `json:"tx_hash"`
+}
+
+// DecodePayoutEvent decodes a bounty release or program payout event. It returns nil
+// and no error for events that aren't payouts.
+func DecodePayoutEvent(ev ContractEvent) (*PayoutEvent, error) {
+	if (ev.Type != "" && ev.Type != "contract") || len(ev.Topic) == 0 {
+		return nil, nil
+	}
+	var topic xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(ev.Topic[0], &topic); err != nil {
+		return nil, fmt.Errorf("failed to decode event topic: %w", err)
+	}
+	sym, ok := topic.GetSym()
+	if !ok {
+		return nil, nil
+	}
+
+	var decode func(xdr.ScVal, ContractEvent, *PayoutEvent) error
+	switch string(sym) {
+	case bountyReleaseTopic:
+		decode = decodeBountyRelease
+	case programPayoutTopic:
+		decode = decodeProgramPayout
+	default:
+		return nil, nil
+	}
+
+	var value xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(ev.Value, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event value: %w", sym, err)
+	}
+	payout := &PayoutEvent{ContractID: ev.ContractID, TxHash: ev.TxHash}
+	if err := decode(value, ev, payout); err != nil {
+		return nil, fmt.Errorf("invalid %s event %s: %w", sym, ev.ID, err)
+	}
+	return payout, nil
+}
+
+// DecodePayoutEvents decodes the payouts among events, in order, skipping unrelated events
+func DecodePayoutEvents(events []ContractEvent) ([]PayoutEvent, error) {
+	var payouts []PayoutEvent
+	for _, ev := range events {
+		payout, err := DecodePayoutEvent(ev)
+		if err != nil {
+			return nil, err
+		}
+		if payout != nil {
+			payouts = append(payouts, *payout)
+		}
+	}
+	return payouts, nil
+}
+
+// batchPayoutSummary is the BatchPay event of a program escrow batch payout: how many
+// recipients the transaction paid and the total, including the contract's fees
+type batchPayoutSummary struct {
+	TxHash string
+	Count  int
+	Total  int64
+}
+
+// decodeBatchPayoutSummary decodes a program BatchPay event. It returns nil and no
+// error for any other event.
+func decodeBatchPayoutSummary(ev ContractEvent) (*batchPayoutSummary, error) {
+	if (ev.Type != "" && ev.Type != "contract") || len(ev.Topic) == 0 {
+		return nil, nil
+	}
+	var topic xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(ev.Topic[0], &topic); err != nil {
+		return nil, fmt.Errorf("failed to decode event topic: %w", err)
+	}
+	if sym, ok := topic.GetSym(); !ok || string(sym) != programBatchPayoutTopic {
+		return nil, nil
+	}
+
+	var value xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(ev.Value, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event value: %w", programBatchPayoutTopic, err)
+	}
+	vec, ok := value.GetVec()
+	if !ok || vec == nil || len(*vec) != 4 {
+		return nil, fmt.Errorf("invalid %s event %s: expected a 4-element vec, got %s", programBatchPayoutTopic, ev.ID, value.Type)
+	}
+	count, ok := (*vec)[1].GetU32()
+	if !ok {
+		return nil, fmt.Errorf("invalid %s event %s: count: expected u32, got %s", programBatchPayoutTopic, ev.ID, (*vec)[1].Type)
+	}
+	total, err := decodeScInt128((*vec)[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s event %s: total: %w", programBatchPayoutTopic, ev.ID, err)
+	}
+	return &batchPayoutSummary{TxHash: ev.TxHash, Count: int(count), Total: total}, nil
+}
+
+// decodeBountyRelease reads a FundsReleased struct, which #[contracttype] encodes as a
+// map keyed by field name.
+func decodeBountyRelease(value xdr.ScVal, _ ContractEvent, payout *PayoutEvent) error {
+	fields, err := decodeScStruct(value)
+	if err != nil {
+		return err
+	}
+	if payout.Recipient, err = decodeScAddress(fields["recipient"]); err != nil {
+		return fmt.Errorf("recipient: %w", err)
+	}
+	if payout.Amount, err = decodeScInt128(fields["amount"]); err != nil {
+		return fmt.Errorf("amount: %w", err)
+	}
+	ts, ok := fields["timestamp"].GetU64()
+	if !ok {
+		return fmt.Errorf("timestamp: expected u64, got %s", fields["timestamp"].Type)
+	}
+	payout.Timestamp = time.Unix(int64(ts), 0).UTC()
+	return nil
+}
+
+// decodeProgramPayout reads a (program_id, recipient, amount, remaining_balance) tuple.
+// The event carries no timestamp, so the ledger close time is used.
+func decodeProgramPayout(value xdr.ScVal, ev ContractEvent, payout *PayoutEvent) error {
+	vec, ok := value.GetVec()
+	if !ok || vec == nil || len(*vec) != 4 {
+		return fmt.Errorf("expected a 4-element vec, got %s", value.Type)
+	}
+	var err error
+	if payout.Recipient, err = decodeScAddress((*vec)[1]); err != nil {
+		return fmt.Errorf("recipient: %w", err)
+	}
+	if payout.Amount, err = decodeScInt128((*vec)[2]); err != nil {
+		return fmt.Errorf("amount: %w", err)
+	}
+	if payout.Timestamp, err = time.Parse(time.RFC3339, ev.LedgerClosedAt); err != nil {
+		return fmt.Errorf("invalid ledgerClosedAt: %w", err)
+	}
+	return nil
+}
+
+// decodeScStruct returns the fields of a #[contracttype] struct, which is encoded as a
+// map keyed by field name
+func decodeScStruct(value xdr.ScVal) (map[string]xdr.ScVal, error) {
+	m, ok := value.GetMap()
+	if !ok || m == nil {
+		return nil, fmt.Errorf("expected a map, got %s", value.Type)
+	}
+	fields := make(map[string]xdr.ScVal, len(*m))
+	for _, entry := range *m {
+		if key, ok := entry.Key.GetSym(); ok {
+			fields[string(key)] = entry.Val
+		}
+	}
+	return fields, nil
+}
+
+// decodeScAddress returns the strkey form of an account or contract address
+func decodeScAddress(v xdr.ScVal) (string, error) {
+	addr, ok := v.GetAddress()
+	if !ok {
+		return "", fmt.Errorf("expected an address, got %s", v.Type)
+	}
+	switch addr.Type {
+	case xdr.ScAddressTypeScAddressTypeAccount:
+		return addr.AccountId.Address(), nil
+	case xdr.ScAddressTypeScAddressTypeContract:
+		return strkey.Encode(strkey.VersionByteContract, addr.ContractId[:])
+	default:
+		return "", fmt.Errorf("unsupported address type %s", addr.Type)
+	}
+}
+
+// decodeScInt128 reads an i128 that fits in an int64 (see EncodeScValInt128)
+func decodeScInt128(v xdr.ScVal) (int64, error) {
+	parts, ok := v.GetI128()
+	if !ok {
+		return 0, fmt.Errorf("expected i128, got %s", v.Type)
+	}
+	switch {
+	case parts.Hi == 0 && uint64(parts.Lo) <= math.MaxInt64:
+		return int64(parts.Lo), nil
+	case parts.Hi == -1 && uint64(parts.Lo) > math.MaxInt64:
+		return int64(parts.Lo), nil
+	default:
+		return 0, fmt.Errorf("i128 value out of int64 range")
+	}
+}
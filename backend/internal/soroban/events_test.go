@@ -0,0 +1,150 @@
+package soroban
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/xdr"
+)
+
+// Recorded escrow contract events (topic and value XDR as returned by getEvents)
+const (
+	testPayoutAlice = "GAAQEAYEAUDAOCAJBIFQYDIOB4IBCEQTCQKRMFYYDENBWHA5DYPSABOV"
+	testPayoutBob   = "GAQSEIZEEUTCOKBJFIVSYLJOF4YDCMRTGQ2TMNZYHE5DWPB5HY7UAIOK"
+	testPayoutCarol = "CCVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKVKUD2U"
+
+	topicFundsReleased = "AAAADwAAAAVmX3JlbAAAAA=="
+	topicBatchReleased = "AAAADwAAAAViX3JlbAAAAA=="
+	topicProgramPayout = "AAAADwAAAAZQYXlvdXQAAA=="
+	topicBatchPay      = "AAAADwAAAAhCYXRjaFBheQ=="
+)
+
+func TestDecodePayoutEvent_BountyRelease(t *testing.T) {
+	// release_funds(42, alice): 1000 XLM at 1760000000
+	ev := ContractEvent{
+		Type:       "contract",
+		ContractID: "CAAQEAYEAUDAOCAJBIFQYDIOB4IBCEQTCQKRMFYYDENBWHA5DYPSBFLM",
+		ID:         "0000000000000000001-0000000000",
+		TxHash:     "aa11",
+		Topic:      []string{topicFundsReleased, "AAAABQAAAAAAAAAq"},
+		Value:      "AAAAEQAAAAEAAAAFAAAADwAAAAZhbW91bnQAAAAAAAoAAAAAAAAAAAAAAAJUC+QAAAAADwAAAAlib3VudHlfaWQAAAAAAAAFAAAAAAAAACoAAAAPAAAACXJlY2lwaWVudAAAAAAAABIAAAAAAAAAAAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8gAAAADwAAABByZW1haW5pbmdfYW1vdW50AAAACgAAAAAAAAAAAAAAAAAAAAAAAAAPAAAACXRpbWVzdGFtcAAAAAAAAAUAAAAAaOd4AA==",
+	}
+
+	payout, err := DecodePayoutEvent(ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := PayoutEvent{
+		ContractID: ev.ContractID,
+		Recipient:  testPayoutAlice,
+		Amount:     10_000_000_000,
+		Timestamp:  time.Unix(1760000000, 0).UTC(),
+		TxHash:     "aa11",
+	}
+	if payout == nil || *payout != want {
+		t.Errorf("got %+v, want %+v", payout, want)
+	}
+}
+
+func TestDecodePayoutEvent_ProgramPayout(t *testing.T) {
+	// single_payout("hack-2025", bob, 500 XLM)
+	ev := ContractEvent{
+		Type:           "contract",
+		LedgerClosedAt: "2025-10-09T08:53:20Z",
+		TxHash:         "bb22",
+		Topic:          []string{topicProgramPayout},
+		Value:          "AAAAEAAAAAEAAAAEAAAADgAAAAloYWNrLTIwMjUAAAAAAAASAAAAAAAAAAAhIiMkJSYnKCkqKywtLi8wMTIzNDU2Nzg5Ojs8PT4/QAAAAAoAAAAAAAAAAAAAAAEqBfIAAAAACgAAAAAAAAAAAAAAA34R1gA=",
+	}
+
+	payout, err := DecodePayoutEvent(ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payout == nil || payout.Recipient != testPayoutBob || payout.Amount != 5_000_000_000 || payout.TxHash != "bb22" {
+		t.Fatalf("unexpected payout %+v", payout)
+	}
+	if !payout.Timestamp.Equal(time.Date(2025, 10, 9, 8, 53, 20, 0, time.UTC)) {
+		t.Errorf("expected the ledger close time, got %v", payout.Timestamp)
+	}
+}
+
+func TestDecodePayoutEvents_Batch(t *testing.T) {
+	// batch_release_funds([(7, alice), (8, carol)]) emits one f_rel per item and a b_rel
+	// summary; a program BatchPay summary and a diagnostic event are skipped as well.
+	events := []ContractEvent{
+		{
+			Type:   "contract",
+			TxHash: "cc33",
+			Topic:  []string{topicFundsReleased, "AAAABQAAAAAAAAAH"},
+			Value:  "AAAAEQAAAAEAAAAFAAAADwAAAAZhbW91bnQAAAAAAAoAAAAAAAAAAAAAAACVAvkAAAAADwAAAAlib3VudHlfaWQAAAAAAAAFAAAAAAAAAAcAAAAPAAAACXJlY2lwaWVudAAAAAAAABIAAAAAAAAAAAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8gAAAADwAAABByZW1haW5pbmdfYW1vdW50AAAACgAAAAAAAAAAAAAAAAAAAAAAAAAPAAAACXRpbWVzdGFtcAAAAAAAAAUAAAAAaOd4ZA==",
+		},
+		{
+			Type:   "contract",
+			TxHash: "cc33",
+			Topic:  []string{topicFundsReleased, "AAAABQAAAAAAAAAI"},
+			Value:  "AAAAEQAAAAEAAAAFAAAADwAAAAZhbW91bnQAAAAAAAoAAAAAAAAAAAAABIwnOVAAAAAADwAAAAlib3VudHlfaWQAAAAAAAAFAAAAAAAAAAgAAAAPAAAACXJlY2lwaWVudAAAAAAAABIAAAABqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqoAAAAPAAAAEHJlbWFpbmluZ19hbW91bnQAAAAKAAAAAAAAAAAAAAAAAAAAAAAAAA8AAAAJdGltZXN0YW1wAAAAAAAABQAAAABo53hk",
+		},
+		{
+			Type:   "contract",
+			TxHash: "cc33",
+			Topic:  []string{topicBatchReleased},
+			Value:  "AAAAEQAAAAEAAAADAAAADwAAAAVjb3VudAAAAAAAAAMAAAACAAAADwAAAAl0aW1lc3RhbXAAAAAAAAAFAAAAAGjneGQAAAAPAAAADHRvdGFsX2Ftb3VudAAAAAoAAAAAAAAAAAAABIy8PEkA",
+		},
+		{
+			Type:   "contract",
+			TxHash: "dd44",
+			Topic:  []string{topicBatchPay},
+			Value:  "AAAAEAAAAAEAAAAEAAAADgAAAAloYWNrLTIwMjUAAAAAAAADAAAAAgAAAAoAAAAAAAAAAAAAAAAAAABkAAAACgAAAAAAAAAAAAAAAAAAAAA=",
+		},
+		{
+			Type:  "diagnostic",
+			Topic: []string{topicFundsReleased},
+		},
+	}
+
+	payouts, err := DecodePayoutEvents(events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(payouts) != 2 {
+		t.Fatalf("expected 2 payouts, got %+v", payouts)
+	}
+	if payouts[0].Recipient != testPayoutAlice || payouts[0].Amount != 2_500_000_000 {
+		t.Errorf("unexpected first payout %+v", payouts[0])
+	}
+	// Recipients may be contracts, and amounts may exceed 32 bits
+	if payouts[1].Recipient != testPayoutCarol || payouts[1].Amount != 5_000_000_000_000 {
+		t.Errorf("unexpected second payout %+v", payouts[1])
+	}
+	for _, p := range payouts {
+		if p.TxHash != "cc33" || !p.Timestamp.Equal(time.Unix(1760000100, 0)) {
+			t.Errorf("unexpected tx hash or timestamp in %+v", p)
+		}
+	}
+}
+
+func TestDecodePayoutEvent_Malformed(t *testing.T) {
+	// A payout topic with a value of the wrong shape is an error, not a skipped event
+	ev := ContractEvent{
+		Type:  "contract",
+		Topic: []string{topicProgramPayout},
+		Value: "AAAADwAAAAVmX3JlbAAAAA==",
+	}
+	if _, err := DecodePayoutEvent(ev); err == nil {
+		t.Error("expected an error for a malformed payout value")
+	}
+
+	// i128 amounts beyond int64 are rejected rather than truncated
+	if _, err := decodeScInt128(mustDecodeScVal(t, "AAAACgAAAAAAAABAAAAAAAAAAAA=")); err == nil {
+		t.Error("expected an out of range error for 2^70")
+	}
+}
+
+func mustDecodeScVal(t *testing.T, b64 string) xdr.ScVal {
+	t.Helper()
+	var v xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(b64, &v); err != nil {
+		t.Fatalf("decode %s: %v", b64, err)
+	}
+	return v
+}
@@ -0,0 +1,122 @@
+package soroban
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// defaultFeeBumpMultiplier is used when FeeConfig.BumpMultiplier is not above 1
+const defaultFeeBumpMultiplier = 2.0
+
+// SetFeeConfig changes fee escalation for subsequent submissions
+func (tb *TransactionBuilder) SetFeeConfig(cfg FeeConfig) {
+	tb.feeConfig = cfg
+}
+
+// SetFeeSource makes a separate account pay for fee-bump transactions. By default the
+// builder's source account pays.
+func (tb *TransactionBuilder) SetFeeSource(feeSourceSecret string) error {
+	kp, err := keypair.ParseFull(feeSourceSecret)
+	if err != nil {
+		return fmt.Errorf("invalid fee source secret: %w", err)
+	}
+	tb.feeSourceKP = kp
+	return nil
+}
+
+// FeeBump wraps the signed transaction innerTxXDR in a fee-bump transaction that pays
+// newFee stroops per operation (the inner operations plus the fee bump itself), signs
+// it with the fee source and submits it. The inner transaction keeps its sequence
+// number and signatures, so only one of the two can ever land.
+func (tb *TransactionBuilder) FeeBump(ctx context.Context, innerTxXDR string, newFee int64) (*TransactionResult, error) {
+	ctx = ensureTraceID(ctx)
+
+	fb, err := tb.buildFeeBump(innerTxXDR, newFee)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, err := fb.Base64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode fee-bump transaction: %w", err)
+	}
+
+	loggerFrom(ctx).Info("submitting fee-bump transaction",
+		"fee_account", fb.FeeAccount(),
+		"base_fee", fb.BaseFee(),
+		"max_fee", fb.MaxFee(),
+	)
+
+	result, err := tb.submitWithRetry(ctx, envelope)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the inner envelope so the transaction can be bumped again if needed
+	result.EnvelopeXDR = innerTxXDR
+	return result, nil
+}
+
+// buildFeeBump builds and signs the fee-bump envelope for innerTxXDR
+func (tb *TransactionBuilder) buildFeeBump(innerTxXDR string, newFee int64) (*txnbuild.FeeBumpTransaction, error) {
+	generic, err := txnbuild.TransactionFromXDR(innerTxXDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inner transaction: %w", err)
+	}
+	inner, ok := generic.Transaction()
+	if !ok {
+		return nil, fmt.Errorf("inner transaction must not itself be a fee-bump transaction")
+	}
+
+	feeSource := tb.feeSource()
+	fb, err := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: feeSource.Address(),
+		BaseFee:    newFee,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build fee-bump transaction: %w", err)
+	}
+
+	fb, err = fb.Sign(tb.client.GetNetworkPassphrase(), feeSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign fee-bump transaction: %w", err)
+	}
+	return fb, nil
+}
+
+func (tb *TransactionBuilder) feeSource() *keypair.Full {
+	if tb.feeSourceKP != nil {
+		return tb.feeSourceKP
+	}
+	return tb.sourceKP
+}
+
+// escalatedBaseFee returns the per-operation base fee to bump innerTxXDR to under cfg
+func escalatedBaseFee(innerTxXDR string, cfg FeeConfig) (int64, error) {
+	generic, err := txnbuild.TransactionFromXDR(innerTxXDR)
+	if err != nil {
+		return 0, fmt.Errorf("invalid inner transaction: %w", err)
+	}
+	inner, ok := generic.Transaction()
+	if !ok {
+		return 0, fmt.Errorf("inner transaction must not itself be a fee-bump transaction")
+	}
+
+	multiplier := cfg.BumpMultiplier
+	if multiplier <= 1 {
+		multiplier = defaultFeeBumpMultiplier
+	}
+	base := inner.BaseFee()
+	fee := int64(math.Ceil(float64(base) * multiplier))
+	if cfg.MaxBaseFee > 0 && fee > cfg.MaxBaseFee {
+		fee = cfg.MaxBaseFee
+	}
+	if fee <= base {
+		return 0, fmt.Errorf("fee cap %d reached, cannot bump base fee %d", cfg.MaxBaseFee, base)
+	}
+	return fee, nil
+}
@@ -0,0 +1,215 @@
+package soroban
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+)
+
+// signedInnerTx returns a signed one-operation testnet transaction envelope
+func signedInnerTx(t *testing.T, source *keypair.Full, baseFee int64) string {
+	t.Helper()
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: source.Address(), Sequence: 100},
+		IncrementSequenceNum: true,
+		BaseFee:              baseFee,
+		Operations:           []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 0}},
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewTimeout(300)},
+	})
+	if err != nil {
+		t.Fatalf("build inner tx: %v", err)
+	}
+	tx, err = tx.Sign(network.TestNetworkPassphrase, source)
+	if err != nil {
+		t.Fatalf("sign inner tx: %v", err)
+	}
+	envelope, err := tx.Base64()
+	if err != nil {
+		t.Fatalf("encode inner tx: %v", err)
+	}
+	return envelope
+}
+
+func newFeeBumpTestBuilder(t *testing.T, rpcURL string) (*TransactionBuilder, *keypair.Full) {
+	t.Helper()
+	client, err := NewClient(Config{RPCURL: rpcURL, Network: NetworkTestnet})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	source := keypair.MustRandom()
+	return &TransactionBuilder{client: client, sourceKP: source}, source
+}
+
+func TestBuildFeeBump_Structure(t *testing.T) {
+	tb, source := newFeeBumpTestBuilder(t, "http://localhost:8000")
+	feeSource := keypair.MustRandom()
+	if err := tb.SetFeeSource(feeSource.Seed()); err != nil {
+		t.Fatalf("SetFeeSource failed: %v", err)
+	}
+
+	innerXDR := signedInnerTx(t, source, txnbuild.MinBaseFee)
+	fb, err := tb.buildFeeBump(innerXDR, 500)
+	if err != nil {
+		t.Fatalf("buildFeeBump failed: %v", err)
+	}
+
+	if fb.FeeAccount() != feeSource.Address() {
+		t.Errorf("expected fee account %s, got %s", feeSource.Address(), fb.FeeAccount())
+	}
+	if fb.BaseFee() != 500 {
+		t.Errorf("expected base fee 500, got %d", fb.BaseFee())
+	}
+	// One inner operation plus the fee bump itself
+	if fb.MaxFee() != 1000 {
+		t.Errorf("expected max fee 1000, got %d", fb.MaxFee())
+	}
+
+	inner := fb.InnerTransaction()
+	if inner.SourceAccount().AccountID != source.Address() || inner.SequenceNumber() != 101 {
+		t.Errorf("inner transaction changed: source %s seq %d", inner.SourceAccount().AccountID, inner.SequenceNumber())
+	}
+	if len(inner.Signatures()) != 1 {
+		t.Errorf("expected inner signature to be preserved, got %d", len(inner.Signatures()))
+	}
+
+	if len(fb.Signatures()) != 1 {
+		t.Fatalf("expected 1 fee-bump signature, got %d", len(fb.Signatures()))
+	}
+	hash, err := fb.Hash(network.TestNetworkPassphrase)
+	if err != nil {
+		t.Fatalf("hash fee bump: %v", err)
+	}
+	if err := feeSource.Verify(hash[:], fb.Signatures()[0].Signature); err != nil {
+		t.Errorf("fee bump not signed by the fee source: %v", err)
+	}
+}
+
+func TestBuildFeeBump_DefaultsToSourceAccount(t *testing.T) {
+	tb, source := newFeeBumpTestBuilder(t, "http://localhost:8000")
+
+	fb, err := tb.buildFeeBump(signedInnerTx(t, source, txnbuild.MinBaseFee), 200)
+	if err != nil {
+		t.Fatalf("buildFeeBump failed: %v", err)
+	}
+	if fb.FeeAccount() != source.Address() {
+		t.Errorf("expected the source account to pay, got %s", fb.FeeAccount())
+	}
+}
+
+func TestBuildFeeBump_RejectsLowerFee(t *testing.T) {
+	tb, source := newFeeBumpTestBuilder(t, "http://localhost:8000")
+
+	if _, err := tb.buildFeeBump(signedInnerTx(t, source, 1000), 500); err == nil {
+		t.Error("expected an error when the bump fee is below the inner fee")
+	}
+}
+
+func TestEscalatedBaseFee(t *testing.T) {
+	innerXDR := signedInnerTx(t, keypair.MustRandom(), 100)
+
+	cases := []struct {
+		cfg  FeeConfig
+		want int64
+	}{
+		{FeeConfig{}, 200},
+		{FeeConfig{BumpMultiplier: 1.5}, 150},
+		{FeeConfig{BumpMultiplier: 10, MaxBaseFee: 400}, 400},
+	}
+	for _, tc := range cases {
+		got, err := escalatedBaseFee(innerXDR, tc.cfg)
+		if err != nil {
+			t.Fatalf("%+v: unexpected error: %v", tc.cfg, err)
+		}
+		if got != tc.want {
+			t.Errorf("%+v: expected %d, got %d", tc.cfg, tc.want, got)
+		}
+	}
+
+	if _, err := escalatedBaseFee(innerXDR, FeeConfig{MaxBaseFee: 100}); err == nil {
+		t.Error("expected an error once the cap is reached")
+	}
+}
+
+func TestConfirm_DynamicFeeBumpsOnTimeout(t *testing.T) {
+	fastConfirmationPolling(t)
+
+	var bumpSubmitted bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/transactions":
+			generic, err := txnbuild.TransactionFromXDR(r.FormValue("tx"))
+			if err != nil {
+				t.Errorf("invalid envelope: %v", err)
+			} else if _, isFeeBump := generic.FeeBump(); !isFeeBump {
+				t.Error("expected a fee-bump envelope")
+			}
+			bumpSubmitted = true
+			_, _ = w.Write([]byte(`{"id":"bumped","hash":"bumped","ledger":12,"successful":true}`))
+		case r.URL.Path == "/transactions/bumped":
+			_, _ = w.Write([]byte(`{"id":"bumped","hash":"bumped","ledger":12,"successful":true}`))
+		default:
+			// The original submission never lands
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"status":404,"title":"Resource Missing"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tb, source := newFeeBumpTestBuilder(t, srv.URL)
+	tb.client.horizonClient = &horizonclient.Client{HorizonURL: srv.URL, HTTP: srv.Client()}
+	tb.retryConfig = RetryConfig{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1}
+	tb.SetConfirmationPolicy(WaitForConfirmationPolicy(50 * time.Millisecond))
+	tb.SetFeeConfig(FeeConfig{DynamicFee: true})
+
+	submitted := &TransactionResult{
+		Hash:        "stuck",
+		Status:      "pending",
+		EnvelopeXDR: signedInnerTx(t, source, txnbuild.MinBaseFee),
+	}
+	result, err := tb.confirm(context.Background(), tb.confirmation, "", submitted)
+	if err != nil {
+		t.Fatalf("confirm failed: %v", err)
+	}
+
+	if !bumpSubmitted {
+		t.Fatal("expected a fee-bump submission after the confirmation timeout")
+	}
+	if !result.IsConfirmed() || result.Hash != "bumped" {
+		t.Errorf("expected the confirmed fee bump, got %+v", result)
+	}
+}
+
+func TestConfirm_NoFeeBumpWithoutDynamicFee(t *testing.T) {
+	fastConfirmationPolling(t)
+
+	var posts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"status":404,"title":"Resource Missing"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	tb, source := newFeeBumpTestBuilder(t, srv.URL)
+	tb.client.horizonClient = &horizonclient.Client{HorizonURL: srv.URL, HTTP: srv.Client()}
+	tb.SetConfirmationPolicy(WaitForConfirmationPolicy(50 * time.Millisecond))
+
+	submitted := &TransactionResult{Hash: "stuck", Status: "pending", EnvelopeXDR: signedInnerTx(t, source, txnbuild.MinBaseFee)}
+	if result, _ := tb.confirm(context.Background(), tb.confirmation, "", submitted); result != submitted {
+		t.Errorf("expected the pending submission result, got %+v", result)
+	}
+	if posts != 0 {
+		t.Errorf("expected no fee-bump submission, got %d", posts)
+	}
+}
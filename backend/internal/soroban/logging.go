@@ -0,0 +1,126 @@
+package soroban
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type loggerKey struct{}
+type traceIDKey struct{}
+
+// WithLogger attaches logger to ctx; every log line this package emits for calls made
+// with the returned context goes through it. Without one, slog.Default() is used.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// WithTraceID tags ctx with a correlation ID that is added as "trace_id" to every log
+// line for the operation (submit, retries, confirmation, RPC calls). Handlers can pass
+// their request ID here; otherwise contract calls generate one.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	ctx = context.WithValue(ctx, traceIDKey{}, traceID)
+	return WithLogger(ctx, loggerFrom(ctx).With("trace_id", traceID))
+}
+
+// TraceIDFromContext returns the trace ID set by WithTraceID, or "".
+func TraceIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// ensureTraceID gives ctx a fresh trace ID unless it already carries one, so an
+// operation started without one is still correlated across its log lines.
+func ensureTraceID(ctx context.Context) context.Context {
+	if TraceIDFromContext(ctx) != "" {
+		return ctx
+	}
+	return WithTraceID(ctx, uuid.NewString())
+}
+
+// loggerFrom returns the logger attached to ctx, or slog.Default().
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// DefaultLogMaxCollectionLen is the largest slice or map LogContractInteraction logs in
+// full when LogRedactionConfig.MaxCollectionLen is zero
+const DefaultLogMaxCollectionLen = 10
+
+// redactedValue replaces the value of a masked contract call arg in logs
+const redactedValue = "***"
+
+// defaultLogMaskKeys are always masked in contract call args
+var defaultLogMaskKeys = []string{"secret", "seed", "private_key", "signing_key"}
+
+// LogRedactionConfig controls how LogContractInteraction writes contract call args.
+type LogRedactionConfig struct {
+	// MaskKeys are arg keys, matched case-insensitively, logged as "***" on top of a
+	// few always-masked ones (secret, seed, private_key, signing_key). Nested maps are
+	// masked too.
+	MaskKeys []string
+	// MaxCollectionLen logs slices, arrays and maps longer than this as their length
+	// only, e.g. "250 items". Zero uses DefaultLogMaxCollectionLen; negative logs them
+	// in full.
+	MaxCollectionLen int
+}
+
+// argRedactor applies a LogRedactionConfig
+type argRedactor struct {
+	mask   map[string]bool
+	maxLen int // 0: no cap
+}
+
+func newArgRedactor(cfg LogRedactionConfig) argRedactor {
+	r := argRedactor{mask: make(map[string]bool), maxLen: cfg.MaxCollectionLen}
+	for _, key := range append(defaultLogMaskKeys, cfg.MaskKeys...) {
+		r.mask[strings.ToLower(strings.TrimSpace(key))] = true
+	}
+	switch {
+	case r.maxLen == 0:
+		r.maxLen = DefaultLogMaxCollectionLen
+	case r.maxLen < 0:
+		r.maxLen = 0
+	}
+	return r
+}
+
+// redact returns a copy of args safe to log; args itself is left alone
+func (r argRedactor) redact(args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		if r.mask[strings.ToLower(key)] {
+			out[key] = redactedValue
+			continue
+		}
+		out[key] = r.redactValue(value)
+	}
+	return out
+}
+
+func (r argRedactor) redactValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if r.maxLen > 0 && v.Len() > r.maxLen {
+			return fmt.Sprintf("%d items", v.Len())
+		}
+	}
+	if nested, ok := value.(map[string]interface{}); ok {
+		return r.redact(nested)
+	}
+	return value
+}
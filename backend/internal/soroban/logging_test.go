@@ -0,0 +1,176 @@
+package soroban
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// newFakeHorizon serves account lookups, rejects the first submission with a 503 (so
+// the retry path logs), accepts the second, and reports the transaction as confirmed.
+func newFakeHorizon(t *testing.T, accountID string) *httptest.Server {
+	t.Helper()
+	var submissions atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/accounts/"):
+			_, _ = w.Write([]byte(`{"id":"` + accountID + `","account_id":"` + accountID + `","sequence":"100","balances":[{"balance":"10000.0000000","asset_type":"native"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/transactions":
+			if submissions.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"status":503,"title":"Service Unavailable"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"abc","hash":"abc","ledger":7,"successful":true}`))
+		case strings.HasPrefix(r.URL.Path, "/transactions/"):
+			_, _ = w.Write([]byte(`{"id":"abc","hash":"abc","ledger":7,"successful":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"status":404,"title":"Resource Missing"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSubmitAndConfirm_SharesTraceIDAcrossLogLines(t *testing.T) {
+	fastConfirmationPolling(t)
+
+	source := keypair.MustRandom()
+	srv := newFakeHorizon(t, source.Address())
+
+	client, err := NewClient(Config{RPCURL: srv.URL, Network: NetworkTestnet})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	client.horizonClient = &horizonclient.Client{HorizonURL: srv.URL, HTTP: srv.Client()}
+
+	tb, err := NewTransactionBuilder(client, source.Seed(), RetryConfig{
+		MaxRetries:        2,
+		InitialDelay:      time.Millisecond,
+		MaxDelay:          time.Millisecond,
+		BackoffMultiplier: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewTransactionBuilder failed: %v", err)
+	}
+	tb.SetConfirmationPolicy(WaitForConfirmationPolicy(time.Second))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ctx := WithLogger(context.Background(), logger)
+
+	result, err := tb.SubmitAndConfirm(ctx, []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 0}})
+	if err != nil {
+		t.Fatalf("SubmitAndConfirm failed: %v", err)
+	}
+	if !result.IsConfirmed() {
+		t.Fatalf("expected confirmed result, got status %q", result.Status)
+	}
+
+	var traceID string
+	messages := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		id, _ := entry["trace_id"].(string)
+		if id == "" {
+			t.Fatalf("log line without trace_id: %s", line)
+		}
+		if traceID == "" {
+			traceID = id
+		} else if id != traceID {
+			t.Fatalf("trace_id changed from %q to %q: %s", traceID, id, line)
+		}
+		messages[entry["msg"].(string)] = true
+	}
+
+	for _, msg := range []string{
+		"transaction submission failed",
+		"retrying transaction submission",
+		"transaction submitted successfully",
+		"transaction confirmed",
+	} {
+		if !messages[msg] {
+			t.Errorf("expected a %q log line, got %v", msg, messages)
+		}
+	}
+}
+
+func TestWithTraceID_KeepsCallerID(t *testing.T) {
+	ctx := WithTraceID(context.Background(), "req-123")
+	if got := TraceIDFromContext(ensureTraceID(ctx)); got != "req-123" {
+		t.Errorf("expected caller trace ID to be kept, got %q", got)
+	}
+	if got := TraceIDFromContext(ensureTraceID(context.Background())); got == "" {
+		t.Error("expected a generated trace ID")
+	}
+}
+
+func TestLogContractInteraction_RedactsArgs(t *testing.T) {
+	client, err := NewClient(Config{
+		RPCURL:       "http://localhost",
+		Network:      NetworkTestnet,
+		LogRedaction: LogRedactionConfig{MaskKeys: []string{"API_Token"}, MaxCollectionLen: 3},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	ctx := WithLogger(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)))
+	args := map[string]interface{}{
+		"api_token":  "tok-do-not-log",
+		"seed":       "SDONOTLOG",
+		"amount":     int64(500),
+		"recipients": []string{"GAAA", "GBBB", "GCCC", "GDDD"},
+		"memo_ids":   []uint64{1, 2},
+		"nested":     map[string]interface{}{"secret": "nested-do-not-log", "count": 2},
+	}
+	client.LogContractInteraction(ctx, testPayoutCarol, "batch_payout", args)
+
+	out := buf.String()
+	for _, leaked := range []string{"tok-do-not-log", "SDONOTLOG", "nested-do-not-log", "GAAA"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("expected %q to be redacted: %s", leaked, out)
+		}
+	}
+
+	var entry struct {
+		Args map[string]any `json:"args"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid log line %q: %v", out, err)
+	}
+	if entry.Args["api_token"] != "***" || entry.Args["seed"] != "***" {
+		t.Errorf("expected masked keys to log ***, got %v", entry.Args)
+	}
+	if entry.Args["recipients"] != "4 items" {
+		t.Errorf("expected the long list to be logged as its length, got %v", entry.Args["recipients"])
+	}
+	if ids, _ := entry.Args["memo_ids"].([]any); len(ids) != 2 || entry.Args["amount"] != float64(500) {
+		t.Errorf("expected short and scalar args to be logged as is, got %v", entry.Args)
+	}
+	if nested, _ := entry.Args["nested"].(map[string]any); nested["secret"] != "***" || nested["count"] != float64(2) {
+		t.Errorf("expected nested keys to be masked, got %v", entry.Args["nested"])
+	}
+
+	// The caller's map is not modified
+	if args["api_token"] != "tok-do-not-log" {
+		t.Error("expected the caller's args to be left alone")
+	}
+}
@@ -0,0 +1,243 @@
+package soroban
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+// DuplicateRecipientMode selects what BatchPayout does with a recipient listed more
+// than once
+type DuplicateRecipientMode int
+
+const (
+	// DuplicatesMerge pays each recipient once, with the sum of its amounts (the default)
+	DuplicatesMerge DuplicateRecipientMode = iota
+	// DuplicatesReject fails the batch with ErrDuplicateRecipient
+	DuplicatesReject
+)
+
+// ErrDuplicateRecipient is returned (wrapped) for a batch listing a recipient twice
+// when duplicates are rejected
+var ErrDuplicateRecipient = errors.New("duplicate_recipient")
+
+// ErrAmountExceedsLimit is returned (wrapped) for a payout, or batch total, above the
+// configured PayoutLimits
+var ErrAmountExceedsLimit = errors.New("amount_exceeds_limit")
+
+// ErrInvalidAmount is returned (wrapped) for a payout amount that isn't positive
+var ErrInvalidAmount = errors.New("invalid_amount")
+
+// ErrPayoutVectorMismatch is returned (wrapped) if a batch would reach the contract with
+// recipient and amount vectors of different lengths. It indicates a bug in this package,
+// never bad input: the contract pairs the vectors by index.
+var ErrPayoutVectorMismatch = errors.New("payout_vector_mismatch")
+
+// PayoutLimits caps payout amounts, in the token's base units, so a mistyped amount
+// can't drain an escrow. Zero means no limit.
+type PayoutLimits struct {
+	MaxPayoutAmount int64 // any single payout, including each batch item
+	MaxBatchTotal   int64 // the sum of a batch
+}
+
+// checkPayoutAmount rejects non-positive amounts and amounts above MaxPayoutAmount
+func (l PayoutLimits) checkPayoutAmount(amount int64) error {
+	if amount <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidAmount, amount)
+	}
+	if l.MaxPayoutAmount > 0 && amount > l.MaxPayoutAmount {
+		return fmt.Errorf("%w: %d exceeds the maximum payout of %d", ErrAmountExceedsLimit, amount, l.MaxPayoutAmount)
+	}
+	return nil
+}
+
+// checkBatch checks every item and the batch total
+func (l PayoutLimits) checkBatch(payouts []PayoutItem) error {
+	var total int64
+	for i, payout := range payouts {
+		if err := l.checkPayoutAmount(payout.Amount); err != nil {
+			return fmt.Errorf("payout %d: %w", i, err)
+		}
+		if total > math.MaxInt64-payout.Amount {
+			return fmt.Errorf("%w: batch total overflows", ErrAmountExceedsLimit)
+		}
+		total += payout.Amount
+	}
+	if l.MaxBatchTotal > 0 && total > l.MaxBatchTotal {
+		return fmt.Errorf("%w: batch total %d exceeds the maximum of %d", ErrAmountExceedsLimit, total, l.MaxBatchTotal)
+	}
+	return nil
+}
+
+// SetPayoutLimits sets the ceilings SinglePayout and BatchPayout enforce before
+// building a transaction
+func (pec *ProgramEscrowContract) SetPayoutLimits(limits PayoutLimits) {
+	pec.limits = limits
+}
+
+// SetPayoutLimits sets the ceiling ReleaseFunds enforces on an explicit amount before
+// building a transaction. MaxBatchTotal doesn't apply.
+func (ec *EscrowContract) SetPayoutLimits(limits PayoutLimits) {
+	ec.limits = limits
+}
+
+// SetDuplicateRecipientMode changes how BatchPayout handles repeated recipients
+func (pec *ProgramEscrowContract) SetDuplicateRecipientMode(mode DuplicateRecipientMode) {
+	pec.duplicates = mode
+}
+
+// normalizePayouts returns payouts with canonical recipients and each recipient listed
+// once, in order of first appearance. Recipients are compared by the address the
+// contract receives, so "g..." and "G..." (or a contract's strkey and hex ID) match.
+func normalizePayouts(payouts []PayoutItem, mode DuplicateRecipientMode) ([]PayoutItem, error) {
+	out := make([]PayoutItem, 0, len(payouts))
+	index := make(map[string]int, len(payouts))
+	for i, payout := range payouts {
+		recipient := canonicalRecipient(payout.Recipient)
+		val, err := EncodeScValAddress(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode recipient %d: %w", i, err)
+		}
+		key, err := val.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode recipient %d: %w", i, err)
+		}
+
+		j, seen := index[string(key)]
+		if !seen {
+			index[string(key)] = len(out)
+			out = append(out, PayoutItem{Recipient: recipient, Amount: payout.Amount})
+			continue
+		}
+		if mode == DuplicatesReject {
+			return nil, fmt.Errorf("%w: recipient %d repeats recipient %s", ErrDuplicateRecipient, i, out[j].Recipient)
+		}
+		if (payout.Amount > 0 && out[j].Amount > math.MaxInt64-payout.Amount) ||
+			(payout.Amount < 0 && out[j].Amount < math.MinInt64-payout.Amount) {
+			return nil, fmt.Errorf("merged amount for recipient %s overflows", out[j].Recipient)
+		}
+		out[j].Amount += payout.Amount
+	}
+	return out, nil
+}
+
+// canonicalRecipient upper-cases strkeys (G..., C..., M...), which are base32 and so
+// case-insensitive to a reader but not to the decoder. Other forms are left alone.
+func canonicalRecipient(addr string) string {
+	addr = strings.TrimSpace(addr)
+	upper := strings.ToUpper(addr)
+	if v, err := strkey.Version(upper); err == nil {
+		if _, err := strkey.Decode(v, upper); err == nil {
+			return upper
+		}
+	}
+	return addr
+}
+
+// payoutFunction names the contract function for a payout: the token-aware variant of
+// function (e.g. single_payout_token) when tokenAddress is set, function itself otherwise
+func payoutFunction(function, tokenAddress string) string {
+	if tokenAddress == "" {
+		return function
+	}
+	return function + "_token"
+}
+
+// appendPayoutToken appends the encoded tokenAddress, the last argument of the
+// token-aware payout functions. An empty tokenAddress leaves args unchanged.
+func appendPayoutToken(args []xdr.ScVal, tokenAddress string) ([]xdr.ScVal, error) {
+	if tokenAddress == "" {
+		return args, nil
+	}
+	tokenVal, err := EncodeScValAddress(canonicalRecipient(tokenAddress))
+	if err != nil {
+		return nil, fmt.Errorf("invalid token address: %w", err)
+	}
+	return append(args, tokenVal), nil
+}
+
+// batchPayoutArgs encodes the recipients and amounts vectors of batch_payout, refusing
+// vectors of different lengths (see ErrPayoutVectorMismatch)
+func batchPayoutArgs(recipientVals, amountVals []xdr.ScVal) ([]xdr.ScVal, error) {
+	if len(recipientVals) != len(amountVals) {
+		return nil, fmt.Errorf("%w: %d recipients, %d amounts", ErrPayoutVectorMismatch, len(recipientVals), len(amountVals))
+	}
+	recipientsVec, err := EncodeScValVec(recipientVals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode recipients vector: %w", err)
+	}
+	amountsVec, err := EncodeScValVec(amountVals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode amounts vector: %w", err)
+	}
+	return []xdr.ScVal{recipientsVec, amountsVec}, nil
+}
+
+// PayoutRecipientResult is whether the contract paid one item of a confirmed batch
+type PayoutRecipientResult struct {
+	Recipient string `json:"recipient"`
+	Amount    int64  `json:"amount"` // requested amount; the contract deducts any payout fee
+	Paid      bool   `json:"paid"`
+}
+
+// batchPayoutRecipients matches payouts, as sent to batch_payout, against the
+// ProgramData it returned. The contract appends a PayoutRecord {recipient, amount,
+// timestamp} to payout_history for every transfer it makes, all stamped with the
+// ledger time, so this call's records are the newest ones. They are matched from the
+// end, in order, and an item without a record wasn't paid.
+func batchPayoutRecipients(ret xdr.ScVal, payouts []PayoutItem) ([]PayoutRecipientResult, error) {
+	program, err := decodeScStruct(ret)
+	if err != nil {
+		return nil, fmt.Errorf("program data: %w", err)
+	}
+	history, ok := program["payout_history"].GetVec()
+	if !ok || history == nil {
+		return nil, fmt.Errorf("payout_history: expected a vec, got %s", program["payout_history"].Type)
+	}
+
+	// Binary address of each record of the newest timestamp, oldest first
+	var latest uint64
+	var recent []string
+	for i := len(*history) - 1; i >= 0; i-- {
+		record, err := decodeScStruct((*history)[i])
+		if err != nil {
+			return nil, fmt.Errorf("payout record %d: %w", i, err)
+		}
+		ts, ok := record["timestamp"].GetU64()
+		if !ok {
+			return nil, fmt.Errorf("payout record %d: timestamp: expected u64, got %s", i, record["timestamp"].Type)
+		}
+		if len(recent) > 0 && uint64(ts) != latest {
+			break
+		}
+		latest = uint64(ts)
+		key, err := record["recipient"].MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("payout record %d: recipient: %w", i, err)
+		}
+		recent = append([]string{string(key)}, recent...)
+	}
+
+	results := make([]PayoutRecipientResult, len(payouts))
+	next := len(recent) - 1
+	for i := len(payouts) - 1; i >= 0; i-- {
+		val, err := EncodeScValAddress(payouts[i].Recipient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode recipient %d: %w", i, err)
+		}
+		key, err := val.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode recipient %d: %w", i, err)
+		}
+		results[i] = PayoutRecipientResult{Recipient: payouts[i].Recipient, Amount: payouts[i].Amount}
+		if next >= 0 && recent[next] == string(key) {
+			results[i].Paid = true
+			next--
+		}
+	}
+	return results, nil
+}
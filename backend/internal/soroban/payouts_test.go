@@ -0,0 +1,352 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+func TestNormalizePayouts_MergesDuplicates(t *testing.T) {
+	carolHex := strings.Repeat("aa", 32) // testPayoutCarol's contract ID
+	got, err := normalizePayouts([]PayoutItem{
+		{Recipient: testPayoutAlice, Amount: 100},
+		{Recipient: testPayoutCarol, Amount: 7},
+		{Recipient: strings.ToLower(testPayoutAlice), Amount: 50},
+		{Recipient: testPayoutBob, Amount: 1},
+		{Recipient: carolHex, Amount: 3},
+	}, DuplicatesMerge)
+	if err != nil {
+		t.Fatalf("normalizePayouts failed: %v", err)
+	}
+
+	want := []PayoutItem{
+		{Recipient: testPayoutAlice, Amount: 150},
+		{Recipient: testPayoutCarol, Amount: 10},
+		{Recipient: testPayoutBob, Amount: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestNormalizePayouts_RejectsDuplicates(t *testing.T) {
+	payouts := []PayoutItem{
+		{Recipient: testPayoutAlice, Amount: 100},
+		{Recipient: testPayoutBob, Amount: 1},
+		{Recipient: " " + strings.ToLower(testPayoutAlice), Amount: 50},
+	}
+	if _, err := normalizePayouts(payouts, DuplicatesReject); !errors.Is(err, ErrDuplicateRecipient) {
+		t.Errorf("expected ErrDuplicateRecipient, got %v", err)
+	}
+
+	// Distinct recipients pass in either mode, lower-case strkeys fixed up
+	got, err := normalizePayouts(payouts[:2], DuplicatesReject)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("expected both recipients, got %+v (%v)", got, err)
+	}
+	if got, _ := normalizePayouts([]PayoutItem{{Recipient: strings.ToLower(testPayoutBob), Amount: 1}}, DuplicatesReject); got[0].Recipient != testPayoutBob {
+		t.Errorf("expected the canonical strkey, got %s", got[0].Recipient)
+	}
+}
+
+func TestNormalizePayouts_MergeOverflow(t *testing.T) {
+	_, err := normalizePayouts([]PayoutItem{
+		{Recipient: testPayoutAlice, Amount: math.MaxInt64},
+		{Recipient: testPayoutAlice, Amount: 1},
+	}, DuplicatesMerge)
+	if err == nil {
+		t.Error("expected an overflow error")
+	}
+}
+
+func TestBatchPayoutOp_Deduplicates(t *testing.T) {
+	pec := NewProgramEscrowContract(nil, nil, testPayoutCarol)
+	payouts := []PayoutItem{
+		{Recipient: testPayoutAlice, Amount: 100},
+		{Recipient: strings.ToLower(testPayoutAlice), Amount: 50},
+	}
+
+	op, err := pec.batchPayoutOp(payouts, "")
+	if err != nil {
+		t.Fatalf("batchPayoutOp failed: %v", err)
+	}
+	args := op.(*txnbuild.InvokeHostFunction).HostFunction.InvokeContract.Args
+	recipients, _ := args[0].GetVec()
+	amounts, _ := args[1].GetVec()
+	if recipients == nil || len(*recipients) != 1 || amounts == nil || len(*amounts) != 1 {
+		t.Fatalf("expected one merged entry, got %v / %v", recipients, amounts)
+	}
+	if amount, _ := (*amounts)[0].GetI64(); amount != 150 {
+		t.Errorf("expected the merged amount 150, got %d", amount)
+	}
+
+	pec.SetDuplicateRecipientMode(DuplicatesReject)
+	if _, err := pec.batchPayoutOp(payouts, ""); !errors.Is(err, ErrDuplicateRecipient) {
+		t.Errorf("expected ErrDuplicateRecipient, got %v", err)
+	}
+}
+
+func TestBatchPayoutArgs_LengthMismatch(t *testing.T) {
+	alice, err := EncodeScValAddress(testPayoutAlice)
+	if err != nil {
+		t.Fatalf("EncodeScValAddress failed: %v", err)
+	}
+	bob, _ := EncodeScValAddress(testPayoutBob)
+	amount, _ := EncodeScValInt64(100)
+
+	// As if a merge step had dropped an amount but kept its recipient
+	if _, err := batchPayoutArgs([]xdr.ScVal{alice, bob}, []xdr.ScVal{amount}); !errors.Is(err, ErrPayoutVectorMismatch) {
+		t.Errorf("expected ErrPayoutVectorMismatch, got %v", err)
+	}
+	if _, err := batchPayoutArgs(nil, []xdr.ScVal{amount}); !errors.Is(err, ErrPayoutVectorMismatch) {
+		t.Errorf("expected ErrPayoutVectorMismatch for missing recipients, got %v", err)
+	}
+
+	args, err := batchPayoutArgs([]xdr.ScVal{alice, bob}, []xdr.ScVal{amount, amount})
+	if err != nil {
+		t.Fatalf("expected matching vectors to encode, got %v", err)
+	}
+	recipients, _ := args[0].GetVec()
+	amounts, _ := args[1].GetVec()
+	if len(args) != 2 || len(*recipients) != 2 || len(*amounts) != 2 {
+		t.Errorf("expected two vectors of two, got %v", args)
+	}
+}
+
+// testPayoutToken is a second token contract (ID 0xbb...) for multi-asset payouts
+const testPayoutToken = "CC53XO53XO53XO53XO53XO53XO53XO53XO53XO53XO53XO53XO53WQD5"
+
+func TestPayoutOps_Token(t *testing.T) {
+	pec := NewProgramEscrowContract(nil, nil, testPayoutCarol)
+	batch := []PayoutItem{{Recipient: testPayoutAlice, Amount: 100}}
+	invocation := func(op txnbuild.Operation) xdr.InvokeContractArgs {
+		t.Helper()
+		return *op.(*txnbuild.InvokeHostFunction).HostFunction.InvokeContract
+	}
+
+	// Without a token: the init-token functions and their usual arguments
+	single, _, err := pec.singlePayoutOp(testPayoutAlice, 100, "")
+	if err != nil {
+		t.Fatalf("singlePayoutOp failed: %v", err)
+	}
+	if call := invocation(single); call.FunctionName != "single_payout" || len(call.Args) != 2 {
+		t.Errorf("expected single_payout with 2 args, got %s with %d", call.FunctionName, len(call.Args))
+	}
+	multi, err := pec.batchPayoutOp(batch, "")
+	if err != nil {
+		t.Fatalf("batchPayoutOp failed: %v", err)
+	}
+	if call := invocation(multi); call.FunctionName != "batch_payout" || len(call.Args) != 2 {
+		t.Errorf("expected batch_payout with 2 args, got %s with %d", call.FunctionName, len(call.Args))
+	}
+
+	// With a token (any case): the token-aware functions, the token last
+	single, _, err = pec.singlePayoutOp(testPayoutAlice, 100, strings.ToLower(testPayoutToken))
+	if err != nil {
+		t.Fatalf("singlePayoutOp failed: %v", err)
+	}
+	call := invocation(single)
+	if call.FunctionName != "single_payout_token" || len(call.Args) != 3 {
+		t.Fatalf("expected single_payout_token with 3 args, got %s with %d", call.FunctionName, len(call.Args))
+	}
+	if token, err := decodeScAddress(call.Args[2]); err != nil || token != testPayoutToken {
+		t.Errorf("expected token %s, got %s (%v)", testPayoutToken, token, err)
+	}
+	multi, err = pec.batchPayoutOp(batch, testPayoutToken)
+	if err != nil {
+		t.Fatalf("batchPayoutOp failed: %v", err)
+	}
+	call = invocation(multi)
+	if call.FunctionName != "batch_payout_token" || len(call.Args) != 3 {
+		t.Fatalf("expected batch_payout_token with 3 args, got %s with %d", call.FunctionName, len(call.Args))
+	}
+	if token, err := decodeScAddress(call.Args[2]); err != nil || token != testPayoutToken {
+		t.Errorf("expected token %s, got %s (%v)", testPayoutToken, token, err)
+	}
+
+	// An invalid token is rejected before anything is built
+	if _, _, err := pec.singlePayoutOp(testPayoutAlice, 100, "not-a-token"); err == nil || !strings.Contains(err.Error(), "invalid token address") {
+		t.Errorf("expected an invalid token address error, got %v", err)
+	}
+	if _, err := pec.batchPayoutOp(batch, "not-a-token"); err == nil || !strings.Contains(err.Error(), "invalid token address") {
+		t.Errorf("expected an invalid token address error, got %v", err)
+	}
+}
+
+func TestSinglePayoutOp_Limits(t *testing.T) {
+	pec := NewProgramEscrowContract(nil, nil, testPayoutCarol)
+	pec.SetPayoutLimits(PayoutLimits{MaxPayoutAmount: 1000})
+
+	if _, _, err := pec.singlePayoutOp(testPayoutAlice, 1000, ""); err != nil {
+		t.Errorf("expected a payout at the limit to pass, got %v", err)
+	}
+
+	_, _, err := pec.singlePayoutOp(testPayoutAlice, 1001, "")
+	if !errors.Is(err, ErrAmountExceedsLimit) || !strings.Contains(err.Error(), "1001") {
+		t.Errorf("expected amount_exceeds_limit naming 1001, got %v", err)
+	}
+
+	for _, amount := range []int64{0, -5} {
+		if _, _, err := pec.singlePayoutOp(testPayoutAlice, amount, ""); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("amount %d: expected ErrInvalidAmount, got %v", amount, err)
+		}
+	}
+
+	// Without limits only the sign is checked
+	pec.SetPayoutLimits(PayoutLimits{})
+	if _, _, err := pec.singlePayoutOp(testPayoutAlice, math.MaxInt64, ""); err != nil {
+		t.Errorf("expected no ceiling by default, got %v", err)
+	}
+	if _, _, err := pec.singlePayoutOp(testPayoutAlice, -1, ""); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("expected ErrInvalidAmount without limits, got %v", err)
+	}
+}
+
+func TestBatchPayoutOp_Limits(t *testing.T) {
+	pec := NewProgramEscrowContract(nil, nil, testPayoutCarol)
+	pec.SetPayoutLimits(PayoutLimits{MaxPayoutAmount: 100, MaxBatchTotal: 150})
+
+	if _, err := pec.batchPayoutOp([]PayoutItem{
+		{Recipient: testPayoutAlice, Amount: 100},
+		{Recipient: testPayoutBob, Amount: 50},
+	}, ""); err != nil {
+		t.Errorf("expected a batch under both limits to pass, got %v", err)
+	}
+
+	cases := map[string]struct {
+		payouts []PayoutItem
+		want    error
+	}{
+		"item over limit": {[]PayoutItem{{Recipient: testPayoutAlice, Amount: 101}}, ErrAmountExceedsLimit},
+		"total over limit": {[]PayoutItem{
+			{Recipient: testPayoutAlice, Amount: 100},
+			{Recipient: testPayoutBob, Amount: 51},
+		}, ErrAmountExceedsLimit},
+		// Merged duplicates are checked as one payout
+		"merged item over limit": {[]PayoutItem{
+			{Recipient: testPayoutAlice, Amount: 60},
+			{Recipient: testPayoutAlice, Amount: 60},
+		}, ErrAmountExceedsLimit},
+		"negative item": {[]PayoutItem{
+			{Recipient: testPayoutAlice, Amount: 10},
+			{Recipient: testPayoutBob, Amount: -10},
+		}, ErrInvalidAmount},
+	}
+	for name, tc := range cases {
+		if _, err := pec.batchPayoutOp(tc.payouts, ""); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestReleaseFunds_Limits(t *testing.T) {
+	client, err := NewClient(Config{RPCURL: "http://127.0.0.1:1", Network: NetworkTestnet})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	// No transaction builder: the amount must be rejected before one is needed
+	ec := NewEscrowContract(client, nil, testPayoutCarol)
+	ec.SetPayoutLimits(PayoutLimits{MaxPayoutAmount: 1000})
+
+	over, negative := int64(5000), int64(-1)
+	if _, err := ec.ReleaseFunds(context.Background(), 1, testPayoutAlice, &over); !errors.Is(err, ErrAmountExceedsLimit) {
+		t.Errorf("expected ErrAmountExceedsLimit, got %v", err)
+	}
+	if _, err := ec.ReleaseFunds(context.Background(), 1, testPayoutAlice, &negative); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+// testProgramData encodes the ProgramData batch_payout returns, with a PayoutRecord
+// {recipient, amount, timestamp} per history entry
+func testProgramData(t *testing.T, history ...PayoutEvent) xdr.ScVal {
+	t.Helper()
+	field := func(name string, val xdr.ScVal) xdr.ScMapEntry {
+		sym, err := EncodeScSymbol(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return xdr.ScMapEntry{Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym}, Val: val}
+	}
+	mustVal := func(val xdr.ScVal, err error) xdr.ScVal {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return val
+	}
+
+	records := make([]xdr.ScVal, len(history))
+	for i, h := range history {
+		record := xdr.ScMap{
+			field("amount", mustVal(EncodeScValInt128(h.Amount))),
+			field("recipient", mustVal(EncodeScValAddress(h.Recipient))),
+			field("timestamp", mustVal(EncodeScValUint64(uint64(h.Timestamp.Unix())))),
+		}
+		records[i] = mustVal(xdr.NewScVal(xdr.ScValTypeScvMap, &record))
+	}
+	program := xdr.ScMap{
+		field("program_id", mustVal(EncodeScValString("hack-2025"))),
+		field("payout_history", mustVal(EncodeScValVec(records))),
+		field("remaining_balance", mustVal(EncodeScValInt128(870))),
+	}
+	return mustVal(xdr.NewScVal(xdr.ScValTypeScvMap, &program))
+}
+
+func TestBatchPayoutRecipients(t *testing.T) {
+	earlier, now := time.Unix(1760000000, 0), time.Unix(1760003600, 0)
+	// batch_payout(alice 100, bob 50, carol 30) that skipped bob, after an earlier
+	// payout to bob. Amounts recorded are net of a 1% fee.
+	ret := testProgramData(t,
+		PayoutEvent{Recipient: testPayoutBob, Amount: 500, Timestamp: earlier},
+		PayoutEvent{Recipient: testPayoutAlice, Amount: 99, Timestamp: now},
+		PayoutEvent{Recipient: testPayoutCarol, Amount: 30, Timestamp: now},
+	)
+
+	got, err := batchPayoutRecipients(ret, []PayoutItem{
+		{Recipient: testPayoutAlice, Amount: 100},
+		{Recipient: testPayoutBob, Amount: 50},
+		{Recipient: testPayoutCarol, Amount: 30},
+	})
+	if err != nil {
+		t.Fatalf("batchPayoutRecipients failed: %v", err)
+	}
+	want := []PayoutRecipientResult{
+		{Recipient: testPayoutAlice, Amount: 100, Paid: true},
+		{Recipient: testPayoutBob, Amount: 50, Paid: false},
+		{Recipient: testPayoutCarol, Amount: 30, Paid: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("result %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// The earlier payout to bob doesn't count for this batch
+	got, err = batchPayoutRecipients(testProgramData(t,
+		PayoutEvent{Recipient: testPayoutBob, Amount: 500, Timestamp: earlier},
+	), []PayoutItem{{Recipient: testPayoutAlice, Amount: 100}})
+	if err != nil {
+		t.Fatalf("batchPayoutRecipients failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Paid {
+		t.Errorf("expected alice unpaid, got %+v", got)
+	}
+
+	if _, err := batchPayoutRecipients(xdr.ScVal{Type: xdr.ScValTypeScvVoid}, nil); err == nil {
+		t.Error("expected an error for a return value that isn't ProgramData")
+	}
+}
@@ -0,0 +1,197 @@
+package soroban
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/txnbuild"
+)
+
+// DefaultPreparedPayoutTTL is how long a prepared payout can be confirmed when
+// PayoutConfirmation.TTL is zero. It stays well inside transactionTimeout so a
+// confirmed envelope is still valid when submitted.
+const DefaultPreparedPayoutTTL = 2 * time.Minute
+
+// ErrConfirmationRequired is returned (wrapped) by BatchPayout for a batch whose total
+// is above PayoutConfirmation.Threshold; use PreparePayout and ConfirmPayout instead
+var ErrConfirmationRequired = errors.New("confirmation_required")
+
+// ErrInvalidConfirmationToken is returned (wrapped) by ConfirmPayout for a token that
+// was never issued, was already used, or has been altered
+var ErrInvalidConfirmationToken = errors.New("invalid_confirmation_token")
+
+// ErrConfirmationExpired is returned (wrapped) by ConfirmPayout for a token past its TTL
+var ErrConfirmationExpired = errors.New("confirmation_expired")
+
+// PayoutConfirmation makes large batch payouts two-step: PreparePayout signs the
+// transaction and returns a token, and only ConfirmPayout with that token submits it.
+// A single compromised call can then not release a large sum on its own.
+type PayoutConfirmation struct {
+	Threshold int64         // batch totals above this need confirming; zero disables
+	TTL       time.Duration // how long a token stays valid; zero means DefaultPreparedPayoutTTL
+}
+
+func (c PayoutConfirmation) ttl() time.Duration {
+	if c.TTL <= 0 {
+		return DefaultPreparedPayoutTTL
+	}
+	return c.TTL
+}
+
+// requires reports whether a batch totalling total must be prepared and confirmed
+func (c PayoutConfirmation) requires(total int64) bool {
+	return c.Threshold > 0 && total > c.Threshold
+}
+
+// PreparedPayout is a signed but unsubmitted batch payout awaiting ConfirmPayout
+type PreparedPayout struct {
+	Token       string
+	EnvelopeXDR string
+	Total       int64
+	ExpiresAt   time.Time
+}
+
+// preparedPayouts holds prepared envelopes by token until they're confirmed or expire
+type preparedPayouts struct {
+	mu      sync.Mutex
+	byToken map[string]PreparedPayout
+	now     func() time.Time // overridden in tests
+}
+
+func (p *preparedPayouts) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// put stores payout under its token, dropping any expired entries on the way
+func (p *preparedPayouts) put(payout PreparedPayout) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.byToken == nil {
+		p.byToken = make(map[string]PreparedPayout)
+	}
+	now := p.clock()
+	for token, prepared := range p.byToken {
+		if !now.Before(prepared.ExpiresAt) {
+			delete(p.byToken, token)
+		}
+	}
+	p.byToken[payout.Token] = payout
+}
+
+// take removes and returns the payout for token. A token can be used only once, even
+// if submitting it fails.
+func (p *preparedPayouts) take(token string) (PreparedPayout, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prepared, ok := p.byToken[token]
+	if !ok {
+		return PreparedPayout{}, ErrInvalidConfirmationToken
+	}
+	delete(p.byToken, token)
+	if !p.clock().Before(prepared.ExpiresAt) {
+		return PreparedPayout{}, ErrConfirmationExpired
+	}
+	return prepared, nil
+}
+
+// newConfirmationToken returns a random 128-bit token, hex encoded
+func newConfirmationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SetPayoutConfirmation sets the batch total above which BatchPayout refuses with
+// ErrConfirmationRequired and the payout must go through PreparePayout/ConfirmPayout
+func (pec *ProgramEscrowContract) SetPayoutConfirmation(cfg PayoutConfirmation) {
+	pec.confirmation = cfg
+}
+
+// PreparePayout builds and signs a batch payout without submitting it and returns it
+// with a confirmation token. The envelope uses the source account's next sequence
+// number, so any other transaction submitted before ConfirmPayout invalidates it.
+func (pec *ProgramEscrowContract) PreparePayout(ctx context.Context, payouts []PayoutItem) (*PreparedPayout, error) {
+	ctx = ensureTraceID(ctx)
+	pec.client.LogContractInteraction(ctx, pec.contractAddress, "prepare_batch_payout", map[string]interface{}{
+		"payout_count": len(payouts),
+	})
+
+	op, err := pec.batchPayoutOp(payouts, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := pec.checkAllowlist(ctx, payoutRecipients(payouts)); err != nil {
+		return nil, err
+	}
+
+	tx, err := pec.txBuilder.buildSigned([]txnbuild.Operation{op}, nil)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := tx.Base64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	token, err := newConfirmationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	prepared := PreparedPayout{
+		Token:       token,
+		EnvelopeXDR: envelope,
+		Total:       payoutTotal(payouts),
+		ExpiresAt:   pec.prepared.clock().Add(pec.confirmation.ttl()),
+	}
+	pec.prepared.put(prepared)
+	return &prepared, nil
+}
+
+// ConfirmPayout submits the payout prepared under token and applies the builder's
+// confirmation policy. Each token is single-use. After Shutdown it fails with
+// ErrShuttingDown and leaves the token unused.
+func (pec *ProgramEscrowContract) ConfirmPayout(ctx context.Context, token string) (*TransactionResult, error) {
+	ctx = ensureTraceID(ctx)
+	if !pec.txBuilder.inflight.accepting() {
+		return nil, ErrShuttingDown
+	}
+
+	prepared, err := pec.prepared.take(token)
+	if err != nil {
+		return nil, err
+	}
+	pec.client.LogContractInteraction(ctx, pec.contractAddress, "confirm_batch_payout", map[string]interface{}{
+		"total": prepared.Total,
+	})
+
+	result, err := pec.txBuilder.submitWithRetry(ctx, prepared.EnvelopeXDR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit transaction: %w", err)
+	}
+	result.EnvelopeXDR = prepared.EnvelopeXDR
+	// Prepared envelopes always carry a plain batch_payout (see PreparePayout)
+	return pec.txBuilder.confirm(ctx, pec.txBuilder.confirmation, "batch_payout", result)
+}
+
+// payoutTotal sums payouts that have already passed PayoutLimits.checkBatch, which
+// rules out overflow
+func payoutTotal(payouts []PayoutItem) int64 {
+	var total int64
+	for _, payout := range payouts {
+		total += payout.Amount
+	}
+	return total
+}
@@ -0,0 +1,124 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newPreparedTestContract(t *testing.T, fake *sequenceHorizon) *ProgramEscrowContract {
+	t.Helper()
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(NoConfirmation())
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+	pec.SetPayoutConfirmation(PayoutConfirmation{Threshold: 1000, TTL: time.Minute})
+	return pec
+}
+
+var largeBatch = []PayoutItem{
+	{Recipient: testPayoutAlice, Amount: 600},
+	{Recipient: testPayoutBob, Amount: 600},
+}
+
+func TestBatchPayout_AboveThresholdRequiresConfirmation(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	pec := newPreparedTestContract(t, fake)
+
+	if _, err := pec.BatchPayout(context.Background(), largeBatch); !errors.Is(err, ErrConfirmationRequired) {
+		t.Fatalf("expected ErrConfirmationRequired, got %v", err)
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be submitted, got %d submissions", len(fake.submissions))
+	}
+
+	// At the threshold a batch still goes through directly
+	small := []PayoutItem{{Recipient: testPayoutAlice, Amount: 1000}}
+	if _, err := pec.BatchPayout(context.Background(), small); err != nil {
+		t.Fatalf("BatchPayout at the threshold failed: %v", err)
+	}
+}
+
+func TestPrepareConfirmPayout(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	pec := newPreparedTestContract(t, fake)
+
+	prepared, err := pec.PreparePayout(context.Background(), largeBatch)
+	if err != nil {
+		t.Fatalf("PreparePayout failed: %v", err)
+	}
+	if prepared.Token == "" || prepared.EnvelopeXDR == "" || prepared.Total != 1200 {
+		t.Fatalf("unexpected prepared payout %+v", prepared)
+	}
+	if len(fake.submissions) != 0 {
+		t.Fatalf("expected PreparePayout not to submit, got %d submissions", len(fake.submissions))
+	}
+
+	result, err := pec.ConfirmPayout(context.Background(), prepared.Token)
+	if err != nil {
+		t.Fatalf("ConfirmPayout failed: %v", err)
+	}
+	if result.Hash != "abc" || result.EnvelopeXDR != prepared.EnvelopeXDR {
+		t.Errorf("unexpected result %+v", result)
+	}
+	// The prepared envelope is submitted as signed, not rebuilt
+	if fake.lookups != 1 || len(fake.submissions) != 1 {
+		t.Errorf("expected 1 account lookup and 1 submission, got %d and %d", fake.lookups, len(fake.submissions))
+	}
+
+	// Tokens are single-use
+	if _, err := pec.ConfirmPayout(context.Background(), prepared.Token); !errors.Is(err, ErrInvalidConfirmationToken) {
+		t.Errorf("expected a reused token to be rejected, got %v", err)
+	}
+}
+
+func TestConfirmPayout_ExpiredToken(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	pec := newPreparedTestContract(t, fake)
+
+	now := time.Now()
+	pec.prepared.now = func() time.Time { return now }
+
+	prepared, err := pec.PreparePayout(context.Background(), largeBatch)
+	if err != nil {
+		t.Fatalf("PreparePayout failed: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := pec.ConfirmPayout(context.Background(), prepared.Token); !errors.Is(err, ErrConfirmationExpired) {
+		t.Fatalf("expected ErrConfirmationExpired, got %v", err)
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be submitted, got %d submissions", len(fake.submissions))
+	}
+}
+
+func TestConfirmPayout_TamperedToken(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	pec := newPreparedTestContract(t, fake)
+
+	prepared, err := pec.PreparePayout(context.Background(), largeBatch)
+	if err != nil {
+		t.Fatalf("PreparePayout failed: %v", err)
+	}
+
+	tampered := []byte(prepared.Token)
+	if tampered[0] == '0' {
+		tampered[0] = '1'
+	} else {
+		tampered[0] = '0'
+	}
+	for _, token := range []string{string(tampered), prepared.Token + "0", ""} {
+		if _, err := pec.ConfirmPayout(context.Background(), token); !errors.Is(err, ErrInvalidConfirmationToken) {
+			t.Errorf("token %q: expected ErrInvalidConfirmationToken, got %v", token, err)
+		}
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be submitted, got %d submissions", len(fake.submissions))
+	}
+
+	// The genuine token still works
+	if _, err := pec.ConfirmPayout(context.Background(), prepared.Token); err != nil {
+		t.Errorf("ConfirmPayout with the genuine token failed: %v", err)
+	}
+}
@@ -3,9 +3,8 @@ package soroban
 import (
 	"context"
 	"fmt"
-	"log/slog"
-	"time"
 
+	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/txnbuild"
 	"github.com/stellar/go/xdr"
 )
@@ -15,6 +14,12 @@ type ProgramEscrowContract struct {
 	client          *Client
 	txBuilder       *TransactionBuilder
 	contractAddress string
+	duplicates      DuplicateRecipientMode // see SetDuplicateRecipientMode
+	limits          PayoutLimits           // see SetPayoutLimits
+	confirmation    PayoutConfirmation     // see SetPayoutConfirmation
+	prepared        preparedPayouts        // batches awaiting ConfirmPayout
+	allowlist       RecipientAllowlist     // see SetRecipientAllowlist
+	ttlExtension    uint32                 // see SetTTLExtension
 }
 
 // NewProgramEscrowContract creates a new program escrow contract client
@@ -26,9 +31,36 @@ func NewProgramEscrowContract(client *Client, txBuilder *TransactionBuilder, con
 	}
 }
 
+// NewProgramEscrowContractWithSource is NewProgramEscrowContract for a program that
+// signs with its own source account, so each funding program's key can be rotated and
+// accounted for separately. An empty sourceSecret uses txBuilder's source account. The
+// program works on its own copy of txBuilder: configure the builder (confirmation
+// policy, fees, result sink) before constructing it.
+func NewProgramEscrowContractWithSource(client *Client, txBuilder *TransactionBuilder, contractAddress, sourceSecret string) (*ProgramEscrowContract, error) {
+	pec := NewProgramEscrowContract(client, txBuilder, contractAddress)
+	if sourceSecret == "" {
+		return pec, nil
+	}
+	if txBuilder == nil {
+		return nil, fmt.Errorf("a transaction builder is required for a program source account")
+	}
+	sourceKP, err := keypair.ParseFull(sourceSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid program source secret: %w", err)
+	}
+	pec.txBuilder = txBuilder.withSource(sourceKP)
+	return pec, nil
+}
+
+// SourceAddress returns the account the program's transactions are built and signed as
+func (pec *ProgramEscrowContract) SourceAddress() string {
+	return pec.txBuilder.sourceKP.Address()
+}
+
 // InitProgram initializes a new program escrow
 func (pec *ProgramEscrowContract) InitProgram(ctx context.Context, programID, authorizedPayoutKey, tokenAddress string) (*TransactionResult, error) {
-	pec.client.LogContractInteraction(pec.contractAddress, "init_program", map[string]interface{}{
+	ctx = ensureTraceID(ctx)
+	pec.client.LogContractInteraction(ctx, pec.contractAddress, "init_program", map[string]interface{}{
 		"program_id":            programID,
 		"authorized_payout_key": authorizedPayoutKey,
 		"token_address":         tokenAddress,
@@ -58,24 +90,49 @@ func (pec *ProgramEscrowContract) InitProgram(ctx context.Context, programID, au
 
 	args := []xdr.ScVal{programIDVal, authKeyVal, tokenVal}
 
-	// Build InvokeHostFunction operation
-	op, err := BuildInvokeHostFunctionOp(contractAddr, "init_program", args)
+	// Check the arguments against the contract spec and build the operation
+	op, err := programEscrowSpec.buildOp(contractAddr, "init_program", args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build operation: %w", err)
+		return nil, err
 	}
 
-	// Build and submit transaction
-	result, err := pec.txBuilder.BuildAndSubmit(ctx, []txnbuild.Operation{op})
+	// Submit and apply the builder's confirmation policy
+	return pec.txBuilder.SubmitAndConfirm(ctx, []txnbuild.Operation{op})
+}
+
+// UpdateAuthorizedPayoutKey rotates the key allowed to trigger payouts, e.g. after the
+// current one was compromised. The new key is validated before anything is built.
+func (pec *ProgramEscrowContract) UpdateAuthorizedPayoutKey(ctx context.Context, newKey string) (*TransactionResult, error) {
+	ctx = ensureTraceID(ctx)
+	pec.client.LogContractInteraction(ctx, pec.contractAddress, "update_authorized_key", map[string]interface{}{
+		"authorized_payout_key": newKey,
+	})
+
+	keyVal, err := EncodeScValAddress(newKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to submit transaction: %w", err)
+		return nil, fmt.Errorf("invalid authorized payout key: %w", err)
 	}
 
-	return result, nil
+	// Encode contract address
+	contractAddr, err := EncodeContractAddress(pec.contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %w", err)
+	}
+
+	// Build InvokeHostFunction operation
+	op, err := BuildInvokeHostFunctionOp(contractAddr, "update_authorized_key", []xdr.ScVal{keyVal})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build operation: %w", err)
+	}
+
+	// Submit and apply the builder's confirmation policy
+	return pec.txBuilder.SubmitAndConfirm(ctx, []txnbuild.Operation{op})
 }
 
 // LockProgramFunds locks funds into the program escrow
 func (pec *ProgramEscrowContract) LockProgramFunds(ctx context.Context, amount int64) (*TransactionResult, error) {
-	pec.client.LogContractInteraction(pec.contractAddress, "lock_program_funds", map[string]interface{}{
+	ctx = ensureTraceID(ctx)
+	pec.client.LogContractInteraction(ctx, pec.contractAddress, "lock_program_funds", map[string]interface{}{
 		"amount": amount,
 	})
 
@@ -93,74 +150,97 @@ func (pec *ProgramEscrowContract) LockProgramFunds(ctx context.Context, amount i
 
 	args := []xdr.ScVal{amountVal}
 
-	// Build InvokeHostFunction operation
-	op, err := BuildInvokeHostFunctionOp(contractAddr, "lock_program_funds", args)
+	// Check the arguments against the contract spec and build the operation
+	op, err := programEscrowSpec.buildOp(contractAddr, "lock_program_funds", args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build operation: %w", err)
+		return nil, err
 	}
 
-	// Build and submit transaction
-	result, err := pec.txBuilder.BuildAndSubmit(ctx, []txnbuild.Operation{op})
-	if err != nil {
-		return nil, fmt.Errorf("failed to submit transaction: %w", err)
-	}
-
-	// Wait for confirmation
-	confirmed, err := pec.txBuilder.WaitForConfirmation(ctx, result.Hash, 60*time.Second)
-	if err != nil {
-		slog.Warn("failed to wait for confirmation", "error", err, "tx_hash", result.Hash)
-		return result, nil
-	}
-
-	return confirmed, nil
+	// Submit and apply the builder's confirmation policy
+	return pec.txBuilder.SubmitAndConfirm(ctx, []txnbuild.Operation{op})
 }
 
 // SinglePayout executes a single payout to one recipient
 func (pec *ProgramEscrowContract) SinglePayout(ctx context.Context, recipientAddress string, amount int64) (*TransactionResult, error) {
-	pec.client.LogContractInteraction(pec.contractAddress, "single_payout", map[string]interface{}{
+	return pec.SinglePayoutWithToken(ctx, recipientAddress, amount, "")
+}
+
+// SinglePayoutWithToken is SinglePayout for a program holding several assets: it pays
+// in the token at tokenAddress through the contract's single_payout_token. An empty
+// tokenAddress pays in the token the program was initialized with.
+func (pec *ProgramEscrowContract) SinglePayoutWithToken(ctx context.Context, recipientAddress string, amount int64, tokenAddress string) (*TransactionResult, error) {
+	ctx = ensureTraceID(ctx)
+	pec.client.LogContractInteraction(ctx, pec.contractAddress, payoutFunction("single_payout", tokenAddress), map[string]interface{}{
 		"recipient": recipientAddress,
 		"amount":    amount,
+		"token":     tokenAddress,
 	})
 
-	// Encode contract address
-	contractAddr, err := EncodeContractAddress(pec.contractAddress)
+	op, memo, err := pec.singlePayoutOp(recipientAddress, amount, tokenAddress)
 	if err != nil {
-		return nil, fmt.Errorf("invalid contract address: %w", err)
+		return nil, err
+	}
+	if err := pec.checkAllowlist(ctx, []string{recipientAddress}); err != nil {
+		return nil, err
 	}
 
-	// Encode function arguments
-	recipientVal, err := EncodeScValAddress(recipientAddress)
+	// Submit and apply the builder's confirmation policy
+	return pec.txBuilder.SubmitAndConfirmWithMemo(ctx, []txnbuild.Operation{op}, memo)
+}
+
+// SimulateSinglePayout dry-runs SinglePayout: it simulates the transaction via RPC and
+// reports whether it would succeed and what it would cost, without signing or submitting.
+func (pec *ProgramEscrowContract) SimulateSinglePayout(ctx context.Context, recipientAddress string, amount int64) (*SimulationResult, error) {
+	op, _, err := pec.singlePayoutOp(recipientAddress, amount, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode recipient address: %w", err)
+		return nil, err
 	}
+	return pec.txBuilder.Simulate(ctx, []txnbuild.Operation{op})
+}
 
-	amountVal, err := EncodeScValInt64(amount)
+// singlePayoutOp builds the single_payout invocation, or single_payout_token for a
+// non-empty tokenAddress. A muxed recipient is paid at its underlying account, and the
+// returned memo carries its ID.
+func (pec *ProgramEscrowContract) singlePayoutOp(recipientAddress string, amount int64, tokenAddress string) (txnbuild.Operation, txnbuild.Memo, error) {
+	// Encode contract address
+	contractAddr, err := EncodeContractAddress(pec.contractAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode amount: %w", err)
+		return nil, nil, fmt.Errorf("invalid contract address: %w", err)
 	}
 
-	args := []xdr.ScVal{recipientVal, amountVal}
+	if err := pec.limits.checkPayoutAmount(amount); err != nil {
+		return nil, nil, err
+	}
 
-	// Build InvokeHostFunction operation
-	op, err := BuildInvokeHostFunctionOp(contractAddr, "single_payout", args)
+	// Same canonical form the allowlist checked, see normalizePayouts for batches
+	recipient, memo, err := payoutRecipient(canonicalRecipient(recipientAddress))
 	if err != nil {
-		return nil, fmt.Errorf("failed to build operation: %w", err)
+		return nil, nil, fmt.Errorf("invalid recipient address: %w", err)
 	}
 
-	// Build and submit transaction
-	result, err := pec.txBuilder.BuildAndSubmit(ctx, []txnbuild.Operation{op})
+	// Encode function arguments
+	recipientVal, err := EncodeScValAddress(recipient)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode recipient address: %w", err)
+	}
+
+	amountVal, err := EncodeScValInt64(amount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to submit transaction: %w", err)
+		return nil, nil, fmt.Errorf("failed to encode amount: %w", err)
 	}
 
-	// Wait for confirmation
-	confirmed, err := pec.txBuilder.WaitForConfirmation(ctx, result.Hash, 60*time.Second)
+	args := []xdr.ScVal{recipientVal, amountVal}
+	args, err = appendPayoutToken(args, tokenAddress)
 	if err != nil {
-		slog.Warn("failed to wait for confirmation", "error", err, "tx_hash", result.Hash)
-		return result, nil
+		return nil, nil, err
 	}
 
-	return confirmed, nil
+	// Check the arguments against the contract spec and build the operation
+	op, err := programEscrowSpec.buildOp(contractAddr, payoutFunction("single_payout", tokenAddress), args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return op, memo, nil
 }
 
 // BatchPayout executes payouts to multiple recipients
@@ -169,11 +249,79 @@ type PayoutItem struct {
 	Amount    int64
 }
 
+// BatchPayout pays every item in one transaction. A recipient listed more than once is
+// paid the sum of its amounts, or the batch is rejected, see SetDuplicateRecipientMode.
+// A transaction has a single memo, so muxed recipients are rejected with
+// ErrMuxedAddress; pay them with SinglePayout. Batches above the PayoutConfirmation
+// threshold are refused with ErrConfirmationRequired.
 func (pec *ProgramEscrowContract) BatchPayout(ctx context.Context, payouts []PayoutItem) (*TransactionResult, error) {
-	pec.client.LogContractInteraction(pec.contractAddress, "batch_payout", map[string]interface{}{
+	return pec.BatchPayoutWithToken(ctx, payouts, "")
+}
+
+// BatchPayoutWithToken is BatchPayout paying every item in the token at tokenAddress,
+// through the contract's batch_payout_token (see SinglePayoutWithToken). An empty
+// tokenAddress pays in the program's init token.
+func (pec *ProgramEscrowContract) BatchPayoutWithToken(ctx context.Context, payouts []PayoutItem, tokenAddress string) (*TransactionResult, error) {
+	ctx = ensureTraceID(ctx)
+	pec.client.LogContractInteraction(ctx, pec.contractAddress, payoutFunction("batch_payout", tokenAddress), map[string]interface{}{
 		"payout_count": len(payouts),
+		"token":        tokenAddress,
 	})
 
+	op, err := pec.batchPayoutOp(payouts, tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	if err := pec.checkAllowlist(ctx, payoutRecipients(payouts)); err != nil {
+		return nil, err
+	}
+	if total := payoutTotal(payouts); pec.confirmation.requires(total) {
+		return nil, fmt.Errorf("%w: batch total %d is above %d, use PreparePayout", ErrConfirmationRequired, total, pec.confirmation.Threshold)
+	}
+
+	// Submit and apply the builder's confirmation policy
+	result, err := pec.txBuilder.SubmitAndConfirm(ctx, []txnbuild.Operation{op})
+	if err != nil {
+		// A failed transaction still has a result, with its hash
+		return result, err
+	}
+	pec.attachPayoutRecipients(ctx, result, payouts)
+	return result, nil
+}
+
+// attachPayoutRecipients sets result.Recipients from the return value of a confirmed
+// batch payout. The return value is only known when confirmation went through RPC; a
+// result without one, or one that doesn't decode, is left as is since the payout
+// itself went through.
+func (pec *ProgramEscrowContract) attachPayoutRecipients(ctx context.Context, result *TransactionResult, payouts []PayoutItem) {
+	if !result.IsConfirmed() || result.ReturnValue == nil {
+		return
+	}
+	// The same normalization batchPayoutOp applied, so it can't fail here
+	sent, err := normalizePayouts(payouts, pec.duplicates)
+	if err != nil {
+		return
+	}
+	recipients, err := batchPayoutRecipients(*result.ReturnValue, sent)
+	if err != nil {
+		loggerFrom(ctx).Warn("failed to decode batch payout recipients", "error", err, "tx_hash", result.Hash)
+		return
+	}
+	result.Recipients = recipients
+}
+
+// SimulateBatchPayout dry-runs BatchPayout without signing or submitting (see SimulateSinglePayout)
+func (pec *ProgramEscrowContract) SimulateBatchPayout(ctx context.Context, payouts []PayoutItem) (*SimulationResult, error) {
+	op, err := pec.batchPayoutOp(payouts, "")
+	if err != nil {
+		return nil, err
+	}
+	return pec.txBuilder.Simulate(ctx, []txnbuild.Operation{op})
+}
+
+// batchPayoutOp builds the batch_payout invocation, or batch_payout_token for a
+// non-empty tokenAddress
+func (pec *ProgramEscrowContract) batchPayoutOp(payouts []PayoutItem, tokenAddress string) (txnbuild.Operation, error) {
 	if len(payouts) == 0 {
 		return nil, fmt.Errorf("payouts list cannot be empty")
 	}
@@ -184,6 +332,15 @@ func (pec *ProgramEscrowContract) BatchPayout(ctx context.Context, payouts []Pay
 		return nil, fmt.Errorf("invalid contract address: %w", err)
 	}
 
+	// Merge or reject repeated recipients before anything reaches the contract
+	payouts, err = normalizePayouts(payouts, pec.duplicates)
+	if err != nil {
+		return nil, err
+	}
+	if err := pec.limits.checkBatch(payouts); err != nil {
+		return nil, err
+	}
+
 	// Encode recipients vector
 	recipientVals := make([]xdr.ScVal, len(payouts))
 	for i, payout := range payouts {
@@ -193,10 +350,6 @@ func (pec *ProgramEscrowContract) BatchPayout(ctx context.Context, payouts []Pay
 		}
 		recipientVals[i] = recipientVal
 	}
-	recipientsVec, err := EncodeScValVec(recipientVals)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode recipients vector: %w", err)
-	}
 
 	// Encode amounts vector
 	amountVals := make([]xdr.ScVal, len(payouts))
@@ -207,33 +360,22 @@ func (pec *ProgramEscrowContract) BatchPayout(ctx context.Context, payouts []Pay
 		}
 		amountVals[i] = amountVal
 	}
-	amountsVec, err := EncodeScValVec(amountVals)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode amounts vector: %w", err)
-	}
-
-	args := []xdr.ScVal{recipientsVec, amountsVec}
 
-	// Build InvokeHostFunction operation
-	op, err := BuildInvokeHostFunctionOp(contractAddr, "batch_payout", args)
+	vecs, err := batchPayoutArgs(recipientVals, amountVals)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build operation: %w", err)
+		return nil, err
 	}
-
-	// Build and submit transaction
-	result, err := pec.txBuilder.BuildAndSubmit(ctx, []txnbuild.Operation{op})
+	args, err := appendPayoutToken(vecs, tokenAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to submit transaction: %w", err)
+		return nil, err
 	}
 
-	// Wait for confirmation
-	confirmed, err := pec.txBuilder.WaitForConfirmation(ctx, result.Hash, 60*time.Second)
+	// Check the arguments against the contract spec and build the operation
+	op, err := programEscrowSpec.buildOp(contractAddr, payoutFunction("batch_payout", tokenAddress), args)
 	if err != nil {
-		slog.Warn("failed to wait for confirmation", "error", err, "tx_hash", result.Hash)
-		return result, nil
+		return nil, err
 	}
-
-	return confirmed, nil
+	return op, nil
 }
 
 // GetProgramInfo retrieves program information (read-only)
@@ -245,10 +387,48 @@ func (pec *ProgramEscrowContract) GetProgramInfo(ctx context.Context) (*ProgramE
 func (pec *ProgramEscrowContract) getProgramInfoRPC(ctx context.Context) (*ProgramEscrowData, error) {
 	// Similar to escrow - requires building transaction XDR and calling simulateTransaction
 	// Then decoding the ScVal return value
-	slog.Warn("GetProgramInfo requires transaction building and XDR decoding")
+	loggerFrom(ctx).Warn("GetProgramInfo requires transaction building and XDR decoding")
 	return nil, fmt.Errorf("GetProgramInfo requires transaction building - use RPC simulateTransaction")
 }
 
+// GetAuthorizedPayoutKey reads the key allowed to trigger payouts (read-only)
+func (pec *ProgramEscrowContract) GetAuthorizedPayoutKey(ctx context.Context) (string, error) {
+	val, err := pec.simulateRead(ctx, "get_authorized_payout_key")
+	if err != nil {
+		return "", err
+	}
+	key, err := decodeScAddress(val)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode authorized payout key: %w", err)
+	}
+	return key, nil
+}
+
+// simulateRead invokes a read-only contract function through simulateTransaction and
+// returns its value. A revert is returned wrapping ErrContractReverted.
+func (pec *ProgramEscrowContract) simulateRead(ctx context.Context, function string, args ...xdr.ScVal) (xdr.ScVal, error) {
+	contractAddr, err := EncodeContractAddress(pec.contractAddress)
+	if err != nil {
+		return xdr.ScVal{}, fmt.Errorf("invalid contract address: %w", err)
+	}
+	op, err := BuildInvokeHostFunctionOp(contractAddr, function, args)
+	if err != nil {
+		return xdr.ScVal{}, fmt.Errorf("failed to build operation: %w", err)
+	}
+
+	result, err := pec.txBuilder.Simulate(ctx, []txnbuild.Operation{op})
+	if err != nil {
+		return xdr.ScVal{}, err
+	}
+	if !result.Success {
+		return xdr.ScVal{}, fmt.Errorf("%s: %w: %s", function, ErrContractReverted, result.Error)
+	}
+	if result.Result == nil {
+		return xdr.ScVal{}, fmt.Errorf("%s returned no value", function)
+	}
+	return *result.Result, nil
+}
+
 // GetRemainingBalance retrieves the remaining balance (read-only)
 func (pec *ProgramEscrowContract) GetRemainingBalance(ctx context.Context) (int64, error) {
 	return pec.getRemainingBalanceRPC(ctx)
@@ -257,6 +437,6 @@ func (pec *ProgramEscrowContract) GetRemainingBalance(ctx context.Context) (int6
 // getRemainingBalanceRPC uses Soroban RPC to get remaining balance
 func (pec *ProgramEscrowContract) getRemainingBalanceRPC(ctx context.Context) (int64, error) {
 	// Similar to getProgramInfoRPC - requires transaction building and XDR decoding
-	slog.Warn("GetRemainingBalance requires transaction building and XDR decoding")
+	loggerFrom(ctx).Warn("GetRemainingBalance requires transaction building and XDR decoding")
 	return 0, fmt.Errorf("GetRemainingBalance requires transaction building - use RPC simulateTransaction")
 }
@@ -0,0 +1,181 @@
+package soroban
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+// simulatingHorizon answers simulateTransaction JSON-RPC calls with result and
+// everything else like sequenceHorizon
+type simulatingHorizon struct {
+	*sequenceHorizon
+	result    string // JSON result of simulateTransaction
+	simulated *txnbuild.Transaction
+}
+
+func (h *simulatingHorizon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/" {
+		h.sequenceHorizon.ServeHTTP(w, r)
+		return
+	}
+	var req struct {
+		Params struct {
+			Transaction string `json:"transaction"`
+		} `json:"params"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	if generic, err := txnbuild.TransactionFromXDR(req.Params.Transaction); err == nil {
+		h.simulated, _ = generic.Transaction()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + h.result + `}`))
+}
+
+func invokedFunction(t *testing.T, tx *txnbuild.Transaction) (string, []xdr.ScVal) {
+	t.Helper()
+	if tx == nil {
+		t.Fatal("expected a transaction")
+	}
+	invoke, ok := tx.Operations()[0].(*txnbuild.InvokeHostFunction)
+	if !ok {
+		t.Fatalf("expected an InvokeHostFunction operation, got %T", tx.Operations()[0])
+	}
+	return string(invoke.HostFunction.InvokeContract.FunctionName), invoke.HostFunction.InvokeContract.Args
+}
+
+func TestGetAuthorizedPayoutKey(t *testing.T) {
+	keyVal, err := EncodeScValAddress(testPayoutAlice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64, err := xdr.MarshalBase64(keyVal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &simulatingHorizon{
+		sequenceHorizon: &sequenceHorizon{sequence: 100},
+		result:          `{"minResourceFee":"100","latestLedger":5,"results":[{"auth":[],"xdr":"` + b64 + `"}]}`,
+	}
+	tb := newSequenceTestBuilder(t, fake)
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	key, err := pec.GetAuthorizedPayoutKey(context.Background())
+	if err != nil {
+		t.Fatalf("GetAuthorizedPayoutKey failed: %v", err)
+	}
+	if key != testPayoutAlice {
+		t.Errorf("expected %s, got %s", testPayoutAlice, key)
+	}
+	if fn, _ := invokedFunction(t, fake.simulated); fn != "get_authorized_payout_key" {
+		t.Errorf("expected get_authorized_payout_key to be simulated, got %s", fn)
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected a read not to submit, got %d submissions", len(fake.submissions))
+	}
+}
+
+func TestGetAuthorizedPayoutKey_Reverted(t *testing.T) {
+	fake := &simulatingHorizon{
+		sequenceHorizon: &sequenceHorizon{sequence: 100},
+		result:          `{"error":"HostError: Error(Contract, #1)","latestLedger":5}`,
+	}
+	tb := newSequenceTestBuilder(t, fake)
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	if _, err := pec.GetAuthorizedPayoutKey(context.Background()); !errors.Is(err, ErrContractReverted) {
+		t.Errorf("expected ErrContractReverted, got %v", err)
+	}
+}
+
+func TestUpdateAuthorizedPayoutKey(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(NoConfirmation())
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	if _, err := pec.UpdateAuthorizedPayoutKey(context.Background(), testPayoutBob); err != nil {
+		t.Fatalf("UpdateAuthorizedPayoutKey failed: %v", err)
+	}
+	fn, args := invokedFunction(t, fake.lastTx)
+	if fn != "update_authorized_key" || len(args) != 1 {
+		t.Fatalf("expected update_authorized_key with one argument, got %s %v", fn, args)
+	}
+	if key, err := decodeScAddress(args[0]); err != nil || key != testPayoutBob {
+		t.Errorf("expected new key %s, got %s (%v)", testPayoutBob, key, err)
+	}
+}
+
+func TestUpdateAuthorizedPayoutKey_RejectsInvalidKey(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	for _, key := range []string{"", "not-a-key", strings.Replace(testPayoutBob, "A", "B", 1), testMuxedAddress} {
+		if _, err := pec.UpdateAuthorizedPayoutKey(context.Background(), key); err == nil {
+			t.Errorf("expected %q to be rejected", key)
+		}
+	}
+	if fake.lookups != 0 || len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be built or submitted, got %d lookups and %d submissions", fake.lookups, len(fake.submissions))
+	}
+}
+
+func TestNewProgramEscrowContractWithSource_SignsWithProgramKey(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(NoConfirmation())
+
+	programA := keypair.MustRandom()
+	pecA, err := NewProgramEscrowContractWithSource(tb.client, tb, testPayoutCarol, programA.Seed())
+	if err != nil {
+		t.Fatalf("NewProgramEscrowContractWithSource failed: %v", err)
+	}
+	pecB, err := NewProgramEscrowContractWithSource(tb.client, tb, testPayoutCarol, "")
+	if err != nil {
+		t.Fatalf("NewProgramEscrowContractWithSource failed: %v", err)
+	}
+	if pecA.SourceAddress() != programA.Address() || pecB.SourceAddress() != tb.sourceKP.Address() {
+		t.Fatalf("expected program A to use its own account and B the builder's, got %s and %s", pecA.SourceAddress(), pecB.SourceAddress())
+	}
+
+	for _, tc := range []struct {
+		pec    *ProgramEscrowContract
+		signer *keypair.Full
+	}{
+		{pecA, programA},
+		{pecB, tb.sourceKP},
+	} {
+		if _, err := tc.pec.UpdateAuthorizedPayoutKey(context.Background(), testPayoutBob); err != nil {
+			t.Fatalf("UpdateAuthorizedPayoutKey failed: %v", err)
+		}
+		tx := fake.lastTx
+		if source := tx.SourceAccount().AccountID; source != tc.signer.Address() {
+			t.Errorf("expected source account %s, got %s", tc.signer.Address(), source)
+		}
+		hash, err := tx.Hash(tb.client.GetNetworkPassphrase())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs := tx.Signatures()
+		if len(sigs) != 1 || tc.signer.Verify(hash[:], sigs[0].Signature) != nil {
+			t.Errorf("expected a single signature by %s", tc.signer.Address())
+		}
+	}
+}
+
+func TestNewProgramEscrowContractWithSource_RejectsInvalidSecret(t *testing.T) {
+	tb := newSequenceTestBuilder(t, &sequenceHorizon{sequence: 100})
+	for _, secret := range []string{"not-a-secret", keypair.MustRandom().Address()} {
+		if _, err := NewProgramEscrowContractWithSource(tb.client, tb, testPayoutCarol, secret); err == nil {
+			t.Errorf("expected %q to be rejected", secret)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package soroban
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stellar/go/txnbuild"
+)
+
+// RecordedPayout is a payout the backend submitted and recorded as confirmed
+type RecordedPayout struct {
+	TxHash    string `json:"tx_hash"`
+	Recipient string `json:"recipient"`
+	Amount    int64  `json:"amount"`
+}
+
+// PayoutRecordSource lists recorded payouts, e.g. db.ChainTransactionSink
+type PayoutRecordSource interface {
+	// RecordedPayouts returns the payouts of transactions confirmed at or after fromLedger
+	RecordedPayouts(ctx context.Context, fromLedger uint32) ([]RecordedPayout, error)
+}
+
+// PayoutDiscrepancyKind classifies a PayoutDiscrepancy
+type PayoutDiscrepancyKind string
+
+const (
+	// DiscrepancyUnrecorded is a payout on-chain without a local record
+	DiscrepancyUnrecorded PayoutDiscrepancyKind = "unrecorded"
+	// DiscrepancyMissingOnChain is a local record without a payout event on-chain
+	DiscrepancyMissingOnChain PayoutDiscrepancyKind = "missing_on_chain"
+	// DiscrepancyAmountMismatch is a payout on-chain and recorded with different amounts
+	DiscrepancyAmountMismatch PayoutDiscrepancyKind = "amount_mismatch"
+)
+
+// PayoutDiscrepancy is a difference between the payouts on-chain and the recorded ones.
+// Recipient is empty for a discrepancy in a batch payout that is only known on-chain by
+// its summary; the amounts are then batch totals.
+type PayoutDiscrepancy struct {
+	Kind           PayoutDiscrepancyKind `json:"kind"`
+	TxHash         string                `json:"tx_hash"`
+	Recipient      string                `json:"recipient,omitempty"`
+	OnChainAmount  int64                 `json:"on_chain_amount"`
+	RecordedAmount int64                 `json:"recorded_amount"`
+}
+
+// ReconcileReport is the outcome of ReconcilePayouts
+type ReconcileReport struct {
+	FromLedger    uint32              `json:"from_ledger"`
+	LatestLedger  uint32              `json:"latest_ledger"`
+	Matched       int                 `json:"matched"`
+	Discrepancies []PayoutDiscrepancy `json:"discrepancies"`
+}
+
+// PayoutReconciler compares the payout events of escrow contracts with the payouts the
+// backend recorded, as an integrity check of the escrows
+type PayoutReconciler struct {
+	client      *Client
+	records     PayoutRecordSource
+	contractIDs []string
+}
+
+// NewPayoutReconciler returns a reconciler for the payouts of contractIDs (at most 5,
+// see GetEvents) recorded in records
+func NewPayoutReconciler(client *Client, records PayoutRecordSource, contractIDs ...string) *PayoutReconciler {
+	return &PayoutReconciler{client: client, records: records, contractIDs: contractIDs}
+}
+
+// ReconcilePayouts fetches the payout events since fromLedger and matches them against
+// the recorded payouts by transaction hash and recipient. It reports on-chain payouts
+// without a record, records without an on-chain payout and amount mismatches.
+//
+// A program escrow batch payout only emits a summary, so the records of such a
+// transaction are checked as a whole, by count and total. Program escrow payout events
+// carry the amount net of the contract's fee while records hold the amount requested,
+// so on a program that charges payout fees single payouts show the fee as a mismatch.
+func (r *PayoutReconciler) ReconcilePayouts(ctx context.Context, fromLedger uint32) (*ReconcileReport, error) {
+	ctx = ensureTraceID(ctx)
+
+	// Records first: anything confirmed by then has its events in the fetch below
+	records, err := r.records.RecordedPayouts(ctx, fromLedger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recorded payouts: %w", err)
+	}
+	events, latest, err := r.client.GetEvents(ctx, fromLedger, r.contractIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contract events: %w", err)
+	}
+
+	var payouts []PayoutEvent
+	var summaries []batchPayoutSummary
+	for _, ev := range events {
+		payout, err := DecodePayoutEvent(ev)
+		if err != nil {
+			return nil, err
+		}
+		if payout != nil {
+			payouts = append(payouts, *payout)
+			continue
+		}
+		summary, err := decodeBatchPayoutSummary(ev)
+		if err != nil {
+			return nil, err
+		}
+		if summary != nil {
+			summaries = append(summaries, *summary)
+		}
+	}
+
+	report := reconcilePayouts(payouts, summaries, records)
+	report.FromLedger = fromLedger
+	report.LatestLedger = latest
+	loggerFrom(ctx).Info("payouts reconciled",
+		"from_ledger", fromLedger,
+		"latest_ledger", latest,
+		"matched", report.Matched,
+		"discrepancies", len(report.Discrepancies),
+	)
+	return report, nil
+}
+
+// reconcilePayouts matches payouts and batch summaries from the chain against records.
+// Repeated payouts to one recipient in one transaction are paired in order.
+func reconcilePayouts(payouts []PayoutEvent, summaries []batchPayoutSummary, records []RecordedPayout) *ReconcileReport {
+	report := &ReconcileReport{Discrepancies: []PayoutDiscrepancy{}}
+
+	type payoutKey struct{ txHash, recipient string }
+	unmatched := map[payoutKey][]int{} // indexes into records, in order
+	used := make([]bool, len(records))
+	for i, rec := range records {
+		key := payoutKey{rec.TxHash, canonicalRecipient(rec.Recipient)}
+		unmatched[key] = append(unmatched[key], i)
+	}
+
+	for _, payout := range payouts {
+		key := payoutKey{payout.TxHash, canonicalRecipient(payout.Recipient)}
+		queue := unmatched[key]
+		if len(queue) == 0 {
+			report.Discrepancies = append(report.Discrepancies, PayoutDiscrepancy{
+				Kind:          DiscrepancyUnrecorded,
+				TxHash:        payout.TxHash,
+				Recipient:     payout.Recipient,
+				OnChainAmount: payout.Amount,
+			})
+			continue
+		}
+		rec := records[queue[0]]
+		used[queue[0]] = true
+		unmatched[key] = queue[1:]
+		if rec.Amount != payout.Amount {
+			report.Discrepancies = append(report.Discrepancies, PayoutDiscrepancy{
+				Kind:           DiscrepancyAmountMismatch,
+				TxHash:         payout.TxHash,
+				Recipient:      payout.Recipient,
+				OnChainAmount:  payout.Amount,
+				RecordedAmount: rec.Amount,
+			})
+			continue
+		}
+		report.Matched++
+	}
+
+	// The records of a batch known only by its summary are checked as a whole
+	for _, summary := range summaries {
+		var count int
+		var total int64
+		for i, rec := range records {
+			if !used[i] && rec.TxHash == summary.TxHash {
+				used[i] = true
+				count++
+				total += rec.Amount
+			}
+		}
+		switch {
+		case count == 0:
+			report.Discrepancies = append(report.Discrepancies, PayoutDiscrepancy{
+				Kind:          DiscrepancyUnrecorded,
+				TxHash:        summary.TxHash,
+				OnChainAmount: summary.Total,
+			})
+		case count != summary.Count || total != summary.Total:
+			report.Discrepancies = append(report.Discrepancies, PayoutDiscrepancy{
+				Kind:           DiscrepancyAmountMismatch,
+				TxHash:         summary.TxHash,
+				OnChainAmount:  summary.Total,
+				RecordedAmount: total,
+			})
+		default:
+			report.Matched += count
+		}
+	}
+
+	for i, rec := range records {
+		if !used[i] {
+			report.Discrepancies = append(report.Discrepancies, PayoutDiscrepancy{
+				Kind:           DiscrepancyMissingOnChain,
+				TxHash:         rec.TxHash,
+				Recipient:      rec.Recipient,
+				RecordedAmount: rec.Amount,
+			})
+		}
+	}
+	return report
+}
+
+// RequestedPayouts returns the payouts a program escrow payout transaction asks for,
+// decoded from its signed envelope (TransactionResult.EnvelopeXDR), or nil for a
+// transaction that doesn't invoke single_payout or batch_payout (or their _token
+// variants). Result sinks use it to record what was paid.
+func RequestedPayouts(envelopeXDR string) ([]PayoutItem, error) {
+	if envelopeXDR == "" {
+		return nil, nil
+	}
+	generic, err := txnbuild.TransactionFromXDR(envelopeXDR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction envelope: %w", err)
+	}
+	tx, ok := generic.Transaction()
+	if fb, isFeeBump := generic.FeeBump(); isFeeBump {
+		tx, ok = fb.InnerTransaction(), true
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	var invoke *txnbuild.InvokeHostFunction
+	for _, op := range tx.Operations() {
+		if candidate, isInvoke := op.(*txnbuild.InvokeHostFunction); isInvoke && candidate.HostFunction.InvokeContract != nil {
+			invoke = candidate
+			break
+		}
+	}
+	if invoke == nil {
+		return nil, nil
+	}
+	function := string(invoke.HostFunction.InvokeContract.FunctionName)
+	args := invoke.HostFunction.InvokeContract.Args
+
+	switch function {
+	case "single_payout", "single_payout_token":
+		if err := programEscrowSpec.check(function, args); err != nil {
+			return nil, err
+		}
+		recipient, err := decodeScAddress(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("recipient: %w", err)
+		}
+		amount, ok := args[1].GetI64()
+		if !ok {
+			return nil, fmt.Errorf("amount: expected i64, got %s", args[1].Type)
+		}
+		return []PayoutItem{{Recipient: recipient, Amount: int64(amount)}}, nil
+
+	case "batch_payout", "batch_payout_token":
+		if err := programEscrowSpec.check(function, args); err != nil {
+			return nil, err
+		}
+		recipients, ok := args[0].GetVec()
+		if !ok || recipients == nil {
+			return nil, fmt.Errorf("recipients: expected a vec, got %s", args[0].Type)
+		}
+		amounts, ok := args[1].GetVec()
+		if !ok || amounts == nil {
+			return nil, fmt.Errorf("amounts: expected a vec, got %s", args[1].Type)
+		}
+		if len(*recipients) != len(*amounts) {
+			return nil, fmt.Errorf("%w: %d recipients, %d amounts", ErrPayoutVectorMismatch, len(*recipients), len(*amounts))
+		}
+		items := make([]PayoutItem, len(*recipients))
+		for i, v := range *recipients {
+			recipient, err := decodeScAddress(v)
+			if err != nil {
+				return nil, fmt.Errorf("recipient %d: %w", i, err)
+			}
+			amount, ok := (*amounts)[i].GetI64()
+			if !ok {
+				return nil, fmt.Errorf("amount %d: expected i64, got %s", i, (*amounts)[i].Type)
+			}
+			items[i] = PayoutItem{Recipient: recipient, Amount: int64(amount)}
+		}
+		return items, nil
+	}
+	return nil, nil
+}
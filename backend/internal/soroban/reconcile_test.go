@@ -0,0 +1,163 @@
+package soroban
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+)
+
+func TestReconcilePayouts(t *testing.T) {
+	payouts := []PayoutEvent{
+		{TxHash: "t1", Recipient: testPayoutAlice, Amount: 100},
+		{TxHash: "t1", Recipient: testPayoutBob, Amount: 200},
+		{TxHash: "t2", Recipient: testPayoutAlice, Amount: 50},  // recorded as 60
+		{TxHash: "t3", Recipient: testPayoutCarol, Amount: 70},  // never recorded
+		{TxHash: "t1", Recipient: testPayoutAlice, Amount: 100}, // second payout to alice in t1
+	}
+	summaries := []batchPayoutSummary{
+		{TxHash: "b1", Count: 2, Total: 30}, // matches
+		{TxHash: "b2", Count: 2, Total: 30}, // one record short
+		{TxHash: "b3", Count: 1, Total: 5},  // never recorded
+	}
+	records := []RecordedPayout{
+		{TxHash: "t1", Recipient: testPayoutBob, Amount: 200},
+		{TxHash: "t1", Recipient: testPayoutAlice, Amount: 100},
+		{TxHash: "t1", Recipient: testPayoutAlice, Amount: 100},
+		{TxHash: "t2", Recipient: testPayoutAlice, Amount: 60},
+		{TxHash: "t4", Recipient: testPayoutBob, Amount: 80}, // no event
+		{TxHash: "b1", Recipient: testPayoutAlice, Amount: 10},
+		{TxHash: "b1", Recipient: testPayoutBob, Amount: 20},
+		{TxHash: "b2", Recipient: testPayoutAlice, Amount: 10},
+	}
+
+	report := reconcilePayouts(payouts, summaries, records)
+
+	if report.Matched != 5 {
+		t.Errorf("expected 5 matched payouts, got %d", report.Matched)
+	}
+	want := []PayoutDiscrepancy{
+		{Kind: DiscrepancyAmountMismatch, TxHash: "t2", Recipient: testPayoutAlice, OnChainAmount: 50, RecordedAmount: 60},
+		{Kind: DiscrepancyUnrecorded, TxHash: "t3", Recipient: testPayoutCarol, OnChainAmount: 70},
+		{Kind: DiscrepancyAmountMismatch, TxHash: "b2", OnChainAmount: 30, RecordedAmount: 10},
+		{Kind: DiscrepancyUnrecorded, TxHash: "b3", OnChainAmount: 5},
+		{Kind: DiscrepancyMissingOnChain, TxHash: "t4", Recipient: testPayoutBob, RecordedAmount: 80},
+	}
+	if !reflect.DeepEqual(report.Discrepancies, want) {
+		t.Errorf("got discrepancies %+v, want %+v", report.Discrepancies, want)
+	}
+}
+
+func TestReconcilePayouts_Clean(t *testing.T) {
+	report := reconcilePayouts(nil, nil, nil)
+	if report.Matched != 0 || report.Discrepancies == nil || len(report.Discrepancies) != 0 {
+		t.Errorf("expected an empty report with no discrepancies, got %+v", report)
+	}
+}
+
+func TestRequestedPayouts(t *testing.T) {
+	source := keypair.MustRandom()
+	pec := NewProgramEscrowContract(nil, nil, testPayoutCarol)
+	op, err := pec.batchPayoutOp([]PayoutItem{
+		{Recipient: testPayoutAlice, Amount: 10},
+		{Recipient: testPayoutBob, Amount: 20},
+	}, "")
+	if err != nil {
+		t.Fatalf("batchPayoutOp failed: %v", err)
+	}
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: source.Address(), Sequence: 100},
+		IncrementSequenceNum: true,
+		BaseFee:              txnbuild.MinBaseFee,
+		Operations:           []txnbuild.Operation{op},
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewTimeout(300)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx, err = tx.Sign(network.TestNetworkPassphrase, source); err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := tx.Base64()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payouts, err := RequestedPayouts(envelope)
+	if err != nil {
+		t.Fatalf("RequestedPayouts failed: %v", err)
+	}
+	want := []PayoutItem{{Recipient: testPayoutAlice, Amount: 10}, {Recipient: testPayoutBob, Amount: 20}}
+	if !reflect.DeepEqual(payouts, want) {
+		t.Errorf("got %+v, want %+v", payouts, want)
+	}
+
+	// Transactions that aren't payouts have nothing to record
+	for _, envelope := range []string{"", signedInnerTx(t, source, txnbuild.MinBaseFee)} {
+		if payouts, err := RequestedPayouts(envelope); err != nil || payouts != nil {
+			t.Errorf("expected no payouts, got %+v, %v", payouts, err)
+		}
+	}
+}
+
+// fakeRecordSource returns fixed records
+type fakeRecordSource struct {
+	records    []RecordedPayout
+	fromLedger uint32
+}
+
+func (f *fakeRecordSource) RecordedPayouts(_ context.Context, fromLedger uint32) ([]RecordedPayout, error) {
+	f.fromLedger = fromLedger
+	return f.records, nil
+}
+
+func TestPayoutReconciler_ReconcilePayouts(t *testing.T) {
+	// The single_payout to bob (bb22) and a two-recipient batch (dd44) from events_test
+	page, err := json.Marshal(map[string]interface{}{
+		"latestLedger": 1500,
+		"events": []ContractEvent{
+			{
+				Type:           "contract",
+				LedgerClosedAt: "2025-10-09T08:53:20Z",
+				TxHash:         "bb22",
+				Topic:          []string{topicProgramPayout},
+				Value:          "AAAAEAAAAAEAAAAEAAAADgAAAAloYWNrLTIwMjUAAAAAAAASAAAAAAAAAAAhIiMkJSYnKCkqKywtLi8wMTIzNDU2Nzg5Ojs8PT4/QAAAAAoAAAAAAAAAAAAAAAEqBfIAAAAACgAAAAAAAAAAAAAAA34R1gA=",
+			},
+			{
+				Type:   "contract",
+				TxHash: "dd44",
+				Topic:  []string{topicBatchPay},
+				Value:  "AAAAEAAAAAEAAAAEAAAADgAAAAloYWNrLTIwMjUAAAAAAAADAAAAAgAAAAoAAAAAAAAAAAAAAAAAAABkAAAACgAAAAAAAAAAAAAAAAAAAAA=",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newRPCStatusServer(t, string(page))
+	records := &fakeRecordSource{records: []RecordedPayout{
+		{TxHash: "bb22", Recipient: testPayoutBob, Amount: 5_000_000_000},
+		{TxHash: "dd44", Recipient: testPayoutAlice, Amount: 40},
+		{TxHash: "dd44", Recipient: testPayoutCarol, Amount: 60},
+		{TxHash: "ee55", Recipient: testPayoutAlice, Amount: 7},
+	}}
+
+	report, err := NewPayoutReconciler(client, records, testPayoutCarol).ReconcilePayouts(context.Background(), 1000)
+	if err != nil {
+		t.Fatalf("ReconcilePayouts failed: %v", err)
+	}
+	if records.fromLedger != 1000 || report.FromLedger != 1000 || report.LatestLedger != 1500 {
+		t.Errorf("unexpected ledgers: records from %d, report %+v", records.fromLedger, report)
+	}
+	if report.Matched != 3 {
+		t.Errorf("expected 3 matched payouts, got %d", report.Matched)
+	}
+	want := []PayoutDiscrepancy{{Kind: DiscrepancyMissingOnChain, TxHash: "ee55", Recipient: testPayoutAlice, RecordedAmount: 7}}
+	if !reflect.DeepEqual(report.Discrepancies, want) {
+		t.Errorf("got discrepancies %+v, want %+v", report.Discrepancies, want)
+	}
+}
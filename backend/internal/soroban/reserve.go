@@ -0,0 +1,82 @@
+package soroban
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/protocols/horizon"
+)
+
+// baseReserve is the network base reserve in stroops (0.5 XLM). An account must hold
+// two base reserves plus one per subentry and per entry it sponsors.
+const baseReserve = 5_000_000
+
+// ErrInsufficientReserve is returned (wrapped in an *InsufficientReserveError) when the
+// source account's XLM balance can't cover its minimum balance plus ReserveConfig.Buffer
+var ErrInsufficientReserve = errors.New("insufficient_reserve")
+
+// InsufficientReserveError reports how far the source account is below the balance
+// BuildAndSubmit requires. Amounts are in stroops. It matches ErrInsufficientReserve
+// with errors.Is.
+type InsufficientReserveError struct {
+	Account   string
+	Available int64 // native balance less selling liabilities
+	Required  int64 // minimum balance plus ReserveConfig.Buffer
+	Shortfall int64
+}
+
+func (e *InsufficientReserveError) Error() string {
+	return fmt.Sprintf("%s: account %s has %s XLM available, %s XLM required (short %s XLM)",
+		ErrInsufficientReserve, e.Account,
+		amount.StringFromInt64(e.Available), amount.StringFromInt64(e.Required), amount.StringFromInt64(e.Shortfall))
+}
+
+func (e *InsufficientReserveError) Unwrap() error {
+	return ErrInsufficientReserve
+}
+
+// SetReserveConfig changes the balance check made before building a transaction
+func (tb *TransactionBuilder) SetReserveConfig(cfg ReserveConfig) {
+	tb.reserve = cfg
+}
+
+// checkReserve fails with an *InsufficientReserveError when account can't keep its
+// minimum balance plus the configured buffer
+func (tb *TransactionBuilder) checkReserve(account horizon.Account) error {
+	if tb.reserve.Disabled {
+		return nil
+	}
+
+	var available int64
+	for _, b := range account.Balances {
+		if b.Asset.Type != "native" {
+			continue
+		}
+		balance, err := amount.ParseInt64(b.Balance)
+		if err != nil {
+			return fmt.Errorf("invalid native balance %q: %w", b.Balance, err)
+		}
+		available = balance
+		if b.SellingLiabilities != "" {
+			liabilities, err := amount.ParseInt64(b.SellingLiabilities)
+			if err != nil {
+				return fmt.Errorf("invalid selling liabilities %q: %w", b.SellingLiabilities, err)
+			}
+			available -= liabilities
+		}
+		break
+	}
+
+	entries := 2 + int64(account.SubentryCount) + int64(account.NumSponsoring) - int64(account.NumSponsored)
+	required := entries*baseReserve + tb.reserve.Buffer
+	if available >= required {
+		return nil
+	}
+	return &InsufficientReserveError{
+		Account:   account.AccountID,
+		Available: available,
+		Required:  required,
+		Shortfall: required - available,
+	}
+}
@@ -0,0 +1,60 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stellar/go/txnbuild"
+)
+
+func TestBuildAndSubmit_BelowReserveFailsEarly(t *testing.T) {
+	// 1.5 XLM with one subentry: the minimum balance is 1.5 XLM, plus a 1 XLM buffer
+	fake := &sequenceHorizon{sequence: 100, balance: "1.5000000", subentries: 1}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetReserveConfig(ReserveConfig{Buffer: 10_000_000})
+
+	_, err := tb.BuildAndSubmit(context.Background(), []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 0}})
+	if !errors.Is(err, ErrInsufficientReserve) {
+		t.Fatalf("expected ErrInsufficientReserve, got %v", err)
+	}
+	var reserveErr *InsufficientReserveError
+	if !errors.As(err, &reserveErr) {
+		t.Fatalf("expected an *InsufficientReserveError, got %T", err)
+	}
+	if reserveErr.Available != 15_000_000 || reserveErr.Required != 25_000_000 || reserveErr.Shortfall != 10_000_000 {
+		t.Errorf("unexpected amounts %+v", reserveErr)
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected nothing submitted, got %d submissions", len(fake.submissions))
+	}
+}
+
+func TestBuildAndSubmit_ReserveCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance string
+		cfg     ReserveConfig
+		wantErr bool
+	}{
+		{"exactly the minimum balance", "1.0000000", ReserveConfig{}, false},
+		{"one stroop short", "0.9999999", ReserveConfig{}, true},
+		{"buffer covered", "1.2000000", ReserveConfig{Buffer: 2_000_000}, false},
+		{"check disabled", "0.5000000", ReserveConfig{Disabled: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &sequenceHorizon{sequence: 100, balance: tt.balance}
+			tb := newSequenceTestBuilder(t, fake)
+			tb.SetReserveConfig(tt.cfg)
+
+			_, err := tb.BuildAndSubmit(context.Background(), []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 0}})
+			if got := errors.Is(err, ErrInsufficientReserve); got != tt.wantErr {
+				t.Errorf("expected insufficient reserve %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
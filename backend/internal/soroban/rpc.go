@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log/slog"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/stellar/go/xdr"
 )
 
 // RPCRequest represents a Soroban RPC JSON-RPC request
@@ -27,13 +30,42 @@ type RPCResponse struct {
 	Error   *RPCError       `json:"error,omitempty"`
 }
 
-// RPCError represents a Soroban RPC error
+// RPCError represents a Soroban RPC error. Client.Call returns it wrapped, so callers
+// can use errors.As and branch on Code.
 type RPCError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Data    string `json:"data,omitempty"`
 }
 
+// Well-known JSON-RPC error codes returned by Soroban RPC
+const (
+	RPCErrorCodeParse          = -32700 // invalid JSON
+	RPCErrorCodeInvalidRequest = -32600 // malformed request; also used for unknown resources (e.g. "transaction not found")
+	RPCErrorCodeMethodNotFound = -32601
+	RPCErrorCodeInvalidParams  = -32602
+	RPCErrorCodeInternal       = -32603
+)
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("RPC error: %s (code: %d)", e.Message, e.Code)
+}
+
+// IsNotFound reports whether the node does not know the requested resource yet,
+// which is expected while polling for a freshly submitted transaction.
+func (e *RPCError) IsNotFound() bool {
+	return e.Code == RPCErrorCodeInvalidRequest && strings.Contains(strings.ToLower(e.Message), "not found")
+}
+
+// IsRPCNotFound reports whether err wraps an RPCError for an unknown resource.
+func IsRPCNotFound(err error) bool {
+	var rpcErr *RPCError
+	return errors.As(err, &rpcErr) && rpcErr.IsNotFound()
+}
+
+// transactionPollInterval is how often PollTransactionStatus asks for a status.
+var transactionPollInterval = 2 * time.Second
+
 // Call makes a JSON-RPC call to the Soroban RPC endpoint
 func (c *Client) Call(ctx context.Context, method string, params interface{}) (*RPCResponse, error) {
 	req := RPCRequest{
@@ -43,43 +75,157 @@ func (c *Client) Call(ctx context.Context, method string, params interface{}) (*
 		Params:  params,
 	}
 
-	reqBody, err := json.Marshal(req)
+	loggerFrom(ctx).Debug("soroban RPC call", "method", method)
+
+	var rpcResp RPCResponse
+	if err := c.post(ctx, c.timeouts.forMethod(method), req, &rpcResp); err != nil {
+		return nil, err
+	}
+
+	if rpcResp.Error != nil {
+		return nil, fmt.Errorf("%s: %w", method, rpcResp.Error)
+	}
+
+	return &rpcResp, nil
+}
+
+// BatchCall sends reqs as a single JSON-RPC batch and returns the responses in the
+// same order as reqs, matched by ID. Each request needs a distinct ID. Per-request
+// failures are left in the corresponding RPCResponse.Error instead of failing the
+// batch; a request the server did not answer gets an RPCErrorCodeInternal error.
+func (c *Client) BatchCall(ctx context.Context, reqs []RPCRequest) ([]RPCResponse, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+
+	batch := make([]RPCRequest, len(reqs))
+	index := make(map[int]int, len(reqs))
+	for i, req := range reqs {
+		if _, dup := index[req.ID]; dup {
+			return nil, fmt.Errorf("duplicate request ID %d in batch", req.ID)
+		}
+		index[req.ID] = i
+		if req.JSONRPC == "" {
+			req.JSONRPC = "2.0"
+		}
+		batch[i] = req
+	}
+
+	loggerFrom(ctx).Debug("soroban RPC batch call", "requests", len(batch))
+
+	var raw json.RawMessage
+	if err := c.post(ctx, c.timeouts.defaultTimeout, batch, &raw); err != nil {
+		return nil, err
+	}
+
+	// A batch the server rejects as a whole comes back as a single error object.
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		var single RPCResponse
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil, fmt.Errorf("failed to decode RPC response: %w", err)
+		}
+		if single.Error != nil {
+			return nil, fmt.Errorf("batch: %w", single.Error)
+		}
+		return nil, fmt.Errorf("unexpected non-array response to batch request")
+	}
+
+	var resps []RPCResponse
+	if err := json.Unmarshal(raw, &resps); err != nil {
+		return nil, fmt.Errorf("failed to decode RPC response: %w", err)
+	}
+
+	out := make([]RPCResponse, len(batch))
+	answered := make([]bool, len(batch))
+	for _, resp := range resps {
+		i, ok := index[resp.ID]
+		if !ok || answered[i] {
+			continue
+		}
+		out[i] = resp
+		answered[i] = true
+	}
+	for i, req := range batch {
+		if !answered[i] {
+			out[i] = RPCResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Error:   &RPCError{Code: RPCErrorCodeInternal, Message: "no response for request in batch"},
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// post sends body to the RPC endpoint and decodes the JSON reply into out. The request
+// is abandoned after timeout, or earlier if ctx ends first. While the circuit breaker
+// is open it fails with ErrCircuitOpen without sending anything, and on a client
+// without RPC with ErrBackendNotConfigured.
+func (c *Client) post(ctx context.Context, timeout time.Duration, body interface{}, out interface{}) error {
+	if err := c.requireBackend(BackendRPC); err != nil {
+		return err
+	}
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+	err := c.send(ctx, timeout, body, out)
+	if err != nil && ctx.Err() != nil {
+		// The caller gave up, which says nothing about the provider
+		c.breaker.release()
+	} else {
+		c.breaker.record(ctx, err)
+	}
+	return err
+}
+
+// send performs one POST for post
+func (c *Client) send(ctx context.Context, timeout time.Duration, body interface{}, out interface{}) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	reqBody, err := json.Marshal(body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.rpcURL, bytes.NewReader(reqBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("RPC call failed: %w", err)
+		return fmt.Errorf("RPC call failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("RPC call failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var rpcResp RPCResponse
-	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
-		return nil, fmt.Errorf("failed to decode RPC response: %w", err)
+		return fmt.Errorf("RPC call failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	if rpcResp.Error != nil {
-		return nil, fmt.Errorf("RPC error: %s (code: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode RPC response: %w", err)
 	}
 
-	return &rpcResp, nil
+	return nil
 }
 
-// SimulateTransaction simulates a transaction using Soroban RPC
-func (c *Client) SimulateTransaction(ctx context.Context, txEnvelopeXDR string) (map[string]interface{}, error) {
+// ErrContractReverted is returned (wrapped, with the contract/host error) by
+// SimulateTransaction when the simulated invocation fails. Transport and RPC errors
+// are returned as they are, so errors.Is tells the two apart.
+var ErrContractReverted = errors.New("contract_reverted")
+
+// SimulateTransaction simulates a transaction using Soroban RPC. If the contract
+// reverts, the parsed result is returned together with an error wrapping
+// ErrContractReverted. EstimatedFee leaves out the transaction's base fee.
+func (c *Client) SimulateTransaction(ctx context.Context, txEnvelopeXDR string) (*SimulationResult, error) {
 	params := map[string]interface{}{
 		"transaction": txEnvelopeXDR,
 	}
@@ -89,14 +235,40 @@ func (c *Client) SimulateTransaction(ctx context.Context, txEnvelopeXDR string)
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp.Result, &result); err != nil {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(resp.Result, &raw); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
 	}
 
+	result := parseSimulationResult(raw, 0)
+	if !result.Success {
+		return result, fmt.Errorf("%w: %s", ErrContractReverted, result.Error)
+	}
+	if result.Result, err = decodeSimulationReturn(raw); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
+// decodeSimulationReturn decodes the return value of the first invocation in a
+// simulateTransaction response, or returns nil if there is none.
+func decodeSimulationReturn(raw map[string]interface{}) (*xdr.ScVal, error) {
+	results, _ := raw["results"].([]interface{})
+	if len(results) == 0 {
+		return nil, nil
+	}
+	first, _ := results[0].(map[string]interface{})
+	b64, _ := first["xdr"].(string)
+	if b64 == "" {
+		return nil, nil
+	}
+	var val xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(b64, &val); err != nil {
+		return nil, fmt.Errorf("failed to decode simulation result: %w", err)
+	}
+	return &val, nil
+}
+
 // SendTransaction sends a transaction using Soroban RPC
 func (c *Client) SendTransaction(ctx context.Context, txEnvelopeXDR string) (string, error) {
 	params := map[string]interface{}{
@@ -140,6 +312,56 @@ func (c *Client) GetTransactionStatus(ctx context.Context, txHash string) (map[s
 	return result, nil
 }
 
+// getEventsPageLimit is the number of events GetEvents asks for per request
+const getEventsPageLimit = 1000
+
+// GetEvents returns the contract events emitted by contractIDs (every contract if none
+// are given; RPC accepts at most 5) from startLedger on, following the RPC's
+// pagination, together with the latest ledger the RPC has seen. RPC keeps a limited
+// history, about a week by default; a startLedger before it is an error.
+func (c *Client) GetEvents(ctx context.Context, startLedger uint32, contractIDs []string) ([]ContractEvent, uint32, error) {
+	filter := map[string]interface{}{"type": "contract"}
+	if len(contractIDs) > 0 {
+		filter["contractIds"] = contractIDs
+	}
+	params := map[string]interface{}{
+		"startLedger": startLedger,
+		"filters":     []interface{}{filter},
+		"pagination":  map[string]interface{}{"limit": getEventsPageLimit},
+	}
+
+	var events []ContractEvent
+	for {
+		resp, err := c.Call(ctx, "getEvents", params)
+		if err != nil {
+			return nil, 0, err
+		}
+		var page struct {
+			Events       []ContractEvent `json:"events"`
+			LatestLedger uint32          `json:"latestLedger"`
+			Cursor       string          `json:"cursor"`
+		}
+		if err := json.Unmarshal(resp.Result, &page); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal result: %w", err)
+		}
+		events = append(events, page.Events...)
+		if len(page.Events) < getEventsPageLimit {
+			return events, page.LatestLedger, nil
+		}
+
+		// Older RPC versions don't return a cursor; an event's ID works as one
+		cursor := page.Cursor
+		if cursor == "" {
+			cursor = page.Events[len(page.Events)-1].ID
+		}
+		// A cursor replaces startLedger
+		params = map[string]interface{}{
+			"filters":    []interface{}{filter},
+			"pagination": map[string]interface{}{"cursor": cursor, "limit": getEventsPageLimit},
+		}
+	}
+}
+
 // GetLatestLedger gets the latest ledger information
 func (c *Client) GetLatestLedger(ctx context.Context) (map[string]interface{}, error) {
 	resp, err := c.Call(ctx, "getLatestLedger", nil)
@@ -158,7 +380,7 @@ func (c *Client) GetLatestLedger(ctx context.Context) (map[string]interface{}, e
 // PollTransactionStatus polls for transaction status until confirmed or timeout
 func (c *Client) PollTransactionStatus(ctx context.Context, txHash string, timeout time.Duration) (map[string]interface{}, error) {
 	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(2 * time.Second)
+	ticker := time.NewTicker(transactionPollInterval)
 	defer ticker.Stop()
 
 	for {
@@ -172,8 +394,11 @@ func (c *Client) PollTransactionStatus(ctx context.Context, txHash string, timeo
 
 			status, err := c.GetTransactionStatus(ctx, txHash)
 			if err != nil {
+				if !IsRPCNotFound(err) {
+					return nil, fmt.Errorf("failed to get transaction status: %w", err)
+				}
 				// Transaction not found yet, continue polling
-				slog.Debug("transaction not found, continuing to poll",
+				loggerFrom(ctx).Debug("transaction not found, continuing to poll",
 					"tx_hash", txHash,
 					"error", err,
 				)
@@ -183,7 +408,7 @@ func (c *Client) PollTransactionStatus(ctx context.Context, txHash string, timeo
 			// Check status
 			if statusVal, ok := status["status"].(string); ok {
 				if statusVal == "SUCCESS" || statusVal == "FAILED" {
-					slog.Info("transaction status determined",
+					loggerFrom(ctx).Info("transaction status determined",
 						"tx_hash", txHash,
 						"status", statusVal,
 					)
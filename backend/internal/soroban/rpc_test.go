@@ -0,0 +1,402 @@
+package soroban
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+)
+
+// newTestRPCServer answers every JSON-RPC call with the next response from responses
+// (the last one repeats).
+func newTestRPCServer(t *testing.T, responses ...RPCResponse) (*Client, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(calls.Add(1)) - 1
+		if i >= len(responses) {
+			i = len(responses) - 1
+		}
+		resp := responses[i]
+		resp.JSONRPC = "2.0"
+		resp.ID = 1
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(Config{RPCURL: srv.URL, Network: NetworkTestnet})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return client, &calls
+}
+
+func TestCall_ReturnsTypedRPCError(t *testing.T) {
+	client, _ := newTestRPCServer(t, RPCResponse{
+		Error: &RPCError{Code: RPCErrorCodeInvalidParams, Message: "invalid hash"},
+	})
+
+	_, err := client.Call(context.Background(), "getTransaction", nil)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected *RPCError, got %T: %v", err, err)
+	}
+	if rpcErr.Code != RPCErrorCodeInvalidParams {
+		t.Errorf("expected code %d, got %d", RPCErrorCodeInvalidParams, rpcErr.Code)
+	}
+	if IsRPCNotFound(err) {
+		t.Error("invalid params should not be classified as not found")
+	}
+}
+
+func TestIsRPCNotFound(t *testing.T) {
+	notFound := &RPCError{Code: RPCErrorCodeInvalidRequest, Message: "transaction not found"}
+	if !IsRPCNotFound(notFound) {
+		t.Error("expected not found")
+	}
+	if IsRPCNotFound(&RPCError{Code: RPCErrorCodeInvalidRequest, Message: "bad request"}) {
+		t.Error("other invalid-request errors are not not-found")
+	}
+	if IsRPCNotFound(errors.New("transaction not found")) {
+		t.Error("untyped errors are not classified")
+	}
+}
+
+func TestPollTransactionStatus_KeepsPollingOnNotFound(t *testing.T) {
+	defer func(d time.Duration) { transactionPollInterval = d }(transactionPollInterval)
+	transactionPollInterval = 10 * time.Millisecond
+
+	client, calls := newTestRPCServer(t,
+		RPCResponse{Error: &RPCError{Code: RPCErrorCodeInvalidRequest, Message: "transaction not found"}},
+		RPCResponse{Result: json.RawMessage(`{"status":"SUCCESS"}`)},
+	)
+
+	status, err := client.PollTransactionStatus(context.Background(), "abc", 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status["status"] != "SUCCESS" {
+		t.Errorf("expected SUCCESS, got %v", status["status"])
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+}
+
+func TestPollTransactionStatus_SurfacesOtherErrors(t *testing.T) {
+	defer func(d time.Duration) { transactionPollInterval = d }(transactionPollInterval)
+	transactionPollInterval = 10 * time.Millisecond
+
+	client, calls := newTestRPCServer(t,
+		RPCResponse{Error: &RPCError{Code: RPCErrorCodeInternal, Message: "database unavailable"}},
+	)
+
+	_, err := client.PollTransactionStatus(context.Background(), "abc", 5*time.Second)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != RPCErrorCodeInternal {
+		t.Fatalf("expected internal RPCError, got %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected to stop after the first failure, got %d calls", n)
+	}
+}
+
+func TestGetNetwork(t *testing.T) {
+	client, _ := newTestRPCServer(t, RPCResponse{Result: json.RawMessage(`{
+		"friendbotUrl": "https://friendbot.stellar.org/",
+		"passphrase": "Test SDF Network ; September 2015",
+		"protocolVersion": 22
+	}`)})
+
+	info, err := client.GetNetwork(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Passphrase != "Test SDF Network ; September 2015" {
+		t.Errorf("unexpected passphrase %q", info.Passphrase)
+	}
+	if info.ProtocolVersion != 22 {
+		t.Errorf("expected protocol version 22, got %d", info.ProtocolVersion)
+	}
+	if info.FriendbotURL != "https://friendbot.stellar.org/" {
+		t.Errorf("unexpected friendbot URL %q", info.FriendbotURL)
+	}
+
+	if err := client.Verify(context.Background()); err != nil {
+		t.Errorf("testnet client against testnet RPC should verify, got %v", err)
+	}
+}
+
+func TestVerify_NetworkMismatch(t *testing.T) {
+	client, _ := newTestRPCServer(t, RPCResponse{Result: json.RawMessage(`{
+		"passphrase": "Test SDF Network ; September 2015",
+		"protocolVersion": 22
+	}`)})
+	client.networkPassphrase = "Public Global Stellar Network ; September 2015"
+
+	if err := client.Verify(context.Background()); !errors.Is(err, ErrNetworkMismatch) {
+		t.Fatalf("expected ErrNetworkMismatch, got %v", err)
+	}
+}
+
+// captureDefaultLog routes slog.Default to a buffer for the duration of the test.
+func captureDefaultLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestNewClient_StandaloneRequiresPassphrase(t *testing.T) {
+	_, err := NewClient(Config{RPCURL: "http://127.0.0.1:1", Network: "standalone"})
+	if !errors.Is(err, ErrPassphraseRequired) {
+		t.Fatalf("expected ErrPassphraseRequired, got %v", err)
+	}
+
+	const standalone = "Standalone Network ; February 2017"
+	logs := captureDefaultLog(t)
+	client, err := NewClient(Config{RPCURL: "http://127.0.0.1:1", Network: "standalone", NetworkPassphrase: standalone})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if client.GetNetworkPassphrase() != standalone {
+		t.Fatalf("expected %q, got %q", standalone, client.GetNetworkPassphrase())
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("expected no warning for a custom network, got %q", logs.String())
+	}
+}
+
+func TestNewClient_WarnsOnPassphraseMismatch(t *testing.T) {
+	logs := captureDefaultLog(t)
+	// Mainnet passphrase with a typo
+	client, err := NewClient(Config{
+		RPCURL:            "http://127.0.0.1:1",
+		Network:           NetworkMainnet,
+		NetworkPassphrase: "Public Global Stellar Network ; September 2016",
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if client.GetNetworkPassphrase() != "Public Global Stellar Network ; September 2016" {
+		t.Fatalf("configured passphrase was replaced: %q", client.GetNetworkPassphrase())
+	}
+	if !strings.Contains(logs.String(), "passphrase does not match") {
+		t.Fatalf("expected a mismatch warning, got %q", logs.String())
+	}
+
+	logs.Reset()
+	if _, err := NewClient(Config{RPCURL: "http://127.0.0.1:1", Network: NetworkTestnet, NetworkPassphrase: network.TestNetworkPassphrase}); err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("expected no warning for a matching passphrase, got %q", logs.String())
+	}
+}
+
+func TestBatchCall_DemultiplexesOutOfOrderResponses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []RPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("expected a batch array: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if len(reqs) != 3 {
+			t.Errorf("expected 3 requests in one POST, got %d", len(reqs))
+		}
+		// Answer in reverse order; request 11 fails and request 12 gets no answer.
+		_, _ = w.Write([]byte(`[
+			{"jsonrpc":"2.0","id":11,"error":{"code":-32600,"message":"transaction not found"}},
+			{"jsonrpc":"2.0","id":10,"result":{"status":"SUCCESS"}}
+		]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(Config{RPCURL: srv.URL, Network: NetworkTestnet})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	resps, err := client.BatchCall(context.Background(), []RPCRequest{
+		{ID: 10, Method: "getTransaction", Params: map[string]string{"hash": "a"}},
+		{ID: 11, Method: "getTransaction", Params: map[string]string{"hash": "b"}},
+		{ID: 12, Method: "getTransaction", Params: map[string]string{"hash": "c"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resps) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(resps))
+	}
+
+	if resps[0].ID != 10 || resps[0].Error != nil || string(resps[0].Result) != `{"status":"SUCCESS"}` {
+		t.Errorf("unexpected response for id 10: %+v", resps[0])
+	}
+	if resps[1].ID != 11 || !IsRPCNotFound(resps[1].Error) {
+		t.Errorf("expected not-found error for id 11, got %+v", resps[1])
+	}
+	if resps[2].ID != 12 || resps[2].Error == nil || resps[2].Error.Code != RPCErrorCodeInternal {
+		t.Errorf("expected missing-response error for id 12, got %+v", resps[2])
+	}
+}
+
+func TestBatchCall_RejectsDuplicateIDs(t *testing.T) {
+	client, calls := newTestRPCServer(t, RPCResponse{})
+
+	_, err := client.BatchCall(context.Background(), []RPCRequest{
+		{ID: 1, Method: "getLatestLedger"},
+		{ID: 1, Method: "getNetwork"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for duplicate IDs")
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("expected no request to be sent, got %d", n)
+	}
+}
+
+// newSlowRPCServer answers getLatestLedger at once and holds every other call until
+// the client gives up or the test ends.
+func newSlowRPCServer(t *testing.T, cfg Config) *Client {
+	t.Helper()
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req RPCRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "getLatestLedger" {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+			return
+		}
+		_ = json.NewEncoder(w).Encode(RPCResponse{JSONRPC: "2.0", ID: 1, Result: json.RawMessage(`{"sequence":7}`)})
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(done) })
+
+	cfg.RPCURL = srv.URL
+	cfg.Network = NetworkTestnet
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return client
+}
+
+func TestCall_ContextDeadlineCancelsSlowResponse(t *testing.T) {
+	client := newSlowRPCServer(t, Config{HTTPTimeout: 10 * time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.SimulateTransaction(ctx, "AAAA")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the ctx deadline to cut the call short, took %v", elapsed)
+	}
+}
+
+func TestCall_PerMethodTimeout(t *testing.T) {
+	client := newSlowRPCServer(t, Config{
+		HTTPTimeout:         10 * time.Second,
+		SimulateTimeout:     50 * time.Millisecond,
+		LatestLedgerTimeout: time.Second,
+	})
+
+	start := time.Now()
+	if _, err := client.SimulateTransaction(context.Background(), "AAAA"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected SimulateTimeout to apply, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected SimulateTimeout rather than HTTPTimeout, took %v", elapsed)
+	}
+
+	// Other methods keep their own deadline
+	ledger, err := client.GetLatestLedger(context.Background())
+	if err != nil || ledger["sequence"] != float64(7) {
+		t.Errorf("expected getLatestLedger to succeed, got %v, %v", ledger, err)
+	}
+}
+
+func TestSimulateTransaction_Success(t *testing.T) {
+	want, err := EncodeScValInt64(4200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64, err := xdr.MarshalBase64(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := newTestRPCServer(t, RPCResponse{
+		Result: json.RawMessage(`{"minResourceFee":"58181","transactionData":"AAAAdata","latestLedger":12,` +
+			`"results":[{"auth":[],"xdr":"` + b64 + `"}]}`),
+	})
+
+	res, err := client.SimulateTransaction(context.Background(), "AAAA")
+	if err != nil {
+		t.Fatalf("SimulateTransaction failed: %v", err)
+	}
+	if !res.Success || res.Error != "" || res.MinResourceFee != 58181 || res.TransactionData != "AAAAdata" {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if res.Result == nil {
+		t.Fatal("expected a decoded return value")
+	}
+	if got, _ := xdr.MarshalBase64(*res.Result); got != b64 {
+		t.Errorf("expected return value %s, got %s", b64, got)
+	}
+}
+
+func TestSimulateTransaction_NoReturnValue(t *testing.T) {
+	client, _ := newTestRPCServer(t, RPCResponse{Result: json.RawMessage(`{"minResourceFee":"100","latestLedger":12}`)})
+
+	res, err := client.SimulateTransaction(context.Background(), "AAAA")
+	if err != nil {
+		t.Fatalf("SimulateTransaction failed: %v", err)
+	}
+	if !res.Success || res.Result != nil {
+		t.Errorf("expected success without a value, got %+v", res)
+	}
+}
+
+func TestSimulateTransaction_Reverted(t *testing.T) {
+	client, _ := newTestRPCServer(t, RPCResponse{
+		Result: json.RawMessage(`{"error":"HostError: Error(Contract, #4)","latestLedger":12}`),
+	})
+
+	res, err := client.SimulateTransaction(context.Background(), "AAAA")
+	if !errors.Is(err, ErrContractReverted) {
+		t.Fatalf("expected ErrContractReverted, got %v", err)
+	}
+	if res == nil || res.Success || res.Error != "HostError: Error(Contract, #4)" {
+		t.Errorf("expected the reverted result alongside the error, got %+v", res)
+	}
+}
+
+func TestSimulateTransaction_TransportErrorIsNotRevert(t *testing.T) {
+	client, _ := newTestRPCServer(t, RPCResponse{
+		Error: &RPCError{Code: RPCErrorCodeInternal, Message: "node is syncing"},
+	})
+
+	_, err := client.SimulateTransaction(context.Background(), "AAAA")
+	var rpcErr *RPCError
+	if errors.Is(err, ErrContractReverted) || !errors.As(err, &rpcErr) {
+		t.Errorf("expected an RPCError, not a revert, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrShuttingDown is returned by BuildAndSubmit, ConfirmPayout and FeeBump (and
+// everything submitting through them) once Shutdown has been called
+var ErrShuttingDown = errors.New("shutting_down")
+
+// inflightSubmissions tracks the confirmations a builder is waiting on, so Shutdown can
+// stop them. It is shared by the builders derived with withSource.
+type inflightSubmissions struct {
+	mu      sync.Mutex
+	closed  bool
+	nextID  uint64
+	cancels map[uint64]context.CancelCauseFunc
+	waits   sync.WaitGroup
+}
+
+// accepting reports whether new transactions may be submitted. A nil tracker always
+// accepts.
+func (s *inflightSubmissions) accepting() bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.closed
+}
+
+// track registers a confirmation wait and returns the context to wait with, cancelled
+// with ErrShuttingDown by Shutdown, and a func to call when the wait is over. A wait
+// that starts after Shutdown gets an already cancelled context.
+func (s *inflightSubmissions) track(ctx context.Context) (context.Context, func()) {
+	if s == nil {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		cancel(ErrShuttingDown)
+		return ctx, func() {}
+	}
+	if s.cancels == nil {
+		s.cancels = make(map[uint64]context.CancelCauseFunc)
+	}
+	id := s.nextID
+	s.nextID++
+	s.cancels[id] = cancel
+	s.waits.Add(1)
+
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.cancels, id)
+		s.mu.Unlock()
+		cancel(nil)
+		s.waits.Done()
+	}
+}
+
+// shutdownCause wraps err, from a wait on a context returned by track, with
+// ErrShuttingDown if Shutdown is what ended the wait
+func shutdownCause(ctx context.Context, err error) error {
+	if err != nil && errors.Is(context.Cause(ctx), ErrShuttingDown) && !errors.Is(err, ErrShuttingDown) {
+		return fmt.Errorf("%w: %w", ErrShuttingDown, err)
+	}
+	return err
+}
+
+// Shutdown stops the builder, and the copies made from it for program source accounts
+// (see NewProgramEscrowContractWithSource), from submitting new transactions (they
+// fail with ErrShuttingDown) and cancels the confirmations still being waited on. Each of those is reported to the result sink as
+// pending, with its hash and function, so a reconciler can resume polling after a
+// restart; callers blocked in SubmitAndConfirm get the pending result back, as on a
+// confirmation timeout. Shutdown returns once every cancelled wait has been recorded,
+// or with ctx's error if that takes longer than ctx allows.
+func (tb *TransactionBuilder) Shutdown(ctx context.Context) error {
+	s := tb.inflight
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.closed = true
+	pending := len(s.cancels)
+	for _, cancel := range s.cancels {
+		cancel(ErrShuttingDown)
+	}
+	s.mu.Unlock()
+
+	loggerFrom(ctx).Info("transaction builder shutting down", "pending_confirmations", pending)
+
+	drained := make(chan struct{})
+	go func() {
+		s.waits.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
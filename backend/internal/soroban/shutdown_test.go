@@ -0,0 +1,137 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdown_FlushesPendingConfirmations(t *testing.T) {
+	fastConfirmationPolling(t)
+	var landed atomic.Bool // never lands
+	tb := newConfirmTestBuilder(t, &landed)
+	tb.inflight = &inflightSubmissions{}
+	sink := &fakeSink{}
+	tb.SetResultSink(sink)
+
+	// One caller blocked waiting, one confirmation polling in the background. Each call
+	// is given its policy up front, as SubmitAndConfirm does.
+	wait := WaitForConfirmationPolicy(time.Minute)
+	waited := make(chan *TransactionResult, 1)
+	go func() {
+		result, _ := tb.confirm(context.Background(), wait, "batch_payout", &TransactionResult{Hash: "wait", Status: "pending"})
+		waited <- result
+	}()
+
+	asyncErr := make(chan error, 1)
+	async := AsyncConfirmationPolicy(time.Minute, func(_ *TransactionResult, err error) {
+		asyncErr <- err
+	})
+	tb.confirm(context.Background(), async, "single_payout", &TransactionResult{Hash: "async", Status: "pending"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tb.inflight.mu.Lock()
+		n := len(tb.inflight.cancels)
+		tb.inflight.mu.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 confirmations in flight, got %d", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tb.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	// The blocked caller gets its pending result back, the async callback the cause
+	select {
+	case result := <-waited:
+		if result.Hash != "wait" || result.IsConfirmed() {
+			t.Errorf("expected the pending submission back, got %+v", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked wait did not return")
+	}
+	select {
+	case err := <-asyncErr:
+		if !errors.Is(err, ErrShuttingDown) {
+			t.Errorf("expected ErrShuttingDown in the callback, got %v", err)
+		}
+	default:
+		t.Error("expected the async callback to have run before Shutdown returned")
+	}
+
+	// Both hashes are flushed to the sink as pending; the async one was also recorded
+	// when it was submitted
+	sink.mu.Lock()
+	var flushed []string
+	for i, res := range sink.results {
+		if res.Status != "pending" {
+			t.Errorf("expected only pending results, got %+v", res)
+		}
+		flushed = append(flushed, res.Hash+"/"+sink.functions[i])
+	}
+	sink.mu.Unlock()
+	sort.Strings(flushed)
+	if got := strings.Join(flushed, ","); got != "async/single_payout,async/single_payout,wait/batch_payout" {
+		t.Errorf("unexpected sink records %s", got)
+	}
+
+	// Nothing new is accepted
+	if _, err := tb.BuildAndSubmit(context.Background(), nil); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown after Shutdown, got %v", err)
+	}
+}
+
+func TestShutdown_WithoutPendingConfirmations(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+
+	if err := tb.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+	if _, err := pec.SinglePayout(context.Background(), testPayoutAlice, 10); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown, got %v", err)
+	}
+	if fake.lookups != 0 || len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be built or submitted, got %d lookups and %d submissions", fake.lookups, len(fake.submissions))
+	}
+}
+
+func TestShutdown_RejectsPreparedPayouts(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	pec := newPreparedTestContract(t, fake)
+
+	prepared, err := pec.PreparePayout(context.Background(), largeBatch)
+	if err != nil {
+		t.Fatalf("PreparePayout failed: %v", err)
+	}
+	if err := pec.txBuilder.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if _, err := pec.ConfirmPayout(context.Background(), prepared.Token); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown, got %v", err)
+	}
+	if _, err := pec.txBuilder.FeeBump(context.Background(), prepared.EnvelopeXDR, 1000); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown from FeeBump, got %v", err)
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be submitted, got %d submissions", len(fake.submissions))
+	}
+	// The refused confirmation didn't spend the token
+	if _, err := pec.prepared.take(prepared.Token); err != nil {
+		t.Errorf("expected the token to stay unused, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package soroban
+
+import (
+	"context"
+
+	"github.com/stellar/go/txnbuild"
+)
+
+// ResultSink persists the outcome of submitted transactions, e.g. as an audit trail of
+// payouts. function is the contract function the transaction invoked, or "" when it
+// didn't invoke one.
+type ResultSink interface {
+	RecordResult(ctx context.Context, function string, res *TransactionResult) error
+}
+
+// SetResultSink makes the builder report every transaction it submits and confirms
+// through SubmitAndConfirm (and ConfirmPayout) to sink; nil (the default) records
+// nothing. With ConfirmAsync a transaction is reported twice: pending when submitted
+// and again once confirmation finishes.
+func (tb *TransactionBuilder) SetResultSink(sink ResultSink) {
+	tb.sink = sink
+}
+
+// recordResult reports res to the sink. A sink that fails is logged and otherwise
+// ignored: the transaction was submitted either way.
+func (tb *TransactionBuilder) recordResult(ctx context.Context, function string, res *TransactionResult) {
+	if tb.sink == nil || res == nil {
+		return
+	}
+	if err := tb.sink.RecordResult(ctx, function, res); err != nil {
+		loggerFrom(ctx).Warn("failed to record transaction result",
+			"error", err,
+			"tx_hash", res.Hash,
+			"function", function,
+		)
+	}
+}
+
+// contractFunctionName is the contract function invoked by the first InvokeHostFunction
+// operation, or "" when there is none
+func contractFunctionName(operations []txnbuild.Operation) string {
+	for _, op := range operations {
+		invoke, ok := op.(*txnbuild.InvokeHostFunction)
+		if !ok || invoke.HostFunction.InvokeContract == nil {
+			continue
+		}
+		return string(invoke.HostFunction.InvokeContract.FunctionName)
+	}
+	return ""
+}
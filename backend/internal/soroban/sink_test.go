@@ -0,0 +1,70 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// fakeSink records the results reported to it
+type fakeSink struct {
+	mu        sync.Mutex
+	functions []string
+	results   []TransactionResult
+	err       error
+}
+
+func (f *fakeSink) RecordResult(_ context.Context, function string, res *TransactionResult) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.functions = append(f.functions, function)
+	f.results = append(f.results, *res)
+	return f.err
+}
+
+func TestResultSink_RecordsPayout(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(NoConfirmation())
+	sink := &fakeSink{}
+	tb.SetResultSink(sink)
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	if _, err := pec.SinglePayout(context.Background(), testPayoutAlice, 10); err != nil {
+		t.Fatalf("SinglePayout failed: %v", err)
+	}
+	if len(sink.results) != 1 {
+		t.Fatalf("expected 1 recorded result, got %d", len(sink.results))
+	}
+	res := sink.results[0]
+	if sink.functions[0] != "single_payout" {
+		t.Errorf("expected function single_payout, got %q", sink.functions[0])
+	}
+	if res.Hash != "abc" || res.Ledger != 9 || res.Status != "pending" || res.Submitted.IsZero() {
+		t.Errorf("unexpected recorded result %+v", res)
+	}
+}
+
+func TestResultSink_FailureDoesNotFailPayout(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(NoConfirmation())
+	sink := &fakeSink{err: errors.New("database unavailable")}
+	tb.SetResultSink(sink)
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	result, err := pec.BatchPayout(context.Background(), []PayoutItem{
+		{Recipient: testPayoutAlice, Amount: 10},
+		{Recipient: testPayoutBob, Amount: 20},
+	})
+	if err != nil {
+		t.Fatalf("expected the payout to succeed despite the sink, got %v", err)
+	}
+	if result.Hash != "abc" {
+		t.Errorf("expected hash abc, got %s", result.Hash)
+	}
+	if len(sink.functions) != 1 || sink.functions[0] != "batch_payout" {
+		t.Errorf("expected one batch_payout to be recorded, got %v", sink.functions)
+	}
+}
@@ -0,0 +1,111 @@
+package soroban
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+// ErrContractArgsMismatch is returned (wrapped) when the arguments encoded for a
+// contract call don't match the function's registered spec, i.e. a bug in the caller
+// that would otherwise only surface as an opaque contract error after submitting
+var ErrContractArgsMismatch = errors.New("contract_args_mismatch")
+
+// contractArg is one parameter of a contract function. elem is the element type of a
+// vec parameter.
+type contractArg struct {
+	name string
+	typ  xdr.ScValType
+	elem xdr.ScValType
+}
+
+// contractSpec maps function names to their ordered parameters
+type contractSpec map[string][]contractArg
+
+// programEscrowSpec lists the program escrow functions this package invokes, with the
+// arguments as it encodes them
+var programEscrowSpec = contractSpec{
+	"init_program": {
+		{name: "program_id", typ: xdr.ScValTypeScvString},
+		{name: "authorized_payout_key", typ: xdr.ScValTypeScvAddress},
+		{name: "token_address", typ: xdr.ScValTypeScvAddress},
+	},
+	"lock_program_funds": {
+		{name: "amount", typ: xdr.ScValTypeScvI64},
+	},
+	"single_payout": {
+		{name: "recipient", typ: xdr.ScValTypeScvAddress},
+		{name: "amount", typ: xdr.ScValTypeScvI64},
+	},
+	"single_payout_token": {
+		{name: "recipient", typ: xdr.ScValTypeScvAddress},
+		{name: "amount", typ: xdr.ScValTypeScvI64},
+		{name: "token", typ: xdr.ScValTypeScvAddress},
+	},
+	"batch_payout": {
+		{name: "recipients", typ: xdr.ScValTypeScvVec, elem: xdr.ScValTypeScvAddress},
+		{name: "amounts", typ: xdr.ScValTypeScvVec, elem: xdr.ScValTypeScvI64},
+	},
+	"batch_payout_token": {
+		{name: "recipients", typ: xdr.ScValTypeScvVec, elem: xdr.ScValTypeScvAddress},
+		{name: "amounts", typ: xdr.ScValTypeScvVec, elem: xdr.ScValTypeScvI64},
+		{name: "token", typ: xdr.ScValTypeScvAddress},
+	},
+}
+
+// check fails with ErrContractArgsMismatch when function isn't in the spec, or args
+// have the wrong arity or types
+func (s contractSpec) check(function string, args []xdr.ScVal) error {
+	params, ok := s[function]
+	if !ok {
+		return fmt.Errorf("%w: unknown function %s", ErrContractArgsMismatch, function)
+	}
+	if len(args) != len(params) {
+		names := make([]string, len(params))
+		for i, p := range params {
+			names[i] = p.name
+		}
+		return fmt.Errorf("%w: %s expects %d arguments (%s), got %d",
+			ErrContractArgsMismatch, function, len(params), strings.Join(names, ", "), len(args))
+	}
+	for i, p := range params {
+		if args[i].Type != p.typ {
+			return fmt.Errorf("%w: %s argument %d (%s) must be %s, got %s",
+				ErrContractArgsMismatch, function, i, p.name, scValTypeName(p.typ), scValTypeName(args[i].Type))
+		}
+		if p.typ != xdr.ScValTypeScvVec {
+			continue
+		}
+		vec, ok := args[i].GetVec()
+		if !ok || vec == nil {
+			continue
+		}
+		for j, v := range *vec {
+			if v.Type != p.elem {
+				return fmt.Errorf("%w: %s argument %d (%s) element %d must be %s, got %s",
+					ErrContractArgsMismatch, function, i, p.name, j, scValTypeName(p.elem), scValTypeName(v.Type))
+			}
+		}
+	}
+	return nil
+}
+
+// buildOp checks args against the spec of function, then builds its invocation
+func (s contractSpec) buildOp(contractAddress xdr.ScAddress, function string, args []xdr.ScVal) (txnbuild.Operation, error) {
+	if err := s.check(function, args); err != nil {
+		return nil, err
+	}
+	op, err := BuildInvokeHostFunctionOp(contractAddress, function, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build operation: %w", err)
+	}
+	return op, nil
+}
+
+// scValTypeName returns t without its generated prefix, e.g. "I64" for ScValTypeScvI64
+func scValTypeName(t xdr.ScValType) string {
+	return strings.TrimPrefix(t.String(), "ScValTypeScv")
+}
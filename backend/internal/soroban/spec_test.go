@@ -0,0 +1,82 @@
+package soroban
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stellar/go/xdr"
+)
+
+func TestContractSpec_Check(t *testing.T) {
+	mustVal := func(v xdr.ScVal, err error) xdr.ScVal {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	alice := mustVal(EncodeScValAddress(testPayoutAlice))
+	amount := mustVal(EncodeScValInt64(100))
+	programID := mustVal(EncodeScValString("hack-2025"))
+
+	tests := []struct {
+		name     string
+		function string
+		args     []xdr.ScVal
+		wantErr  string // substring of the error; empty for a valid call
+	}{
+		{"init_program", "init_program", []xdr.ScVal{programID, alice, alice}, ""},
+		{"lock_program_funds", "lock_program_funds", []xdr.ScVal{amount}, ""},
+		{"single_payout", "single_payout", []xdr.ScVal{alice, amount}, ""},
+		{"batch_payout", "batch_payout", []xdr.ScVal{
+			mustVal(EncodeScValVec([]xdr.ScVal{alice, alice})),
+			mustVal(EncodeScValVec([]xdr.ScVal{amount, amount})),
+		}, ""},
+		{"wrong arity", "single_payout", []xdr.ScVal{alice, amount, alice},
+			"single_payout expects 2 arguments (recipient, amount), got 3"},
+		{"swapped arguments", "single_payout", []xdr.ScVal{amount, alice},
+			"single_payout argument 0 (recipient) must be Address, got I64"},
+		{"wrong vec element", "batch_payout", []xdr.ScVal{
+			mustVal(EncodeScValVec([]xdr.ScVal{alice})),
+			mustVal(EncodeScValVec([]xdr.ScVal{alice})),
+		}, "batch_payout argument 1 (amounts) element 0 must be I64, got Address"},
+		{"unknown function", "single_payuot", []xdr.ScVal{alice, amount}, "unknown function single_payuot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := programEscrowSpec.check(tt.function, tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected a valid call, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrContractArgsMismatch) {
+				t.Fatalf("expected ErrContractArgsMismatch, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected %q in the error, got %q", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestContractSpec_BuildOpRejectsBeforeBuilding(t *testing.T) {
+	contractAddr, err := EncodeContractAddress(testPayoutCarol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount, err := EncodeScValInt64(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op, err := programEscrowSpec.buildOp(contractAddr, "lock_program_funds", []xdr.ScVal{amount, amount})
+	if !errors.Is(err, ErrContractArgsMismatch) || op != nil {
+		t.Fatalf("expected no operation and ErrContractArgsMismatch, got %v, %v", op, err)
+	}
+	if _, err := programEscrowSpec.buildOp(contractAddr, "lock_program_funds", []xdr.ScVal{amount}); err != nil {
+		t.Errorf("expected a valid call to build, got %v", err)
+	}
+}
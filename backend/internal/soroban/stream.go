@@ -0,0 +1,150 @@
+package soroban
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// DefaultPayoutChunkSize is the number of items StreamBatchPayout puts in one transaction
+// when no chunk size is given
+const DefaultPayoutChunkSize = 50
+
+// PayoutChunk is one BatchPayout transaction submitted by StreamBatchPayout. Status is
+// the transaction's: "success" once confirmed, "pending" if it wasn't confirmed (yet),
+// or "failed" if it landed but failed.
+type PayoutChunk struct {
+	Hash   string `json:"hash"`
+	Status string `json:"status"`
+	Items  int    `json:"items"`
+	Amount int64  `json:"amount"`
+	Fee    int64  `json:"fee"`
+}
+
+// StreamPayoutSummary totals the chunks submitted by StreamBatchPayout. Items, TotalPaid
+// and TotalFee only count confirmed chunks; the pending ones are totalled apart, to be
+// checked by hash later. Chunks lists every submitted chunk.
+type StreamPayoutSummary struct {
+	Items        int           `json:"items"`
+	TotalPaid    int64         `json:"total_paid"`
+	TotalFee     int64         `json:"total_fee"`
+	PendingItems int           `json:"pending_items"`
+	TotalPending int64         `json:"total_pending"`
+	Chunks       []PayoutChunk `json:"chunks"`
+}
+
+// StreamBatchPayout pays items read lazily from a source, e.g. rows of a large
+// distribution, in BatchPayout transactions of at most chunkSize items (0 uses
+// DefaultPayoutChunkSize). Only one chunk is held in memory at a time. A channel can be
+// passed as an iter.Seq with PayoutItemsFromChannel.
+//
+// Every chunk is a separate BatchPayout, so the allowlist, the PayoutConfirmation
+// threshold and duplicate recipient handling apply per chunk. Chunks are paid in order
+// and the first failure stops the stream: the returned summary covers the chunks that
+// were submitted, and nothing after the failed chunk was. A chunk that failed on-ledger
+// is listed with its hash. A chunk left unconfirmed, by a confirmation timeout or a
+// policy other than ConfirmWait, is counted as pending rather than paid, and the stream
+// goes on.
+func (pec *ProgramEscrowContract) StreamBatchPayout(ctx context.Context, items iter.Seq[PayoutItem], chunkSize int) (*StreamPayoutSummary, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultPayoutChunkSize
+	}
+	summary := &StreamPayoutSummary{Chunks: []PayoutChunk{}}
+	chunk := make([]PayoutItem, 0, chunkSize)
+
+	flush := func() error {
+		result, err := pec.BatchPayout(ctx, chunk)
+		if err != nil && result == nil {
+			return fmt.Errorf("payout chunk %d: %w", len(summary.Chunks), err)
+		}
+		submitted := PayoutChunk{
+			Hash:   result.Hash,
+			Status: result.Status,
+			Items:  len(chunk),
+			Amount: payoutTotal(chunk),
+			Fee:    result.FeeCharged,
+		}
+		summary.Chunks = append(summary.Chunks, submitted)
+		switch {
+		case err != nil:
+			// Landed but failed: nothing was paid
+			return fmt.Errorf("payout chunk %d: %w", len(summary.Chunks)-1, err)
+		case result.IsConfirmed():
+			summary.Items += submitted.Items
+			summary.TotalPaid += submitted.Amount
+			summary.TotalFee += submitted.Fee
+		default:
+			loggerFrom(ctx).Warn("payout chunk not confirmed, counting it as pending",
+				"tx_hash", submitted.Hash,
+				"items", submitted.Items,
+			)
+			summary.PendingItems += submitted.Items
+			summary.TotalPending += submitted.Amount
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+
+	for item := range items {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		chunk = append(chunk, item)
+		if len(chunk) == chunkSize {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+	if len(chunk) > 0 {
+		if err := flush(); err != nil {
+			return summary, err
+		}
+	}
+
+	loggerFrom(ctx).Info("streamed batch payout complete",
+		"items", summary.Items,
+		"chunks", len(summary.Chunks),
+		"total_paid", summary.TotalPaid,
+		"total_fee", summary.TotalFee,
+		"pending_items", summary.PendingItems,
+	)
+	return summary, nil
+}
+
+// EstimateBatchPayoutFee returns the total network fee, in stroops, of paying payouts
+// the way StreamBatchPayout does with the default chunk size: every chunk of up to
+// DefaultPayoutChunkSize items is simulated, and its resource fee plus base fee added
+// up. A list that fits in one chunk is a single BatchPayout, so this is also its cost.
+// Nothing is signed or submitted. A chunk that would revert fails the estimate with an
+// error wrapping ErrContractReverted.
+func (pec *ProgramEscrowContract) EstimateBatchPayoutFee(ctx context.Context, payouts []PayoutItem) (int64, error) {
+	if len(payouts) == 0 {
+		return 0, fmt.Errorf("payouts list cannot be empty")
+	}
+	var total int64
+	for start := 0; start < len(payouts); start += DefaultPayoutChunkSize {
+		chunk := payouts[start:min(start+DefaultPayoutChunkSize, len(payouts))]
+		result, err := pec.SimulateBatchPayout(ctx, chunk)
+		if err != nil {
+			return 0, fmt.Errorf("payout chunk %d: %w", start/DefaultPayoutChunkSize, err)
+		}
+		if !result.Success {
+			return 0, fmt.Errorf("payout chunk %d: %w: %s", start/DefaultPayoutChunkSize, ErrContractReverted, result.Error)
+		}
+		total += result.EstimatedFee
+	}
+	return total, nil
+}
+
+// PayoutItemsFromChannel adapts a channel to the iter.Seq taken by StreamBatchPayout.
+// The sequence ends when ch is closed.
+func PayoutItemsFromChannel(ch <-chan PayoutItem) iter.Seq[PayoutItem] {
+	return func(yield func(PayoutItem) bool) {
+		for item := range ch {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
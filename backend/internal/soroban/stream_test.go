@@ -0,0 +1,309 @@
+package soroban
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// payoutStreamHorizon is a fake Horizon that accepts every submission, charging a fixed
+// fee, and records the recipients and amount of each batch_payout it receives. Submitted
+// transactions are confirmed unless unconfirmed is set. It also answers RPC
+// getTransaction, with FAILED for failHash and SUCCESS otherwise.
+type payoutStreamHorizon struct {
+	mu          sync.Mutex
+	sequence    int64
+	fee         int64
+	unconfirmed bool
+	failHash    string
+	items       []int   // recipients per submitted batch
+	amounts     []int64 // total amount per submitted batch
+	paid        int     // recipients across all batches
+}
+
+func (h *payoutStreamHorizon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/accounts/"):
+		h.sequence++
+		id := strings.TrimPrefix(r.URL.Path, "/accounts/")
+		_, _ = w.Write([]byte(`{"id":"` + id + `","account_id":"` + id + `","sequence":"` + strconv.FormatInt(h.sequence, 10) +
+			`","balances":[{"balance":"10000.0000000","asset_type":"native"}]}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/transactions":
+		generic, err := txnbuild.TransactionFromXDR(r.FormValue("tx"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		tx, _ := generic.Transaction()
+		args := tx.Operations()[0].(*txnbuild.InvokeHostFunction).HostFunction.InvokeContract.Args
+		recipients, _ := args[0].GetVec()
+		amounts, _ := args[1].GetVec()
+		var total int64
+		for _, amount := range *amounts {
+			v, _ := amount.GetI64()
+			total += int64(v)
+		}
+		h.items = append(h.items, len(*recipients))
+		h.amounts = append(h.amounts, total)
+		h.paid += len(*recipients)
+
+		hash := fmt.Sprintf("tx%d", len(h.items))
+		_, _ = fmt.Fprintf(w, `{"id":%q,"hash":%q,"ledger":9,"successful":true,"fee_charged":"%d"}`, hash, hash, h.fee)
+	case strings.HasPrefix(r.URL.Path, "/transactions/") && !h.unconfirmed:
+		hash := strings.TrimPrefix(r.URL.Path, "/transactions/")
+		_, _ = fmt.Fprintf(w, `{"id":%q,"hash":%q,"ledger":9,"successful":true,"fee_charged":"%d"}`, hash, hash, h.fee)
+	case r.Method == http.MethodPost && r.URL.Path == "/":
+		var req struct {
+			Params struct {
+				Hash string `json:"hash"`
+			} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		status := "SUCCESS"
+		if req.Params.Hash == h.failHash {
+			status = "FAILED"
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"status":%q,"ledger":9}}`, status)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"status":404,"title":"Resource Missing"}`))
+	}
+}
+
+func (h *payoutStreamHorizon) paidItems() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.paid
+}
+
+func TestStreamBatchPayout_Chunks(t *testing.T) {
+	fastConfirmationPolling(t)
+	fake := &payoutStreamHorizon{sequence: 100, fee: 250}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(WaitForConfirmationPolicy(5 * time.Second))
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	const total, chunkSize = 1000, 100
+	produced, maxBuffered := 0, 0
+	items := func(yield func(PayoutItem) bool) {
+		for i := 1; i <= total; i++ {
+			produced++
+			maxBuffered = max(maxBuffered, produced-fake.paidItems())
+			if !yield(PayoutItem{Recipient: keypair.MustRandom().Address(), Amount: int64(i)}) {
+				return
+			}
+		}
+	}
+
+	summary, err := pec.StreamBatchPayout(context.Background(), items, chunkSize)
+	if err != nil {
+		t.Fatalf("StreamBatchPayout failed: %v", err)
+	}
+	if summary.Items != total || len(summary.Chunks) != total/chunkSize {
+		t.Fatalf("expected %d items in %d chunks, got %d in %d", total, total/chunkSize, summary.Items, len(summary.Chunks))
+	}
+	if summary.TotalPaid != total*(total+1)/2 {
+		t.Errorf("expected total paid %d, got %d", total*(total+1)/2, summary.TotalPaid)
+	}
+	if want := int64(250 * total / chunkSize); summary.TotalFee != want {
+		t.Errorf("expected total fee %d, got %d", want, summary.TotalFee)
+	}
+	for i, chunk := range summary.Chunks {
+		if want := fmt.Sprintf("tx%d", i+1); chunk.Hash != want || chunk.Status != "success" {
+			t.Errorf("chunk %d: expected confirmed hash %s, got %+v", i, want, chunk)
+		}
+		if chunk.Items != chunkSize || fake.items[i] != chunkSize {
+			t.Errorf("chunk %d: expected %d recipients, got %d (submitted %d)", i, chunkSize, chunk.Items, fake.items[i])
+		}
+		if chunk.Amount != fake.amounts[i] {
+			t.Errorf("chunk %d: summary amount %d does not match the submitted %d", i, chunk.Amount, fake.amounts[i])
+		}
+	}
+	// The source is read one chunk ahead of what has been paid, never further
+	if maxBuffered > chunkSize {
+		t.Errorf("expected at most %d items buffered, got %d", chunkSize, maxBuffered)
+	}
+}
+
+func TestStreamBatchPayout_StopsAtFailedChunk(t *testing.T) {
+	fastConfirmationPolling(t)
+	fake := &payoutStreamHorizon{sequence: 100, fee: 100}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(WaitForConfirmationPolicy(5 * time.Second))
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	ch := make(chan PayoutItem)
+	go func() {
+		defer close(ch)
+		for i := 1; i <= 25; i++ {
+			amount := int64(10)
+			if i == 15 {
+				amount = 0
+			}
+			ch <- PayoutItem{Recipient: keypair.MustRandom().Address(), Amount: amount}
+		}
+	}()
+
+	summary, err := pec.StreamBatchPayout(context.Background(), PayoutItemsFromChannel(ch), 10)
+	if !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("expected ErrInvalidAmount, got %v", err)
+	}
+	for range ch {
+		// drain the producer
+	}
+	if len(summary.Chunks) != 1 || summary.Items != 10 || summary.TotalPaid != 100 || summary.TotalFee != 100 {
+		t.Errorf("expected only the first chunk in the summary, got %+v", summary)
+	}
+	if len(fake.items) != 1 {
+		t.Errorf("expected one submitted batch, got %d", len(fake.items))
+	}
+}
+
+// A chunk that lands but fails is not paid: it is listed with its hash and stops the
+// stream
+func TestStreamBatchPayout_StopsAtRevertedChunk(t *testing.T) {
+	fastConfirmationPolling(t)
+	fake := &payoutStreamHorizon{sequence: 100, fee: 100, failHash: "tx2"}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.client.confirmVia = BackendRPC
+	tb.SetConfirmationPolicy(WaitForConfirmationPolicy(5 * time.Second))
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	items := func(yield func(PayoutItem) bool) {
+		for i := 0; i < 30; i++ {
+			if !yield(PayoutItem{Recipient: keypair.MustRandom().Address(), Amount: 10}) {
+				return
+			}
+		}
+	}
+	summary, err := pec.StreamBatchPayout(context.Background(), items, 10)
+	if !errors.Is(err, ErrTransactionFailed) {
+		t.Fatalf("expected ErrTransactionFailed, got %v", err)
+	}
+	if summary.Items != 10 || summary.TotalPaid != 100 || summary.PendingItems != 0 {
+		t.Errorf("expected only the first chunk paid, got %+v", summary)
+	}
+	if len(summary.Chunks) != 2 || summary.Chunks[1].Hash != "tx2" || summary.Chunks[1].Status != "failed" {
+		t.Errorf("expected the reverted chunk listed as failed, got %+v", summary.Chunks)
+	}
+	if len(fake.items) != 2 {
+		t.Errorf("expected no batch submitted after the reverted one, got %d", len(fake.items))
+	}
+}
+
+// A chunk still unconfirmed when the wait times out may yet land: it is counted as
+// pending, not paid
+func TestStreamBatchPayout_CountsUnconfirmedChunksAsPending(t *testing.T) {
+	fastConfirmationPolling(t)
+	fake := &payoutStreamHorizon{sequence: 100, fee: 100, unconfirmed: true}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(WaitForConfirmationPolicy(30 * time.Millisecond))
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	items := func(yield func(PayoutItem) bool) {
+		for i := 0; i < 20; i++ {
+			if !yield(PayoutItem{Recipient: keypair.MustRandom().Address(), Amount: 10}) {
+				return
+			}
+		}
+	}
+	summary, err := pec.StreamBatchPayout(context.Background(), items, 10)
+	if err != nil {
+		t.Fatalf("StreamBatchPayout failed: %v", err)
+	}
+	if summary.Items != 0 || summary.TotalPaid != 0 || summary.TotalFee != 0 {
+		t.Errorf("expected nothing counted as paid, got %+v", summary)
+	}
+	if summary.PendingItems != 20 || summary.TotalPending != 200 {
+		t.Errorf("expected 20 items worth 200 pending, got %+v", summary)
+	}
+	for i, chunk := range summary.Chunks {
+		if chunk.Hash == "" || chunk.Status != "pending" {
+			t.Errorf("chunk %d: expected a pending chunk with its hash, got %+v", i, chunk)
+		}
+	}
+}
+
+// feeSimulatingHorizon answers simulateTransaction with a resource fee of 1000 stroops
+// per batch_payout recipient, and everything else like sequenceHorizon
+type feeSimulatingHorizon struct {
+	*sequenceHorizon
+	simulated []int // recipients per simulated batch
+}
+
+func (h *feeSimulatingHorizon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/" {
+		h.sequenceHorizon.ServeHTTP(w, r)
+		return
+	}
+	var req struct {
+		Params struct {
+			Transaction string `json:"transaction"`
+		} `json:"params"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	generic, err := txnbuild.TransactionFromXDR(req.Params.Transaction)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	tx, _ := generic.Transaction()
+	recipients, _ := tx.Operations()[0].(*txnbuild.InvokeHostFunction).HostFunction.InvokeContract.Args[0].GetVec()
+	h.simulated = append(h.simulated, len(*recipients))
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"minResourceFee":"%d","latestLedger":5}}`, 1000*len(*recipients))
+}
+
+func TestEstimateBatchPayoutFee_SumsChunks(t *testing.T) {
+	fake := &feeSimulatingHorizon{sequenceHorizon: &sequenceHorizon{sequence: 100}}
+	tb := newSequenceTestBuilder(t, fake)
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	payouts := make([]PayoutItem, 2*DefaultPayoutChunkSize+10)
+	for i := range payouts {
+		payouts[i] = PayoutItem{Recipient: keypair.MustRandom().Address(), Amount: 10}
+	}
+
+	fee, err := pec.EstimateBatchPayoutFee(context.Background(), payouts)
+	if err != nil {
+		t.Fatalf("EstimateBatchPayoutFee failed: %v", err)
+	}
+	// Each chunk costs its resource fee plus the base fee of its one operation
+	want := int64(1000*len(payouts) + 3*txnbuild.MinBaseFee)
+	if fee != want {
+		t.Errorf("expected %d stroops, got %d", want, fee)
+	}
+	if got := fmt.Sprint(fake.simulated); got != fmt.Sprint([]int{DefaultPayoutChunkSize, DefaultPayoutChunkSize, 10}) {
+		t.Errorf("expected chunks of %d, %d and 10 to be simulated, got %s", DefaultPayoutChunkSize, DefaultPayoutChunkSize, got)
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected an estimate not to submit, got %d submissions", len(fake.submissions))
+	}
+}
+
+func TestEstimateBatchPayoutFee_Reverted(t *testing.T) {
+	fake := &simulatingHorizon{
+		sequenceHorizon: &sequenceHorizon{sequence: 100},
+		result:          `{"error":"HostError: Error(Contract, #4)","latestLedger":5}`,
+	}
+	tb := newSequenceTestBuilder(t, fake)
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	if _, err := pec.EstimateBatchPayoutFee(context.Background(), []PayoutItem{{Recipient: testPayoutAlice, Amount: 10}}); !errors.Is(err, ErrContractReverted) {
+		t.Errorf("expected ErrContractReverted, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package soroban
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+// DefaultTTLExtensionLedgers is how far BumpTTL extends an entry's TTL when no extension
+// is configured: about 30 days of 5 second ledgers
+const DefaultTTLExtensionLedgers uint32 = 518_400
+
+// SetTTLExtension sets the number of ledgers BumpTTL keeps the program's state alive
+// for, counted from the ledger the bump lands in. 0 uses DefaultTTLExtensionLedgers.
+// The network caps it at its maximum entry TTL.
+func (pec *ProgramEscrowContract) SetTTLExtension(ledgers uint32) {
+	pec.ttlExtension = ledgers
+}
+
+// BumpTTL extends the TTL of the program's contract instance, which holds the program
+// data, so the escrow isn't archived. Entries whose TTL is already past the extension
+// are left as they are. The footprint and resource fee come from simulating the
+// operation, and the transaction is submitted with the builder's confirmation policy.
+func (pec *ProgramEscrowContract) BumpTTL(ctx context.Context) (*TransactionResult, error) {
+	ctx = ensureTraceID(ctx)
+	extendTo := pec.ttlExtension
+	if extendTo == 0 {
+		extendTo = DefaultTTLExtensionLedgers
+	}
+	pec.client.LogContractInteraction(ctx, pec.contractAddress, "extend_footprint_ttl", map[string]interface{}{
+		"extend_to": extendTo,
+	})
+
+	contractAddr, err := EncodeContractAddress(pec.contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %w", err)
+	}
+	op := extendFootprintTTLOp([]xdr.LedgerKey{contractInstanceKey(contractAddr)}, extendTo)
+
+	// Simulation fills in the resources and the fee the network charges for them
+	sim, err := pec.txBuilder.Simulate(ctx, []txnbuild.Operation{op})
+	if err != nil {
+		return nil, err
+	}
+	if !sim.Success {
+		return nil, fmt.Errorf("extend_footprint_ttl: %w: %s", ErrContractReverted, sim.Error)
+	}
+	var data xdr.SorobanTransactionData
+	if err := xdr.SafeUnmarshalBase64(sim.TransactionData, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode simulated transaction data: %w", err)
+	}
+	op.Ext.SorobanData = &data
+
+	return pec.txBuilder.SubmitAndConfirm(ctx, []txnbuild.Operation{op})
+}
+
+// extendFootprintTTLOp builds an ExtendFootprintTtl operation for keys, which the
+// operation takes from the transaction's read-only footprint
+func extendFootprintTTLOp(keys []xdr.LedgerKey, extendTo uint32) *txnbuild.ExtendFootprintTtl {
+	return &txnbuild.ExtendFootprintTtl{
+		ExtendTo: extendTo,
+		Ext: xdr.TransactionExt{
+			V: 1,
+			SorobanData: &xdr.SorobanTransactionData{
+				Resources: xdr.SorobanResources{
+					Footprint: xdr.LedgerFootprint{
+						ReadOnly:  keys,
+						ReadWrite: []xdr.LedgerKey{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// contractInstanceKey is the ledger key of the instance entry of the contract at
+// contractAddr, where the contract keeps its instance storage
+func contractInstanceKey(contractAddr xdr.ScAddress) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type: xdr.LedgerEntryTypeContractData,
+		ContractData: &xdr.LedgerKeyContractData{
+			Contract:   contractAddr,
+			Key:        xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance},
+			Durability: xdr.ContractDataDurabilityPersistent,
+		},
+	}
+}
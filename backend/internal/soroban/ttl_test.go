@@ -0,0 +1,116 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+func TestExtendFootprintTTLOp(t *testing.T) {
+	contractAddr, err := EncodeContractAddress(testPayoutCarol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := extendFootprintTTLOp([]xdr.LedgerKey{contractInstanceKey(contractAddr)}, 1000)
+
+	if op.ExtendTo != 1000 {
+		t.Errorf("expected ExtendTo 1000, got %d", op.ExtendTo)
+	}
+	body, err := op.BuildXDR()
+	if err != nil {
+		t.Fatalf("BuildXDR failed: %v", err)
+	}
+	if body.Body.Type != xdr.OperationTypeExtendFootprintTtl || uint32(body.Body.ExtendFootprintTtlOp.ExtendTo) != 1000 {
+		t.Errorf("expected an ExtendFootprintTtl operation extending to 1000, got %+v", body.Body)
+	}
+
+	ext, err := op.BuildTransactionExt()
+	if err != nil {
+		t.Fatalf("BuildTransactionExt failed: %v", err)
+	}
+	if ext.V != 1 || ext.SorobanData == nil {
+		t.Fatalf("expected soroban transaction data, got %+v", ext)
+	}
+	footprint := ext.SorobanData.Resources.Footprint
+	if len(footprint.ReadOnly) != 1 || len(footprint.ReadWrite) != 0 {
+		t.Fatalf("expected one read-only key and no read-write keys, got %+v", footprint)
+	}
+	key := footprint.ReadOnly[0]
+	if key.Type != xdr.LedgerEntryTypeContractData || key.ContractData == nil {
+		t.Fatalf("expected a contract data key, got %+v", key)
+	}
+	if key.ContractData.Key.Type != xdr.ScValTypeScvLedgerKeyContractInstance {
+		t.Errorf("expected the contract instance key, got %v", key.ContractData.Key.Type)
+	}
+	if key.ContractData.Durability != xdr.ContractDataDurabilityPersistent {
+		t.Errorf("expected persistent durability, got %v", key.ContractData.Durability)
+	}
+	if !key.ContractData.Contract.Equals(contractAddr) {
+		t.Errorf("expected the key to belong to %s", testPayoutCarol)
+	}
+}
+
+func TestBumpTTL(t *testing.T) {
+	contractAddr, err := EncodeContractAddress(testPayoutCarol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := xdr.MarshalBase64(xdr.SorobanTransactionData{
+		Resources: xdr.SorobanResources{
+			Footprint: xdr.LedgerFootprint{ReadOnly: []xdr.LedgerKey{contractInstanceKey(contractAddr)}},
+		},
+		ResourceFee: 5000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &simulatingHorizon{
+		sequenceHorizon: &sequenceHorizon{sequence: 100},
+		result:          `{"minResourceFee":"5000","latestLedger":5,"transactionData":"` + data + `"}`,
+	}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(NoConfirmation())
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+	pec.SetTTLExtension(2000)
+
+	if _, err := pec.BumpTTL(context.Background()); err != nil {
+		t.Fatalf("BumpTTL failed: %v", err)
+	}
+	if fake.simulated == nil {
+		t.Fatal("expected the bump to be simulated first")
+	}
+	if len(fake.submissions) != 1 {
+		t.Fatalf("expected one submission, got %d", len(fake.submissions))
+	}
+	op, ok := fake.lastTx.Operations()[0].(*txnbuild.ExtendFootprintTtl)
+	if !ok || op.ExtendTo != 2000 {
+		t.Fatalf("expected an ExtendFootprintTtl operation extending to 2000, got %+v", fake.lastTx.Operations()[0])
+	}
+	// The simulated resources are attached and their fee paid on top of the base fee
+	envelope := fake.lastTx.ToXDR()
+	if sorobanData := envelope.V1.Tx.Ext.SorobanData; sorobanData == nil || sorobanData.ResourceFee != 5000 || len(sorobanData.Resources.Footprint.ReadOnly) != 1 {
+		t.Errorf("expected the simulated transaction data, got %+v", sorobanData)
+	}
+	if fee := fake.lastTx.MaxFee(); fee != txnbuild.MinBaseFee+5000 {
+		t.Errorf("expected a fee of %d, got %d", txnbuild.MinBaseFee+5000, fee)
+	}
+}
+
+func TestBumpTTL_Reverted(t *testing.T) {
+	fake := &simulatingHorizon{
+		sequenceHorizon: &sequenceHorizon{sequence: 100},
+		result:          `{"error":"HostError: Error(Storage, MissingValue)","latestLedger":5}`,
+	}
+	tb := newSequenceTestBuilder(t, fake)
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	if _, err := pec.BumpTTL(context.Background()); !errors.Is(err, ErrContractReverted) {
+		t.Errorf("expected ErrContractReverted, got %v", err)
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be submitted, got %d submissions", len(fake.submissions))
+	}
+}
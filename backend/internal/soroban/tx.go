@@ -3,12 +3,14 @@ package soroban
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/stellar/go/clients/horizonclient"
 	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/txnbuild"
 	"github.com/stellar/go/xdr"
 )
@@ -18,6 +20,17 @@ type TransactionBuilder struct {
 	client      *Client
 	sourceKP    *keypair.Full
 	retryConfig RetryConfig
+	// confirmation is applied by SubmitAndConfirm; the zero value waits DefaultConfirmationTimeout
+	confirmation ConfirmationPolicy
+	feeConfig    FeeConfig
+	// reserve is checked against the source account before building
+	reserve ReserveConfig
+	// feeSourceKP pays for fee-bump transactions; nil means the source account pays
+	feeSourceKP *keypair.Full
+	// sink records submitted transactions; nil records nothing
+	sink ResultSink
+	// inflight tracks confirmations being waited on for Shutdown; nil tracks nothing
+	inflight *inflightSubmissions
 }
 
 // NewTransactionBuilder creates a new transaction builder
@@ -31,17 +44,99 @@ func NewTransactionBuilder(client *Client, sourceSecret string, retryConfig Retr
 		client:      client,
 		sourceKP:    sourceKP,
 		retryConfig: retryConfig,
+		inflight:    &inflightSubmissions{},
 	}, nil
 }
 
-// BuildAndSubmit builds a transaction, signs it, and submits it to the network
-func (tb *TransactionBuilder) BuildAndSubmit(ctx context.Context, operations []txnbuild.Operation) (*TransactionResult, error) {
+// withSource returns a copy of tb that builds and signs as sourceKP. It shares tb's
+// client and takes the rest of its configuration as it is at the time of the call.
+func (tb *TransactionBuilder) withSource(sourceKP *keypair.Full) *TransactionBuilder {
+	derived := *tb
+	derived.sourceKP = sourceKP
+	return &derived
+}
+
+// transactionTimeout bounds how long a built transaction stays valid. A submission
+// that hasn't landed by then can no longer be fee-bumped and must be rebuilt.
+const transactionTimeout = 5 * time.Minute
+
+// maxSequenceResyncs caps how often BuildAndSubmit re-reads the account sequence and
+// rebuilds after tx_bad_seq, so a persistently conflicting sequence can't loop forever
+const maxSequenceResyncs = 1
+
+// ErrBadSequence is returned (wrapped) when Horizon rejects a transaction with
+// tx_bad_seq, i.e. the source account's sequence moved on since it was read
+var ErrBadSequence = errors.New("transaction sequence number is stale")
+
+// BuildAndSubmit builds a transaction, signs it, and submits it to the network. If the
+// sequence number turns out to be stale (tx_bad_seq), the account is re-read and the
+// transaction rebuilt, re-signed and resubmitted, up to maxSequenceResyncs times. A
+// source account below its minimum balance fails with ErrInsufficientReserve before
+// anything is built (see SetReserveConfig). After Shutdown it fails with
+// ErrShuttingDown.
+//
+// extraSigners sign after the source account, for operations that need another
+// account's authorization, e.g. the authorized payout key co-signing a payout. A nil
+// or invalid signer fails with ErrInvalidSigner before anything is built.
+func (tb *TransactionBuilder) BuildAndSubmit(ctx context.Context, operations []txnbuild.Operation, extraSigners ...*keypair.Full) (*TransactionResult, error) {
+	return tb.BuildAndSubmitWithMemo(ctx, operations, nil, extraSigners...)
+}
+
+// BuildAndSubmitWithMemo is BuildAndSubmit with a transaction memo; nil means none.
+func (tb *TransactionBuilder) BuildAndSubmitWithMemo(ctx context.Context, operations []txnbuild.Operation, memo txnbuild.Memo, extraSigners ...*keypair.Full) (*TransactionResult, error) {
+	ctx = ensureTraceID(ctx)
+	if !tb.inflight.accepting() {
+		return nil, ErrShuttingDown
+	}
+	if err := checkSigners(extraSigners); err != nil {
+		return nil, err
+	}
+
+	for resync := 0; ; resync++ {
+		tx, err := tb.buildSigned(operations, memo, extraSigners...)
+		if err != nil {
+			return nil, err
+		}
+
+		envelope, err := tx.Base64()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode transaction: %w", err)
+		}
+
+		// Submit with retry
+		result, err := tb.submitWithRetry(ctx, envelope)
+		if errors.Is(err, ErrBadSequence) && resync < maxSequenceResyncs {
+			loggerFrom(ctx).Warn("stale sequence number, resyncing account",
+				"sequence", tx.SequenceNumber(),
+				"resync", resync+1,
+			)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.EnvelopeXDR = envelope
+		return result, nil
+	}
+}
+
+// buildSigned reads the source account, checks its reserve and builds and signs a
+// transaction for operations at its next sequence, with memo if not nil. extraSigners
+// sign after the source account; one that is the source account is skipped.
+func (tb *TransactionBuilder) buildSigned(operations []txnbuild.Operation, memo txnbuild.Memo, extraSigners ...*keypair.Full) (*txnbuild.Transaction, error) {
 	// Get account details
+	hc, err := tb.client.horizon()
+	if err != nil {
+		return nil, err
+	}
 	accountRequest := horizonclient.AccountRequest{AccountID: tb.sourceKP.Address()}
-	accountDetail, err := tb.client.GetHorizonClient().AccountDetail(accountRequest)
+	accountDetail, err := hc.AccountDetail(accountRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account details: %w", err)
 	}
+	if err := tb.checkReserve(accountDetail); err != nil {
+		return nil, err
+	}
 
 	// Build transaction
 	tx, err := txnbuild.NewTransaction(
@@ -50,6 +145,8 @@ func (tb *TransactionBuilder) BuildAndSubmit(ctx context.Context, operations []t
 			IncrementSequenceNum: true,
 			BaseFee:              txnbuild.MinBaseFee,
 			Operations:           operations,
+			Memo:                 memo,
+			Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewTimeout(int64(transactionTimeout / time.Second))},
 		},
 	)
 	if err != nil {
@@ -61,50 +158,187 @@ func (tb *TransactionBuilder) BuildAndSubmit(ctx context.Context, operations []t
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
+	if signers := tb.coSigners(extraSigners); len(signers) > 0 {
+		tx, err = tx.Sign(tb.client.GetNetworkPassphrase(), signers...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign transaction with extra signers: %w", err)
+		}
+	}
+
+	return tx, nil
+}
+
+// ErrInvalidSigner is returned (wrapped) for an extra signer that is nil or doesn't
+// hold a valid secret seed
+var ErrInvalidSigner = errors.New("invalid_signer")
+
+// ParseSigners parses secret seeds into extra signers for BuildAndSubmit, failing with
+// ErrInvalidSigner on the first one that isn't a valid secret
+func ParseSigners(secrets ...string) ([]*keypair.Full, error) {
+	signers := make([]*keypair.Full, len(secrets))
+	for i, secret := range secrets {
+		kp, err := keypair.ParseFull(secret)
+		if err != nil {
+			return nil, fmt.Errorf("%w: signer %d: %w", ErrInvalidSigner, i, err)
+		}
+		signers[i] = kp
+	}
+	return signers, nil
+}
+
+// checkSigners fails with ErrInvalidSigner when a signer is nil or its seed isn't a
+// valid secret, as with a zero keypair.Full
+func checkSigners(signers []*keypair.Full) error {
+	for i, kp := range signers {
+		if kp == nil {
+			return fmt.Errorf("%w: signer %d is nil", ErrInvalidSigner, i)
+		}
+		if _, err := keypair.ParseFull(kp.Seed()); err != nil {
+			return fmt.Errorf("%w: signer %d: %w", ErrInvalidSigner, i, err)
+		}
+	}
+	return nil
+}
+
+// coSigners is extraSigners without the source account and repeats, which would add
+// signatures the network rejects as extra (tx_bad_auth_extra)
+func (tb *TransactionBuilder) coSigners(extraSigners []*keypair.Full) []*keypair.Full {
+	seen := map[string]bool{tb.sourceKP.Address(): true}
+	var signers []*keypair.Full
+	for _, kp := range extraSigners {
+		if seen[kp.Address()] {
+			continue
+		}
+		seen[kp.Address()] = true
+		signers = append(signers, kp)
+	}
+	return signers
+}
+
+// Simulate builds an unsigned transaction for operations and runs it through
+// simulateTransaction. Nothing is signed or submitted.
+func (tb *TransactionBuilder) Simulate(ctx context.Context, operations []txnbuild.Operation) (*SimulationResult, error) {
+	ctx = ensureTraceID(ctx)
+
+	// Get account details
+	hc, err := tb.client.horizon()
+	if err != nil {
+		return nil, err
+	}
+	accountRequest := horizonclient.AccountRequest{AccountID: tb.sourceKP.Address()}
+	accountDetail, err := hc.AccountDetail(accountRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account details: %w", err)
+	}
+
+	tx, err := txnbuild.NewTransaction(
+		txnbuild.TransactionParams{
+			SourceAccount:        &accountDetail,
+			IncrementSequenceNum: true,
+			BaseFee:              txnbuild.MinBaseFee,
+			Operations:           operations,
+			Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewTimeout(int64(transactionTimeout / time.Second))},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build transaction: %w", err)
+	}
 
-	// Submit with retry
-	return tb.submitWithRetry(ctx, tx)
+	envelope, err := tx.Base64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	// A revert is a result here, reported through Success and Error
+	result, err := tb.client.SimulateTransaction(ctx, envelope)
+	if err != nil && !errors.Is(err, ErrContractReverted) {
+		return nil, fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+	result.EstimatedFee += tx.BaseFee() * int64(len(operations))
+	loggerFrom(ctx).Info("transaction simulated",
+		"success", result.Success,
+		"estimated_fee", result.EstimatedFee,
+		"error", result.Error,
+	)
+	return result, nil
 }
 
-// submitWithRetry submits a transaction with retry logic
-func (tb *TransactionBuilder) submitWithRetry(ctx context.Context, tx *txnbuild.Transaction) (*TransactionResult, error) {
+// parseSimulationResult converts a simulateTransaction response. A response with an
+// "error" field means the invocation would fail.
+func parseSimulationResult(raw map[string]interface{}, baseFee int64) *SimulationResult {
+	result := &SimulationResult{Raw: raw}
+
+	if errMsg, ok := raw["error"].(string); ok && errMsg != "" {
+		result.Error = errMsg
+	} else {
+		result.Success = true
+	}
+
+	// minResourceFee is a stringified integer in the RPC response
+	switch fee := raw["minResourceFee"].(type) {
+	case string:
+		if n, err := strconv.ParseInt(fee, 10, 64); err == nil {
+			result.MinResourceFee = n
+		}
+	case float64:
+		result.MinResourceFee = int64(fee)
+	}
+	result.EstimatedFee = baseFee + result.MinResourceFee
+
+	if ledger, ok := raw["latestLedger"].(float64); ok {
+		result.LatestLedger = uint32(ledger)
+	}
+	result.TransactionData, _ = raw["transactionData"].(string)
+
+	return result
+}
+
+// submitWithRetry submits a signed transaction envelope with retry logic. After
+// Shutdown it fails with ErrShuttingDown, whichever path the envelope came from.
+func (tb *TransactionBuilder) submitWithRetry(ctx context.Context, envelopeXDR string) (*TransactionResult, error) {
+	if !tb.inflight.accepting() {
+		return nil, ErrShuttingDown
+	}
+	hc, err := tb.client.horizon()
+	if err != nil {
+		return nil, err
+	}
 	var lastErr error
-	delay := tb.retryConfig.InitialDelay
+	delays := newBackoff(tb.retryConfig)
 
 	for attempt := 0; attempt <= tb.retryConfig.MaxRetries; attempt++ {
 		if attempt > 0 {
-			slog.Info("retrying transaction submission",
+			delay := delays.next()
+			loggerFrom(ctx).Info("retrying transaction submission",
 				"attempt", attempt,
 				"max_retries", tb.retryConfig.MaxRetries,
 				"delay", delay,
 			)
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
-			}
-			delay = time.Duration(float64(delay) * tb.retryConfig.BackoffMultiplier)
-			if delay > tb.retryConfig.MaxDelay {
-				delay = tb.retryConfig.MaxDelay
+			if err := waitRetry(ctx, delay); err != nil {
+				return nil, fmt.Errorf("%w (last attempt: %w)", err, lastErr)
 			}
 		}
 
 		// Submit transaction
-		resp, err := tb.client.GetHorizonClient().SubmitTransaction(tx)
+		resp, err := hc.SubmitTransactionXDR(envelopeXDR)
 		if err != nil {
 			lastErr = err
 			if herr, ok := err.(*horizonclient.Error); ok {
-				slog.Warn("transaction submission failed",
+				loggerFrom(ctx).Warn("transaction submission failed",
 					"attempt", attempt+1,
 					"error", herr.Problem.Detail,
 					"result_codes", herr.Problem.Extras,
 				)
+				// Resubmitting the same envelope can't fix a stale sequence; the caller rebuilds
+				if transactionResultCode(herr) == "tx_bad_seq" {
+					return nil, fmt.Errorf("%w: %w", ErrBadSequence, err)
+				}
 				// Don't retry on certain errors
 				if isNonRetryableError(herr) {
 					return nil, fmt.Errorf("non-retryable error: %w", err)
 				}
 			} else {
-				slog.Warn("transaction submission failed",
+				loggerFrom(ctx).Warn("transaction submission failed",
 					"attempt", attempt+1,
 					"error", err,
 				)
@@ -115,13 +349,14 @@ func (tb *TransactionBuilder) submitWithRetry(ctx context.Context, tx *txnbuild.
 		// Success
 		ledger := uint32(resp.Ledger)
 		result := &TransactionResult{
-			Hash:      resp.Hash,
-			Ledger:    ledger,
-			Status:    "pending",
-			Submitted: time.Now(),
+			Hash:       resp.Hash,
+			Ledger:     ledger,
+			Status:     "pending",
+			Submitted:  time.Now(),
+			FeeCharged: resp.FeeCharged,
 		}
 
-		slog.Info("transaction submitted successfully",
+		loggerFrom(ctx).Info("transaction submitted successfully",
 			"tx_hash", resp.Hash,
 			"ledger", resp.Ledger,
 		)
@@ -134,30 +369,38 @@ func (tb *TransactionBuilder) submitWithRetry(ctx context.Context, tx *txnbuild.
 
 // isNonRetryableError checks if an error should not be retried
 func isNonRetryableError(herr *horizonclient.Error) bool {
-	// Check result codes
+	switch transactionResultCode(herr) {
+	// These errors should not be retried
+	case "tx_bad_auth", "tx_bad_seq", "tx_insufficient_balance", "tx_no_source_account":
+		return true
+	}
+	return false
+}
+
+// transactionResultCode returns the transaction-level result code from a Horizon
+// submission error, or "" if there is none
+func transactionResultCode(herr *horizonclient.Error) string {
 	if resultCodes, ok := herr.Problem.Extras["result_codes"].(map[string]interface{}); ok {
 		if transactionCode, ok := resultCodes["transaction"].(string); ok {
-			// These errors should not be retried
-			nonRetryableCodes := []string{
-				"tx_bad_auth",
-				"tx_bad_seq",
-				"tx_insufficient_balance",
-				"tx_no_source_account",
-			}
-			for _, code := range nonRetryableCodes {
-				if transactionCode == code {
-					return true
-				}
-			}
+			return transactionCode
 		}
 	}
-	return false
+	return ""
 }
 
-// WaitForConfirmation polls for transaction confirmation
+// WaitForConfirmation polls for transaction confirmation through the client's
+// confirmation backend: TransactionDetail on Horizon, or WaitForConfirmationRPC.
 func (tb *TransactionBuilder) WaitForConfirmation(ctx context.Context, txHash string, timeout time.Duration) (*TransactionResult, error) {
+	via := tb.client.ConfirmationBackend()
+	if via == BackendRPC {
+		return tb.WaitForConfirmationRPC(ctx, txHash, timeout)
+	}
+	if err := tb.client.requireBackend(via); err != nil {
+		return nil, err
+	}
+
 	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(2 * time.Second)
+	ticker := time.NewTicker(transactionPollInterval)
 	defer ticker.Stop()
 
 	for {
@@ -169,24 +412,18 @@ func (tb *TransactionBuilder) WaitForConfirmation(ctx context.Context, txHash st
 				return nil, fmt.Errorf("timeout waiting for transaction confirmation: %s", txHash)
 			}
 
-			tx, err := tb.client.GetHorizonClient().TransactionDetail(txHash)
+			result, err := tb.horizonConfirmation(ctx, txHash)
 			if err != nil {
+				return nil, err
+			}
+			if result == nil {
 				// Transaction not found yet, continue polling
 				continue
 			}
 
-			// Transaction found
-			result := &TransactionResult{
-				Hash:      txHash,
-				Ledger:    uint32(tx.Ledger),
-				Status:    "success",
-				Submitted: time.Now(), // Approximate
-				Confirmed: time.Now(),
-			}
-
-			slog.Info("transaction confirmed",
+			loggerFrom(ctx).Info("transaction confirmed",
 				"tx_hash", txHash,
-				"ledger", tx.Ledger,
+				"ledger", result.Ledger,
 			)
 
 			return result, nil
@@ -194,10 +431,101 @@ func (tb *TransactionBuilder) WaitForConfirmation(ctx context.Context, txHash st
 	}
 }
 
-// EncodeContractAddress encodes a contract address to XDR
+// horizonConfirmation looks txHash up on Horizon. A transaction Horizon doesn't have
+// yet gives a nil result and no error.
+func (tb *TransactionBuilder) horizonConfirmation(_ context.Context, txHash string) (*TransactionResult, error) {
+	tx, err := tb.client.horizonClient.TransactionDetail(txHash)
+	if err != nil {
+		return nil, nil
+	}
+	return &TransactionResult{
+		Hash:       txHash,
+		Ledger:     uint32(tx.Ledger),
+		Status:     "success",
+		Submitted:  time.Now(), // Approximate
+		Confirmed:  time.Now(),
+		FeeCharged: tx.FeeCharged,
+	}, nil
+}
+
+// WaitForConfirmationRPC polls getTransaction until txHash reaches a final status,
+// which suits Soroban transactions better than Horizon. A transaction that landed but
+// failed comes back with status "failed" and an error wrapping ErrTransactionFailed.
+func (tb *TransactionBuilder) WaitForConfirmationRPC(ctx context.Context, txHash string, timeout time.Duration) (*TransactionResult, error) {
+	if err := tb.client.requireBackend(BackendRPC); err != nil {
+		return nil, err
+	}
+	status, err := tb.client.PollTransactionStatus(ctx, txHash, timeout)
+	if err != nil {
+		return nil, err
+	}
+	result := rpcTransactionResult(txHash, status)
+	if !result.IsConfirmed() {
+		return result, fmt.Errorf("%w: %s", ErrTransactionFailed, txHash)
+	}
+	return result, nil
+}
+
+// rpcTransactionResult converts a final getTransaction response (SUCCESS or FAILED)
+func rpcTransactionResult(txHash string, status map[string]interface{}) *TransactionResult {
+	result := &TransactionResult{
+		Hash:      txHash,
+		Status:    "failed",
+		Submitted: time.Now(), // Approximate
+		Confirmed: time.Now(),
+	}
+	if status["status"] == "SUCCESS" {
+		result.Status = "success"
+	}
+	if ledger, ok := status["ledger"].(float64); ok {
+		result.Ledger = uint32(ledger)
+	}
+	if resultXDR, ok := status["resultXdr"].(string); ok {
+		var txResult xdr.TransactionResult
+		if err := xdr.SafeUnmarshalBase64(resultXDR, &txResult); err == nil {
+			result.FeeCharged = int64(txResult.FeeCharged)
+		}
+	}
+	if metaXDR, ok := status["resultMetaXdr"].(string); ok {
+		result.ReturnValue = contractReturnValue(metaXDR)
+	}
+	return result
+}
+
+// contractReturnValue decodes the invoked contract function's return value from a
+// transaction's result meta, or returns nil when there is none
+func contractReturnValue(metaXDR string) *xdr.ScVal {
+	var meta xdr.TransactionMeta
+	if err := xdr.SafeUnmarshalBase64(metaXDR, &meta); err != nil {
+		return nil
+	}
+	if v3, ok := meta.GetV3(); ok && v3.SorobanMeta != nil {
+		return &v3.SorobanMeta.ReturnValue
+	}
+	if v4, ok := meta.GetV4(); ok && v4.SorobanMeta != nil {
+		return v4.SorobanMeta.ReturnValue
+	}
+	return nil
+}
+
+// EncodeContractAddress encodes a contract address to XDR. contractID may be a strkey
+// ("C..." as shown by explorers), a 64-char hex string, or base64 of the 32-byte ID.
 func EncodeContractAddress(contractID string) (xdr.ScAddress, error) {
-	// Contract ID is typically a hex string (64 chars) or base64
 	var hash xdr.Hash
+
+	// Strkey contract address (56 chars, version byte 'C', CRC16 checksum)
+	if len(contractID) == 56 && contractID[0] == 'C' {
+		raw, err := strkey.Decode(strkey.VersionByteContract, contractID)
+		if err != nil {
+			return xdr.ScAddress{}, fmt.Errorf("invalid contract strkey: %w", err)
+		}
+		copy(hash[:], raw)
+		contractId := xdr.ContractId(hash)
+		return xdr.ScAddress{
+			Type:       xdr.ScAddressTypeScAddressTypeContract,
+			ContractId: &contractId,
+		}, nil
+	}
 	
 	// Try hex first (64 hex chars = 32 bytes)
 	if len(contractID) == 64 {
@@ -223,7 +551,7 @@ func EncodeContractAddress(contractID string) (xdr.ScAddress, error) {
 	// Try base64
 	bytes, err := base64.StdEncoding.DecodeString(contractID)
 	if err != nil {
-		return xdr.ScAddress{}, fmt.Errorf("invalid contract ID format (expected hex or base64): %w", err)
+		return xdr.ScAddress{}, fmt.Errorf("invalid contract ID format (expected C... strkey, hex or base64): %w", err)
 	}
 	if len(bytes) != 32 {
 		return xdr.ScAddress{}, fmt.Errorf("contract ID must be 32 bytes, got %d", len(bytes))
@@ -0,0 +1,296 @@
+package soroban
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+func TestParseSimulationResult_Success(t *testing.T) {
+	res := parseSimulationResult(map[string]interface{}{
+		"minResourceFee": "58181",
+		"latestLedger":   float64(123456),
+	}, 100)
+
+	if !res.Success || res.Error != "" {
+		t.Errorf("expected success, got %+v", res)
+	}
+	if res.MinResourceFee != 58181 {
+		t.Errorf("expected min resource fee 58181, got %d", res.MinResourceFee)
+	}
+	if res.EstimatedFee != 58281 {
+		t.Errorf("expected estimated fee 58281, got %d", res.EstimatedFee)
+	}
+	if res.LatestLedger != 123456 {
+		t.Errorf("expected latest ledger 123456, got %d", res.LatestLedger)
+	}
+}
+
+func TestParseSimulationResult_ContractError(t *testing.T) {
+	res := parseSimulationResult(map[string]interface{}{
+		"error":        "HostError: Error(Contract, #4)",
+		"latestLedger": float64(10),
+	}, 100)
+
+	if res.Success {
+		t.Error("expected failure")
+	}
+	if res.Error != "HostError: Error(Contract, #4)" {
+		t.Errorf("unexpected error %q", res.Error)
+	}
+	if res.EstimatedFee != 100 {
+		t.Errorf("expected base fee only, got %d", res.EstimatedFee)
+	}
+}
+
+const badSeqProblem = `{"type":"https://stellar.org/horizon-errors/transaction_failed","title":"Transaction Failed","status":400,` +
+	`"extras":{"result_codes":{"transaction":"tx_bad_seq"}}}`
+
+// sequenceHorizon is a fake Horizon whose account sequence advances on every lookup
+// (as if another writer used it) and which answers submissions from a script.
+type sequenceHorizon struct {
+	mu          sync.Mutex
+	sequence    int64
+	lookups     int
+	submissions []int64 // sequence numbers of submitted envelopes
+	rejectFirst int     // number of submissions to reject with tx_bad_seq
+	lastTx      *txnbuild.Transaction
+	balance     string // native balance of the account; empty means 10000 XLM
+	subentries  int
+}
+
+func (h *sequenceHorizon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/accounts/"):
+		h.lookups++
+		h.sequence += 5
+		id := strings.TrimPrefix(r.URL.Path, "/accounts/")
+		balance := h.balance
+		if balance == "" {
+			balance = "10000.0000000"
+		}
+		_, _ = w.Write([]byte(`{"id":"` + id + `","account_id":"` + id + `","sequence":"` + strconv.FormatInt(h.sequence, 10) +
+			`","subentry_count":` + strconv.Itoa(h.subentries) + `,"balances":[{"balance":"` + balance + `","asset_type":"native"}]}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/transactions":
+		generic, err := txnbuild.TransactionFromXDR(r.FormValue("tx"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		tx, _ := generic.Transaction()
+		h.submissions = append(h.submissions, tx.SequenceNumber())
+		h.lastTx = tx
+		if len(h.submissions) <= h.rejectFirst {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(badSeqProblem))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"abc","hash":"abc","ledger":9,"successful":true}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"status":404,"title":"Resource Missing"}`))
+	}
+}
+
+func newSequenceTestBuilder(t *testing.T, fake http.Handler) *TransactionBuilder {
+	t.Helper()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(Config{RPCURL: srv.URL, Network: NetworkTestnet})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	client.horizonClient = &horizonclient.Client{HorizonURL: srv.URL, HTTP: srv.Client()}
+
+	tb, err := NewTransactionBuilder(client, keypair.MustRandom().Seed(), RetryConfig{
+		MaxRetries:        3,
+		InitialDelay:      time.Millisecond,
+		MaxDelay:          time.Millisecond,
+		BackoffMultiplier: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewTransactionBuilder failed: %v", err)
+	}
+	return tb
+}
+
+func TestBuildAndSubmit_ResyncsOnBadSequence(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100, rejectFirst: 1}
+	tb := newSequenceTestBuilder(t, fake)
+
+	result, err := tb.BuildAndSubmit(context.Background(), []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 0}})
+	if err != nil {
+		t.Fatalf("expected success after resync, got %v", err)
+	}
+	if result.Hash != "abc" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	if fake.lookups != 2 {
+		t.Errorf("expected the account to be re-read once, got %d lookups", fake.lookups)
+	}
+	// First envelope used 105+1; the rebuilt one must use the fresh 110+1.
+	if len(fake.submissions) != 2 || fake.submissions[0] != 106 || fake.submissions[1] != 111 {
+		t.Errorf("expected submissions with sequences [106 111], got %v", fake.submissions)
+	}
+}
+
+func TestBuildAndSubmit_BadSequenceResyncIsCapped(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100, rejectFirst: 100}
+	tb := newSequenceTestBuilder(t, fake)
+
+	_, err := tb.BuildAndSubmit(context.Background(), []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 0}})
+	if !errors.Is(err, ErrBadSequence) {
+		t.Fatalf("expected ErrBadSequence, got %v", err)
+	}
+	// tx_bad_seq is not retried with the same envelope, only rebuilt maxSequenceResyncs times.
+	if want := 1 + maxSequenceResyncs; len(fake.submissions) != want {
+		t.Errorf("expected %d submissions, got %d", want, len(fake.submissions))
+	}
+}
+
+func TestBuildAndSubmit_ExtraSigners(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+	payoutKey := keypair.MustRandom()
+	cosigner := keypair.MustRandom()
+
+	// The source and a repeated signer are only signed for once
+	_, err := tb.BuildAndSubmit(context.Background(), []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 0}},
+		payoutKey, cosigner, tb.sourceKP, payoutKey)
+	if err != nil {
+		t.Fatalf("BuildAndSubmit failed: %v", err)
+	}
+
+	hash, err := fake.lastTx.Hash(tb.client.GetNetworkPassphrase())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatures := fake.lastTx.Signatures()
+	want := []*keypair.Full{tb.sourceKP, payoutKey, cosigner}
+	if len(signatures) != len(want) {
+		t.Fatalf("expected %d signatures, got %d", len(want), len(signatures))
+	}
+	for i, kp := range want {
+		if signatures[i].Hint != xdr.SignatureHint(kp.Hint()) {
+			t.Errorf("signature %d: expected the hint of %s", i, kp.Address())
+		}
+		if err := kp.Verify(hash[:], signatures[i].Signature); err != nil {
+			t.Errorf("signature %d: not a valid signature by %s: %v", i, kp.Address(), err)
+		}
+	}
+}
+
+func TestBuildAndSubmit_InvalidSigner(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+
+	for _, signer := range []*keypair.Full{nil, {}} {
+		_, err := tb.BuildAndSubmit(context.Background(), []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 0}}, keypair.MustRandom(), signer)
+		if !errors.Is(err, ErrInvalidSigner) {
+			t.Errorf("expected ErrInvalidSigner, got %v", err)
+		}
+	}
+	if fake.lookups != 0 || len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be built or submitted, got %d lookups and %d submissions", fake.lookups, len(fake.submissions))
+	}
+
+	if _, err := ParseSigners(keypair.MustRandom().Seed(), "not-a-secret"); !errors.Is(err, ErrInvalidSigner) {
+		t.Errorf("expected ErrInvalidSigner from ParseSigners, got %v", err)
+	}
+	if _, err := ParseSigners(keypair.MustRandom().Address()); !errors.Is(err, ErrInvalidSigner) {
+		t.Errorf("expected ErrInvalidSigner for a public key, got %v", err)
+	}
+}
+
+func TestSinglePayout_MuxedRecipientSetsMemo(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(NoConfirmation())
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	if _, err := pec.SinglePayout(context.Background(), testMuxedAddress, 500); err != nil {
+		t.Fatalf("SinglePayout failed: %v", err)
+	}
+	if fake.lastTx == nil {
+		t.Fatal("expected a submitted transaction")
+	}
+	if memo := fake.lastTx.Memo(); memo != txnbuild.MemoID(testMuxedID) {
+		t.Errorf("expected memo ID %d, got %#v", uint64(testMuxedID), memo)
+	}
+
+	// The contract pays the underlying account
+	invoke, ok := fake.lastTx.Operations()[0].(*txnbuild.InvokeHostFunction)
+	if !ok {
+		t.Fatalf("expected an InvokeHostFunction operation, got %T", fake.lastTx.Operations()[0])
+	}
+	recipient, err := decodeScAddress(invoke.HostFunction.InvokeContract.Args[0])
+	if err != nil || recipient != testMuxedAccount {
+		t.Errorf("expected recipient %s, got %s (%v)", testMuxedAccount, recipient, err)
+	}
+
+	// A plain recipient gets no memo
+	if _, err := pec.SinglePayout(context.Background(), testMuxedAccount, 500); err != nil {
+		t.Fatalf("SinglePayout failed: %v", err)
+	}
+	if memo := fake.lastTx.Memo(); memo != nil {
+		t.Errorf("expected no memo for a G... recipient, got %#v", memo)
+	}
+}
+
+func TestSinglePayoutWithToken_SubmitsTokenAwareCall(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+	tb.SetConfirmationPolicy(NoConfirmation())
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	if _, err := pec.SinglePayoutWithToken(context.Background(), testPayoutAlice, 500, testPayoutToken); err != nil {
+		t.Fatalf("SinglePayoutWithToken failed: %v", err)
+	}
+	invoke := fake.lastTx.Operations()[0].(*txnbuild.InvokeHostFunction)
+	if fn := invoke.HostFunction.InvokeContract.FunctionName; fn != "single_payout_token" {
+		t.Errorf("expected single_payout_token, got %s", fn)
+	}
+
+	// SinglePayout keeps paying in the init token
+	if _, err := pec.SinglePayout(context.Background(), testPayoutAlice, 500); err != nil {
+		t.Fatalf("SinglePayout failed: %v", err)
+	}
+	invoke = fake.lastTx.Operations()[0].(*txnbuild.InvokeHostFunction)
+	if fn := invoke.HostFunction.InvokeContract.FunctionName; fn != "single_payout" {
+		t.Errorf("expected single_payout, got %s", fn)
+	}
+}
+
+func TestBatchPayout_RejectsMuxedRecipient(t *testing.T) {
+	fake := &sequenceHorizon{sequence: 100}
+	tb := newSequenceTestBuilder(t, fake)
+	pec := NewProgramEscrowContract(tb.client, tb, testPayoutCarol)
+
+	_, err := pec.BatchPayout(context.Background(), []PayoutItem{
+		{Recipient: testMuxedAccount, Amount: 1},
+		{Recipient: testMuxedAddress, Amount: 1},
+	})
+	if !errors.Is(err, ErrMuxedAddress) {
+		t.Errorf("expected ErrMuxedAddress, got %v", err)
+	}
+	if len(fake.submissions) != 0 {
+		t.Errorf("expected nothing to be submitted, got %d submissions", len(fake.submissions))
+	}
+}
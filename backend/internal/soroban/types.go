@@ -48,6 +48,36 @@ type TransactionResult struct {
 	Status    string    `json:"status"`
 	Submitted time.Time `json:"submitted"`
 	Confirmed time.Time `json:"confirmed,omitempty"`
+	// FeeCharged is the fee Horizon reports for the transaction, in stroops
+	FeeCharged int64 `json:"fee_charged,omitempty"`
+	// EnvelopeXDR is the signed (inner) transaction envelope, kept so a stuck
+	// submission can be fee-bumped
+	EnvelopeXDR string `json:"envelope_xdr,omitempty"`
+	// ReturnValue is the invoked contract function's return value, when confirmed
+	// through RPC
+	ReturnValue *xdr.ScVal `json:"-"`
+	// Recipients is, for a confirmed BatchPayout, which recipients the contract paid
+	Recipients []PayoutRecipientResult `json:"recipients,omitempty"`
+}
+
+// IsConfirmed reports whether the transaction was seen on-ledger. A result that is
+// not confirmed was still submitted and may land later.
+func (r *TransactionResult) IsConfirmed() bool {
+	return r != nil && r.Status == "success"
+}
+
+// SimulationResult is the outcome of simulating a transaction without submitting it.
+// A reverted invocation has Error set; a successful one that returns nothing has a nil
+// Result.
+type SimulationResult struct {
+	Success         bool                   `json:"success"`
+	Error           string                 `json:"error,omitempty"`            // contract/host error reported by the simulation
+	Result          *xdr.ScVal             `json:"-"`                          // return value of the (first) invoked function
+	MinResourceFee  int64                  `json:"min_resource_fee"`           // stroops, as reported by simulateTransaction
+	EstimatedFee    int64                  `json:"estimated_fee"`              // base fee plus resource fee, in stroops
+	TransactionData string                 `json:"transaction_data,omitempty"` // SorobanTransactionData XDR to attach before submitting
+	LatestLedger    uint32                 `json:"latest_ledger,omitempty"`
+	Raw             map[string]interface{} `json:"-"`
 }
 
 // ContractAddress represents a Soroban contract address
@@ -70,6 +100,8 @@ type RetryConfig struct {
 	InitialDelay    time.Duration
 	MaxDelay        time.Duration
 	BackoffMultiplier float64
+	// Jitter randomizes each delay (default JitterNone)
+	Jitter JitterStrategy
 }
 
 // DefaultRetryConfig returns a default retry configuration
@@ -81,3 +113,24 @@ func DefaultRetryConfig() RetryConfig {
 		BackoffMultiplier: 2.0,
 	}
 }
+
+// FeeConfig configures fee escalation for submitted transactions
+type FeeConfig struct {
+	// DynamicFee fee-bumps a transaction once when confirmation times out
+	DynamicFee bool
+	// BumpMultiplier scales the inner transaction's per-operation base fee (default 2)
+	BumpMultiplier float64
+	// MaxBaseFee caps the bumped per-operation base fee in stroops (0 = no cap)
+	MaxBaseFee int64
+}
+
+// ReserveConfig configures the balance check made before building a transaction, so a
+// source account that can't pay fails early with ErrInsufficientReserve rather than
+// with tx_insufficient_balance from the network
+type ReserveConfig struct {
+	// Disabled skips the check
+	Disabled bool
+	// Buffer is required on top of the account's minimum balance, in stroops, e.g. to
+	// cover fees
+	Buffer int64
+}
@@ -1,13 +1,20 @@
 package soroban
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/txnbuild"
 	"github.com/stellar/go/xdr"
 )
 
+// ErrMuxedAddress is returned (wrapped) for a muxed M... address where a plain account
+// or contract address is required. Contracts can't hold muxed addresses; only payouts
+// accept them, see SplitMuxedAddress.
+var ErrMuxedAddress = errors.New("muxed address not allowed")
+
 // EncodeScValString encodes a string as ScVal
 func EncodeScValString(s string) (xdr.ScVal, error) {
 	// Convert string to ScSymbol or ScString
@@ -28,6 +35,22 @@ func EncodeScValInt64(i int64) (xdr.ScVal, error) {
 	}, nil
 }
 
+// EncodeScValInt128 encodes an int64 as an i128 ScVal (Soroban token amounts are i128)
+func EncodeScValInt128(i int64) (xdr.ScVal, error) {
+	hi := xdr.Int64(0)
+	if i < 0 {
+		hi = -1 // sign-extend into the high 64 bits
+	}
+	parts := xdr.Int128Parts{
+		Hi: hi,
+		Lo: xdr.Uint64(uint64(i)),
+	}
+	return xdr.ScVal{
+		Type: xdr.ScValTypeScvI128,
+		I128: &parts,
+	}, nil
+}
+
 // EncodeScValUint64 encodes a uint64 as ScVal
 func EncodeScValUint64(u uint64) (xdr.ScVal, error) {
 	u64 := xdr.Uint64(u)
@@ -37,8 +60,13 @@ func EncodeScValUint64(u uint64) (xdr.ScVal, error) {
 	}, nil
 }
 
-// EncodeScValAddress encodes an address string as ScVal
+// EncodeScValAddress encodes an address string as ScVal. Muxed addresses are rejected
+// with ErrMuxedAddress.
 func EncodeScValAddress(addrStr string) (xdr.ScVal, error) {
+	if isMuxedAddress(addrStr) {
+		return xdr.ScVal{}, fmt.Errorf("%w: %s", ErrMuxedAddress, addrStr)
+	}
+
 	// Try parsing as account address first
 	kp, err := keypair.ParseAddress(addrStr)
 	if err == nil {
@@ -70,6 +98,41 @@ func EncodeScValAddress(addrStr string) (xdr.ScVal, error) {
 	return xdr.ScVal{}, fmt.Errorf("invalid address format: %s", addrStr)
 }
 
+// isMuxedAddress reports whether addr is a valid M... strkey
+func isMuxedAddress(addr string) bool {
+	_, err := strkey.Decode(strkey.VersionByteMuxedAccount, addr)
+	return err == nil
+}
+
+// SplitMuxedAddress splits a muxed M... address into its underlying G... account and
+// memo ID, as used by exchanges and custodians to tell deposits apart. Other addresses
+// are returned unchanged with a nil ID.
+func SplitMuxedAddress(addr string) (string, *uint64, error) {
+	if !isMuxedAddress(addr) {
+		return addr, nil, nil
+	}
+	var muxed xdr.MuxedAccount
+	if err := muxed.SetAddress(addr); err != nil {
+		return "", nil, fmt.Errorf("invalid muxed address: %w", err)
+	}
+	id, err := muxed.GetId()
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid muxed address: %w", err)
+	}
+	account := muxed.ToAccountId()
+	return account.Address(), &id, nil
+}
+
+// payoutRecipient resolves a payout recipient that may be muxed into the address the
+// contract pays and the memo the transaction must carry (nil for plain addresses).
+func payoutRecipient(addr string) (string, txnbuild.Memo, error) {
+	account, memoID, err := SplitMuxedAddress(addr)
+	if err != nil || memoID == nil {
+		return account, nil, err
+	}
+	return account, txnbuild.MemoID(*memoID), nil
+}
+
 // EncodeScValVec encodes a slice of ScVal as ScVal vector
 func EncodeScValVec(vals []xdr.ScVal) (xdr.ScVal, error) {
 	vec := xdr.ScVec(vals)
@@ -80,6 +143,20 @@ func EncodeScValVec(vals []xdr.ScVal) (xdr.ScVal, error) {
 	}, nil
 }
 
+// EncodeScValVoid encodes a unit value, which is also how Soroban encodes Option::None
+func EncodeScValVoid() xdr.ScVal {
+	return xdr.ScVal{Type: xdr.ScValTypeScvVoid}
+}
+
+// EncodeScValEnumVariant encodes a unit variant of a #[contracttype] enum (e.g. RefundMode::Full)
+func EncodeScValEnumVariant(name string) (xdr.ScVal, error) {
+	sym, err := EncodeScSymbol(name)
+	if err != nil {
+		return xdr.ScVal{}, err
+	}
+	return EncodeScValVec([]xdr.ScVal{{Type: xdr.ScValTypeScvSymbol, Sym: &sym}})
+}
+
 // EncodeScSymbol encodes a symbol (function name) as ScSymbol
 func EncodeScSymbol(s string) (xdr.ScSymbol, error) {
 	// ScSymbol is just a string in XDR
@@ -1,6 +1,7 @@
 package soroban
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stellar/go/xdr"
@@ -45,6 +46,44 @@ func TestEncodeScValUint64(t *testing.T) {
 	}
 }
 
+func TestEncodeScValInt128(t *testing.T) {
+	val, err := EncodeScValInt128(1_000_000)
+	if err != nil {
+		t.Fatalf("EncodeScValInt128 failed: %v", err)
+	}
+	if val.Type != xdr.ScValTypeScvI128 {
+		t.Errorf("expected ScvI128, got %v", val.Type)
+	}
+	if val.I128 == nil || val.I128.Hi != 0 || val.I128.Lo != 1_000_000 {
+		t.Errorf("expected hi=0 lo=1000000, got %v", val.I128)
+	}
+
+	neg, _ := EncodeScValInt128(-1)
+	if neg.I128 == nil || neg.I128.Hi != -1 || neg.I128.Lo != xdr.Uint64(^uint64(0)) {
+		t.Errorf("expected sign-extended -1, got %v", neg.I128)
+	}
+}
+
+func TestEncodeScValVoid(t *testing.T) {
+	if val := EncodeScValVoid(); val.Type != xdr.ScValTypeScvVoid {
+		t.Errorf("expected ScvVoid, got %v", val.Type)
+	}
+}
+
+func TestEncodeScValEnumVariant(t *testing.T) {
+	val, err := EncodeScValEnumVariant("Full")
+	if err != nil {
+		t.Fatalf("EncodeScValEnumVariant failed: %v", err)
+	}
+	if val.Type != xdr.ScValTypeScvVec || val.Vec == nil || len(**val.Vec) != 1 {
+		t.Fatalf("expected single-element vec, got %v", val)
+	}
+	tag := (**val.Vec)[0]
+	if tag.Type != xdr.ScValTypeScvSymbol || tag.Sym == nil || *tag.Sym != "Full" {
+		t.Errorf("expected symbol Full, got %v", tag)
+	}
+}
+
 func TestEncodeScValVec(t *testing.T) {
 	vals := []xdr.ScVal{
 		{Type: xdr.ScValTypeScvI64, I64: func() *xdr.Int64 { v := xdr.Int64(1); return &v }()},
@@ -91,6 +130,41 @@ func TestEncodeContractAddress(t *testing.T) {
 	}
 }
 
+func TestEncodeContractAddress_Strkey(t *testing.T) {
+	// Same 32-byte ID (0x01..0x20) in strkey and hex form.
+	const strkeyID = "CAAQEAYEAUDAOCAJBIFQYDIOB4IBCEQTCQKRMFYYDENBWHA5DYPSBFLM"
+	const hexID = "0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"
+
+	fromStrkey, err := EncodeContractAddress(strkeyID)
+	if err != nil {
+		t.Fatalf("EncodeContractAddress failed with strkey: %v", err)
+	}
+	fromHex, err := EncodeContractAddress(hexID)
+	if err != nil {
+		t.Fatalf("EncodeContractAddress failed with hex: %v", err)
+	}
+	if fromStrkey.Type != xdr.ScAddressTypeScAddressTypeContract || fromStrkey.ContractId == nil {
+		t.Fatalf("expected contract address, got %v", fromStrkey)
+	}
+	if *fromStrkey.ContractId != *fromHex.ContractId {
+		t.Errorf("strkey and hex decode to different IDs: %x vs %x", *fromStrkey.ContractId, *fromHex.ContractId)
+	}
+	if fromStrkey.ContractId[0] != 0x01 || fromStrkey.ContractId[31] != 0x20 {
+		t.Errorf("unexpected contract ID bytes: %x", *fromStrkey.ContractId)
+	}
+}
+
+func TestEncodeContractAddress_StrkeyBadChecksum(t *testing.T) {
+	// Last character altered so the CRC16 no longer matches.
+	if _, err := EncodeContractAddress("CAAQEAYEAUDAOCAJBIFQYDIOB4IBCEQTCQKRMFYYDENBWHA5DYPSBFLA"); err == nil {
+		t.Error("expected checksum error")
+	}
+	// A G... account key is not a contract address.
+	if _, err := EncodeContractAddress("GAAQEAYEAUDAOCAJBIFQYDIOB4IBCEQTCQKRMFYYDENBWHA5DYPSBFLM"); err == nil {
+		t.Error("expected error for non-contract strkey")
+	}
+}
+
 func TestDefaultRetryConfig(t *testing.T) {
 	config := DefaultRetryConfig()
 	if config.MaxRetries != 3 {
@@ -106,3 +180,48 @@ func TestDefaultRetryConfig(t *testing.T) {
 		t.Errorf("expected BackoffMultiplier 2.0, got %f", config.BackoffMultiplier)
 	}
 }
+
+// SEP-23 muxed account: testMuxedAccount with memo ID 1234567890
+const (
+	testMuxedAccount = "GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ"
+	testMuxedAddress = "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJUAAAAAAETFQC2K6JE"
+	testMuxedID      = 1234567890
+)
+
+func TestSplitMuxedAddress(t *testing.T) {
+	account, id, err := SplitMuxedAddress(testMuxedAddress)
+	if err != nil {
+		t.Fatalf("SplitMuxedAddress failed: %v", err)
+	}
+	if account != testMuxedAccount || id == nil || *id != testMuxedID {
+		t.Errorf("expected %s with ID %d, got %s with %v", testMuxedAccount, testMuxedID, account, id)
+	}
+
+	// IDs use the full uint64 range; the SEP-23 zero-ID vector decodes too
+	for addr, want := range map[string]uint64{
+		"MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAW36K": 1<<63 + 5,
+		"MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJUAAAAAAAAAAAACJUQ": 0,
+	} {
+		account, id, err := SplitMuxedAddress(addr)
+		if err != nil || account != testMuxedAccount || id == nil || *id != want {
+			t.Errorf("%s: expected ID %d, got %s %v (%v)", addr, want, account, id, err)
+		}
+	}
+
+	// Plain accounts and contracts pass through
+	for _, addr := range []string{testMuxedAccount, testPayoutCarol} {
+		got, id, err := SplitMuxedAddress(addr)
+		if err != nil || got != addr || id != nil {
+			t.Errorf("%s: expected it unchanged, got %s %v (%v)", addr, got, id, err)
+		}
+	}
+}
+
+func TestEncodeScValAddress_RejectsMuxed(t *testing.T) {
+	if _, err := EncodeScValAddress(testMuxedAddress); !errors.Is(err, ErrMuxedAddress) {
+		t.Errorf("expected ErrMuxedAddress, got %v", err)
+	}
+	if _, err := EncodeScValAddress(testMuxedAccount); err != nil {
+		t.Errorf("expected the underlying account to encode, got %v", err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/time/rate"
 
+	"github.com/jagadeesh/grainlify/backend/internal/broadcast"
 	"github.com/jagadeesh/grainlify/backend/internal/config"
 	"github.com/jagadeesh/grainlify/backend/internal/github"
 )
@@ -24,6 +25,8 @@ type Worker struct {
 	limiter *rate.Limiter
 	gh      *github.Client
 	workerID string
+
+	completed *broadcast.Signal
 }
 
 func New(cfg config.Config, pool *pgxpool.Pool) *Worker {
@@ -36,6 +39,12 @@ func New(cfg config.Config, pool *pgxpool.Pool) *Worker {
 	}
 }
 
+// NotifyCompletions makes the worker notify s after every successfully completed job,
+// e.g. so the leaderboard can refresh.
+func (w *Worker) NotifyCompletions(s *broadcast.Signal) {
+	w.completed = s
+}
+
 func (w *Worker) Run(ctx context.Context) error {
 	if w.pool == nil {
 		return fmt.Errorf("db not configured")
@@ -106,6 +115,9 @@ SET status = $2, attempts = attempts + 1, last_error = NULLIF($3, ''), updated_a
 WHERE id = $1
 `, jobID, status, lastErr)
 
+	if runErr == nil {
+		w.completed.Notify()
+	}
 	return nil
 }
 
@@ -0,0 +1,3 @@
+-- Remove language breakdown from ecosystems
+ALTER TABLE ecosystems
+  DROP COLUMN IF EXISTS languages;
@@ -0,0 +1,3 @@
+-- Add language breakdown (name + percentage pairs) to ecosystems
+ALTER TABLE ecosystems
+  ADD COLUMN IF NOT EXISTS languages JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,5 @@
+-- Remove soft delete support from ecosystems
+DROP INDEX IF EXISTS idx_ecosystems_deleted_at;
+
+ALTER TABLE ecosystems
+  DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,6 @@
+-- Add deleted_at for soft deletes
+ALTER TABLE ecosystems
+  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Create index for filtering live ecosystems
+CREATE INDEX IF NOT EXISTS idx_ecosystems_deleted_at ON ecosystems(deleted_at) WHERE deleted_at IS NULL;
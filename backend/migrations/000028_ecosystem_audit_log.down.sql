@@ -0,0 +1,2 @@
+-- Remove ecosystem audit trail
+DROP TABLE IF EXISTS ecosystem_audit_log;
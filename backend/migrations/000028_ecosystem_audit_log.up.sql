@@ -0,0 +1,12 @@
+-- Audit trail for admin changes to ecosystems.
+-- ecosystem_id is intentionally not a foreign key so history survives hard deletes.
+CREATE TABLE IF NOT EXISTS ecosystem_audit_log (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  ecosystem_id UUID NOT NULL,
+  actor_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete', 'hard_delete', 'restore')),
+  changes JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ecosystem_audit_log_ecosystem ON ecosystem_audit_log(ecosystem_id, created_at DESC);
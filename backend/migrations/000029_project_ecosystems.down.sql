@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS sync_project_primary_ecosystem ON projects;
+DROP FUNCTION IF EXISTS sync_project_primary_ecosystem();
+DROP TABLE IF EXISTS project_ecosystems;
@@ -0,0 +1,38 @@
+-- Projects can belong to several ecosystems (e.g. a cross-chain SDK).
+-- projects.ecosystem_id stays as the project's primary ecosystem and is always
+-- mirrored into this table by the trigger below.
+CREATE TABLE IF NOT EXISTS project_ecosystems (
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  ecosystem_id UUID NOT NULL REFERENCES ecosystems(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, ecosystem_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_ecosystems_ecosystem ON project_ecosystems(ecosystem_id);
+
+-- Backfill from the existing single-ecosystem column.
+INSERT INTO project_ecosystems (project_id, ecosystem_id)
+SELECT id, ecosystem_id FROM projects WHERE ecosystem_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Keep the primary ecosystem associated whenever it is set or changed.
+CREATE OR REPLACE FUNCTION sync_project_primary_ecosystem()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.ecosystem_id IS NOT NULL THEN
+        INSERT INTO project_ecosystems (project_id, ecosystem_id)
+        VALUES (NEW.id, NEW.ecosystem_id)
+        ON CONFLICT DO NOTHING;
+    END IF;
+    IF TG_OP = 'UPDATE' AND OLD.ecosystem_id IS NOT NULL AND OLD.ecosystem_id IS DISTINCT FROM NEW.ecosystem_id THEN
+        DELETE FROM project_ecosystems
+        WHERE project_id = NEW.id AND ecosystem_id = OLD.ecosystem_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER sync_project_primary_ecosystem
+    AFTER INSERT OR UPDATE OF ecosystem_id ON projects
+    FOR EACH ROW
+    EXECUTE FUNCTION sync_project_primary_ecosystem();
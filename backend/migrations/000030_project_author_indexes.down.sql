@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_github_prs_project_author;
+DROP INDEX IF EXISTS idx_github_issues_project_author;
@@ -0,0 +1,8 @@
+-- Per-project author indexes so contributor counts scoped to a set of projects
+-- (e.g. an ecosystem's contributor_count) can be answered with index-only scans.
+
+CREATE INDEX IF NOT EXISTS idx_github_issues_project_author ON github_issues(project_id, author_login)
+WHERE author_login IS NOT NULL AND author_login != '';
+
+CREATE INDEX IF NOT EXISTS idx_github_prs_project_author ON github_pull_requests(project_id, author_login)
+WHERE author_login IS NOT NULL AND author_login != '';
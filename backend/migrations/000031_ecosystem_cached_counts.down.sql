@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS recount_project_ecosystems ON projects;
+DROP FUNCTION IF EXISTS recount_project_ecosystems();
+DROP FUNCTION IF EXISTS recount_ecosystem(UUID);
+ALTER TABLE ecosystems
+  DROP COLUMN IF EXISTS cached_user_count,
+  DROP COLUMN IF EXISTS cached_project_count;
@@ -0,0 +1,47 @@
+-- Ecosystem project/user counts are cached on the row instead of being aggregated
+-- over all projects on every list. The trigger below keeps them current as projects
+-- are created, moved between ecosystems, re-owned or (soft-)deleted; concurrent
+-- changes can still leave them slightly off, which POST /admin/ecosystems/:id/recount
+-- repairs.
+ALTER TABLE ecosystems
+  ADD COLUMN IF NOT EXISTS cached_project_count BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS cached_user_count BIGINT NOT NULL DEFAULT 0;
+
+-- Authoritative counts for one ecosystem: its live projects and their distinct owners.
+CREATE OR REPLACE FUNCTION recount_ecosystem(eco_id UUID)
+RETURNS void AS $$
+BEGIN
+    UPDATE ecosystems e
+    SET cached_project_count = c.project_count,
+        cached_user_count = c.user_count
+    FROM (
+        SELECT COUNT(*) AS project_count, COUNT(DISTINCT owner_user_id) AS user_count
+        FROM projects
+        WHERE ecosystem_id = eco_id AND deleted_at IS NULL
+    ) c
+    WHERE e.id = eco_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Backfill
+SELECT recount_ecosystem(id) FROM ecosystems;
+
+CREATE OR REPLACE FUNCTION recount_project_ecosystems()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' AND OLD.ecosystem_id IS NOT NULL THEN
+        PERFORM recount_ecosystem(OLD.ecosystem_id);
+    END IF;
+    IF TG_OP <> 'DELETE' AND NEW.ecosystem_id IS NOT NULL THEN
+        IF TG_OP = 'INSERT' OR NEW.ecosystem_id IS DISTINCT FROM OLD.ecosystem_id THEN
+            PERFORM recount_ecosystem(NEW.ecosystem_id);
+        END IF;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER recount_project_ecosystems
+    AFTER INSERT OR DELETE OR UPDATE OF ecosystem_id, owner_user_id, deleted_at ON projects
+    FOR EACH ROW
+    EXECUTE FUNCTION recount_project_ecosystems();
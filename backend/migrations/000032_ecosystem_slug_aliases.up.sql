@@ -0,0 +1,10 @@
+-- Slugs follow the ecosystem name, so a rename changes the slug. Every old slug is
+-- kept here so bookmarks and ?ecosystem= links keep resolving to the renamed
+-- ecosystem. A live ecosystem's current slug always wins over an alias.
+CREATE TABLE IF NOT EXISTS ecosystem_slug_aliases (
+  slug TEXT PRIMARY KEY,
+  ecosystem_id UUID NOT NULL REFERENCES ecosystems(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ecosystem_slug_aliases_ecosystem ON ecosystem_slug_aliases(ecosystem_id);
@@ -0,0 +1,10 @@
+-- KYC-approved payout recipients per program. Programs that must only pay approved
+-- addresses check this table before building a payout; removing a row revokes the
+-- approval. Addresses are stored as upper-case G.../C... strkeys.
+CREATE TABLE IF NOT EXISTS payout_recipient_allowlist (
+  program_id TEXT NOT NULL,
+  address TEXT NOT NULL,
+  approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (program_id, address)
+);
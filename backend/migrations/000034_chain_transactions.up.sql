@@ -0,0 +1,16 @@
+-- Contract transactions submitted by the backend (payouts, etc.), recorded through the
+-- soroban result sink. A row is written when a transaction is submitted and updated in
+-- place when it confirms; a confirmed row is never moved back to pending.
+CREATE TABLE IF NOT EXISTS chain_transactions (
+  hash TEXT PRIMARY KEY,
+  function TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL,
+  ledger BIGINT,
+  fee_charged BIGINT,
+  submitted_at TIMESTAMPTZ NOT NULL,
+  confirmed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_chain_transactions_submitted_at ON chain_transactions(submitted_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS leaderboard_snapshot_entries;
+DROP TABLE IF EXISTS leaderboard_snapshots;
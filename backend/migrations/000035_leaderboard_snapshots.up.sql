@@ -0,0 +1,27 @@
+-- Frozen contributor rankings, so a long export or scroll session can page through one
+-- consistent leaderboard (GET /leaderboard?snapshot_id=) while syncs keep changing the
+-- live data. filter_key records the filters the ranking was taken with. Entries hold the
+-- ranked rows as the live leaderboard query returned them.
+CREATE TABLE IF NOT EXISTS leaderboard_snapshots (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  filter_key TEXT NOT NULL,
+  contributors INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_leaderboard_snapshots_created_at ON leaderboard_snapshots(created_at);
+
+CREATE TABLE IF NOT EXISTS leaderboard_snapshot_entries (
+  snapshot_id UUID NOT NULL REFERENCES leaderboard_snapshots(id) ON DELETE CASCADE,
+  username TEXT NOT NULL,
+  avatar_url TEXT NOT NULL DEFAULT '',
+  user_id TEXT NOT NULL DEFAULT '',
+  contribution_count INT NOT NULL,
+  ecosystems TEXT[],
+  issues INT NOT NULL,
+  merged_pull_requests INT NOT NULL,
+  recent_contributions INT NOT NULL,
+  first_contribution_at TIMESTAMPTZ,
+  global_rank INT NOT NULL,
+  PRIMARY KEY (snapshot_id, global_rank, username)
+);
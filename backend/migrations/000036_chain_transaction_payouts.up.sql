@@ -0,0 +1,10 @@
+-- The payouts a recorded program escrow transaction requested, decoded from its
+-- envelope by the soroban result sink, in the order they appear in the transaction.
+-- Payout reconciliation matches them against the contract's payout events.
+CREATE TABLE IF NOT EXISTS chain_transaction_payouts (
+  hash TEXT NOT NULL REFERENCES chain_transactions(hash) ON DELETE CASCADE,
+  position INT NOT NULL,
+  recipient TEXT NOT NULL,
+  amount BIGINT NOT NULL,
+  PRIMARY KEY (hash, position)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE leaderboard_snapshot_entries
+  DROP COLUMN IF EXISTS opened_pull_requests;
@@ -0,0 +1,4 @@
+-- PRs opened by each snapshotted contributor, whatever the snapshot's PR state filter,
+-- so snapshot rows report prs_opened like live ones.
+ALTER TABLE leaderboard_snapshot_entries
+  ADD COLUMN IF NOT EXISTS opened_pull_requests INT NOT NULL DEFAULT 0;
@@ -579,8 +579,8 @@ export function AdminPage() {
       setErrorMessage(null);
       await updateEcosystem(editingEcosystem.id, {
         name: editFormData.name,
-        description: editFormData.description || undefined,
-        website_url: editFormData.websiteUrl || undefined,
+        description: editFormData.description,
+        website_url: editFormData.websiteUrl,
         status: editFormData.status as 'active' | 'inactive',
       });
 
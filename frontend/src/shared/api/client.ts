@@ -606,26 +606,18 @@ export const deleteEcosystem = (id: string) =>
     method: 'DELETE',
   });
 
+// Partial update: omitted fields are left unchanged, empty strings clear them.
 export const updateEcosystem = (id: string, data: {
-  name: string;
+  name?: string;
   description?: string;
   website_url?: string;
-  status: 'active' | 'inactive';
+  status?: 'active' | 'inactive';
 }) =>
   apiRequest<{
-    id: string;
-    slug: string;
-    name: string;
-    description: string;
-    website_url: string;
-    status: string;
-    project_count: number;
-    user_count: number;
-    created_at: string;
-    updated_at: string;
+    ok: boolean;
   }>(`/admin/ecosystems/${id}`, {
     requiresAuth: true,
-    method: 'PUT',
+    method: 'PATCH',
     body: JSON.stringify(data),
   });
 